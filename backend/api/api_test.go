@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func testContext(method, target, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func TestLanguageFollowsAcceptLanguage(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                         LangTH,
+		"en":                       LangEN,
+		"en-US,en;q=0.9":           LangEN,
+		"th-TH,th;q=0.9,en;q=0.8":  LangTH,
+		"fr;q=1,en;q=0.5,th;q=0.4": LangEN,
+		"de":                       LangTH,
+	} {
+		c, _ := testContext(http.MethodGet, "/", "")
+		c.Request.Header.Set("Accept-Language", header)
+		if got := Language(c); got != want {
+			t.Errorf("Accept-Language %q = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestFailWritesLocalizedBodyWithFieldDetails(t *testing.T) {
+	var in struct {
+		Email string `json:"email" binding:"required,email"`
+		Qty   int    `json:"qty" binding:"min=1"`
+	}
+	c, w := testContext(http.MethodPost, "/", `{"email":"nope","qty":0}`)
+	c.Request.Header.Set("Accept-Language", "en")
+	if BindJSON(c, &in) {
+		t.Fatal("invalid body was accepted")
+	}
+	if w.Code != http.StatusBadRequest || !c.IsAborted() {
+		t.Fatalf("status = %d, aborted = %v", w.Code, c.IsAborted())
+	}
+	var body struct {
+		Error   string       `json:"error"`
+		Code    string       `json:"code"`
+		Details []FieldError `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != CodeValidation || body.Error != "Validation failed" || len(body.Details) != 2 {
+		t.Fatalf("body = %s", w.Body)
+	}
+	if d := body.Details[1]; d.Field != "qty" || d.Code != "min" || d.Message != "must be at least 1" {
+		t.Errorf("detail = %+v", d)
+	}
+}
+
+func TestInternalErrorHidesCause(t *testing.T) {
+	c, w := testContext(http.MethodGet, "/", "")
+	Fail(c, InternalMsg("บันทึกไม่สำเร็จ", errSecret))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), errSecret.Error()) {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body)
+	}
+	if len(c.Errors) != 1 {
+		t.Error("cause was not recorded on the context")
+	}
+}
+
+var errSecret = errors.New("dial tcp 10.0.0.5:5432: password authentication failed")
+
+func TestUnpagedListIsCappedAtMaxPageSize(t *testing.T) {
+	items := make([]int, MaxPageSize+50)
+	c, w := testContext(http.MethodGet, "/items", "")
+	ListAll(c, items)
+	var body struct {
+		Data []int    `json:"data"`
+		Meta PageMeta `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := PageMeta{Page: 1, PageSize: MaxPageSize, Total: int64(len(items)), TotalPages: 2}
+	if len(body.Data) != MaxPageSize || body.Meta != want {
+		t.Errorf("items = %d, meta = %+v, want %d items and %+v", len(body.Data), body.Meta, MaxPageSize, want)
+	}
+}
+
+func TestListAllPaginatesSlice(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	cases := []struct {
+		query string
+		code  int
+		data  int
+		meta  PageMeta
+	}{
+		{"", http.StatusOK, 5, PageMeta{Page: 1, PageSize: MaxPageSize, Total: 5, TotalPages: 1}},
+		{"?page=2&pageSize=2", http.StatusOK, 2, PageMeta{Page: 2, PageSize: 2, Total: 5, TotalPages: 3}},
+		{"?page=9&pageSize=2", http.StatusOK, 0, PageMeta{Page: 9, PageSize: 2, Total: 5, TotalPages: 3}},
+		{"?pageSize=999", http.StatusBadRequest, 0, PageMeta{}},
+		{"?page=0", http.StatusBadRequest, 0, PageMeta{}},
+	}
+	for _, tc := range cases {
+		c, w := testContext(http.MethodGet, "/items"+tc.query, "")
+		ListAll(c, items, gin.H{"note": "x"})
+		if w.Code != tc.code {
+			t.Errorf("%s: status = %d, body = %s", tc.query, w.Code, w.Body)
+			continue
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		var body struct {
+			Data []int    `json:"data"`
+			Meta PageMeta `json:"meta"`
+			Note string   `json:"note"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data) != tc.data || body.Meta != tc.meta || body.Note != "x" {
+			t.Errorf("%s: body = %s", tc.query, w.Body)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ======================================================
+// แปลง error จากการ bind/validate (ShouldBindJSON, ShouldBindQuery ...) เป็น Error พร้อมรายช่อง
+// ชื่อช่องใช้ตาม tag json/form ให้ตรงกับที่ผู้เรียกส่งมา
+// ======================================================
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form", "uri"} {
+				name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
+
+// BindJSON bind body แล้วตอบ 400 ให้เองถ้าไม่ผ่าน (คืน false = ตอบไปแล้ว)
+func BindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		Fail(c, BindError(err))
+		return false
+	}
+	return true
+}
+
+// BindQuery เหมือน BindJSON สำหรับ query string
+func BindQuery(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		Fail(c, BindError(err))
+		return false
+	}
+	return true
+}
+
+// ParamID อ่าน id จาก path (เช่น :id) ไม่ใช่ตัวเลขตอบ 400 invalid_id ให้เอง (คืน false = ตอบไปแล้ว)
+func ParamID(c *gin.Context, name string) (uint, bool) {
+	n, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		Fail(c, InvalidID(name))
+		return 0, false
+	}
+	return uint(n), true
+}
+
+// BindError แปลง error ของ binding เป็น Error (validation_failed / invalid_json)
+func BindError(err error) *Error {
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		e := NewError(http.StatusBadRequest, CodeValidation).WithCause(err)
+		for _, fe := range ve {
+			e.Details = append(e.Details, FieldError{Field: fieldPath(fe), Code: fe.Tag(), param: fe.Param()})
+		}
+		return e
+	}
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		return NewError(http.StatusBadRequest, CodeValidation).WithCause(err).
+			WithDetails(FieldError{Field: te.Field, Code: "type", param: te.Type.String()})
+	}
+	var se *json.SyntaxError
+	if errors.As(err, &se) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return NewError(http.StatusBadRequest, CodeInvalidJSON).WithCause(err)
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return NewError(http.StatusRequestEntityTooLarge, "").WithCause(err)
+	}
+	return NewError(http.StatusBadRequest, CodeValidation).WithCause(err).MsgEN(err.Error())
+}
+
+// Validation ข้อมูลไม่ผ่านการตรวจ (ข้อความจาก validate() ของ xxxIn)
+func Validation(th string) *Error {
+	return NewError(http.StatusBadRequest, CodeValidation).Msg(th)
+}
+
+// Invalid error validation ของช่องเดียว (ตรวจเองใน handler)
+func Invalid(field, code, th string) *Error {
+	return NewError(http.StatusBadRequest, CodeValidation).Msg(th).
+		WithDetails(FieldError{Field: field, Code: code, Message: th})
+}
+
+// RuleError ข้อผิดพลาดรายช่องตามกฎใน ruleMessages (ใช้กับตัวตรวจอื่นที่ไม่ใช่ validator เช่น OpenAPI)
+func RuleError(field, rule, param string) FieldError {
+	return FieldError{Field: field, Code: rule, param: param}
+}
+
+// ชื่อช่องแบบมี path (เช่น items[0].qty) ตัดชื่อ struct ด้านนอกสุดทิ้ง
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+// ข้อความของกฎ validation ที่พบบ่อย (%s = param ของกฎ)
+var ruleMessages = map[string]message{
+	"required": {"จำเป็นต้องระบุ", "is required"},
+	"email":    {"รูปแบบอีเมลไม่ถูกต้อง", "must be a valid email"},
+	"min":      {"ต้องไม่น้อยกว่า %s", "must be at least %s"},
+	"max":      {"ต้องไม่เกิน %s", "must be at most %s"},
+	"len":      {"ต้องมีความยาว %s", "must have length %s"},
+	"gt":       {"ต้องมากกว่า %s", "must be greater than %s"},
+	"gte":      {"ต้องไม่น้อยกว่า %s", "must be at least %s"},
+	"lt":       {"ต้องน้อยกว่า %s", "must be less than %s"},
+	"lte":      {"ต้องไม่เกิน %s", "must be at most %s"},
+	"oneof":    {"ต้องเป็นค่าใดค่าหนึ่งใน: %s", "must be one of: %s"},
+	"number":   {"ต้องเป็นตัวเลข", "must be a number"},
+	"numeric":  {"ต้องเป็นตัวเลข", "must be numeric"},
+	"url":      {"รูปแบบ URL ไม่ถูกต้อง", "must be a valid URL"},
+	"datetime": {"รูปแบบวันเวลาไม่ถูกต้อง (%s)", "must be a datetime (%s)"},
+	"type":     {"ชนิดข้อมูลไม่ถูกต้อง (ต้องเป็น %s)", "has wrong type (expected %s)"},
+	"date":     {"รูปแบบวันที่ไม่ถูกต้อง (YYYY-MM-DD)", "must be a date (YYYY-MM-DD)"},
+}
+
+func localizeDetails(details []FieldError, lang string) []FieldError {
+	out := make([]FieldError, len(details))
+	for i, d := range details {
+		out[i] = d
+		m, ok := ruleMessages[d.Code]
+		// ไม่รู้จักกฎ หรือกฎต้องใช้ param แต่ไม่มี: ใช้ข้อความที่ handler ตั้งไว้
+		if !ok || (strings.Contains(m.th, "%s") && d.param == "") {
+			if d.Message == "" {
+				out[i].Message = catalogMessage(CodeValidation, http.StatusBadRequest, lang)
+			}
+			continue
+		}
+		if d.Message != "" && lang == LangTH {
+			continue
+		}
+		text := m.th
+		if lang == LangEN {
+			text = m.en
+		}
+		if strings.Contains(text, "%s") {
+			text = fmt.Sprintf(text, strings.ReplaceAll(d.param, " ", ", "))
+		}
+		out[i].Message = text
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// รูปแบบ error ของ API (ทุก controller/middleware ตอบแบบนี้)
+//   {
+//     "error":     "ข้อความตามภาษา (Accept-Language: th ค่าเริ่มต้น / en)",
+//     "code":      "order_not_found",          // ให้โปรแกรมตรวจ (ไม่เปลี่ยนตามภาษา)
+//     "details":   [{"field": "email", "code": "required", "message": "..."}],  // เฉพาะ validation
+//     "requestId": "..."                      // ใช้ตามรอยใน log
+//   }
+// - "error" ยังเป็นข้อความเหมือนเดิม หน้าเว็บที่แสดง response.data.error ใช้ต่อได้
+// - 5xx ไม่ส่งรายละเอียดภายใน (เช่น error ของฐานข้อมูล) ออกไป แต่ log ไว้พร้อม request id
+// ======================================================
+
+// Error error ของ API ที่รู้ status/code/ข้อความ
+type Error struct {
+	Status  int
+	Code    string
+	TH, EN  string         // ว่าง = ใช้ข้อความตาม code/status จาก catalog
+	Details []FieldError   // ข้อผิดพลาดรายช่อง (validation)
+	Extra   map[string]any // ค่าเพิ่มเติมใน body เช่น retryAfter
+	Cause   error          // สาเหตุภายใน (log เท่านั้น)
+}
+
+// FieldError ข้อผิดพลาดของช่องข้อมูลหนึ่งช่อง
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	param string // ค่าของกฎ validation (เช่น min=8) ใช้ประกอบข้อความ
+}
+
+func (e *Error) Error() string {
+	msg := e.EN
+	if msg == "" {
+		msg = e.TH
+	}
+	if msg == "" {
+		msg = e.Code
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", msg, e.Cause)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// NewError สร้าง error ตาม status + code (code ว่าง = code มาตรฐานของ status)
+func NewError(status int, code string) *Error {
+	if code == "" {
+		code = statusCode(status)
+	}
+	return &Error{Status: status, Code: code}
+}
+
+func (e *Error) clone() *Error {
+	cp := *e
+	return &cp
+}
+
+// Msg ข้อความภาษาไทย (ภาษาอังกฤษใช้ของ catalog ถ้าไม่ได้ตั้ง MsgEN)
+func (e *Error) Msg(th string) *Error {
+	cp := e.clone()
+	cp.TH = th
+	return cp
+}
+
+// Msgf Msg แบบ format
+func (e *Error) Msgf(format string, args ...any) *Error {
+	return e.Msg(fmt.Sprintf(format, args...))
+}
+
+// MsgEN ข้อความภาษาอังกฤษ
+func (e *Error) MsgEN(en string) *Error {
+	cp := e.clone()
+	cp.EN = en
+	return cp
+}
+
+// WithCause แนบสาเหตุภายใน (log เท่านั้น ไม่ส่งให้ผู้เรียก)
+func (e *Error) WithCause(err error) *Error {
+	cp := e.clone()
+	cp.Cause = err
+	return cp
+}
+
+// WithDetails แนบข้อผิดพลาดรายช่อง
+func (e *Error) WithDetails(d ...FieldError) *Error {
+	cp := e.clone()
+	cp.Details = append(append([]FieldError(nil), e.Details...), d...)
+	return cp
+}
+
+// With ค่าเพิ่มเติมใน body
+func (e *Error) With(key string, value any) *Error {
+	cp := e.clone()
+	cp.Extra = make(map[string]any, len(e.Extra)+1)
+	for k, v := range e.Extra {
+		cp.Extra[k] = v
+	}
+	cp.Extra[key] = value
+	return cp
+}
+
+// ทางลัดตาม status (code ว่าง = code มาตรฐาน, th ว่าง = ข้อความมาตรฐาน)
+func BadRequest(code, th string) *Error   { return NewError(http.StatusBadRequest, code).Msg(th) }
+func Unauthorized(code, th string) *Error { return NewError(http.StatusUnauthorized, code).Msg(th) }
+func Forbidden(code, th string) *Error    { return NewError(http.StatusForbidden, code).Msg(th) }
+func NotFound(code, th string) *Error     { return NewError(http.StatusNotFound, code).Msg(th) }
+func Conflict(code, th string) *Error     { return NewError(http.StatusConflict, code).Msg(th) }
+
+// Internal error ภายใน: ผู้เรียกเห็นข้อความทั่วไป สาเหตุไป log
+func Internal(cause error) *Error {
+	return NewError(http.StatusInternalServerError, CodeInternal).WithCause(cause)
+}
+
+// InternalMsg เหมือน Internal แต่บอกว่าขั้นตอนไหนล้มเหลว (ข้อความนี้ส่งให้ผู้เรียก)
+func InternalMsg(th string, cause error) *Error {
+	return Internal(cause).Msg(th)
+}
+
+// InvalidID พารามิเตอร์ id ใน path ไม่ใช่ตัวเลข
+func InvalidID(param string) *Error {
+	return NewError(http.StatusBadRequest, CodeInvalidID).
+		WithDetails(FieldError{Field: param, Code: "number", Message: "ต้องเป็นตัวเลข"})
+}
+
+// Fail ตอบ error แล้วหยุด chain; err ที่ไม่ใช่ *Error ถือเป็น internal error
+func Fail(c *gin.Context, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Internal(err)
+	}
+	lang := Language(c)
+	body := gin.H{}
+	for k, v := range e.Extra {
+		body[k] = v
+	}
+	body["error"] = e.message(lang)
+	body["code"] = e.Code
+	if len(e.Details) > 0 {
+		body["details"] = localizeDetails(e.Details, lang)
+	}
+	if id := services.RequestIDFrom(c.Request.Context()); id != "" {
+		body["requestId"] = id
+	}
+
+	if e.Cause != nil {
+		_ = c.Error(e.Cause) // ให้ access log เห็นสาเหตุ
+	}
+	if e.Status >= http.StatusInternalServerError && e.Status != http.StatusServiceUnavailable {
+		slog.ErrorContext(c.Request.Context(), "request failed",
+			"code", e.Code, "status", e.Status, "path", c.Request.URL.Path, "error", e.Error())
+	}
+	c.AbortWithStatusJSON(e.Status, body)
+}
+
+func (e *Error) message(lang string) string {
+	if lang == LangEN {
+		if e.EN != "" {
+			return e.EN
+		}
+		return catalogMessage(e.Code, e.Status, LangEN)
+	}
+	if e.TH != "" {
+		return e.TH
+	}
+	return catalogMessage(e.Code, e.Status, LangTH)
+}
+
+// ภาษาที่รองรับ
+const (
+	LangTH = "th"
+	LangEN = "en"
+)
+
+// Language เลือกภาษาจาก Accept-Language (ตามลำดับ q) ไม่รู้จัก = ไทย
+func Language(c *gin.Context) string {
+	best, bestQ := LangTH, -1.0
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, q := parseLangPart(part)
+		if tag != LangTH && tag != LangEN {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+func parseLangPart(part string) (string, float64) {
+	fields := strings.Split(strings.TrimSpace(part), ";")
+	tag := strings.ToLower(strings.TrimSpace(fields[0]))
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		tag = tag[:i]
+	}
+	q := 1.0
+	for _, f := range fields[1:] {
+		f = strings.TrimSpace(f)
+		if strings.HasPrefix(f, "q=") {
+			if _, err := fmt.Sscanf(f[2:], "%g", &q); err != nil {
+				q = 0
+			}
+		}
+	}
+	return tag, q
+}
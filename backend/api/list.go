@@ -0,0 +1,154 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// รูปแบบรายการ (ทุก endpoint ที่คืนรายการ)
+//   {"data": [...], "meta": {"page": 1, "pageSize": 20, "total": 135, "totalPages": 7}, ...ค่าประกอบอื่น เช่น "range"}
+// - ?page=&pageSize= (pageSize สูงสุด MaxPageSize)
+// - ไม่ส่ง pageSize = ขนาดค่าเริ่มต้นของ endpoint (PageFromDefault) หรือ MaxPageSize
+//   ไม่มีการตอบทั้งตารางในคำขอเดียว ผู้เรียกดู meta.totalPages แล้วขอหน้าถัดไปเอง
+// - รายการจากตารางใช้ ListSpec (query.go) กรอง/เรียง/ค้นหาแล้วแบ่งหน้าในฐานข้อมูล
+//   ListAll ใช้กับรายการที่คำนวณ/รวมผลในโปรแกรมเท่านั้น (รายงาน, timeline)
+// ======================================================
+
+const MaxPageSize = 200
+
+// Page หน้าที่ขอ (Size 0 = ทั้งหมด ใช้ภายในโปรแกรมเท่านั้น คำขอจาก HTTP มีขนาดเสมอ)
+type Page struct {
+	Number int
+	Size   int
+}
+
+// PageMeta ข้อมูลการแบ่งหน้าที่ตอบกลับ
+type PageMeta struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"pageSize"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+// PageFrom อ่าน ?page=&pageSize= (ไม่ส่ง pageSize = MaxPageSize)
+func PageFrom(c *gin.Context) (Page, *Error) {
+	return PageFromDefault(c, 0)
+}
+
+// PageFromDefault เหมือน PageFrom แต่ใช้ defaultSize เมื่อไม่ส่ง pageSize (0 = MaxPageSize)
+func PageFromDefault(c *gin.Context, defaultSize int) (Page, *Error) {
+	p := Page{Number: 1, Size: defaultSize}
+	rawPage, rawSize := strings.TrimSpace(c.Query("page")), strings.TrimSpace(c.Query("pageSize"))
+	if rawPage != "" {
+		n, err := strconv.Atoi(rawPage)
+		if err != nil || n < 1 {
+			return p, Invalid("page", "min", "page ต้องเป็นจำนวนเต็มตั้งแต่ 1")
+		}
+		p.Number = n
+	}
+	if rawSize != "" {
+		n, err := strconv.Atoi(rawSize)
+		if err != nil || n < 1 || n > MaxPageSize {
+			return p, Invalid("pageSize", "max", "pageSize ต้องอยู่ระหว่าง 1-"+strconv.Itoa(MaxPageSize))
+		}
+		p.Size = n
+	} else if rawPage != "" && p.Size == 0 {
+		p.Size = 20 // ขอหน้าแต่ไม่บอกขนาด
+	} else if p.Size == 0 {
+		p.Size = MaxPageSize
+	}
+	return p, nil
+}
+
+func (p Page) meta(total int64) PageMeta {
+	m := PageMeta{Page: p.Number, PageSize: p.Size, Total: total, TotalPages: 1}
+	if p.Size == 0 {
+		m.Page, m.PageSize = 1, int(total)
+		return m
+	}
+	m.TotalPages = int((total + int64(p.Size) - 1) / int64(p.Size))
+	if m.TotalPages == 0 {
+		m.TotalPages = 1
+	}
+	return m
+}
+
+// Paginate นับทั้งหมดแล้วดึงเฉพาะหน้าที่ขอจาก query ลง dest
+// (query ควรมี Order เพื่อให้แต่ละหน้าคงที่)
+func Paginate(query *gorm.DB, p Page, dest any) (PageMeta, error) {
+	if query.Statement.Model == nil && query.Statement.Table == "" {
+		query = query.Model(dest) // Count ต้องรู้ตาราง
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return PageMeta{}, err
+	}
+	q := query
+	if p.Size > 0 {
+		q = q.Offset((p.Number - 1) * p.Size).Limit(p.Size)
+	}
+	if err := q.Find(dest).Error; err != nil {
+		return PageMeta{}, err
+	}
+	return p.meta(total), nil
+}
+
+// PageSlice ตัดหน้าจาก slice ที่ประกอบในโปรแกรมแล้ว (รายการที่คำนวณ/รวมผลเอง)
+func PageSlice(items any, p Page) (any, PageMeta) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return items, p.meta(1)
+	}
+	total := v.Len()
+	if p.Size == 0 {
+		return items, p.meta(int64(total))
+	}
+	start := (p.Number - 1) * p.Size
+	if start > total {
+		start = total
+	}
+	end := start + p.Size
+	if end > total {
+		end = total
+	}
+	return v.Slice(start, end).Interface(), p.meta(int64(total))
+}
+
+// List ตอบรายการที่แบ่งหน้าแล้ว; extra = ค่าประกอบระดับบนสุด (ห้ามใช้ชื่อ data/meta)
+func List(c *gin.Context, items any, meta PageMeta, extra ...gin.H) {
+	body := gin.H{}
+	for _, e := range extra {
+		for k, v := range e {
+			body[k] = v
+		}
+	}
+	body["data"] = nonNil(items)
+	body["meta"] = meta
+	c.JSON(http.StatusOK, body)
+}
+
+// ListAll ตอบรายการจาก slice ในโปรแกรม (แบ่งหน้าตาม ?page=&pageSize= ถ้าส่งมา)
+func ListAll(c *gin.Context, items any, extra ...gin.H) {
+	p, perr := PageFrom(c)
+	if perr != nil {
+		Fail(c, perr)
+		return
+	}
+	data, meta := PageSlice(items, p)
+	List(c, data, meta, extra...)
+}
+
+// slice nil -> [] (ให้ JSON เป็น [] ไม่ใช่ null)
+func nonNil(items any) any {
+	v := reflect.ValueOf(items)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return items
+}
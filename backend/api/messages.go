@@ -0,0 +1,142 @@
+package api
+
+import "net/http"
+
+// code มาตรฐานตาม status (ใช้เมื่อไม่มี code เฉพาะ)
+const (
+	CodeBadRequest     = "bad_request"
+	CodeValidation     = "validation_failed"
+	CodeInvalidJSON    = "invalid_json"
+	CodeInvalidID      = "invalid_id"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+	CodeTooLarge       = "payload_too_large"
+	CodeUnprocessable  = "unprocessable_entity"
+	CodeLocked         = "locked"
+	CodeTooManyRequest = "too_many_requests"
+	CodeInternal       = "internal_error"
+	CodeUnavailable    = "service_unavailable"
+)
+
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusLocked:
+		return CodeLocked
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequest
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+type message struct{ th, en string }
+
+// ข้อความของแต่ละ code (code ที่ไม่อยู่ในนี้ใช้ข้อความของ code มาตรฐานตาม status)
+var catalog = map[string]message{
+	CodeBadRequest:     {"คำขอไม่ถูกต้อง", "Bad request"},
+	CodeValidation:     {"ข้อมูลไม่ถูกต้อง", "Validation failed"},
+	CodeInvalidJSON:    {"รูปแบบข้อมูล JSON ไม่ถูกต้อง", "Malformed JSON body"},
+	CodeInvalidID:      {"รหัสอ้างอิงไม่ถูกต้อง", "Invalid id"},
+	CodeUnauthorized:   {"กรุณาเข้าสู่ระบบ", "Authentication required"},
+	CodeForbidden:      {"ไม่มีสิทธิ์ทำรายการนี้", "Forbidden"},
+	CodeNotFound:       {"ไม่พบข้อมูล", "Not found"},
+	CodeConflict:       {"ข้อมูลขัดแย้งกับสถานะปัจจุบัน", "Conflict with current state"},
+	CodeTooLarge:       {"ข้อมูลมีขนาดใหญ่เกินกำหนด", "Payload too large"},
+	CodeUnprocessable:  {"ไม่สามารถดำเนินการกับข้อมูลนี้ได้", "Unprocessable entity"},
+	CodeLocked:         {"บัญชีถูกล็อกชั่วคราว", "Locked"},
+	CodeTooManyRequest: {"คำขอมากเกินไป กรุณารอสักครู่", "Too many requests, please retry later"},
+	CodeInternal:       {"เกิดข้อผิดพลาดภายในระบบ", "Internal server error"},
+	CodeUnavailable:    {"ระบบไม่พร้อมให้บริการชั่วคราว", "Service temporarily unavailable"},
+
+	// ยืนยันตัวตน
+	"missing_token":         {"ไม่พบ token กรุณาเข้าสู่ระบบ", "Missing bearer token"},
+	"invalid_token":         {"token ไม่ถูกต้องหรือหมดอายุ", "Invalid or expired token"},
+	"invalid_credentials":   {"อีเมลหรือรหัสผ่านไม่ถูกต้อง", "Invalid email or password"},
+	"account_locked":        {"บัญชีถูกล็อกชั่วคราว กรุณาลองใหม่ภายหลัง", "Account temporarily locked"},
+	"invalid_customer":      {"ไม่พบข้อมูลลูกค้าของ token นี้", "Token is not bound to a customer"},
+	"employee_only":         {"เฉพาะพนักงานเท่านั้น", "Employees only"},
+	"supervisor_only":       {"เฉพาะหัวหน้างานหรือผู้ดูแลระบบเท่านั้น", "Supervisors or admins only"},
+	"server_not_configured": {"ระบบยังตั้งค่าไม่ครบ", "Server is not configured"},
+	"registration_disabled": {"ปิดการสมัครสมาชิกด้วยตนเอง", "Self registration is disabled"},
+	"password_policy":       {"รหัสผ่านไม่ผ่านเงื่อนไข", "Password does not meet the policy"},
+	"password_unchanged":    {"รหัสผ่านใหม่ต้องไม่ซ้ำกับรหัสผ่านเดิม", "New password must differ from the current one"},
+	"reset_token_invalid":   {"ลิงก์รีเซ็ตรหัสผ่านไม่ถูกต้องหรือหมดอายุแล้ว", "Reset link is invalid or expired"},
+
+	// ออเดอร์/ชำระเงิน/ใบเสร็จ
+	"invalid_order_id":             {"รหัสออเดอร์ไม่ถูกต้อง", "Invalid order id"},
+	"order_not_found":              {"ไม่พบออเดอร์", "Order not found"},
+	"no_orders":                    {"ยังไม่มีออเดอร์", "No orders yet"},
+	"order_not_paid":               {"ออเดอร์ยังไม่ได้ชำระเงิน", "Order has not been paid"},
+	"payment_not_found":            {"ไม่พบการชำระเงิน", "Payment not found"},
+	"payment_already_paid":         {"ออเดอร์นี้ชำระเงินแล้ว", "Order is already paid"},
+	"cannot_create_payment":        {"สร้างรายการชำระเงินไม่สำเร็จ", "Could not create payment"},
+	"save_payment_failed":          {"บันทึกการชำระเงินไม่สำเร็จ", "Could not save payment"},
+	"slip_verification_disabled":   {"ปิดการตรวจสลิปอัตโนมัติ", "Slip verification is disabled"},
+	"empty_image":                  {"ไม่พบรูปสลิป", "Slip image is empty"},
+	"image_too_large":              {"รูปสลิปมีขนาดใหญ่เกินกำหนด", "Slip image is too large"},
+	"amount_mismatch":              {"ยอดเงินในสลิปไม่ตรงกับยอดที่ต้องชำระ", "Slip amount does not match"},
+	"duplicate_slip":               {"สลิปนี้ถูกใช้แล้ว", "Slip has already been used"},
+	"easyslip_unreachable":         {"เชื่อมต่อระบบตรวจสลิปไม่ได้", "Slip verification service unreachable"},
+	"easyslip_bad_response":        {"ระบบตรวจสลิปตอบกลับไม่ถูกต้อง", "Invalid response from slip verification service"},
+	"easyslip_verify_failed":       {"ตรวจสอบสลิปไม่ผ่าน", "Slip verification failed"},
+	"easyslip_application_expired": {"บริการตรวจสลิปหมดอายุ", "Slip verification application expired"},
+	"receipt_not_found":            {"ไม่พบใบเสร็จ", "Receipt not found"},
+	"receipt_already_cancelled":    {"ใบเสร็จถูกยกเลิกแล้ว", "Receipt is already cancelled"},
+	"issue_receipt_failed":         {"ออกใบเสร็จไม่สำเร็จ", "Could not issue receipt"},
+	"reissue_receipt_failed":       {"ออกใบเสร็จใหม่ไม่สำเร็จ", "Could not reissue receipt"},
+	"cancel_receipt_failed":        {"ยกเลิกใบเสร็จไม่สำเร็จ", "Could not cancel receipt"},
+	"load_receipt_failed":          {"โหลดใบเสร็จไม่สำเร็จ", "Could not load receipt"},
+	"render_receipt_failed":        {"สร้างไฟล์ใบเสร็จไม่สำเร็จ", "Could not render receipt"},
+	"reason_required":              {"กรุณาระบุเหตุผล", "Reason is required"},
+
+	// ลิ้นชักเงินสด/ปิดยอด
+	"day_closed":                {"ปิดยอดของวันนั้นแล้ว แก้ไขไม่ได้", "Business day is already closed"},
+	"invalid_date":              {"วันที่ไม่ถูกต้อง (YYYY-MM-DD)", "Invalid date (YYYY-MM-DD)"},
+	"future_date":               {"ปิดยอดล่วงหน้าไม่ได้", "Cannot close a future business day"},
+	"invalid_opening_float":     {"เงินทอนตั้งต้นไม่ถูกต้อง", "Invalid opening float"},
+	"invalid_counted_amount":    {"ยอดเงินที่นับได้ไม่ถูกต้อง", "Invalid counted amount"},
+	"cash_session_already_open": {"มีรอบลิ้นชักที่เปิดอยู่แล้ว", "A cash session is already open"},
+	"cash_session_closed":       {"รอบลิ้นชักนี้ปิดแล้ว", "Cash session is closed"},
+	"cash_session_not_found":    {"ไม่พบรอบลิ้นชัก", "Cash session not found"},
+	"no_open_cash_session":      {"ยังไม่มีรอบลิ้นชักที่เปิดอยู่", "No open cash session"},
+	"open_cash_sessions":        {"ยังมีรอบลิ้นชักที่เปิดอยู่", "Cash sessions are still open"},
+	"not_session_owner":         {"ไม่ใช่ผู้เปิดรอบลิ้นชักนี้", "Not the owner of this cash session"},
+
+	// อื่น ๆ
+	"route_not_found": {"ไม่พบ endpoint ที่เรียก", "No such endpoint"},
+	"shutting_down":   {"ระบบกำลังปิดปรับปรุง", "Server is shutting down"},
+	"unknown_dataset": {"ไม่รู้จักชุดข้อมูลที่ขอ", "Unknown dataset"},
+	"db_error":        {"เกิดข้อผิดพลาดกับฐานข้อมูล", "Database error"},
+	"duplicate":       {"ข้อมูลนี้มีอยู่แล้ว", "Already exists"},
+}
+
+func catalogMessage(code string, status int, lang string) string {
+	m, ok := catalog[code]
+	if !ok {
+		m = catalog[statusCode(status)]
+	}
+	if lang == LangEN {
+		return m.en
+	}
+	return m.th
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ค้นหา/กรอง/เรียงรายการจาก query string (ใช้คู่กับ Paginate)
+//   ?q=สมชาย&status=waiting,done&from=2025-01-01&to=2025-01-31&sort=-createdAt,name&page=2&pageSize=20
+// - filter/sort รับเฉพาะชื่อที่อยู่ใน ListSpec (คอลัมน์ใน SQL มาจากโค้ดเท่านั้น ไม่ใช่จากผู้เรียก)
+// - sort ที่ไม่รู้จัก / ค่า filter ผิดรูปแบบ = 400 พร้อม details
+// - q แยกคำด้วยช่องว่าง ทุกคำต้องพบในคอลัมน์ Search อย่างน้อยหนึ่งคอลัมน์ (ไม่แยกตัวพิมพ์)
+// - ปิดท้ายลำดับด้วย primary key เสมอ ให้แต่ละหน้าคงที่
+// - page/pageSize ตาม PageFromDefault(DefaultPageSize)
+// ======================================================
+
+// FilterOp วิธีเทียบค่าของ filter
+type FilterOp int
+
+const (
+	OpEq      FilterOp = iota // = (หลายค่าคั่นด้วย , = IN; "all" = ไม่กรอง)
+	OpID                      // เหมือน OpEq แต่ทุกค่าต้องเป็นตัวเลข
+	OpLike                    // มีข้อความนี้อยู่ (ไม่แยกตัวพิมพ์)
+	OpBool                    // true/false/1/0
+	OpNull                    // true = IS NULL, false = IS NOT NULL
+	OpFrom                    // ตั้งแต่วันที่ (YYYY-MM-DD ตามเวลาร้าน)
+	OpTo                      // ถึงวันที่ (รวมทั้งวัน)
+	OpDayFrom                 // เหมือน OpFrom แต่คอลัมน์เก็บวันที่เป็นข้อความ YYYY-MM-DD
+	OpDayTo                   // เหมือน OpTo แต่คอลัมน์เก็บวันที่เป็นข้อความ YYYY-MM-DD
+)
+
+// Filter filter หนึ่งตัว: ?<ชื่อ>=ค่า -> Column <Op> ค่า
+type Filter struct {
+	Column string
+	Op     FilterOp
+}
+
+// ListSpec กติกาการค้นหา/กรอง/เรียงของ endpoint รายการหนึ่ง
+type ListSpec struct {
+	Filters         map[string]Filter // ชื่อ query param -> คอลัมน์
+	Sorts           map[string]string // ชื่อใน ?sort= -> คอลัมน์ (นำหน้าด้วย - = มากไปน้อย)
+	Presets         map[string]string // ชื่อใน ?sort= -> ORDER BY สำเร็จรูป (เช่น breach)
+	DefaultSort     string            // เมื่อไม่ส่ง sort เช่น "-createdAt"
+	Search          []string          // คอลัมน์ที่ ?q= ค้น
+	DefaultPageSize int               // 0 = MaxPageSize
+}
+
+const (
+	maxSearchLen    = 100
+	maxFilterValues = 50
+)
+
+// Apply ใส่ filter/q/sort ลงใน query แล้วคืนหน้าที่ขอ
+// dest ใช้หาตาราง (เมื่อ query ยังไม่มี Model) และ primary key ที่ใช้ปิดท้ายลำดับ
+func (s ListSpec) Apply(c *gin.Context, query *gorm.DB, dest any) (*gorm.DB, Page, *Error) {
+	page, perr := PageFromDefault(c, s.DefaultPageSize)
+	if perr != nil {
+		return query, page, perr
+	}
+	if query.Statement.Model == nil && query.Statement.Table == "" {
+		query = query.Model(dest)
+	}
+	query, err := s.Filter(c, query)
+	if err != nil {
+		return query, page, err
+	}
+
+	orders, err := s.order(c.DefaultQuery("sort", s.DefaultSort))
+	if err != nil {
+		return query, page, err
+	}
+	for _, o := range orders {
+		query = query.Order(o)
+	}
+	if key := primaryKey(query); key != "" {
+		query = query.Order(key)
+	}
+	return query, page, nil
+}
+
+// Filter ใส่เฉพาะ filter/q (ไม่เรียง ไม่แบ่งหน้า) เช่น export ที่ดึงทีละชุดตาม primary key
+// ให้ได้รายการเดียวกับหน้ารายการที่ใช้ ListSpec เดียวกัน
+func (s ListSpec) Filter(c *gin.Context, query *gorm.DB) (*gorm.DB, *Error) {
+	for _, name := range sortedKeys(s.Filters) {
+		f := s.Filters[name]
+		raw, ok := c.GetQuery(name)
+		if raw = strings.TrimSpace(raw); !ok || raw == "" || (f.Op == OpEq && raw == "all") {
+			continue
+		}
+		var err *Error
+		if query, err = f.apply(query, name, raw); err != nil {
+			return query, err
+		}
+	}
+
+	q := strings.TrimSpace(c.Query("q"))
+	if len([]rune(q)) > maxSearchLen {
+		return query, Invalid("q", "max", "คำค้นยาวได้ไม่เกิน "+strconv.Itoa(maxSearchLen)+" ตัวอักษร")
+	}
+	if q != "" && len(s.Search) > 0 {
+		query = s.search(query, q)
+	}
+	return query, nil
+}
+
+// Find Apply + Paginate ในคราวเดียว; คืน false เมื่อตอบ error ไปแล้ว
+func (s ListSpec) Find(c *gin.Context, query *gorm.DB, dest any) (PageMeta, bool) {
+	q, page, perr := s.Apply(c, query, dest)
+	if perr != nil {
+		Fail(c, perr)
+		return PageMeta{}, false
+	}
+	meta, err := Paginate(q, page, dest)
+	if err != nil {
+		Fail(c, Internal(err))
+		return PageMeta{}, false
+	}
+	return meta, true
+}
+
+func (f Filter) apply(db *gorm.DB, name, raw string) (*gorm.DB, *Error) {
+	switch f.Op {
+	case OpEq, OpID:
+		values := splitValues(raw)
+		if len(values) > maxFilterValues {
+			return db, Invalid(name, "max", name+" ระบุได้ไม่เกิน "+strconv.Itoa(maxFilterValues)+" ค่า")
+		}
+		args := make([]any, 0, len(values))
+		for _, v := range values {
+			if f.Op == OpID {
+				n, err := strconv.ParseUint(v, 10, 64)
+				if err != nil {
+					return db, Invalid(name, "number", name+" ต้องเป็นตัวเลข")
+				}
+				args = append(args, n)
+				continue
+			}
+			args = append(args, v)
+		}
+		if len(args) == 1 {
+			return db.Where(f.Column+" = ?", args[0]), nil
+		}
+		return db.Where(f.Column+" IN ?", args), nil
+	case OpLike:
+		return db.Where("LOWER("+f.Column+") LIKE ? ESCAPE '!'", likePattern(raw)), nil
+	case OpBool, OpNull:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return db, Invalid(name, "boolean", name+" ต้องเป็น true หรือ false")
+		}
+		if f.Op == OpNull {
+			if b {
+				return db.Where(f.Column + " IS NULL"), nil
+			}
+			return db.Where(f.Column + " IS NOT NULL"), nil
+		}
+		return db.Where(f.Column+" = ?", b), nil
+	case OpFrom, OpTo, OpDayFrom, OpDayTo:
+		day, err := time.ParseInLocation("2006-01-02", raw, services.ShopLocation())
+		if err != nil {
+			return db, Invalid(name, "datetime", name+" ต้องเป็นวันที่รูปแบบ YYYY-MM-DD")
+		}
+		switch f.Op {
+		case OpFrom:
+			return db.Where(f.Column+" >= ?", day), nil
+		case OpTo:
+			return db.Where(f.Column+" < ?", day.AddDate(0, 0, 1)), nil
+		case OpDayFrom:
+			return db.Where(f.Column+" >= ?", day.Format("2006-01-02")), nil
+		}
+		return db.Where(f.Column+" <= ?", day.Format("2006-01-02")), nil
+	}
+	return db, nil
+}
+
+// ทุกคำใน q ต้องพบในคอลัมน์ใดคอลัมน์หนึ่ง
+func (s ListSpec) search(db *gorm.DB, q string) *gorm.DB {
+	for _, term := range strings.Fields(q) {
+		conds := make([]string, len(s.Search))
+		args := make([]any, len(s.Search))
+		for i, col := range s.Search {
+			// LOWER ทั้งสองฝั่ง: LIKE ของ Postgres แยกตัวพิมพ์ ของ SQLite/MySQL ไม่แยก
+			conds[i] = "LOWER(" + col + ") LIKE ? ESCAPE '!'"
+			args[i] = likePattern(term)
+		}
+		db = db.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+	return db
+}
+
+func (s ListSpec) order(raw string) ([]string, *Error) {
+	var out []string
+	for _, key := range splitValues(raw) {
+		if preset, ok := s.Presets[key]; ok {
+			out = append(out, preset)
+			continue
+		}
+		dir := " ASC"
+		if strings.HasPrefix(key, "-") {
+			key, dir = key[1:], " DESC"
+		}
+		col, ok := s.Sorts[key]
+		if !ok {
+			return nil, Invalid("sort", "oneof", "เรียงได้ตาม: "+strings.Join(s.sortKeys(), ", "))
+		}
+		out = append(out, col+dir)
+	}
+	return out, nil
+}
+
+func (s ListSpec) sortKeys() []string {
+	keys := append(sortedKeys(s.Sorts), sortedKeys(s.Presets)...)
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// primary key ของตารางหลัก (ระบุชื่อตาราง กันชื่อซ้ำเมื่อ JOIN)
+func primaryKey(db *gorm.DB) string {
+	stmt := db.Statement
+	if stmt.Model == nil || stmt.Parse(stmt.Model) != nil || stmt.Schema == nil {
+		return ""
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return ""
+	}
+	return stmt.Table + "." + pk.DBName
+}
+
+// %คำ% สำหรับ LIKE โดยหนี % _ ! ที่ผู้ใช้พิมพ์มา
+func likePattern(term string) string {
+	r := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return "%" + r.Replace(strings.ToLower(term)) + "%"
+}
+
+func splitValues(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/migrations"
+)
+
+// ======================================================
+// คำสั่งของ binary (ไม่ระบุคำสั่ง = เปิดเซิร์ฟเวอร์)
+//   go run . migrate up       รัน migration ที่ค้างทั้งหมด
+//   go run . migrate down     ย้อน migration ล่าสุด 1 เวอร์ชัน
+//   go run . migrate status   ดูสถานะ
+//   go run . seed             ใส่ข้อมูลตัวอย่าง (เครื่องพัฒนาเท่านั้น)
+// ======================================================
+
+const usage = `usage:
+  server                    start the HTTP server
+  server migrate up|down|status
+  server seed               insert demo data (refused when APP_ENV=production)`
+
+// runCommand คืน exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "migrate":
+		if len(args) != 2 {
+			break
+		}
+		if err := config.ConnectDatabase(); err != nil {
+			fmt.Fprintln(os.Stderr, "database:", err)
+			return 1
+		}
+		return runMigrate(args[1])
+	case "seed":
+		if len(args) != 1 {
+			break
+		}
+		if err := config.ConnectDatabase(); err != nil {
+			fmt.Fprintln(os.Stderr, "database:", err)
+			return 1
+		}
+		if err := config.Seed(); err != nil {
+			fmt.Fprintln(os.Stderr, "seed:", err)
+			return 1
+		}
+		fmt.Println("✅ seed completed")
+		return 0
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
+
+func runMigrate(sub string) int {
+	db := config.DB
+	switch sub {
+	case "up":
+		ran, err := migrations.Up(db)
+		for _, m := range ran {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			return 1
+		}
+		if len(ran) == 0 {
+			fmt.Println("already up to date")
+		}
+	case "down":
+		m, err := migrations.Down(db)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			return 1
+		}
+		if m == nil {
+			fmt.Println("nothing to roll back")
+		} else {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		st, err := migrations.StatusOf(db)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			return 1
+		}
+		for _, s := range st {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+				if s.Modified {
+					state += " (MODIFIED since applied)"
+				}
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	return 0
+}
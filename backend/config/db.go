@@ -1,221 +1,136 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
-	"github.com/OnpreeyaMi/project-sa/entity"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/OnpreeyaMi/project-sa/migrations"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func ConnectDatabase() {
-	database, err := gorm.Open(sqlite.Open("sa_laundry.db"), &gorm.Config{})
-	if err != nil {
-		panic("ไม่สามารถเชื่อมต่อฐานข้อมูลได้")
-	}
-	DB = database
-	fmt.Println("เชื่อมต่อ SQLite สำเร็จ!")
-}
-
-func SetupDatabase() {
-	err := DB.AutoMigrate(
-		&entity.Address{},
-		&entity.ClothType{},
-		&entity.Complaint{},
-		&entity.Customer{},
-		&entity.DetergentCategory{},
-		&entity.Detergent{},
-		&entity.Employee{},
-		&entity.EmployeeStatus{},
-		&entity.History{},
-		&entity.HistoryComplain{},
-		&entity.LaundryProcess{},
-		&entity.Machine{},
-		&entity.Order{},
-		&entity.OrderHistory{},
-		&entity.Payment{},
-		&entity.PositionCount{},
-		&entity.Position{},
-		&entity.PurchaseDetergent{},
-		&entity.Queue{},
-		&entity.QueueAssignment{},
-		&entity.QueueHistory{},
-		&entity.ReplyComplaint{},
-		&entity.ServiceType{},
-		&entity.SortedClothes{},
-		&entity.SortingRecord{},
-		&entity.SortingHistory{},
-		&entity.TimeSlot{},
-		&entity.User{},
-		&entity.Gender{},
-		&entity.History{},
-		&entity.HistoryComplain{},
-		&entity.Promotion{},
-		&entity.PromotionCondition{},
-		&entity.PromotionUsage{},
-		&entity.ComplaintAttachment{},  //เพิ่มตาราง เก็บการอัปโหลด ไฟล์เพิ่มเติม
-		&entity.Role{},
-		&entity.DiscountType{},
-		&entity.DetergentUsageHistory{},
-	)
-	if err != nil {
-		fmt.Println("Error in AutoMigrate:", err)
-	} else {
-		fmt.Println("AutoMigrate completed successfully.")
-	}
+// ======================================================
+// การเชื่อมต่อฐานข้อมูล (ส่วน database ของ Settings)
+//   DB_DRIVER  sqlite (ค่าเริ่มต้น) | postgres | mysql
+//   DB_DSN     sqlite:   path ไฟล์ (ค่าเริ่มต้น sa_laundry.db) หรือ file:...?mode=memory
+//              postgres: "host=localhost user=sa password=sa dbname=sa_laundry port=5432 sslmode=disable" หรือ postgres://...
+//              mysql:    "sa:sa@tcp(localhost:3306)/sa_laundry"
+//   DB_MAX_OPEN_CONNS / DB_MAX_IDLE_CONNS / DB_CONN_MAX_LIFETIME (เช่น 30m)
+//   DB_AUTO_MIGRATE=true  รัน migration ที่ค้างตอนเปิดเซิร์ฟเวอร์
+// ======================================================
 
-	MockData()
-}
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
 
-func phash(p string) string {
-	b, err := bcrypt.GenerateFromPassword([]byte(p), bcrypt.DefaultCost)
-	if err != nil {
-		panic(err)
-	}
-	return string(b)
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" env:"DB_DRIVER"`
+	DSN             string        `yaml:"dsn" env:"DB_DSN"`
+	MaxOpenConns    int           `yaml:"maxOpenConns" env:"DB_MAX_OPEN_CONNS"` // 0 = ไม่จำกัด
+	MaxIdleConns    int           `yaml:"maxIdleConns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME"`
+	AutoMigrate     bool          `yaml:"autoMigrate" env:"DB_AUTO_MIGRATE"`
 }
 
-func MockData() {
-	// --- Roles ---
-	roles := []entity.Role{{Name: "admin"}, {Name: "customer"}, {Name: "employee"}}
-	for _, r := range roles {
-		DB.FirstOrCreate(&r, entity.Role{Name: r.Name})
-	}
-
-	// --- Users (ensure bcrypt hash) ---
-	users := []entity.User{
-		{Email: "admin@example.com", Password: phash("1234"), RoleID: 1},
-		{Email: "customer1@example.com", Password: phash("1234"), RoleID: 2},
-		{Email: "customer2@example.com", Password: phash("1234"), RoleID: 2},
-		// {Email: "employee1@example.com", Password: phash("123456"), RoleID: 3},
-		// {Email: "employee2@example.com", Password: phash("123456"), RoleID: 3},
-	}
-	for _, u := range users {
-		var exist entity.User
-		if err := DB.Where("email = ?", u.Email).First(&exist).Error; err == nil {
-			// อัปเกรด record เดิมถ้ายังเป็น plain
-			if len(exist.Password) > 0 && exist.Password[0] != '$' {
-				exist.Password = phash(exist.Password)
-				DB.Save(&exist)
-			}
+// เติมพารามิเตอร์ที่โค้ดต้องพึ่งลงใน DSN (ถ้าผู้ใช้ไม่ได้ระบุเอง)
+func withDSNParams(dsn string, params ...string) string {
+	for i := 0; i+1 < len(params); i += 2 {
+		if strings.Contains(dsn, params[i]+"=") {
 			continue
-		} else if err != nil && err != gorm.ErrRecordNotFound {
-			log.Println(err)
-		} else {
-			DB.Create(&u)
 		}
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + params[i] + "=" + params[i+1]
 	}
+	return dsn
+}
 
-	// --- Customers ---
-	customers := []entity.Customer{
-		{FirstName: "Nuntawut", LastName: "K.", PhoneNumber: "0812345678", GenderID: 1, UserID: 2},
-		{FirstName: "Alice", LastName: "B.", PhoneNumber: "0898765432", GenderID: 1, UserID: 3},
-	}
-	for _, c := range customers {
-		DB.FirstOrCreate(&c, entity.Customer{PhoneNumber: c.PhoneNumber})
-	}
-	// Genders
-	genders := []entity.Gender{{Name: "ชาย"}, {Name: "หญิง"}, {Name: "อืนๆ"}}
-	for _, g := range genders {
-		DB.FirstOrCreate(&g, entity.Gender{Name: g.Name})
-	}
-	// --- Mock Address ---
-	addresses := []entity.Address{
-		{CustomerID: 1, AddressDetails: "123 Main St, Bangkok", Latitude: 13.7563, Longitude: 100.5018, IsDefault: true},
-		{CustomerID: 2, AddressDetails: "456 Second St, Chiang Mai", Latitude: 18.7883, Longitude: 98.9853, IsDefault: true},
-	}
-	for _, a := range addresses {
-		DB.FirstOrCreate(&a, entity.Address{CustomerID: a.CustomerID, AddressDetails: a.AddressDetails})
+func dialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverSQLite, DriverPostgres, DriverMySQL:
+	default:
+		return nil, fmt.Errorf("ไม่รองรับ DB_DRIVER=%q (sqlite, postgres, mysql)", cfg.Driver)
+	}
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("ต้องระบุ DB_DSN สำหรับ %s", cfg.Driver)
+	}
+	switch cfg.Driver {
+	case DriverSQLite:
+		// _txlock=immediate: ธุรกรรมจองสิทธิ์เขียนตั้งแต่ BEGIN แทน SELECT ... FOR UPDATE ที่ SQLite ไม่มี
+		return sqlite.Open(withDSNParams(cfg.DSN, "_busy_timeout", "5000", "_txlock", "immediate")), nil
+	case DriverMySQL:
+		return mysql.Open(withDSNParams(cfg.DSN, "parseTime", "true", "charset", "utf8mb4")), nil
+	default: // postgres
+		return postgres.Open(cfg.DSN), nil
 	}
+}
 
-	// Service Types
-	services := []entity.ServiceType{
-		{Type: "ซัก 10kg", Price: 50, Capacity: 10},
-		{Type: "ซัก 14kg", Price: 70, Capacity: 14},
-		{Type: "ซัก 18kg", Price: 90, Capacity: 18},
-		{Type: "ซัก 28kg", Price: 120, Capacity: 28},
-		{Type: "อบ 14kg", Price: 50, Capacity: 14},
-		{Type: "อบ 25kg", Price: 70, Capacity: 25},
-		{Type: "ไม่อบ", Price: 0, Capacity: 0},
-	}
-	for _, s := range services {
-		DB.FirstOrCreate(&s, entity.ServiceType{Type: s.Type})
+// OpenDatabase เปิดการเชื่อมต่อตาม cfg และตั้งค่า connection pool
+func OpenDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
+	d, err := dialector(cfg)
+	if err != nil {
+		return nil, err
 	}
-
-	// --- Mock DetergentCategory ---
-	categories := []entity.DetergentCategory{
-		{Name: "น้ำยาซัก", Description: "สำหรับทำความสะอาดเสื้อผ้า"},
-		{Name: "ปรับผ้านุ่ม", Description: "สำหรับทำให้ผ้านุ่มและมีกลิ่นหอม"},
+	// TranslateError: error ของแต่ละฐานข้อมูลแปลงเป็น gorm.ErrDuplicatedKey ฯลฯ (ดู IsDuplicateKey)
+	db, err := gorm.Open(d, &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("เชื่อมต่อฐานข้อมูล %s ไม่ได้: %w", cfg.Driver, err)
 	}
-	for _, c := range categories {
-		DB.FirstOrCreate(&c, entity.DetergentCategory{Name: c.Name})
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	return db, nil
+}
 
-	// --- DiscountType ---
-	discountTypes := []entity.DiscountType{
-		{TypeName: "เปอร์เซ็นต์", Description: "ลดเป็นเปอร์เซ็นต์"},
-		{TypeName: "จำนวนเงิน", Description: "ลดเป็นจำนวนเงิน"},
-	}
-	for _, dt := range discountTypes {
-		DB.FirstOrCreate(&dt, entity.DiscountType{TypeName: dt.TypeName})
+// ConnectDatabase เปิดฐานข้อมูลตาม Current().Database แล้วตั้งเป็น DB
+func ConnectDatabase() error {
+	cfg := Current().Database
+	database, err := OpenDatabase(cfg)
+	if err != nil {
+		return err
 	}
+	DB = database
+	slog.Info("database connected", "driver", cfg.Driver)
+	return nil
+}
 
-	
-	// --- Machines ---
-	machines := []entity.Machine{
-		{Machine_type: "washing", Machine_number: 1, Capacity_kg: 7, Status: "available"},
-		{Machine_type: "washing", Machine_number: 2, Capacity_kg: 10, Status: "available"},
-		{Machine_type: "washing", Machine_number: 3, Capacity_kg: 8, Status: "available"},
-		{Machine_type: "washing", Machine_number: 4, Capacity_kg: 12, Status: "available"},
-		{Machine_type: "drying", Machine_number: 1, Capacity_kg: 7, Status: "available"},
-		{Machine_type: "drying", Machine_number: 2, Capacity_kg: 10, Status: "available"},
-		{Machine_type: "drying", Machine_number: 3, Capacity_kg: 12, Status: "available"},
-	}
-	for _, m := range machines {
-		DB.FirstOrCreate(&m, entity.Machine{Machine_type: m.Machine_type, Capacity_kg: m.Capacity_kg, Status: m.Status})
-	}
-	// --- Positions (ตัวอย่าง) ---
-	positions := []entity.Position{
-		{PositionName: "พนักงานซักผ้า"},
-		{PositionName: "พนักงานขนส่งผ้า"},
+// IsDuplicateKey ชนค่า unique (ทุกฐานข้อมูล; ต้องเปิด TranslateError ซึ่ง OpenDatabase ตั้งไว้แล้ว)
+func IsDuplicateKey(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// SetupDatabase ตรวจว่าฐานข้อมูลรัน migration ครบก่อนเปิดเซิร์ฟเวอร์ (ไม่แก้ schema เอง)
+// database.autoMigrate / DB_AUTO_MIGRATE=true จะรัน migration ที่ค้างให้เลย (สะดวกตอนพัฒนา)
+func SetupDatabase() error {
+	pending, err := migrations.Pending(DB)
+	if err != nil {
+		return err
 	}
-	for _, p := range positions {
-		DB.FirstOrCreate(&p, entity.Position{PositionName: p.PositionName})
+	if len(pending) == 0 {
+		return nil
 	}
-	// --- Mock TimeSlot ---
-	var countTS int64
-	DB.Model(&entity.TimeSlot{}).Count(&countTS)
-	if countTS == 0 {
-		now := time.Now()
-		pickupSlots := []entity.TimeSlot{
-			{Start_time: now.Add(1 * time.Hour), End_time: now.Add(2 * time.Hour), SlotType: "pickup", Capacity: 5, Status: "available"},
-			{Start_time: now.Add(3 * time.Hour), End_time: now.Add(4 * time.Hour), SlotType: "pickup", Capacity: 5, Status: "available"},
-		}
-		deliverySlots := []entity.TimeSlot{
-			{Start_time: now.Add(5 * time.Hour), End_time: now.Add(6 * time.Hour), SlotType: "delivery", Capacity: 5, Status: "available"},
-			{Start_time: now.Add(7 * time.Hour), End_time: now.Add(8 * time.Hour), SlotType: "delivery", Capacity: 5, Status: "available"},
-		}
-		for _, ts := range pickupSlots {
-			DB.Create(&ts)
-		}
-		for _, ts := range deliverySlots {
-			DB.Create(&ts)
+	if Current().Database.AutoMigrate {
+		ran, err := migrations.Up(DB)
+		for _, m := range ran {
+			slog.Info("migration applied", "version", m.Version, "name", m.Name)
 		}
+		return err
 	}
-	// // --- EmployeeStatus (ค่าเริ่มต้น) ---
-	// statuses := []entity.EmployeeStatus{
-	// 	{StatusName: "active", StatusDescription: "กำลังปฏิบัติงาน"},
-	// 	{StatusName: "inactive", StatusDescription: "ยังไม่ปฏิบัติงาน"},
-	// 	{StatusName: "onleave", StatusDescription: "ลาพัก"},
-	// }
-	// for _, s := range statuses {
-	// 	DB.FirstOrCreate(&s, entity.EmployeeStatus{StatusName: s.StatusName})
-	// }
-
-	fmt.Println("✅ Mock data added successfully!")
+	return fmt.Errorf("ฐานข้อมูลยังไม่เป็นเวอร์ชันล่าสุด (ค้าง %d migration เริ่มที่ %04d_%s) ให้รัน `migrate up` ก่อน",
+		len(pending), pending[0].Version, pending[0].Name)
 }
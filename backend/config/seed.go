@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/migrations"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ข้อมูลตัวอย่างสำหรับเครื่องพัฒนา/ทดสอบ (คำสั่ง `seed` เท่านั้น ไม่รันตอนเปิดเซิร์ฟเวอร์)
+// - ผู้ใช้ทดลอง admin/customer1/customer2 รหัสผ่าน 1234, ลูกค้า, ที่อยู่, เครื่องซัก-อบ, ช่วงเวลารับ-ส่ง
+// - ข้อมูลอ้างอิงที่ระบบต้องมี (role, เพศ, ประเภทบริการ, ตำแหน่ง ฯลฯ) อยู่ใน migration 0002 แล้ว
+// - APP_ENV=production ไม่ยอมรัน
+// ======================================================
+
+var ErrSeedProduction = errors.New("ไม่อนุญาตให้ใส่ข้อมูลตัวอย่างเมื่อ APP_ENV=production")
+
+func phash(p string) string {
+	b, err := bcrypt.GenerateFromPassword([]byte(p), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+// Seed ใส่ข้อมูลตัวอย่าง (รันซ้ำได้ ข้อมูลที่มีอยู่แล้วจะข้าม)
+func Seed() error {
+	if Current().IsProduction() {
+		return ErrSeedProduction
+	}
+	pending, err := migrations.Pending(DB)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("ยังค้าง %d migration ให้รัน `migrate up` ก่อน", len(pending))
+	}
+	return DB.Transaction(seedDemoData)
+}
+
+func seedDemoData(tx *gorm.DB) error {
+	// --- Users (ลูกค้า 2 คน + admin) ---
+	users := []entity.User{
+		{Email: "admin@example.com", Password: phash("1234"), RoleID: 1},
+		{Email: "customer1@example.com", Password: phash("1234"), RoleID: 2},
+		{Email: "customer2@example.com", Password: phash("1234"), RoleID: 2},
+	}
+	userIDs := map[string]uint{}
+	for _, u := range users {
+		if err := tx.Where(entity.User{Email: u.Email}).Attrs(u).FirstOrCreate(&u).Error; err != nil {
+			return err
+		}
+		userIDs[u.Email] = u.ID
+	}
+
+	// --- Customers + ที่อยู่ ---
+	customers := []struct {
+		email    string
+		customer entity.Customer
+		address  entity.Address
+	}{
+		{"customer1@example.com",
+			entity.Customer{FirstName: "Nuntawut", LastName: "K.", PhoneNumber: "0812345678", GenderID: 1},
+			entity.Address{AddressDetails: "123 Main St, Bangkok", Latitude: 13.7563, Longitude: 100.5018, IsDefault: true}},
+		{"customer2@example.com",
+			entity.Customer{FirstName: "Alice", LastName: "B.", PhoneNumber: "0898765432", GenderID: 1},
+			entity.Address{AddressDetails: "456 Second St, Chiang Mai", Latitude: 18.7883, Longitude: 98.9853, IsDefault: true}},
+	}
+	for _, c := range customers {
+		c.customer.UserID = userIDs[c.email]
+		if err := tx.Where(entity.Customer{PhoneNumber: c.customer.PhoneNumber}).Attrs(c.customer).FirstOrCreate(&c.customer).Error; err != nil {
+			return err
+		}
+		c.address.CustomerID = c.customer.ID
+		if err := tx.Where(entity.Address{CustomerID: c.address.CustomerID, AddressDetails: c.address.AddressDetails}).
+			Attrs(c.address).FirstOrCreate(&c.address).Error; err != nil {
+			return err
+		}
+	}
+
+	// --- Machines ---
+	machines := []entity.Machine{
+		{Machine_type: "washing", Machine_number: 1, Capacity_kg: 7, Status: "available"},
+		{Machine_type: "washing", Machine_number: 2, Capacity_kg: 10, Status: "available"},
+		{Machine_type: "washing", Machine_number: 3, Capacity_kg: 8, Status: "available"},
+		{Machine_type: "washing", Machine_number: 4, Capacity_kg: 12, Status: "available"},
+		{Machine_type: "drying", Machine_number: 1, Capacity_kg: 7, Status: "available"},
+		{Machine_type: "drying", Machine_number: 2, Capacity_kg: 10, Status: "available"},
+		{Machine_type: "drying", Machine_number: 3, Capacity_kg: 12, Status: "available"},
+	}
+	for _, m := range machines {
+		if err := tx.Where(entity.Machine{Machine_type: m.Machine_type, Machine_number: m.Machine_number}).
+			Attrs(m).FirstOrCreate(&m).Error; err != nil {
+			return err
+		}
+	}
+
+	// --- TimeSlot (ถ้ายังไม่มีเลย) ---
+	var countTS int64
+	if err := tx.Model(&entity.TimeSlot{}).Count(&countTS).Error; err != nil {
+		return err
+	}
+	if countTS == 0 {
+		now := time.Now()
+		slots := []entity.TimeSlot{
+			{Start_time: now.Add(1 * time.Hour), End_time: now.Add(2 * time.Hour), SlotType: "pickup", Capacity: 5, Status: "available"},
+			{Start_time: now.Add(3 * time.Hour), End_time: now.Add(4 * time.Hour), SlotType: "pickup", Capacity: 5, Status: "available"},
+			{Start_time: now.Add(5 * time.Hour), End_time: now.Add(6 * time.Hour), SlotType: "delivery", Capacity: 5, Status: "available"},
+			{Start_time: now.Add(7 * time.Hour), End_time: now.Add(8 * time.Hour), SlotType: "delivery", Capacity: 5, Status: "available"},
+		}
+		if err := tx.Create(&slots).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
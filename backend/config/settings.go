@@ -0,0 +1,455 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ======================================================
+// ค่าตั้งค่าของระบบ (รวมไว้ที่เดียว ตรวจความถูกต้องตอนเริ่มโปรแกรม)
+// ลำดับความสำคัญ (หลังทับหน้า): ค่าเริ่มต้น < ไฟล์ YAML < .env < environment จริง
+// - ไฟล์ YAML: CONFIG_FILE หรือ ./config.yaml ถ้ามี (ตัวอย่างใน config.example.yaml)
+// - ชื่อ env ของแต่ละค่าอยู่ใน tag `env` ของ struct ด้านล่าง
+// ======================================================
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+// ใช้เมื่อไม่ได้ตั้ง JWT_SECRET ตอนพัฒนา (production ไม่ยอม)
+const devJWTSecret = "dev-only-insecure-jwt-secret"
+
+type Settings struct {
+	Env      string          `yaml:"env" env:"APP_ENV"`
+	Port     int             `yaml:"port" env:"PORT"`
+	Log      LogSettings     `yaml:"log"`
+	Database DatabaseConfig  `yaml:"database"`
+	Auth     AuthSettings    `yaml:"auth"`
+	CORS     CORSSettings    `yaml:"cors"`
+	Upload   UploadSettings  `yaml:"upload"`
+	Shop     ShopSettings    `yaml:"shop"`
+	Payment  PaymentSettings `yaml:"payment"`
+	Mail     MailSettings    `yaml:"mail"`
+	Blob     BlobSettings    `yaml:"blob"`
+	Features FeatureSettings `yaml:"features"`
+
+	// เวลารอคำขอที่ค้าง/งานเบื้องหลังให้จบตอนปิดเซิร์ฟเวอร์ (SIGTERM)
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	// เวลารอหลัง /readyz ตอบ 503 ก่อนหยุดรับ connection (ให้ load balancer ถอดออกก่อน)
+	DrainDelay time.Duration `yaml:"drainDelay" env:"SHUTDOWN_DRAIN_DELAY"`
+
+	location *time.Location
+}
+
+type LogSettings struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"` // debug | info | warn | error
+}
+
+type AuthSettings struct {
+	JWTSecret     string        `yaml:"jwtSecret" env:"JWT_SECRET"`
+	TokenTTL      time.Duration `yaml:"tokenTTL" env:"JWT_TTL"`
+	FileURLSecret string        `yaml:"fileURLSecret" env:"FILE_URL_SECRET"` // ว่าง = ใช้ JWT secret
+	FileURLTTL    time.Duration `yaml:"fileURLTTL" env:"FILE_URL_TTL"`       // อายุลิงก์ดาวน์โหลดไฟล์แนบ
+
+	PasswordResetTTL time.Duration `yaml:"passwordResetTTL" env:"PASSWORD_RESET_TTL"`
+	PasswordResetURL string        `yaml:"passwordResetURL" env:"PASSWORD_RESET_URL"` // หน้าตั้งรหัสผ่านใหม่ฝั่ง frontend (แนบ ?token=)
+}
+
+type CORSSettings struct {
+	AllowedOrigins []string `yaml:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"` // คั่นด้วย , ; "*" = ทุก origin (ห้ามใน production)
+}
+
+type UploadSettings struct {
+	MaxComplaintFiles int `yaml:"maxComplaintFiles" env:"UPLOAD_MAX_COMPLAINT_FILES"`
+	MaxFileMB         int `yaml:"maxFileMB" env:"UPLOAD_MAX_FILE_MB"`
+	MaxSlipMB         int `yaml:"maxSlipMB" env:"UPLOAD_MAX_SLIP_MB"`
+}
+
+type ShopSettings struct {
+	Name     string `yaml:"name" env:"SHOP_NAME"`
+	TaxID    string `yaml:"taxId" env:"SHOP_TAX_ID"`
+	Address  string `yaml:"address" env:"SHOP_ADDRESS"`
+	Timezone string `yaml:"timezone" env:"SHOP_TIMEZONE"` // วันทำการ/กะ/รายงานนับตามเขตเวลานี้
+
+	ReceiptFontPath string `yaml:"receiptFontPath" env:"RECEIPT_FONT_PATH"` // ฟอนต์ TTF ภาษาไทยของใบเสร็จ (ว่าง = Helvetica)
+}
+
+type PaymentSettings struct {
+	EasySlipToken string `yaml:"easySlipToken" env:"EASYSLIP_TOKEN"`
+}
+
+// MailSettings ตัวส่งอีเมล
+// - local: เขียนไฟล์ .eml ลง LocalDir แทนการส่งจริง (พัฒนา/ทดสอบ)
+// - smtp : ส่งผ่าน SMTP server
+type MailSettings struct {
+	Driver   string `yaml:"driver" env:"MAIL_DRIVER"` // local | smtp
+	LocalDir string `yaml:"localDir" env:"MAIL_LOCAL_DIR"`
+	From     string `yaml:"from" env:"MAIL_FROM"`
+	SMTPHost string `yaml:"smtpHost" env:"SMTP_HOST"`
+	SMTPPort int    `yaml:"smtpPort" env:"SMTP_PORT"`
+	SMTPUser string `yaml:"smtpUser" env:"SMTP_USER"`
+	SMTPPass string `yaml:"smtpPass" env:"SMTP_PASS"`
+}
+
+// BlobSettings ที่เก็บไฟล์แนบ (ตอนนี้รองรับดิสก์ในเครื่อง)
+type BlobSettings struct {
+	Driver    string `yaml:"driver" env:"BLOB_DRIVER"` // local
+	LocalRoot string `yaml:"localRoot" env:"BLOB_LOCAL_ROOT"`
+}
+
+type FeatureSettings struct {
+	SLAChecker        bool          `yaml:"slaChecker" env:"FEATURE_SLA_CHECKER"`
+	SLACheckInterval  time.Duration `yaml:"slaCheckInterval" env:"SLA_CHECK_INTERVAL"`
+	SLASupervisor     string        `yaml:"slaSupervisor" env:"SLA_SUPERVISOR_POSITION"` // ชื่อตำแหน่งที่รับเรื่อง escalate
+	LeaveStatusSync   bool          `yaml:"leaveStatusSync" env:"FEATURE_LEAVE_STATUS_SYNC"`
+	SlipVerification  bool          `yaml:"slipVerification" env:"FEATURE_SLIP_VERIFICATION"`
+	SelfRegistration  bool          `yaml:"selfRegistration" env:"FEATURE_SELF_REGISTRATION"`
+	Metrics           bool          `yaml:"metrics" env:"FEATURE_METRICS"`                      // GET /metrics (Prometheus)
+	RequestValidation bool          `yaml:"requestValidation" env:"FEATURE_REQUEST_VALIDATION"` // ตรวจคำขอกับ openapi.yaml
+}
+
+// DefaultSettings ค่าเริ่มต้นสำหรับเครื่องพัฒนา
+func DefaultSettings() *Settings {
+	return &Settings{
+		Env:  EnvDevelopment,
+		Port: 8000,
+		Log:  LogSettings{Level: "info"},
+		Database: DatabaseConfig{
+			Driver:          DriverSQLite,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Auth: AuthSettings{
+			TokenTTL:         24 * time.Hour,
+			FileURLTTL:       15 * time.Minute,
+			PasswordResetTTL: 30 * time.Minute,
+			PasswordResetURL: "http://localhost:5173/reset-password",
+		},
+		CORS:   CORSSettings{AllowedOrigins: []string{"http://localhost:5173", "http://127.0.0.1:5173"}},
+		Upload: UploadSettings{MaxComplaintFiles: 5, MaxFileMB: 10, MaxSlipMB: 2},
+		Shop:   ShopSettings{Timezone: "Asia/Bangkok"},
+		Mail:   MailSettings{Driver: "local", LocalDir: "mail-outbox", SMTPPort: 587},
+		Blob:   BlobSettings{Driver: "local", LocalRoot: "uploads"},
+		Features: FeatureSettings{
+			SLAChecker:        true,
+			SLACheckInterval:  time.Minute,
+			SLASupervisor:     "หัวหน้างาน",
+			LeaveStatusSync:   true,
+			SlipVerification:  true,
+			SelfRegistration:  true,
+			Metrics:           true,
+			RequestValidation: true,
+		},
+		ShutdownTimeout: 20 * time.Second,
+		DrainDelay:      5 * time.Second,
+	}
+}
+
+var (
+	settingsMu sync.RWMutex
+	settings   *Settings
+)
+
+// Current ค่าตั้งค่าที่ใช้อยู่ (ยังไม่ Load = ค่าเริ่มต้น เช่น ตอนรัน test)
+func Current() *Settings {
+	settingsMu.RLock()
+	s := settings
+	settingsMu.RUnlock()
+	if s != nil {
+		return s
+	}
+	s = DefaultSettings()
+	if err := s.finish(); err != nil {
+		panic(err) // ค่าเริ่มต้นต้องผ่านเสมอ
+	}
+	SetCurrent(s)
+	return s
+}
+
+func SetCurrent(s *Settings) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	settings = s
+}
+
+// Load อ่านค่าตั้งค่า (YAML -> .env -> env) ตรวจแล้วตั้งเป็น Current
+func Load() (*Settings, error) {
+	_ = godotenv.Load() // โหลด .env จากโฟลเดอร์ที่รัน (ไม่ทับ env ที่ตั้งไว้แล้ว)
+
+	s := DefaultSettings()
+	path := strings.TrimSpace(os.Getenv("CONFIG_FILE"))
+	if path == "" {
+		if _, err := os.Stat("config.yaml"); err == nil {
+			path = "config.yaml"
+		}
+	}
+	if path != "" {
+		if err := s.loadYAML(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(s).Elem()); err != nil {
+		return nil, err
+	}
+	if err := s.finish(); err != nil {
+		return nil, err
+	}
+	SetCurrent(s)
+	return s, nil
+}
+
+func (s *Settings) loadYAML(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("เปิดไฟล์ตั้งค่า %s ไม่ได้: %w", path, err)
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true) // พิมพ์ชื่อ key ผิดให้แจ้ง ไม่ใช่เงียบ
+	if err := dec.Decode(s); err != nil {
+		return fmt.Errorf("ไฟล์ตั้งค่า %s ไม่ถูกต้อง: %w", path, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv ทับค่าจาก env ตาม tag `env` (เข้า struct ซ้อนด้วย)
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := f.Tag.Get("env")
+		if key == "" {
+			if fv.Kind() == reflect.Struct {
+				if err := applyEnv(fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		raw, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		raw = strings.TrimSpace(raw)
+		bad := func() error { return fmt.Errorf("%s: ค่า %q ไม่ถูกต้อง", key, raw) }
+		switch {
+		case f.Type == durationType:
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return bad()
+			}
+			fv.SetInt(int64(d))
+		case fv.Kind() == reflect.String:
+			fv.SetString(raw)
+		case fv.Kind() == reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return bad()
+			}
+			fv.SetInt(int64(n))
+		case fv.Kind() == reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return bad()
+			}
+			fv.SetBool(b)
+		case fv.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+			var list []string
+			for _, p := range strings.Split(raw, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					list = append(list, p)
+				}
+			}
+			fv.Set(reflect.ValueOf(list))
+		default:
+			return fmt.Errorf("%s: ไม่รองรับชนิด %s", key, f.Type)
+		}
+	}
+	return nil
+}
+
+// finish เติมค่าที่ขึ้นกับค่าอื่น แล้วตรวจทั้งหมด (แจ้งทุกข้อที่ผิดพร้อมกัน)
+func (s *Settings) finish() error {
+	s.Env = strings.ToLower(strings.TrimSpace(s.Env))
+	s.Database.Driver = strings.ToLower(strings.TrimSpace(s.Database.Driver))
+	s.Mail.Driver = strings.ToLower(strings.TrimSpace(s.Mail.Driver))
+	s.Blob.Driver = strings.ToLower(strings.TrimSpace(s.Blob.Driver))
+	if s.Database.Driver == DriverSQLite && s.Database.DSN == "" {
+		s.Database.DSN = "sa_laundry.db"
+	}
+	if s.Auth.JWTSecret == "" && s.Env != EnvProduction {
+		slog.Warn("config: JWT_SECRET ไม่ได้ตั้ง ใช้ค่าสำหรับพัฒนาเท่านั้น")
+		s.Auth.JWTSecret = devJWTSecret
+	}
+	if s.Auth.FileURLSecret == "" {
+		s.Auth.FileURLSecret = s.Auth.JWTSecret
+	}
+
+	var errs []string
+	add := func(key, format string, args ...interface{}) {
+		errs = append(errs, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Env {
+	case EnvDevelopment, EnvProduction, EnvTest:
+	default:
+		add("APP_ENV", "ต้องเป็น development, production หรือ test (ได้ %q)", s.Env)
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		add("PORT", "ต้องอยู่ระหว่าง 1-65535 (ได้ %d)", s.Port)
+	}
+
+	switch strings.ToLower(s.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		add("LOG_LEVEL", "ต้องเป็น debug, info, warn หรือ error (ได้ %q)", s.Log.Level)
+	}
+	if s.ShutdownTimeout < time.Second {
+		add("SHUTDOWN_TIMEOUT", "ต้องอย่างน้อย 1s (ได้ %s)", s.ShutdownTimeout)
+	}
+	if s.DrainDelay < 0 || s.DrainDelay >= s.ShutdownTimeout {
+		add("SHUTDOWN_DRAIN_DELAY", "ต้องไม่ติดลบและน้อยกว่า SHUTDOWN_TIMEOUT (ได้ %s)", s.DrainDelay)
+	}
+
+	db := s.Database
+	switch db.Driver {
+	case DriverSQLite, DriverPostgres, DriverMySQL:
+		if db.DSN == "" {
+			add("DB_DSN", "ต้องระบุสำหรับ %s", db.Driver)
+		}
+	default:
+		add("DB_DRIVER", "ต้องเป็น sqlite, postgres หรือ mysql (ได้ %q)", db.Driver)
+	}
+	if db.MaxOpenConns < 0 || db.MaxIdleConns < 0 || db.ConnMaxLifetime < 0 {
+		add("DB_MAX_OPEN_CONNS/DB_MAX_IDLE_CONNS/DB_CONN_MAX_LIFETIME", "ต้องไม่ติดลบ")
+	}
+
+	if s.Auth.JWTSecret == "" {
+		add("JWT_SECRET", "ต้องระบุใน production")
+	} else if s.Env == EnvProduction && (len(s.Auth.JWTSecret) < 32 || s.Auth.JWTSecret == devJWTSecret) {
+		add("JWT_SECRET", "ใน production ต้องยาวอย่างน้อย 32 ตัวอักษร")
+	}
+	if s.Auth.TokenTTL < time.Minute {
+		add("JWT_TTL", "ต้องอย่างน้อย 1m (ได้ %s)", s.Auth.TokenTTL)
+	}
+	if s.Auth.FileURLTTL < time.Minute || s.Auth.FileURLTTL > 24*time.Hour {
+		add("FILE_URL_TTL", "ต้องอยู่ระหว่าง 1m-24h (ได้ %s)", s.Auth.FileURLTTL)
+	}
+	if s.Auth.PasswordResetTTL < time.Minute || s.Auth.PasswordResetTTL > 24*time.Hour {
+		add("PASSWORD_RESET_TTL", "ต้องอยู่ระหว่าง 1m-24h (ได้ %s)", s.Auth.PasswordResetTTL)
+	}
+	if u, err := url.Parse(s.Auth.PasswordResetURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("PASSWORD_RESET_URL", "ต้องเป็น URL เต็ม (เช่น https://shop.example.com/reset-password) ได้ %q", s.Auth.PasswordResetURL)
+	}
+
+	if len(s.CORS.AllowedOrigins) == 0 {
+		add("CORS_ALLOWED_ORIGINS", "ต้องระบุอย่างน้อย 1 origin")
+	}
+	for _, o := range s.CORS.AllowedOrigins {
+		if o == "*" {
+			if s.Env == EnvProduction {
+				add("CORS_ALLOWED_ORIGINS", "ห้ามใช้ * ใน production")
+			}
+			continue
+		}
+		if u, err := url.Parse(o); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			add("CORS_ALLOWED_ORIGINS", "%q ไม่ใช่ origin (เช่น https://shop.example.com)", o)
+		}
+	}
+
+	if s.Upload.MaxComplaintFiles < 1 || s.Upload.MaxComplaintFiles > 50 {
+		add("UPLOAD_MAX_COMPLAINT_FILES", "ต้องอยู่ระหว่าง 1-50 (ได้ %d)", s.Upload.MaxComplaintFiles)
+	}
+	if s.Upload.MaxFileMB < 1 || s.Upload.MaxFileMB > 100 {
+		add("UPLOAD_MAX_FILE_MB", "ต้องอยู่ระหว่าง 1-100 (ได้ %d)", s.Upload.MaxFileMB)
+	}
+	if s.Upload.MaxSlipMB < 1 || s.Upload.MaxSlipMB > 20 {
+		add("UPLOAD_MAX_SLIP_MB", "ต้องอยู่ระหว่าง 1-20 (ได้ %d)", s.Upload.MaxSlipMB)
+	}
+
+	if loc, err := time.LoadLocation(s.Shop.Timezone); err != nil || s.Shop.Timezone == "" {
+		add("SHOP_TIMEZONE", "ไม่รู้จักเขตเวลา %q (เช่น Asia/Bangkok)", s.Shop.Timezone)
+	} else {
+		s.location = loc
+	}
+	if p := s.Shop.ReceiptFontPath; p != "" {
+		if _, err := os.Stat(p); err != nil {
+			add("RECEIPT_FONT_PATH", "เปิดไฟล์ฟอนต์ %q ไม่ได้", p)
+		}
+	}
+
+	switch s.Mail.Driver {
+	case "local":
+		if strings.TrimSpace(s.Mail.LocalDir) == "" {
+			add("MAIL_LOCAL_DIR", "ต้องระบุเมื่อ MAIL_DRIVER=local")
+		}
+	case "smtp":
+		if strings.TrimSpace(s.Mail.SMTPHost) == "" {
+			add("SMTP_HOST", "ต้องระบุเมื่อ MAIL_DRIVER=smtp")
+		}
+		if strings.TrimSpace(s.Mail.From) == "" {
+			add("MAIL_FROM", "ต้องระบุเมื่อ MAIL_DRIVER=smtp")
+		}
+		if s.Mail.SMTPPort < 1 || s.Mail.SMTPPort > 65535 {
+			add("SMTP_PORT", "ต้องอยู่ระหว่าง 1-65535 (ได้ %d)", s.Mail.SMTPPort)
+		}
+	default:
+		add("MAIL_DRIVER", "ต้องเป็น local หรือ smtp (ได้ %q)", s.Mail.Driver)
+	}
+
+	switch s.Blob.Driver {
+	case "local":
+		if strings.TrimSpace(s.Blob.LocalRoot) == "" {
+			add("BLOB_LOCAL_ROOT", "ต้องระบุเมื่อ BLOB_DRIVER=local")
+		}
+	default:
+		add("BLOB_DRIVER", "ต้องเป็น local (ได้ %q)", s.Blob.Driver)
+	}
+
+	if s.Features.SLAChecker && s.Features.SLACheckInterval <= 0 {
+		add("SLA_CHECK_INTERVAL", "ต้องมากกว่า 0 เมื่อเปิด FEATURE_SLA_CHECKER")
+	}
+	if s.Features.SLAChecker && strings.TrimSpace(s.Features.SLASupervisor) == "" {
+		add("SLA_SUPERVISOR_POSITION", "ต้องระบุเมื่อเปิด FEATURE_SLA_CHECKER")
+	}
+	if s.Env == EnvProduction && s.Features.SlipVerification && s.Payment.EasySlipToken == "" {
+		add("EASYSLIP_TOKEN", "ต้องระบุเมื่อเปิด FEATURE_SLIP_VERIFICATION ใน production")
+	}
+
+	if len(errs) > 0 {
+		return errors.New("ค่าตั้งค่าไม่ถูกต้อง:\n  - " + strings.Join(errs, "\n  - "))
+	}
+	return nil
+}
+
+// Location เขตเวลาของร้าน
+func (s *Settings) Location() *time.Location {
+	if s.location == nil {
+		return time.Local
+	}
+	return s.location
+}
+
+func (s *Settings) IsProduction() bool { return s.Env == EnvProduction }
+
+func (u UploadSettings) MaxFileBytes() int64 { return int64(u.MaxFileMB) << 20 }
+func (u UploadSettings) MaxSlipBytes() int   { return u.MaxSlipMB << 20 }
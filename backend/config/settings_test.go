@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadExampleFileWithEnvOverrides(t *testing.T) {
+	t.Cleanup(func() { SetCurrent(nil) })
+	t.Setenv("CONFIG_FILE", filepath.Join("..", "config.example.yaml"))
+	t.Setenv("PORT", "9000")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://shop.example.com, http://localhost:5173")
+	t.Setenv("FEATURE_SLA_CHECKER", "false")
+	t.Setenv("JWT_TTL", "2h")
+	t.Setenv("MAIL_DRIVER", "SMTP")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("MAIL_FROM", "shop@example.com")
+	t.Setenv("FILE_URL_TTL", "5m")
+
+	s, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Port != 9000 || s.Auth.TokenTTL != 2*time.Hour || s.Features.SLAChecker {
+		t.Errorf("env overrides not applied: port=%d ttl=%s sla=%v", s.Port, s.Auth.TokenTTL, s.Features.SLAChecker)
+	}
+	if len(s.CORS.AllowedOrigins) != 2 || s.CORS.AllowedOrigins[0] != "https://shop.example.com" {
+		t.Errorf("origins = %q", s.CORS.AllowedOrigins)
+	}
+	if s.Database.ConnMaxLifetime != 30*time.Minute || s.Upload.MaxFileBytes() != 10<<20 {
+		t.Errorf("file values not applied: %+v %+v", s.Database, s.Upload)
+	}
+	if s.Mail.Driver != "smtp" || s.Mail.SMTPPort != 587 || s.Auth.FileURLTTL != 5*time.Minute || s.Features.SLASupervisor != "หัวหน้างาน" {
+		t.Errorf("mail/auth values: %+v ttl=%s supervisor=%q", s.Mail, s.Auth.FileURLTTL, s.Features.SLASupervisor)
+	}
+	if s.DrainDelay != 5*time.Second {
+		t.Errorf("drain delay = %s", s.DrainDelay)
+	}
+	if s.Location().String() != "Asia/Bangkok" {
+		t.Errorf("location = %s", s.Location())
+	}
+	if Current() != s {
+		t.Error("Load did not set Current")
+	}
+}
+
+func TestLoadRejectsUnknownYAMLKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("prot: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("err = %v, want unknown field error", err)
+	}
+}
+
+func TestValidationReportsEveryProblem(t *testing.T) {
+	s := DefaultSettings()
+	s.Env = EnvProduction
+	s.Port = 0
+	s.Auth.JWTSecret = "short"
+	s.CORS.AllowedOrigins = []string{"*", "localhost:5173"}
+	s.Upload.MaxFileMB = 0
+	s.Shop.Timezone = "Mars/Olympus"
+	s.Auth.PasswordResetURL = "/reset-password"
+	s.Mail.Driver = "smtp"
+	s.Blob.Driver = "s3"
+	s.DrainDelay = s.ShutdownTimeout
+
+	err := s.finish()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, key := range []string{"PORT", "JWT_SECRET", "CORS_ALLOWED_ORIGINS", "UPLOAD_MAX_FILE_MB", "SHOP_TIMEZONE", "EASYSLIP_TOKEN",
+		"PASSWORD_RESET_URL", "SMTP_HOST", "MAIL_FROM", "BLOB_DRIVER", "SHUTDOWN_DRAIN_DELAY"} {
+		if !strings.Contains(err.Error(), key+":") {
+			t.Errorf("error does not mention %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestDevelopmentFallsBackToDevSecret(t *testing.T) {
+	s := DefaultSettings()
+	if err := s.finish(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Auth.JWTSecret == "" || s.Auth.FileURLSecret != s.Auth.JWTSecret {
+		t.Errorf("secrets = %q / %q", s.Auth.JWTSecret, s.Auth.FileURLSecret)
+	}
+
+	s = DefaultSettings()
+	s.Env = EnvProduction
+	s.Features.SlipVerification = false
+	if err := s.finish(); err == nil || !strings.Contains(err.Error(), "JWT_SECRET") {
+		t.Errorf("production without JWT_SECRET: err = %v", err)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -15,7 +17,7 @@ import (
 func GetEmployeeMe(c *gin.Context) {
 	uidVal, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing user in context"})
+		api.Fail(c, api.Unauthorized("", ""))
 		return
 	}
 	userID := uidVal.(uint)
@@ -29,12 +31,42 @@ func GetEmployeeMe(c *gin.Context) {
 		First(&emp).Error; err != nil {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+			api.Fail(c, api.NotFound("", "ไม่พบพนักงาน"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, emp)
 }
+
+// role ของผู้ใช้ที่ล็อกอินอยู่ ("" ถ้าไม่มี token) ใช้ค่าที่ middlewares.RequireRole ตั้งไว้ถ้ามี
+func currentRole(c *gin.Context) string {
+	if v, ok := c.Get("role"); ok {
+		return v.(string)
+	}
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		return ""
+	}
+	role, err := services.UserRole(config.DB, uidVal.(uint))
+	if err != nil {
+		return ""
+	}
+	c.Set("role", role)
+	return role
+}
+
+// คืน EmployeeID ของผู้ใช้ที่ล็อกอินอยู่ (nil ถ้าไม่มี token หรือไม่ใช่พนักงาน)
+func currentEmployeeID(c *gin.Context) *uint {
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		return nil
+	}
+	var emp entity.Employee
+	if err := config.DB.Select("id").Where("user_id = ?", uidVal.(uint)).First(&emp).Error; err != nil {
+		return nil
+	}
+	return &emp.ID
+}
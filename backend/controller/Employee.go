@@ -2,18 +2,16 @@ package controller
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 /* ====================== Utilities ====================== */
@@ -53,116 +51,6 @@ func parseDateThaiAware(s string) (time.Time, error) {
 	return time.Time{}, lastErr
 }
 
-func findOrCreatePosition(tx *gorm.DB, name string) (uint, error) {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return 0, nil
-	}
-	var pos entity.Position
-	if err := tx.Where("position_name = ?", name).First(&pos).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			pos = entity.Position{PositionName: name}
-			if err := tx.Create(&pos).Error; err != nil {
-				return 0, err
-			}
-		} else {
-			return 0, err
-		}
-	}
-	return pos.ID, nil
-}
-
-func modifyPositionCount(tx *gorm.DB, positionID uint, delta int) error {
-	if positionID == 0 {
-		return nil
-	}
-	var pc entity.PositionCount
-	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Where("position_id = ?", positionID).First(&pc).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			pc = entity.PositionCount{PositionID: positionID, TotalEmployee: 0}
-			if err := tx.Create(&pc).Error; err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
-	return tx.Model(&entity.PositionCount{}).
-		Where("position_id = ?", positionID).
-		UpdateColumn("total_employee",
-			gorm.Expr("CASE WHEN total_employee + ? < 0 THEN 0 ELSE total_employee + ? END", delta, delta),
-		).Error
-}
-
-func defaultStatusDescription(name string) string {
-	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "active":
-		return "กำลังปฏิบัติงาน"
-	case "inactive":
-		return "ยังไม่ปฏิบัติงาน"
-	case "onleave":
-		return "ลาพัก"
-	default:
-		return ""
-	}
-}
-
-func upsertEmployeeStatus(tx *gorm.DB, name, desc string) (uint, error) {
-	name = strings.ToLower(strings.TrimSpace(name))
-	desc = strings.TrimSpace(desc)
-	if name == "" {
-		return 0, nil
-	}
-	var st entity.EmployeeStatus
-	err := tx.Where("status_name = ?", name).First(&st).Error
-	switch {
-	case errors.Is(err, gorm.ErrRecordNotFound):
-		if desc == "" {
-			desc = defaultStatusDescription(name)
-		}
-		st = entity.EmployeeStatus{StatusName: name, StatusDescription: desc}
-		if err := tx.Create(&st).Error; err != nil {
-			return 0, err
-		}
-		return st.ID, nil
-	case err != nil:
-		return 0, err
-	default:
-		if desc == "" {
-			desc = defaultStatusDescription(name)
-		}
-		if st.StatusDescription != desc {
-			if err := tx.Model(&st).Update("StatusDescription", desc).Error; err != nil {
-				return 0, err
-			}
-		}
-		return st.ID, nil
-	}
-}
-
-// ---------- NEW: role utilities ----------
-func getOrCreateRoleID(tx *gorm.DB, roleName string) (uint, error) {
-	roleName = strings.TrimSpace(roleName)
-	if roleName == "" {
-		return 0, errors.New("empty role name")
-	}
-	var r entity.Role
-	err := tx.Where("name = ?", roleName).First(&r).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		r = entity.Role{Name: roleName}
-		if err := tx.Create(&r).Error; err != nil {
-			return 0, err
-		}
-		return r.ID, nil
-	}
-	if err != nil {
-		return 0, err
-	}
-	return r.ID, nil
-}
-
 /* ====================== DTO ====================== */
 
 type EmployeePayload struct {
@@ -182,465 +70,158 @@ type EmployeePayload struct {
 	UserID            uint
 }
 
-/* ====================== Handlers ====================== */
-
-// POST /employees
-func CreateEmployee(c *gin.Context) {
-	var p EmployeePayload
-	if err := c.ShouldBindJSON(&p); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if strings.TrimSpace(p.Email) == "" || strings.TrimSpace(p.Password) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email and password are required"})
-		return
-	}
-
-	tx := config.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// position
-	posID := p.PositionID
-	if posID == 0 && strings.TrimSpace(p.Position) != "" {
-		id, err := findOrCreatePosition(tx, p.Position)
-		if err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		posID = id
-	}
-
-	// date
+// วันเริ่มงาน: joinDate หรือ startDate (ว่างทั้งคู่ = nil)
+func (p EmployeePayload) startDate() (*time.Time, error) {
 	jd := strings.TrimSpace(p.JoinDate)
 	if jd == "" {
 		jd = strings.TrimSpace(p.StartDate)
 	}
-	startTime, err := parseDateThaiAware(jd)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(400, gin.H{"error": "invalid join/start date"})
-		return
-	}
-
-	// status + description
-	if strings.TrimSpace(p.StatusDescription) == "" {
-		p.StatusDescription = defaultStatusDescription(p.Status)
+	if jd == "" {
+		return nil, nil
 	}
-	statusID, err := upsertEmployeeStatus(tx, p.Status, p.StatusDescription)
+	t, err := parseDateThaiAware(jd)
 	if err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
+	return &t, nil
+}
 
-	// --------- USER + ROLE = employee ----------
-	// unique email check
-	{
-		var existed entity.User
-		if err := tx.Where("email = ?", p.Email).First(&existed).Error; err == nil {
-			tx.Rollback()
-			c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
-			return
-		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-	}
-	empRoleID, err := getOrCreateRoleID(tx, "employee")
-	if err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": "cannot ensure role: " + err.Error()})
-		return
+func (p EmployeePayload) input(start *time.Time) services.EmployeeInput {
+	return services.EmployeeInput{
+		Code:              p.Code,
+		FirstName:         p.FirstName,
+		LastName:          p.LastName,
+		Gender:            p.Gender,
+		Position:          p.Position,
+		PositionID:        p.PositionID,
+		Phone:             p.Phone,
+		Email:             p.Email,
+		Password:          p.Password,
+		StartDate:         start,
+		Status:            p.Status,
+		StatusDescription: p.StatusDescription,
 	}
+}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(p.Password), bcrypt.DefaultCost)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": "hash password failed"})
-		return
-	}
-	u := entity.User{
-		Email:    p.Email,
-		Password: string(hashed),
-		RoleID:   empRoleID, // 🔗 ผูก role = employee
-	}
-	if err := tx.Create(&u).Error; err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": err.Error()})
-		return
+// แปลง error ของ EmployeeService เป็นคำตอบ HTTP
+func writeEmployeeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrEmployeeNotFound):
+		api.Fail(c, api.NotFound("", "ไม่พบพนักงาน"))
+	case errors.Is(err, services.ErrCredentialsRequired):
+		api.Fail(c, api.BadRequest("", "กรุณากรอกอีเมลและรหัสผ่าน"))
+	case errors.Is(err, services.ErrPasswordRequired):
+		api.Fail(c, api.BadRequest("", "กรุณาระบุรหัสผ่านสำหรับสร้างบัญชีผู้ใช้"))
+	case errors.Is(err, services.ErrEmailTaken):
+		api.Fail(c, api.Conflict("", "อีเมลนี้ถูกใช้แล้ว"))
+	case errors.Is(err, services.ErrEmployeeCodeTaken):
+		api.Fail(c, api.Conflict("", "รหัสพนักงานนี้ถูกใช้แล้ว"))
+	default:
+		passwordErrorJSON(c, err)
 	}
+}
 
-	// code unique check (optional)
-	code := strings.TrimSpace(p.Code)
-	if code != "" {
-		var dup entity.Employee
-		if err := tx.Where("code = ?", code).First(&dup).Error; err == nil {
-			tx.Rollback()
-			c.JSON(http.StatusConflict, gin.H{"error": "employeeCode already exists"})
-			return
-		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-	}
+/* ====================== Handlers ====================== */
 
-	emp := entity.Employee{
-		Code:             code,
-		FirstName:        p.FirstName,
-		LastName:         p.LastName,
-		Gender:           strings.ToLower(strings.TrimSpace(p.Gender)),
-		Phone:            p.Phone,
-		StartDate:        startTime,
-		UserID:           u.ID,
-		PositionID:       posID,
-		EmployeeStatusID: statusID,
-	}
-	if err := tx.Create(&emp).Error; err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": err.Error()})
+// POST /employees
+func CreateEmployee(c *gin.Context) {
+	var p EmployeePayload
+	if !api.BindJSON(c, &p) {
 		return
 	}
-
-	// gen code if empty
-	if emp.Code == "" {
-		gen := fmt.Sprintf("EMP%03d", emp.ID)
-		if err := tx.Model(&entity.Employee{}).Where("id = ?", emp.ID).Update("code", gen).Error; err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		emp.Code = gen
-	}
-
-	// adjust position count
-	if posID != 0 {
-		if err := modifyPositionCount(tx, posID, 1); err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
+	if strings.TrimSpace(p.Email) == "" || strings.TrimSpace(p.Password) == "" {
+		api.Fail(c, api.BadRequest("", "กรุณากรอกอีเมลและรหัสผ่าน"))
+		return
 	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	start, err := p.startDate()
+	if err != nil || start == nil {
+		api.Fail(c, api.BadRequest("", "วันที่เริ่มงานไม่ถูกต้อง"))
 		return
 	}
 
-	var created entity.Employee
-	if err := config.DB.Preload("User").Preload("Position").Preload("EmployeeStatus").
-		First(&created, emp.ID).Error; err != nil {
-		c.JSON(http.StatusCreated, gin.H{"id": emp.ID})
+	emp, err := services.NewEmployeeService(auditDB(c)).Create(p.input(start))
+	if err != nil {
+		writeEmployeeError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, created)
+	c.JSON(http.StatusCreated, emp)
 }
 
 // GET /employees/:id
 func GetEmployee(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid id"})
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
-	var emp entity.Employee
-	if err := config.DB.Preload("User").Preload("Position").Preload("EmployeeStatus").
-		First(&emp, uint(id)).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(404, gin.H{"error": "employee not found"})
-		} else {
-			c.JSON(500, gin.H{"error": err.Error()})
-		}
+	emp, err := services.NewEmployeeService(config.DB).Get(id)
+	if err != nil {
+		writeEmployeeError(c, err)
 		return
 	}
-	c.JSON(200, emp)
+	c.JSON(http.StatusOK, emp)
+}
+
+// GET /employees?q=&positionId=&statusId=&gender=&sort=code
+var employeeList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"positionId": {Column: "employees.position_id", Op: api.OpID},
+		"statusId":   {Column: "employees.employee_status_id", Op: api.OpID},
+		"gender":     {Column: "employees.gender", Op: api.OpEq},
+	},
+	Sorts: map[string]string{
+		"id":        "employees.id",
+		"code":      "employees.code",
+		"firstName": "employees.first_name",
+		"lastName":  "employees.last_name",
+		"startDate": "employees.start_date",
+	},
+	Search: []string{"employees.code", "employees.first_name", "employees.last_name", "employees.phone", "users.email"},
 }
 
-// GET /employees
 func ListEmployees(c *gin.Context) {
 	var list []entity.Employee
-	if err := config.DB.Preload("User").Preload("Position").Preload("EmployeeStatus").
-		Find(&list).Error; err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	meta, ok := employeeList.Find(c, config.DB.Model(&entity.Employee{}).
+		Joins("LEFT JOIN users ON users.id = employees.user_id").
+		Preload("User").Preload("Position").Preload("EmployeeStatus"), &list)
+	if !ok {
 		return
 	}
-	c.JSON(200, list)
+	api.List(c, list, meta)
 }
 
 // PUT /employees/:id
 func UpdateEmployee(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid id"})
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
 	var p EmployeePayload
-	if err := c.ShouldBindJSON(&p); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	tx := config.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	var e entity.Employee
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Preload("User").
-		First(&e, uint(id)).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			tx.Rollback()
-			c.JSON(404, gin.H{"error": "employee not found"})
-		} else {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-		}
-		return
-	}
-
-	// code
-	if code := strings.TrimSpace(p.Code); code != "" && code != e.Code {
-		var dup entity.Employee
-		if err := tx.Where("code = ?", code).First(&dup).Error; err == nil && dup.ID != e.ID {
-			tx.Rollback()
-			c.JSON(http.StatusConflict, gin.H{"error": "employeeCode already exists"})
-			return
-		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		e.Code = code
-	}
-
-	// position
-	newPosID := p.PositionID
-	if newPosID == 0 && strings.TrimSpace(p.Position) != "" {
-		idp, err := findOrCreatePosition(tx, p.Position)
-		if err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		newPosID = idp
-	}
-	oldPosID := e.PositionID
-
-	// date
-	jd := strings.TrimSpace(p.JoinDate)
-	if jd == "" {
-		jd = strings.TrimSpace(p.StartDate)
-	}
-	if jd != "" {
-		t, err := parseDateThaiAware(jd)
-		if err != nil {
-			tx.Rollback()
-			c.JSON(400, gin.H{"error": "invalid join/start date"})
-			return
-		}
-		e.StartDate = t
-	}
-
-	// status
-	if strings.TrimSpace(p.Status) != "" {
-		if strings.TrimSpace(p.StatusDescription) == "" {
-			p.StatusDescription = defaultStatusDescription(p.Status)
-		}
-		statusID, err := upsertEmployeeStatus(tx, p.Status, p.StatusDescription)
-		if err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		e.EmployeeStatusID = statusID
-	}
-
-	// fields
-	e.FirstName = p.FirstName
-	e.LastName = p.LastName
-	e.Gender = strings.ToLower(strings.TrimSpace(p.Gender))
-	e.Phone = p.Phone
-	e.PositionID = newPosID
-
-	// user (email/password) + ensure role = employee เมื่อสร้างใหม่
-	if e.UserID != 0 {
-		var user entity.User
-		if err := tx.First(&user, e.UserID).Error; err == nil {
-			if strings.TrimSpace(p.Email) != "" && p.Email != user.Email {
-				var exists entity.User
-				if err := tx.Where("email = ?", p.Email).First(&exists).Error; err == nil && exists.ID != user.ID {
-					tx.Rollback()
-					c.JSON(409, gin.H{"error": "email already exists"})
-					return
-				}
-				user.Email = p.Email
-			}
-			if strings.TrimSpace(p.Password) != "" {
-				hashed, err := bcrypt.GenerateFromPassword([]byte(p.Password), bcrypt.DefaultCost)
-				if err != nil {
-					tx.Rollback()
-					c.JSON(500, gin.H{"error": "hash password failed"})
-					return
-				}
-				user.Password = string(hashed)
-			}
-			// ถ้า user ยังไม่มี role (กรณีข้อมูลเก่า) -> set เป็น employee
-			if user.RoleID == 0 {
-				empRoleID, err := getOrCreateRoleID(tx, "employee")
-				if err != nil {
-					tx.Rollback()
-					c.JSON(500, gin.H{"error": "cannot ensure role: " + err.Error()})
-					return
-				}
-				user.RoleID = empRoleID
-			}
-			if err := tx.Save(&user).Error; err != nil {
-				tx.Rollback()
-				c.JSON(500, gin.H{"error": err.Error()})
-				return
-			}
-		}
-	} else if strings.TrimSpace(p.Email) != "" {
-		// สร้าง user ใหม่และผูก role = employee
-		if strings.TrimSpace(p.Password) == "" {
-			tx.Rollback()
-			c.JSON(400, gin.H{"error": "password required to create user"})
-			return
-		}
-		var exists entity.User
-		if err := tx.Where("email = ?", p.Email).First(&exists).Error; err == nil {
-			tx.Rollback()
-			c.JSON(409, gin.H{"error": "email already exists"})
-			return
-		}
-		empRoleID, err := getOrCreateRoleID(tx, "employee")
-		if err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": "cannot ensure role: " + err.Error()})
-			return
-		}
-		hashed, err := bcrypt.GenerateFromPassword([]byte(p.Password), bcrypt.DefaultCost)
-		if err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": "hash password failed"})
-			return
-		}
-		u := entity.User{Email: p.Email, Password: string(hashed), RoleID: empRoleID}
-		if err := tx.Create(&u).Error; err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		e.UserID = u.ID
-	}
-
-	if err := tx.Save(&e).Error; err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &p) {
 		return
 	}
-
-	// adjust position count
-	if oldPosID != newPosID {
-		if oldPosID != 0 {
-			if err := modifyPositionCount(tx, oldPosID, -1); err != nil {
-				tx.Rollback()
-				c.JSON(500, gin.H{"error": err.Error()})
-				return
-			}
-		}
-		if newPosID != 0 {
-			if err := modifyPositionCount(tx, newPosID, 1); err != nil {
-				tx.Rollback()
-				c.JSON(500, gin.H{"error": err.Error()})
-				return
-			}
-		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	start, err := p.startDate()
+	if err != nil {
+		api.Fail(c, api.BadRequest("", "วันที่เริ่มงานไม่ถูกต้อง"))
 		return
 	}
 
-	var updated entity.Employee
-	if err := config.DB.Preload("User").Preload("Position").Preload("EmployeeStatus").
-		First(&updated, uint(id)).Error; err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	emp, err := services.NewEmployeeService(auditDB(c)).Update(id, p.input(start))
+	if err != nil {
+		writeEmployeeError(c, err)
 		return
 	}
-	c.JSON(200, updated)
+	c.JSON(http.StatusOK, emp)
 }
 
-// DELETE /employees/:id
+// DELETE /employees/:id (ลบ user ที่ผูกด้วย; ไม่พบ = 204 เหมือนลบแล้ว)
 func DeleteEmployee(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid id"})
-		return
-	}
-
-	tx := config.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	var emp entity.Employee
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		First(&emp, uint(id)).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			tx.Rollback()
-			c.Status(204)
-		} else {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-		}
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
-	posID := emp.PositionID
-	userID := emp.UserID
-
-	if err := tx.Delete(&entity.Employee{}, uint(id)).Error; err != nil {
-		tx.Rollback()
-		c.JSON(500, gin.H{"error": err.Error()})
-		return
-	}
-	// ลบ user ที่ผูก (คงพฤติกรรมเดิม)
-	if userID != 0 {
-		if err := tx.Delete(&entity.User{}, userID).Error; err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-	}
-	if posID != 0 {
-		if err := modifyPositionCount(tx, posID, -1); err != nil {
-			tx.Rollback()
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	if err := services.NewEmployeeService(auditDB(c)).Delete(id); err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
-	c.Status(204)
-}
\ No newline at end of file
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// บัญชีผู้ใช้ (ทุก role: ลูกค้า/พนักงาน/admin)
+// - POST /me/password          {oldPassword, newPassword}   (ต้องแนบ token)
+// - POST /password/forgot      {email}  -> ส่งลิงก์รีเซ็ตทางอีเมล
+// - POST /password/reset       {token, newPassword}
+// - PUT  /employee/me          {firstName, lastName, phone, gender}  พนักงานแก้โปรไฟล์ตัวเอง
+// ======================================================
+
+var (
+	mailerMu sync.RWMutex
+	mailer   services.Mailer
+)
+
+// SetMailer กำหนดตัวส่งอีเมล (เรียกตอนเริ่มโปรแกรม)
+func SetMailer(m services.Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	mailer = m
+}
+
+func currentMailer() services.Mailer {
+	mailerMu.RLock()
+	m := mailer
+	mailerMu.RUnlock()
+	if m == nil {
+		m = services.NewLocalMailer("./mail-outbox")
+		SetMailer(m)
+	}
+	return m
+}
+
+// ลิงก์หน้าตั้งรหัสผ่านใหม่ฝั่ง frontend (PASSWORD_RESET_URL) แนบ ?token=
+func passwordResetLink(token string) string {
+	base := strings.TrimSpace(config.Current().Auth.PasswordResetURL)
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
+
+// แปลง error จากการตั้งรหัสผ่านเป็น response
+func passwordErrorJSON(c *gin.Context, err error) {
+	switch {
+	case services.IsPasswordPolicyError(err):
+		api.Fail(c, api.BadRequest("password_policy", err.Error()))
+	case errors.Is(err, services.ErrPasswordUnchanged):
+		api.Fail(c, api.BadRequest("password_unchanged", err.Error()))
+	case errors.Is(err, services.ErrResetTokenInvalid):
+		api.Fail(c, api.BadRequest("reset_token_invalid", err.Error()))
+	default:
+		api.Fail(c, api.Internal(err))
+	}
+}
+
+type changePasswordIn struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// POST /me/password
+func ChangeMyPassword(c *gin.Context) {
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		api.Fail(c, api.Unauthorized("", ""))
+		return
+	}
+	var in changePasswordIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+
+	var user entity.User
+	if err := config.DB.First(&user, uidVal.(uint)).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบผู้ใช้"))
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(in.OldPassword)) != nil {
+		api.Fail(c, api.Unauthorized("", "รหัสผ่านเดิมไม่ถูกต้อง"))
+		return
+	}
+	if in.NewPassword == in.OldPassword {
+		passwordErrorJSON(c, services.ErrPasswordUnchanged)
+		return
+	}
+	hashed, err := services.HashPassword(in.NewPassword, user.Email)
+	if err != nil {
+		passwordErrorJSON(c, err)
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", hashed).Error; err != nil {
+			return err
+		}
+		// เปลี่ยนรหัสแล้ว ลิงก์รีเซ็ตที่ค้างอยู่ใช้ไม่ได้อีก
+		return tx.Model(&entity.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error
+	})
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	recordAuthEvent(c, &user.ID, user.Email, AuthEventPasswordChange, "")
+	c.JSON(http.StatusOK, gin.H{"message": "เปลี่ยนรหัสผ่านเรียบร้อย"})
+}
+
+type forgotPasswordIn struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// POST /password/forgot
+// ตอบข้อความเดียวกันเสมอ ไม่บอกว่ามีอีเมลนี้ในระบบหรือไม่ (ส่งอีเมลไม่สำเร็จก็ตอบเหมือนเดิม แล้วบันทึก log ไว้)
+func ForgotPassword(c *gin.Context) {
+	var in forgotPasswordIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	okMsg := gin.H{"message": "หากอีเมลนี้มีบัญชีอยู่ ระบบได้ส่งลิงก์ตั้งรหัสผ่านใหม่ไปแล้ว"}
+
+	var user entity.User
+	err := config.DB.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(in.Email))).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		recordAuthEvent(c, nil, in.Email, AuthEventResetRequest, "unknown_email")
+		c.JSON(http.StatusOK, okMsg)
+		return
+	}
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	token, exp, err := services.IssuePasswordReset(config.DB, user.ID, time.Now())
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	msg := services.MailMessage{
+		To:      user.Email,
+		Subject: "ตั้งรหัสผ่านใหม่",
+		Body: "มีคำขอตั้งรหัสผ่านใหม่สำหรับบัญชีนี้\n\n" +
+			"เปิดลิงก์ด้านล่างเพื่อตั้งรหัสผ่านใหม่ (ใช้ได้ครั้งเดียว หมดอายุ " +
+			exp.In(shopLocation()).Format("02/01/2006 15:04") + " น.)\n" +
+			passwordResetLink(token) + "\n\n" +
+			"หากไม่ได้ขอตั้งรหัสผ่านใหม่ ไม่ต้องทำอะไร รหัสผ่านเดิมยังใช้ได้ตามปกติ\n",
+	}
+	if err := currentMailer().Send(c.Request.Context(), msg); err != nil {
+		slog.ErrorContext(c.Request.Context(), "password reset mail failed", "user_id", user.ID, "error", err)
+		recordAuthEvent(c, &user.ID, user.Email, AuthEventResetRequest, "mail_failed")
+		c.JSON(http.StatusOK, okMsg)
+		return
+	}
+	recordAuthEvent(c, &user.ID, user.Email, AuthEventResetRequest, "")
+	c.JSON(http.StatusOK, okMsg)
+}
+
+type resetPasswordIn struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// POST /password/reset
+func ResetPassword(c *gin.Context) {
+	var in resetPasswordIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	user, err := services.ResetPassword(config.DB, in.Token, in.NewPassword, time.Now())
+	if err != nil {
+		passwordErrorJSON(c, err)
+		return
+	}
+	recordAuthEvent(c, &user.ID, user.Email, AuthEventPasswordReset, "")
+	c.JSON(http.StatusOK, gin.H{"message": "ตั้งรหัสผ่านใหม่เรียบร้อย กรุณาเข้าสู่ระบบอีกครั้ง"})
+}
+
+// ======================================================
+// PUT /employee/me  (พนักงานแก้ข้อมูลส่วนตัว; ตำแหน่ง/สถานะ/อีเมลยังต้องให้ admin แก้)
+// ======================================================
+
+type employeeProfileIn struct {
+	FirstName string `json:"firstName" binding:"required"`
+	LastName  string `json:"lastName"`
+	Phone     string `json:"phone"`
+	Gender    string `json:"gender"`
+}
+
+func UpdateEmployeeMe(c *gin.Context) {
+	id := currentEmployeeID(c)
+	if id == nil {
+		api.Fail(c, api.NotFound("", "ไม่พบพนักงาน"))
+		return
+	}
+	var in employeeProfileIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	in.FirstName = strings.TrimSpace(in.FirstName)
+	if in.FirstName == "" {
+		api.Fail(c, api.BadRequest("", "กรุณาระบุชื่อ"))
+		return
+	}
+	if err := config.DB.Model(&entity.Employee{}).Where("id = ?", *id).Updates(map[string]interface{}{
+		"first_name": in.FirstName,
+		"last_name":  strings.TrimSpace(in.LastName),
+		"phone":      strings.TrimSpace(in.Phone),
+		"gender":     strings.ToLower(strings.TrimSpace(in.Gender)),
+	}).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	GetEmployeeMe(c)
+}
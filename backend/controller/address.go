@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,7 +21,7 @@ type AddressPayload struct {
 func CreateAddress(c *gin.Context) {
 	userIDRaw, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		api.Fail(c, api.Unauthorized("", ""))
 		return
 	}
 	userID := userIDRaw.(uint)
@@ -27,26 +29,23 @@ func CreateAddress(c *gin.Context) {
 	// ดึง customer จาก userID
 	var customer entity.Customer
 	if err := config.DB.Where("user_id = ?", userID).First(&customer).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 
 	var payload AddressPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
-	address := entity.Address{
+	address, err := services.NewAddressService(config.DB).Create(services.NewAddress{
 		CustomerID:     customer.ID, // ใช้ customer.ID ที่ถูกต้อง
 		AddressDetails: payload.AddressDetails,
 		Latitude:       payload.Latitude,
 		Longitude:      payload.Longitude,
-		IsDefault:      false,
-	}
-
-	if err := config.DB.Create(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	})
+	if err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -85,14 +84,13 @@ func UpdateAddress(c *gin.Context) {
 	addressID, _ := strconv.Atoi(id)
 
 	var payload AddressPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
 	var address entity.Address
 	if err := config.DB.First(&address, addressID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบที่อยู่"))
 		return
 	}
 
@@ -101,7 +99,7 @@ func UpdateAddress(c *gin.Context) {
 	address.Longitude = payload.Longitude
 
 	if err := config.DB.Save(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -115,19 +113,12 @@ func SetMainAddress(c *gin.Context) {
 
 	var address entity.Address
 	if err := config.DB.First(&address, addressID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบที่อยู่"))
 		return
 	}
 
-	// Reset main ของลูกค้าคนนี้ก่อน
-	config.DB.Model(&entity.Address{}).
-		Where("customer_id = ?", address.CustomerID).
-		Update("is_default", false)
-
-	// ตั้งค่าที่เลือกเป็น main
-	address.IsDefault = true
-	if err := config.DB.Save(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := services.NewAddressService(config.DB).SetDefault(address.CustomerID, address.ID); err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -141,12 +132,12 @@ func DeleteAddress(c *gin.Context) {
 
 	var address entity.Address
 	if err := config.DB.First(&address, addressID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบที่อยู่"))
 		return
 	}
 
 	if err := config.DB.Delete(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
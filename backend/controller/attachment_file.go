@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// ไฟล์แนบคำร้องเรียน: เก็บผ่าน BlobStore + ดาวน์โหลดด้วยลิงก์ลงลายเซ็น
+// - GET /attachments/:id?variant=thumb&exp=&sig=
+// ======================================================
+
+var (
+	blobStoreMu sync.RWMutex
+	blobStore   services.BlobStore
+)
+
+// SetAttachmentStore กำหนด BlobStore (เรียกตอนเริ่มโปรแกรม)
+func SetAttachmentStore(s services.BlobStore) {
+	blobStoreMu.Lock()
+	defer blobStoreMu.Unlock()
+	blobStore = s
+}
+
+func attachmentStore() services.BlobStore {
+	blobStoreMu.RLock()
+	s := blobStore
+	blobStoreMu.RUnlock()
+	if s == nil {
+		s = services.NewLocalBlobStore("./uploads")
+		SetAttachmentStore(s)
+	}
+	return s
+}
+
+// อ่านไฟล์ทั้งก้อน (จำกัดขนาดซ้ำอีกชั้นเผื่อ header โกหก)
+func readUploadedFile(fh *multipart.FileHeader, limit int64) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("file too large")
+	}
+	return data, nil
+}
+
+// ลิงก์ดาวน์โหลดแบบ absolute ที่ลงลายเซ็นแล้ว
+func signedAttachmentURL(c *gin.Context, id uint, variant string, exp time.Time) string {
+	return fmt.Sprintf("%s/attachments/%d?%s", absoluteBaseURL(c), id, services.SignFileQuery(id, variant, exp))
+}
+
+func attachmentItemFor(c *gin.Context, a *entity.ComplaintAttachment) attachmentItem {
+	exp := time.Now().Add(services.FileURLTTL())
+	item := attachmentItem{
+		URL:       signedAttachmentURL(c, a.ID, "", exp),
+		Name:      a.FileName,
+		Mime:      strings.TrimSpace(a.MimeType),
+		Size:      a.SizeBytes,
+		ExpiresAt: &exp,
+	}
+	if a.ThumbKey != "" {
+		item.Thumb = signedAttachmentURL(c, a.ID, "thumb", exp)
+	}
+	return item
+}
+
+// ไฟล์ที่อัปโหลดก่อนมี BlobStore ไม่มี StorageKey -> ใช้ตำแหน่งเดิม complaints/<PublicID>/<FileName>
+func attachmentKey(a *entity.ComplaintAttachment) (string, error) {
+	if a.StorageKey != "" {
+		return a.StorageKey, nil
+	}
+	if a.ComplaintID == nil {
+		return "", services.ErrBlobNotFound
+	}
+	var comp entity.Complaint
+	if err := config.DB.Select("public_id").First(&comp, *a.ComplaintID).Error; err != nil {
+		return "", err
+	}
+	return path.Join("complaints", comp.PublicID, a.FileName), nil
+}
+
+func ServeAttachment(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		api.Fail(c, api.InvalidID("id"))
+		return
+	}
+	id := uint(id64)
+	variant := c.Query("variant")
+	if variant != "" && variant != "thumb" {
+		api.Fail(c, api.BadRequest("", "variant ไม่ถูกต้อง"))
+		return
+	}
+	if !services.VerifyFileSignature(id, variant, c.Query("exp"), c.Query("sig"), time.Now()) {
+		api.Fail(c, api.Forbidden("", "ลิงก์ไม่ถูกต้องหรือหมดอายุแล้ว"))
+		return
+	}
+
+	var att entity.ComplaintAttachment
+	if err := config.DB.First(&att, id).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบไฟล์"))
+		return
+	}
+
+	key, mime, name := "", strings.TrimSpace(att.MimeType), att.FileName
+	if variant == "thumb" {
+		if att.ThumbKey == "" {
+			api.Fail(c, api.NotFound("", "ไม่มีรูปย่อ"))
+			return
+		}
+		key, mime = att.ThumbKey, "image/jpeg"
+		name = strings.TrimSuffix(name, path.Ext(name)) + "_thumb.jpg"
+	} else if key, err = attachmentKey(&att); err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบไฟล์"))
+		return
+	}
+
+	rc, err := attachmentStore().Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, services.ErrBlobNotFound) {
+			api.Fail(c, api.NotFound("", "ไม่พบไฟล์"))
+		} else {
+			api.Fail(c, api.Internal(err))
+		}
+		return
+	}
+	defer rc.Close()
+
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	c.Header("Content-Type", mime)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, sanitize(name)))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, rc)
+}
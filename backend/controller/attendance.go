@@ -0,0 +1,327 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ลงเวลาเข้า-ออกงาน (ต้องแนบ token พนักงาน)
+// - POST /employee/me/clock-in
+// - POST /employee/me/clock-out
+// - GET  /employee/me/attendance?month=YYYY-MM
+// รายงาน
+// - GET  /attendance/report?month=YYYY-MM&employeeId=
+// ======================================================
+
+func requireEmployee(c *gin.Context) (*entity.Employee, bool) {
+	id := currentEmployeeID(c)
+	if id == nil {
+		api.Fail(c, api.Forbidden("", "เฉพาะพนักงานที่เข้าสู่ระบบเท่านั้น"))
+		return nil, false
+	}
+	var emp entity.Employee
+	if err := config.DB.Preload("EmployeeStatus").First(&emp, *id).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return nil, false
+	}
+	return &emp, true
+}
+
+type clockNoteIn struct {
+	Note string `json:"note"`
+}
+
+func ClockIn(c *gin.Context) {
+	emp, ok := requireEmployee(c)
+	if !ok {
+		return
+	}
+	var in clockNoteIn
+	_ = c.ShouldBindJSON(&in) // body ไม่บังคับ
+
+	if emp.EmployeeStatus != nil && strings.EqualFold(emp.EmployeeStatus.StatusName, services.EmployeeStatusOnLeave) {
+		api.Fail(c, api.Conflict("", "อยู่ระหว่างลา ไม่สามารถลงเวลาเข้างานได้"))
+		return
+	}
+
+	var open int64
+	config.DB.Model(&entity.Attendance{}).Where("employee_id = ? AND clock_out_at IS NULL", emp.ID).Count(&open)
+	if open > 0 {
+		api.Fail(c, api.Conflict("", "ลงเวลาเข้างานไว้แล้ว กรุณาลงเวลาออกก่อน"))
+		return
+	}
+
+	now := time.Now()
+	shift, err := services.FindScheduledShift(config.DB, emp.ID, now)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	att := entity.Attendance{
+		EmployeeID: emp.ID,
+		WorkDate:   shift.WorkDate,
+		ClockInAt:  now,
+		Note:       strings.TrimSpace(in.Note),
+	}
+	if shift.Template != nil {
+		att.ShiftTemplateID = &shift.Template.ID
+		att.ScheduledStart = &shift.Start
+		att.ScheduledEnd = &shift.End
+		if now.After(shift.Start) {
+			att.LateMinutes = int(now.Sub(shift.Start).Minutes())
+		}
+	}
+	if err := config.DB.Create(&att).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, att)
+}
+
+func ClockOut(c *gin.Context) {
+	emp, ok := requireEmployee(c)
+	if !ok {
+		return
+	}
+	var in clockNoteIn
+	_ = c.ShouldBindJSON(&in)
+
+	var att entity.Attendance
+	err := config.DB.Preload("ShiftTemplate").
+		Where("employee_id = ? AND clock_out_at IS NULL", emp.ID).
+		Order("clock_in_at DESC").First(&att).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.Fail(c, api.Conflict("", "ยังไม่ได้ลงเวลาเข้างาน"))
+		return
+	}
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	now := time.Now()
+	worked := int(now.Sub(att.ClockInAt).Minutes())
+	if att.ShiftTemplate != nil && worked > att.ShiftTemplate.BreakMinutes {
+		worked -= att.ShiftTemplate.BreakMinutes
+	}
+	updates := map[string]interface{}{
+		"clock_out_at":   now,
+		"worked_minutes": worked,
+	}
+	if att.ScheduledEnd != nil && now.Before(*att.ScheduledEnd) {
+		updates["early_leave_minutes"] = int(att.ScheduledEnd.Sub(now).Minutes())
+	}
+	if note := strings.TrimSpace(in.Note); note != "" {
+		updates["note"] = strings.TrimSpace(att.Note + "\n" + note)
+	}
+	if err := config.DB.Model(&att).Updates(updates).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	config.DB.First(&att, att.ID)
+	c.JSON(http.StatusOK, att)
+}
+
+// ======================================================
+// สรุปการเข้างานรายเดือน
+// ======================================================
+
+type attendanceSummary struct {
+	EmployeeID        uint   `json:"employeeId"`
+	Name              string `json:"name"`
+	Position          string `json:"position,omitempty"`
+	ScheduledDays     int    `json:"scheduledDays"` // วันที่มีกะในตาราง (ถึงวันนี้)
+	PresentDays       int    `json:"presentDays"`
+	LateDays          int    `json:"lateDays"`
+	LateMinutes       int    `json:"lateMinutes"`
+	EarlyLeaveMinutes int    `json:"earlyLeaveMinutes"`
+	WorkedMinutes     int    `json:"workedMinutes"`
+	LeaveDays         int    `json:"leaveDays"`  // วันลาที่อนุมัติแล้วในเดือนนี้
+	AbsentDays        int    `json:"absentDays"` // มีกะ ไม่ได้ลา และไม่ได้ลงเวลา
+}
+
+// เดือนของรายงาน -> วันแรกของเดือน (เขตเวลาร้าน)
+func parseAttendanceMonth(c *gin.Context) (time.Time, error) {
+	loc := shopLocation()
+	s := strings.TrimSpace(c.Query("month"))
+	if s == "" {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc), nil
+	}
+	return time.ParseInLocation("2006-01", s, loc)
+}
+
+func buildAttendanceSummaries(db *gorm.DB, month time.Time, employeeID *uint) ([]attendanceSummary, error) {
+	first := month.Format("2006-01-02")
+	next := month.AddDate(0, 1, 0)
+	last := next.AddDate(0, 0, -1).Format("2006-01-02")
+	today := time.Now().In(shopLocation()).Format("2006-01-02")
+
+	empQ := db.Preload("Position").Order("id")
+	if employeeID != nil {
+		empQ = empQ.Where("id = ?", *employeeID)
+	}
+	var emps []entity.Employee
+	if err := empQ.Find(&emps).Error; err != nil {
+		return nil, err
+	}
+
+	var rosters []entity.EmployeeRoster
+	var atts []entity.Attendance
+	var leaves []entity.LeaveRequest
+	if err := db.Find(&rosters).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("work_date >= ? AND work_date <= ?", first, last).Find(&atts).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("status = ? AND start_date <= ? AND end_date >= ?", services.LeaveApproved, last, first).
+		Find(&leaves).Error; err != nil {
+		return nil, err
+	}
+
+	// วันในสัปดาห์ที่มีกะ -> เริ่มนับตั้งแต่วันที่จัดตาราง (ไม่นับขาดงานย้อนหลัง)
+	workdays := map[uint]map[int]string{}
+	for _, r := range rosters {
+		if workdays[r.EmployeeID] == nil {
+			workdays[r.EmployeeID] = map[int]string{}
+		}
+		workdays[r.EmployeeID][r.Weekday] = businessDate(r.CreatedAt)
+	}
+	present := map[uint]map[string]bool{}
+	out := make([]attendanceSummary, 0, len(emps))
+	idx := map[uint]int{}
+	for i, e := range emps {
+		s := attendanceSummary{EmployeeID: e.ID, Name: employeeDisplayName(&emps[i], e.ID)}
+		if e.Position != nil {
+			s.Position = e.Position.PositionName
+		}
+		idx[e.ID] = len(out)
+		out = append(out, s)
+		present[e.ID] = map[string]bool{}
+	}
+	for _, a := range atts {
+		i, ok := idx[a.EmployeeID]
+		if !ok {
+			continue
+		}
+		s := &out[i]
+		if !present[a.EmployeeID][a.WorkDate] {
+			present[a.EmployeeID][a.WorkDate] = true
+			s.PresentDays++
+		}
+		if a.LateMinutes > 0 {
+			s.LateDays++
+			s.LateMinutes += a.LateMinutes
+		}
+		s.EarlyLeaveMinutes += a.EarlyLeaveMinutes
+		s.WorkedMinutes += a.WorkedMinutes
+	}
+	onLeave := map[uint]map[string]bool{}
+	for _, l := range leaves {
+		if _, ok := idx[l.EmployeeID]; !ok {
+			continue
+		}
+		if onLeave[l.EmployeeID] == nil {
+			onLeave[l.EmployeeID] = map[string]bool{}
+		}
+		for d := month; d.Before(next); d = d.AddDate(0, 0, 1) {
+			ds := d.Format("2006-01-02")
+			if ds >= l.StartDate && ds <= l.EndDate {
+				onLeave[l.EmployeeID][ds] = true
+			}
+		}
+	}
+
+	for i := range out {
+		s := &out[i]
+		s.LeaveDays = len(onLeave[s.EmployeeID])
+		for d := month; d.Before(next); d = d.AddDate(0, 0, 1) {
+			ds := d.Format("2006-01-02")
+			since, ok := workdays[s.EmployeeID][int(d.Weekday())]
+			if !ok || ds < since || ds > today {
+				continue
+			}
+			s.ScheduledDays++
+			// วันนี้ยังไม่จบ ไม่นับขาดงาน
+			if ds < today && !present[s.EmployeeID][ds] && !onLeave[s.EmployeeID][ds] {
+				s.AbsentDays++
+			}
+		}
+	}
+	return out, nil
+}
+
+// GET /employee/me/attendance?month=YYYY-MM&open=true&sort=clockInAt
+var myAttendanceList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"open": {Column: "clock_out_at", Op: api.OpNull}, // true = ยังไม่ลงเวลาออก
+	},
+	Sorts:       map[string]string{"id": "id", "clockInAt": "clock_in_at", "workDate": "work_date", "lateMinutes": "late_minutes"},
+	DefaultSort: "clockInAt",
+}
+
+func GetMyAttendance(c *gin.Context) {
+	emp, ok := requireEmployee(c)
+	if !ok {
+		return
+	}
+	month, err := parseAttendanceMonth(c)
+	if err != nil {
+		api.Fail(c, api.BadRequest("", "month ต้องอยู่ในรูปแบบ YYYY-MM"))
+		return
+	}
+	var items []entity.Attendance
+	meta, ok := myAttendanceList.Find(c, config.DB.Model(&entity.Attendance{}).Preload("ShiftTemplate").
+		Where("employee_id = ? AND work_date >= ? AND work_date <= ?", emp.ID,
+			month.Format("2006-01-02"), month.AddDate(0, 1, -1).Format("2006-01-02")), &items)
+	if !ok {
+		return
+	}
+	sums, err := buildAttendanceSummaries(config.DB, month, &emp.ID)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	var summary *attendanceSummary
+	if len(sums) > 0 {
+		summary = &sums[0]
+	}
+	api.List(c, items, meta, gin.H{"month": month.Format("2006-01"), "summary": summary})
+}
+
+// GET /attendance/report?month=YYYY-MM&employeeId=
+func AttendanceReport(c *gin.Context) {
+	month, err := parseAttendanceMonth(c)
+	if err != nil {
+		api.Fail(c, api.BadRequest("", "month ต้องอยู่ในรูปแบบ YYYY-MM"))
+		return
+	}
+	var empID *uint
+	if s := strings.TrimSpace(c.Query("employeeId")); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			api.Fail(c, api.BadRequest("", "employeeId ไม่ถูกต้อง"))
+			return
+		}
+		uid := uint(id)
+		empID = &uid
+	}
+	items, err := buildAttendanceSummaries(config.DB, month, empID)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	api.ListAll(c, items, gin.H{"month": month.Format("2006-01")})
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// Audit log การแก้ข้อมูลฝั่ง admin (บันทึกโดย services/audit.go)
+// - GET /audit?entity=promotion&id=&actor=&action=&field=&from=&to=&sort=-id&page=&pageSize=
+//   เช่น ใครแก้ส่วนลดโปรโมชัน #3 สัปดาห์ก่อน:
+//   /audit?entity=promotion&id=3&field=discount_value&from=2025-01-06&to=2025-01-12
+// - GET /audit/entities
+// ======================================================
+
+// auditDB คือ config.DB ที่แนบผู้ทำรายการ (จาก token เท่านั้น) ให้ audit log
+func auditDB(c *gin.Context) *gorm.DB {
+	actor := services.AuditActor{IP: c.ClientIP(), Route: c.Request.Method + " " + c.FullPath()}
+	if v, ok := c.Get("userID"); ok {
+		uid := v.(uint)
+		actor.UserID = &uid
+	}
+	return config.DB.WithContext(services.WithAuditActor(c.Request.Context(), actor))
+}
+
+type auditItem struct {
+	ID       uint                   `json:"id"`
+	At       string                 `json:"at"`
+	Entity   string                 `json:"entity"`
+	EntityID uint                   `json:"entityId"`
+	Action   string                 `json:"action"`
+	Actor    gin.H                  `json:"actor"` // null = ระบบ
+	Route    string                 `json:"route,omitempty"`
+	IP       string                 `json:"ip,omitempty"`
+	Before   map[string]interface{} `json:"before,omitempty"`
+	After    map[string]interface{} `json:"after,omitempty"`
+	Changed  []string               `json:"changed"`
+}
+
+var auditFieldPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func decodeAuditJSON(s string) map[string]interface{} {
+	if s == "" {
+		return nil
+	}
+	var m map[string]interface{}
+	if json.Unmarshal([]byte(s), &m) != nil {
+		return nil
+	}
+	return m
+}
+
+var auditLogList = api.ListSpec{
+	Sorts:           map[string]string{"id": "id", "createdAt": "created_at"},
+	DefaultSort:     "-id",
+	DefaultPageSize: 100,
+}
+
+func ListAuditLogs(c *gin.Context) {
+	db := config.DB.Model(&entity.AuditLog{}).Preload("ActorUser")
+	if s := strings.TrimSpace(c.Query("entity")); s != "" {
+		name, ok := services.AuditEntityName(s)
+		if !ok {
+			api.Fail(c, api.BadRequest("", "ไม่รู้จักประเภทข้อมูลที่ขอ").With("entities", services.AuditEntities()))
+			return
+		}
+		db = db.Where("entity_type = ?", name)
+	}
+	if s := strings.TrimSpace(c.Query("id")); s != "" {
+		if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+			api.Fail(c, api.InvalidID("id"))
+			return
+		}
+		db = db.Where("entity_id = ?", s)
+	}
+	if s := strings.TrimSpace(c.Query("actor")); s != "" {
+		db = db.Where("actor_user_id = ?", s)
+	}
+	if s := strings.TrimSpace(c.Query("action")); s != "" {
+		db = db.Where("action = ?", strings.ToLower(s))
+	}
+	// from/to ใช้กติกาเดียวกับรายงาน แต่ไม่ระบุ = ไม่จำกัดช่วง
+	if c.Query("from") != "" || c.Query("to") != "" {
+		start, end, err := parseReportRange(c)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		// audit_logs บันทึกด้วยเวลาเครื่องเสมอ แปลงขอบเขตเป็นเขตเวลาเดียวกันก่อนเทียบ
+		db = db.Where("created_at >= ? AND created_at < ?", start.Local(), end.Local())
+	}
+	// คอลัมน์ที่เปลี่ยน (ชื่อคอลัมน์ในฐานข้อมูล เช่น discount_value)
+	if field := strings.TrimSpace(c.Query("field")); field != "" {
+		if !auditFieldPattern.MatchString(field) {
+			api.Fail(c, api.BadRequest("", "field ไม่ถูกต้อง"))
+			return
+		}
+		key := `%"` + field + `":%`
+		db = db.Where("before_json LIKE ? OR after_json LIKE ?", key, key)
+	}
+
+	var logs []entity.AuditLog
+	db, page, perr := auditLogList.Apply(c, db, &logs)
+	if perr != nil {
+		api.Fail(c, perr)
+		return
+	}
+	meta, err := api.Paginate(db, page, &logs)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	items := make([]auditItem, 0, len(logs))
+	for _, l := range logs {
+		before, after := decodeAuditJSON(l.Before), decodeAuditJSON(l.After)
+		changed := make([]string, 0, len(after))
+		if before != nil && after != nil {
+			for k := range after {
+				changed = append(changed, k)
+			}
+			sort.Strings(changed)
+		}
+		it := auditItem{
+			ID:       l.ID,
+			At:       l.CreatedAt.In(shopLocation()).Format("2006-01-02T15:04:05-07:00"),
+			Entity:   l.EntityType,
+			EntityID: l.EntityID,
+			Action:   l.Action,
+			Route:    l.Route,
+			IP:       l.ActorIP,
+			Before:   before,
+			After:    after,
+			Changed:  changed,
+		}
+		if l.ActorUserID != nil {
+			it.Actor = gin.H{"userId": *l.ActorUserID}
+			if l.ActorUser != nil {
+				it.Actor["email"] = l.ActorUser.Email
+			}
+		}
+		items = append(items, it)
+	}
+	api.List(c, items, meta)
+}
+
+func ListAuditEntities(c *gin.Context) {
+	api.ListAll(c, services.AuditEntities())
+}
@@ -1,47 +1,90 @@
 package controller
 
 import (
+	"log/slog"
 	"net/http"
-	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-var jwtSecret = []byte("your_secret_key") // ให้ตรงกับ middlewares
+// secret/อายุ token มาจาก config (JWT_SECRET, JWT_TTL) ให้ตรงกับ middlewares
+func jwtSecret() []byte { return []byte(config.Current().Auth.JWTSecret) }
 
-type LoginInput struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+// ล็อกอินผิดติดกันครบ loginMaxFailures ครั้ง -> ล็อกบัญชี loginLockDuration
+const (
+	loginMaxFailures  = 5
+	loginLockDuration = 15 * time.Minute
+)
+
+// ประเภทเหตุการณ์ใน auth_events
+const (
+	AuthEventLoginSuccess   = "login_success"
+	AuthEventLoginFailure   = "login_failure"
+	AuthEventAccountLocked  = "account_locked"
+	AuthEventAccountUnlock  = "account_unlocked"
+	AuthEventRateLimited    = "rate_limited"
+	AuthEventTokenRefresh   = "token_refresh"
+	AuthEventLogout         = "logout"
+	AuthEventPasswordChange = "password_change"
+	AuthEventResetRequest   = "password_reset_request"
+	AuthEventPasswordReset  = "password_reset"
+)
+
+// จำกัดจำนวนครั้งที่เข้าสู่ระบบผิดต่อบัญชี (นับทั้งอีเมลที่ไม่มีในระบบ ซึ่งไม่มีแถวให้ล็อก)
+var loginAccountLimiter = services.NewRateLimiter(10, 15*time.Minute)
+
+// ResetLoginLimits ล้างตัวนับการเข้าสู่ระบบต่อบัญชี (ใช้ใน test ที่เริ่มจากฐานข้อมูลใหม่)
+func ResetLoginLimits() { loginAccountLimiter.ResetAll() }
+
+// RecordIPRateLimited บันทึก auth_events เมื่อ IP ถูกจำกัดจำนวนครั้ง (ใช้กับ middlewares.RateLimitByIP)
+func RecordIPRateLimited(c *gin.Context, limiter string) {
+	recordAuthEvent(c, nil, "", AuthEventRateLimited, "ip:"+limiter)
 }
 
-// POST /login
-func Login(c *gin.Context) {
-	var in LoginInput
-	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+// บันทึก auth_events; บันทึกไม่สำเร็จไม่ทำให้คำขอล้ม
+func recordAuthEvent(c *gin.Context, userID *uint, email, eventType, reason string) {
+	ua := c.Request.UserAgent()
+	if len(ua) > 255 {
+		ua = ua[:255]
 	}
-
-	var user entity.User
-	if err := config.DB.Preload("Role").Preload("Employee").
-		Where("email = ?", in.Email).
-		First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
-		fmt.Println("customer.Addresses:", user.Customers[0].Addresses)
-		return
+	ev := entity.AuthEvent{
+		UserID:    userID,
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		EventType: eventType,
+		Reason:    reason,
+		IP:        c.ClientIP(),
+		UserAgent: ua,
+	}
+	if v, ok := c.Get("userID"); ok {
+		if actor := v.(uint); userID == nil || actor != *userID {
+			ev.ActorID = &actor
+		}
 	}
+	if err := config.DB.Create(&ev).Error; err != nil {
+		slog.ErrorContext(c.Request.Context(), "auth event write failed", "error", err)
+	}
+}
 
-	// compare hashed password
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(in.Password)) != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
-		return
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	secs := int(d.Seconds())
+	if d > time.Duration(secs)*time.Second {
+		secs++
 	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+}
 
+// ออก JWT ให้ user (user ต้อง preload Role และ Employee)
+func issueToken(user *entity.User) (gin.H, error) {
 	roleName := ""
 	if user.Role != nil {
 		roleName = user.Role.Name
@@ -56,22 +99,221 @@ func Login(c *gin.Context) {
 		"email":       user.Email,
 		"role":        roleName,
 		"employee_id": employeeID,
-		"exp":         time.Now().Add(24 * time.Hour).Unix(),
+		"exp":         time.Now().Add(config.Current().Auth.TokenTTL).Unix(),
 		"iat":         time.Now().Unix(),
 	}
 	tk := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := tk.SignedString(jwtSecret)
+	tokenString, err := tk.SignedString(jwtSecret())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
-		return
+		return nil, err
 	}
 
 	// ส่งคีย์ตัวพิมพ์เล็กให้ฝั่ง Frontend ใช้ง่าย
-	c.JSON(http.StatusOK, gin.H{
+	return gin.H{
 		"id":         user.ID,
 		"email":      user.Email,
 		"role":       roleName,
 		"token":      tokenString,
 		"employeeId": employeeID,
+	}, nil
+}
+
+type LoginInput struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// POST /login
+func Login(c *gin.Context) {
+	var in LoginInput
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	now := time.Now()
+
+	// ตรวจโควตาก่อน แต่นับเฉพาะครั้งที่ล้มเหลว (ล็อกอินสำเร็จไม่กินโควตา)
+	if ok, wait := loginAccountLimiter.Check(email, now); !ok {
+		recordAuthEvent(c, nil, email, AuthEventRateLimited, "account")
+		setRetryAfter(c, wait)
+		api.Fail(c, api.NewError(http.StatusTooManyRequests, "").Msg("พยายามเข้าสู่ระบบบ่อยเกินไป กรุณารอสักครู่"))
+		return
+	}
+
+	var user entity.User
+	if err := config.DB.Preload("Role").Preload("Employee").
+		Where("LOWER(email) = ?", email).
+		First(&user).Error; err != nil {
+		recordAuthEvent(c, nil, email, AuthEventLoginFailure, "unknown_email")
+		loginAccountLimiter.Allow(email, now)
+		api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_credentials"))
+		return
+	}
+
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		recordAuthEvent(c, &user.ID, email, AuthEventLoginFailure, "locked")
+		setRetryAfter(c, user.LockedUntil.Sub(now))
+		api.Fail(c, api.NewError(http.StatusLocked, "account_locked").Msg("บัญชีถูกล็อกชั่วคราวเนื่องจากเข้าสู่ระบบผิดหลายครั้ง").With("lockedUntil", user.LockedUntil))
+		return
+	}
+
+	// compare hashed password
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(in.Password)) != nil {
+		recordAuthEvent(c, &user.ID, email, AuthEventLoginFailure, "bad_password")
+		loginAccountLimiter.Allow(email, now)
+		locked, err := registerLoginFailure(config.DB, user.ID, now)
+		if err != nil {
+			api.Fail(c, api.Internal(err))
+			return
+		}
+		if locked != nil {
+			recordAuthEvent(c, &user.ID, email, AuthEventAccountLocked, "too_many_failures")
+			setRetryAfter(c, locked.Sub(now))
+			api.Fail(c, api.NewError(http.StatusLocked, "account_locked").Msg("บัญชีถูกล็อกชั่วคราวเนื่องจากเข้าสู่ระบบผิดหลายครั้ง").With("lockedUntil", locked))
+			return
+		}
+		api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_credentials"))
+		return
+	}
+
+	if user.FailedLoginCount != 0 || user.LockedUntil != nil {
+		config.DB.Model(&entity.User{}).Where("id = ?", user.ID).
+			Updates(map[string]interface{}{"failed_login_count": 0, "locked_until": nil})
+	}
+
+	out, err := issueToken(&user)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("สร้าง token ไม่สำเร็จ", err))
+		return
+	}
+	recordAuthEvent(c, &user.ID, email, AuthEventLoginSuccess, "")
+	c.JSON(http.StatusOK, out)
+}
+
+// เพิ่มตัวนับล็อกอินผิด; ครบกำหนดแล้วล็อกบัญชีและเริ่มนับใหม่ คืนเวลาปลดล็อกถ้าเพิ่งถูกล็อก
+func registerLoginFailure(db *gorm.DB, userID uint, now time.Time) (*time.Time, error) {
+	var locked *time.Time
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).Where("id = ?", userID).
+			Update("failed_login_count", gorm.Expr("failed_login_count + 1")).Error; err != nil {
+			return err
+		}
+		var u entity.User
+		if err := tx.Select("id", "failed_login_count").First(&u, userID).Error; err != nil {
+			return err
+		}
+		if u.FailedLoginCount < loginMaxFailures {
+			return nil
+		}
+		until := now.Add(loginLockDuration)
+		locked = &until
+		return tx.Model(&entity.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"failed_login_count": 0, "locked_until": until}).Error
 	})
+	return locked, err
+}
+
+// POST /token/refresh  (แนบ token เดิมที่ยังไม่หมดอายุ)
+func RefreshToken(c *gin.Context) {
+	uid := c.MustGet("userID").(uint)
+	var user entity.User
+	if err := config.DB.Preload("Role").Preload("Employee").First(&user, uid).Error; err != nil {
+		api.Fail(c, api.Unauthorized("", "ไม่พบผู้ใช้"))
+		return
+	}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		recordAuthEvent(c, &user.ID, user.Email, AuthEventTokenRefresh, "locked")
+		api.Fail(c, api.NewError(http.StatusLocked, "account_locked").With("lockedUntil", user.LockedUntil))
+		return
+	}
+	out, err := issueToken(&user)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("สร้าง token ไม่สำเร็จ", err))
+		return
+	}
+	recordAuthEvent(c, &user.ID, user.Email, AuthEventTokenRefresh, "")
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /logout
+// token เป็น JWT แบบ stateless ฝั่ง client ต้องลบ token เอง; ฝั่ง server บันทึกเหตุการณ์ไว้ตรวจสอบ
+func Logout(c *gin.Context) {
+	uid := c.MustGet("userID").(uint)
+	var user entity.User
+	if err := config.DB.Select("id", "email").First(&user, uid).Error; err != nil {
+		api.Fail(c, api.Unauthorized("", "ไม่พบผู้ใช้"))
+		return
+	}
+	recordAuthEvent(c, &user.ID, user.Email, AuthEventLogout, "")
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// ======================================================
+// admin: ตรวจประวัติการเข้าสู่ระบบ / ปลดล็อกบัญชี
+// - GET  /auth-events?userId=&email=&type=&ip=&from=&to=&sort=-id&page=&pageSize=
+// - GET  /users/locked?q=
+// - POST /users/:id/unlock
+// ======================================================
+
+var authEventList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"userId": {Column: "user_id", Op: api.OpID},
+		"type":   {Column: "event_type", Op: api.OpEq},
+		"ip":     {Column: "ip", Op: api.OpEq},
+		"from":   {Column: "created_at", Op: api.OpFrom},
+		"to":     {Column: "created_at", Op: api.OpTo},
+	},
+	Sorts:           map[string]string{"id": "id", "createdAt": "created_at"},
+	DefaultSort:     "-id",
+	DefaultPageSize: 100,
+}
+
+func ListAuthEvents(c *gin.Context) {
+	db := config.DB.Model(&entity.AuthEvent{})
+	if s := strings.TrimSpace(c.Query("email")); s != "" {
+		db = db.Where("email = ?", strings.ToLower(s))
+	}
+	var items []entity.AuthEvent
+	meta, ok := authEventList.Find(c, db, &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta)
+}
+
+var lockedUserList = api.ListSpec{
+	Sorts:       map[string]string{"id": "id", "email": "email", "lockedUntil": "locked_until"},
+	DefaultSort: "-lockedUntil",
+	Search:      []string{"email"},
+}
+
+func ListLockedUsers(c *gin.Context) {
+	// ล็อกที่หมดเวลาแล้วยังค้างค่าไว้จนกว่าจะล็อกอินสำเร็จ จึงกรองด้วยเวลาปัจจุบัน
+	var users []entity.User
+	meta, ok := lockedUserList.Find(c, config.DB.Model(&entity.User{}).Select("id", "email", "locked_until").
+		Where("locked_until > ?", time.Now()), &users)
+	if !ok {
+		return
+	}
+	items := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		items = append(items, gin.H{"id": u.ID, "email": u.Email, "lockedUntil": u.LockedUntil})
+	}
+	api.List(c, items, meta)
+}
+
+func UnlockUser(c *gin.Context) {
+	var user entity.User
+	if err := config.DB.Select("id", "email").First(&user, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบผู้ใช้"))
+		return
+	}
+	if err := config.DB.Model(&entity.User{}).Where("id = ?", user.ID).
+		Updates(map[string]interface{}{"failed_login_count": 0, "locked_until": nil}).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	loginAccountLimiter.Reset(strings.ToLower(user.Email))
+	recordAuthEvent(c, &user.ID, user.Email, AuthEventAccountUnlock, "admin")
+	c.JSON(http.StatusOK, gin.H{"message": "unlocked", "id": user.ID})
 }
@@ -0,0 +1,505 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCashSessionAlreadyOpen = errors.New("cash_session_already_open")
+	ErrCashSessionClosed      = errors.New("cash_session_closed")
+	ErrNotSessionOwner        = errors.New("not_session_owner")
+	ErrOpenCashSessions       = errors.New("open_cash_sessions")
+)
+
+// ======================================================
+// Helpers: วันทำการ (ตามเวลาไทย) + ตรวจการปิดยอด
+// ======================================================
+
+func shopLocation() *time.Location {
+	return services.ShopLocation()
+}
+
+func businessDate(t time.Time) string {
+	return services.BusinessDate(t)
+}
+
+// ช่วงเวลา [start, end) ของวันทำการ
+func businessDayRange(date string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", date, shopLocation())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 0, 1), nil
+}
+
+// ยอดเงินสดที่รับในรอบ (เฉพาะที่ชำระแล้ว)
+func cashSessionTotal(tx *gorm.DB, sessionID uint) (float64, error) {
+	var sum float64
+	err := tx.Model(&entity.Payment{}).
+		Where("cash_session_id = ? AND payment_status = ?", sessionID, "paid").
+		Select("COALESCE(SUM(verified_amount),0)").Scan(&sum).Error
+	return sum, err
+}
+
+// การชำระเงินที่สำเร็จในช่วง [start, end)
+// (SQLite เก็บเวลาเป็นข้อความพร้อม offset จึงดึงกว้างไว้ก่อนแล้วกรองซ้ำในโค้ด)
+func paymentsPaidBetween(tx *gorm.DB, start, end time.Time) ([]entity.Payment, error) {
+	var list []entity.Payment
+	if err := tx.Omit("check_payment_b64").
+		Where("payment_status = ? AND created_at < ?", "paid", end.AddDate(0, 0, 1)).
+		Where("COALESCE(slip_verified_at, created_at) >= ?", start.AddDate(0, 0, -1)).
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	out := list[:0]
+	for _, p := range list {
+		at := p.CreatedAt
+		if p.SlipVerifiedAt != nil {
+			at = *p.SlipVerifiedAt
+		}
+		if !at.Before(start) && at.Before(end) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func cashSessionView(s *entity.CashSession) gin.H {
+	out := gin.H{
+		"id":             s.ID,
+		"employeeId":     s.EmployeeID,
+		"businessDate":   s.BusinessDate,
+		"status":         s.Status,
+		"openedAt":       s.OpenedAt.Format(time.RFC3339),
+		"openingFloat":   s.OpeningFloat,
+		"expectedAmount": s.ExpectedAmount,
+		"countedAmount":  s.CountedAmount,
+		"difference":     s.Difference,
+		"note":           s.Note,
+	}
+	if s.ClosedAt != nil {
+		out["closedAt"] = s.ClosedAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+func writeCashError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDayClosed):
+		api.Fail(c, api.NewError(http.StatusConflict, "day_closed"))
+	case errors.Is(err, services.ErrNoOpenCashSession):
+		api.Fail(c, api.NewError(http.StatusConflict, "no_open_cash_session"))
+	default:
+		api.Fail(c, api.Internal(err))
+	}
+}
+
+// ======================================================
+// POST /cash/sessions/open
+// ======================================================
+
+type openCashSessionIn struct {
+	OpeningFloat float64 `json:"openingFloat"`
+}
+
+func OpenCashSession(c *gin.Context) {
+	empID := currentEmployeeID(c)
+	if empID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
+	var in openCashSessionIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if in.OpeningFloat < 0 {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "invalid_opening_float"))
+		return
+	}
+
+	var sess entity.CashSession
+	txErr := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := services.EnsureDayOpen(tx, now); err != nil {
+			return err
+		}
+		if _, err := services.FindOpenCashSession(tx, *empID); err == nil {
+			return ErrCashSessionAlreadyOpen
+		} else if !errors.Is(err, services.ErrNoOpenCashSession) {
+			return err
+		}
+		sess = entity.CashSession{
+			EmployeeID:     *empID,
+			BusinessDate:   businessDate(now),
+			Status:         "open",
+			OpenedAt:       now,
+			OpeningFloat:   in.OpeningFloat,
+			ExpectedAmount: in.OpeningFloat,
+		}
+		return tx.Create(&sess).Error
+	})
+	if txErr != nil {
+		if errors.Is(txErr, ErrCashSessionAlreadyOpen) {
+			api.Fail(c, api.NewError(http.StatusConflict, "cash_session_already_open"))
+			return
+		}
+		writeCashError(c, txErr)
+		return
+	}
+	c.JSON(http.StatusCreated, cashSessionView(&sess))
+}
+
+// ======================================================
+// GET /cash/sessions/current
+// ======================================================
+
+func GetCurrentCashSession(c *gin.Context) {
+	empID := currentEmployeeID(c)
+	if empID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
+	sess, err := services.FindOpenCashSession(config.DB, *empID)
+	if err != nil {
+		if errors.Is(err, services.ErrNoOpenCashSession) {
+			api.Fail(c, api.NewError(http.StatusNotFound, "no_open_cash_session"))
+			return
+		}
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	cash, err := cashSessionTotal(config.DB, sess.ID)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	sess.ExpectedAmount = sess.OpeningFloat + cash
+	c.JSON(http.StatusOK, cashSessionView(sess))
+}
+
+// ======================================================
+// POST /cash/sessions/:id/close
+// ======================================================
+
+type closeCashSessionIn struct {
+	CountedAmount *float64 `json:"countedAmount" binding:"required"`
+	Note          string   `json:"note"`
+}
+
+func CloseCashSession(c *gin.Context) {
+	empID := currentEmployeeID(c)
+	if empID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
+	var in closeCashSessionIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if *in.CountedAmount < 0 {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "invalid_counted_amount"))
+		return
+	}
+
+	var sess entity.CashSession
+	txErr := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&sess, c.Param("id")).Error; err != nil {
+			return err
+		}
+		if sess.EmployeeID != *empID {
+			return ErrNotSessionOwner
+		}
+		if sess.Status != "open" {
+			return ErrCashSessionClosed
+		}
+		cash, err := cashSessionTotal(tx, sess.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		counted := *in.CountedAmount
+		sess.Status = "closed"
+		sess.ClosedAt = &now
+		sess.CountedAmount = &counted
+		sess.ExpectedAmount = round2(sess.OpeningFloat + cash)
+		sess.Difference = round2(counted - sess.ExpectedAmount)
+		sess.Note = strings.TrimSpace(in.Note)
+		return tx.Save(&sess).Error
+	})
+	if txErr != nil {
+		switch {
+		case errors.Is(txErr, gorm.ErrRecordNotFound):
+			api.Fail(c, api.NewError(http.StatusNotFound, "cash_session_not_found"))
+		case errors.Is(txErr, ErrNotSessionOwner):
+			api.Fail(c, api.NewError(http.StatusForbidden, "not_session_owner"))
+		case errors.Is(txErr, ErrCashSessionClosed):
+			api.Fail(c, api.NewError(http.StatusConflict, "cash_session_closed"))
+		default:
+			writeCashError(c, txErr)
+		}
+		return
+	}
+
+	out := cashSessionView(&sess)
+	switch {
+	case sess.Difference > 0:
+		out["result"] = "over"
+	case sess.Difference < 0:
+		out["result"] = "short"
+	default:
+		out["result"] = "balanced"
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// ======================================================
+// GET /cash/sessions?date=YYYY-MM-DD&status=&employeeId=&sort=openedAt
+// ======================================================
+
+var cashSessionList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"status":     {Column: "status", Op: api.OpEq},
+		"employeeId": {Column: "employee_id", Op: api.OpID},
+	},
+	Sorts:       map[string]string{"id": "id", "openedAt": "opened_at", "difference": "difference"},
+	DefaultSort: "openedAt",
+}
+
+func ListCashSessions(c *gin.Context) {
+	date := strings.TrimSpace(c.DefaultQuery("date", businessDate(time.Now())))
+	var list []entity.CashSession
+	meta, ok := cashSessionList.Find(c, config.DB.Model(&entity.CashSession{}).Where("business_date = ?", date), &list)
+	if !ok {
+		return
+	}
+	out := make([]gin.H, 0, len(list))
+	for i := range list {
+		out = append(out, cashSessionView(&list[i]))
+	}
+	api.List(c, out, meta)
+}
+
+// ======================================================
+// POST /payments/:id/collect-cash  (พนักงานรับเงินสดจากลูกค้า)
+// ======================================================
+
+func CollectCashPayment(c *gin.Context) {
+	empID := currentEmployeeID(c)
+	if empID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
+	id, ok := api.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	pay, receipt, err := services.NewPaymentService(config.DB).CollectCash(id, *empID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPaymentNotFound):
+			api.Fail(c, api.NewError(http.StatusNotFound, "payment_not_found"))
+		case errors.Is(err, services.ErrPaymentAlreadyPaid):
+			api.Fail(c, api.NewError(http.StatusConflict, "payment_already_paid"))
+		default:
+			writeCashError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "payment": pay, "receipt_no": receipt.DocumentNo})
+}
+
+// ======================================================
+// Z-report
+// ======================================================
+
+type zReportPaymentType struct {
+	PaymentType string  `json:"paymentType"`
+	Count       int     `json:"count"`
+	Amount      float64 `json:"amount"`
+}
+
+type zReport struct {
+	BusinessDate  string               `json:"businessDate"`
+	Closed        bool                 `json:"closed"`
+	ClosedAt      *time.Time           `json:"closedAt,omitempty"`
+	PaymentCount  int                  `json:"paymentCount"`
+	TotalSales    float64              `json:"totalSales"`
+	CashSales     float64              `json:"cashSales"`
+	TransferSales float64              `json:"transferSales"`
+	ByPaymentType []zReportPaymentType `json:"byPaymentType"`
+	Sessions      []gin.H              `json:"sessions"`
+	OpenSessions  int                  `json:"openSessions"`
+	CashOverShort float64              `json:"cashOverShort"`
+	ReceiptFrom   string               `json:"receiptFrom,omitempty"`
+	ReceiptTo     string               `json:"receiptTo,omitempty"`
+}
+
+func buildZReport(tx *gorm.DB, date string) (*zReport, error) {
+	start, end, err := businessDayRange(date)
+	if err != nil {
+		return nil, err
+	}
+	rep := &zReport{BusinessDate: date, ByPaymentType: []zReportPaymentType{}, Sessions: []gin.H{}}
+
+	pays, err := paymentsPaidBetween(tx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	byType := map[string]*zReportPaymentType{}
+	order := []string{}
+	for _, p := range pays {
+		r, ok := byType[p.PaymentType]
+		if !ok {
+			r = &zReportPaymentType{PaymentType: p.PaymentType}
+			byType[p.PaymentType] = r
+			order = append(order, p.PaymentType)
+		}
+		amt := float64(p.VerifiedAmount)
+		r.Count++
+		r.Amount += amt
+		rep.PaymentCount++
+		rep.TotalSales += amt
+		if strings.EqualFold(p.PaymentType, "cash") {
+			rep.CashSales += amt
+		} else {
+			rep.TransferSales += amt
+		}
+	}
+	for _, k := range order {
+		rep.ByPaymentType = append(rep.ByPaymentType, *byType[k])
+	}
+
+	var sessions []entity.CashSession
+	if err := tx.Where("business_date = ?", date).Order("opened_at ASC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		s := &sessions[i]
+		if s.Status == "open" {
+			rep.OpenSessions++
+			if cash, err := cashSessionTotal(tx, s.ID); err == nil {
+				s.ExpectedAmount = s.OpeningFloat + cash
+			}
+		} else {
+			rep.CashOverShort += s.Difference
+		}
+		rep.Sessions = append(rep.Sessions, cashSessionView(s))
+	}
+	rep.CashOverShort = round2(rep.CashOverShort)
+
+	var receipts []entity.Receipt
+	if err := tx.Where("issued_at >= ? AND issued_at < ?", start.AddDate(0, 0, -1), end.AddDate(0, 0, 1)).
+		Order("year ASC, seq_no ASC").Find(&receipts).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range receipts {
+		if r.IssuedAt.Before(start) || !r.IssuedAt.Before(end) {
+			continue
+		}
+		if rep.ReceiptFrom == "" {
+			rep.ReceiptFrom = r.DocumentNo
+		}
+		rep.ReceiptTo = r.DocumentNo
+	}
+
+	var closing entity.DayClosing
+	if err := tx.Where("business_date = ?", date).First(&closing).Error; err == nil {
+		rep.Closed = true
+		rep.ClosedAt = &closing.ClosedAt
+	}
+	return rep, nil
+}
+
+// GET /cash/z-report?date=YYYY-MM-DD
+func GetZReport(c *gin.Context) {
+	date := strings.TrimSpace(c.DefaultQuery("date", businessDate(time.Now())))
+	rep, err := buildZReport(config.DB, date)
+	if err != nil {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "invalid_date"))
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// ======================================================
+// POST /cash/day-close  (ปิดยอดสิ้นวัน)
+// ======================================================
+
+type dayCloseIn struct {
+	Date string `json:"date"`
+	Note string `json:"note"`
+}
+
+func CloseBusinessDay(c *gin.Context) {
+	var in dayCloseIn
+	_ = c.ShouldBindJSON(&in)
+	today := businessDate(time.Now())
+	date := strings.TrimSpace(in.Date)
+	if date == "" {
+		date = today
+	}
+	if _, _, err := businessDayRange(date); err != nil {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "invalid_date"))
+		return
+	}
+	// วันที่ยังมาไม่ถึงปิดไม่ได้ (YYYY-MM-DD เทียบเป็นข้อความได้)
+	if date > today {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "future_date"))
+		return
+	}
+	// ผู้ดูแลระบบอาจไม่มีข้อมูลพนักงาน (ผู้ปิดยอดยังอยู่ใน audit log)
+	empID := currentEmployeeID(c)
+
+	var rep *zReport
+	txErr := auditDB(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		rep, err = buildZReport(tx, date)
+		if err != nil {
+			return err
+		}
+		if rep.Closed {
+			return services.ErrDayClosed
+		}
+		if rep.OpenSessions > 0 {
+			return ErrOpenCashSessions
+		}
+		now := time.Now()
+		closing := entity.DayClosing{
+			BusinessDate:  date,
+			ClosedAt:      now,
+			PaymentCount:  rep.PaymentCount,
+			TotalSales:    rep.TotalSales,
+			CashSales:     rep.CashSales,
+			TransferSales: rep.TransferSales,
+			CashOverShort: rep.CashOverShort,
+			Note:          strings.TrimSpace(in.Note),
+			ClosedBy:      empID,
+		}
+		if err := tx.Create(&closing).Error; err != nil {
+			return err
+		}
+		rep.Closed = true
+		rep.ClosedAt = &now
+		return nil
+	})
+	if txErr != nil {
+		if errors.Is(txErr, ErrOpenCashSessions) {
+			api.Fail(c, api.NewError(http.StatusConflict, "open_cash_sessions").With("openSessions", rep.OpenSessions))
+			return
+		}
+		writeCashError(c, txErr)
+		return
+	}
+	c.JSON(http.StatusCreated, rep)
+}
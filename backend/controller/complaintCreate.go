@@ -1,18 +1,19 @@
 package controller
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
-	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -25,10 +26,8 @@ import (
 - ฝั่ง Frontend จะยิง 2 คำขอต่อเนื่องอัตโนมัติ (กดปุ่มครั้งเดียว)
 */
 
-const (
-	maxFilesPerComplaint = 5
-	maxFileSizeBytes     = 10 * 1024 * 1024 // 10MB/ไฟล์
-)
+// จำนวนไฟล์/ขนาดต่อไฟล์มาจาก config (UPLOAD_MAX_COMPLAINT_FILES, UPLOAD_MAX_FILE_MB)
+const thumbnailMaxSide = 320 // px ด้านยาวของรูปย่อ
 
 func init() {
 	// กันความซ้ำของ rand.Intn
@@ -40,21 +39,6 @@ func makePublicID() string {
 	return fmt.Sprintf("CMP-%s-%06d", time.Now().Format("20060102-150405"), rand.Intn(1000000))
 }
 
-func guessMimeByExt(name string) string {
-	switch strings.ToLower(filepath.Ext(name)) {
-	case ".png":
-		return "image/png"
-	case ".jpg", ".jpeg":
-		return "image/jpeg"
-	case ".webp":
-		return "image/webp"
-	case ".pdf":
-		return "application/pdf"
-	default:
-		return "application/octet-stream"
-	}
-}
-
 func sanitize(name string) string {
 	base := filepath.Base(name)
 	var b strings.Builder
@@ -75,218 +59,254 @@ func sanitize(name string) string {
 	return out
 }
 
-// ========== POST /complaints  (public; ไม่ต้อง Authorizes) ==========
+// ========== POST /complaints, POST /customer/complaints  (ลูกค้า; ต้องแนบ token) ==========
 // รับเฉพาะฟอร์มข้อมูล complaint (ไม่จัดการไฟล์แนบใน endpoint นี้)
 func CreateComplaint(c *gin.Context) {
 	email := strings.TrimSpace(c.PostForm("email")) // ช่องทางติดต่อ (optional)
 	title := strings.TrimSpace(c.PostForm("title"))
 	desc := strings.TrimSpace(c.PostForm("description"))
 	orderIDStr := strings.TrimSpace(c.PostForm("orderId"))
-	custIDStr := strings.TrimSpace(c.PostForm("customerId"))
+	category := strings.TrimSpace(c.PostForm("category")) // optional: รหัสหมวด (GET /complaint-categories)
+	priority := strings.TrimSpace(c.PostForm("priority")) // optional: low|normal|high|urgent (ว่าง = ตามหมวด)
 
 	// validate ขั้นต่ำ
 	if title == "" || desc == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "กรอกหัวข้อ และรายละเอียด ให้ครบถ้วน"})
+		api.Fail(c, api.BadRequest("", "กรอกหัวข้อ และรายละเอียด ให้ครบถ้วน"))
 		return
 	}
 
-	// parse orderID (optional)
-	var orderIDPtr *uint
-	if orderIDStr != "" {
-		if v, err := strconv.ParseUint(orderIDStr, 10, 64); err == nil {
-			u := uint(v)
-			orderIDPtr = &u
-		}
+	// parse orderID (optional) + รายการผ้าที่มีปัญหา (optional)
+	orderIDPtr, err := parseComplaintOrderID(orderIDStr)
+	if err != nil {
+		api.Fail(c, api.BadRequest("", "เลขคำสั่งซื้อไม่ถูกต้อง"))
+		return
 	}
-
-	// parse customerID (optional แต่ปกติควรจะมี)
-	var customerIDPtr *uint
-	if custIDStr != "" {
-		if v, err := strconv.ParseUint(custIDStr, 10, 64); err == nil {
-			u := uint(v)
-			customerIDPtr = &u
-		}
+	itemIDs, err := parseComplaintItemIDs(c.PostFormArray("itemIds"))
+	if err != nil {
+		api.Fail(c, api.BadRequest("", "รายการผ้าไม่ถูกต้อง"))
+		return
 	}
-
-	// เตรียม complaint
-	pubID := makePublicID()
-	comp := entity.Complaint{
-		StatusComplaint: "รอดำเนินการ",
-		Title:           title,
-		Description:     desc,
-		CreateDate:      time.Now(),
-		Email:           email,
-		OrderID:         orderIDPtr,
-		PublicID:        pubID,
+	if len(itemIDs) > 0 && orderIDPtr == nil {
+		api.Fail(c, api.BadRequest("", "กรุณาระบุเลขคำสั่งซื้อของรายการผ้า"))
+		return
 	}
-	if customerIDPtr != nil {
-		comp.CustomerID = *customerIDPtr
+
+	// เจ้าของคำร้องมาจาก JWT เท่านั้น (ไม่เชื่อ customerId จากฟอร์ม)
+	customerIDPtr := currentCustomerID(c)
+	if customerIDPtr == nil {
+		api.Fail(c, api.Forbidden("", "เฉพาะลูกค้าที่เข้าสู่ระบบเท่านั้น"))
+		return
 	}
 
-	// บันทึก
-	if err := config.DB.Create(&comp).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกคำร้องเรียนไม่สำเร็จ: " + err.Error()})
+	// ผูกออเดอร์ (ต้องเป็นของลูกค้าคนนี้) -> หมวด/ระดับความสำคัญ -> SLA -> บันทึก + มอบหมายพนักงานตามหมวด
+	comp, err := services.NewComplaintService(config.DB).Create(services.NewComplaint{
+		CustomerID:  *customerIDPtr,
+		Title:       title,
+		Description: desc,
+		Email:       email,
+		Category:    category,
+		Priority:    priority,
+		OrderID:     orderIDPtr,
+		ItemIDs:     itemIDs,
+		PublicID:    makePublicID(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrComplaintOrderNotFound):
+			api.Fail(c, api.NotFound("", "ไม่พบคำสั่งซื้อนี้ในบัญชีของคุณ"))
+		case errors.Is(err, services.ErrComplaintItemNotInOrder):
+			api.Fail(c, api.BadRequest("", "รายการผ้าที่เลือกไม่อยู่ในคำสั่งซื้อนี้"))
+		case errors.Is(err, services.ErrUnknownComplaintCategory):
+			api.Fail(c, api.BadRequest("", "ไม่พบหมวดคำร้องเรียนนี้"))
+		default:
+			api.Fail(c, api.InternalMsg("บันทึกคำร้องเรียนไม่สำเร็จ", err))
+		}
 		return
 	}
 
 	// ส่งกลับ publicId ให้ frontend เอาไปใช้กับ endpoint อัปโหลดไฟล์
-	c.JSON(http.StatusCreated, gin.H{"id": comp.PublicID})
+	c.JSON(http.StatusCreated, gin.H{
+		"id":                 comp.PublicID,
+		"category":           comp.Category,
+		"priority":           comp.Priority,
+		"firstResponseDueAt": comp.FirstResponseDueAt,
+		"resolutionDueAt":    comp.ResolutionDueAt,
+	})
 }
 
-// ========== POST /complaints/:publicId/attachments  (public) ==========
-// อัปโหลดไฟล์แนบทั้งหมดในคำขอเดียว
+// ========== POST /complaints/:publicId/attachments, POST /customer/complaints/:publicId/attachments ==========
+// อัปโหลดไฟล์แนบทั้งหมดในคำขอเดียว (เฉพาะเจ้าของคำร้อง)
 func AddComplaintAttachments(c *gin.Context) {
 	publicID := strings.TrimSpace(c.Param("publicId"))
 	if publicID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "publicId จำเป็น"})
+		api.Fail(c, api.BadRequest("", "publicId จำเป็น"))
 		return
 	}
 
-	// 1) หา Complaint จาก PublicID
+	customerID := currentCustomerID(c)
+	if customerID == nil {
+		api.Fail(c, api.Forbidden("", "เฉพาะลูกค้าที่เข้าสู่ระบบเท่านั้น"))
+		return
+	}
+
+	// 1) หา Complaint จาก PublicID (ของลูกค้าคนนี้เท่านั้น)
 	var comp entity.Complaint
-	if err := config.DB.Where("public_id = ?", publicID).First(&comp).Error; err != nil {
+	if err := config.DB.Where("public_id = ? AND customer_id = ?", publicID, *customerID).First(&comp).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบคำร้องเรียนนี้"})
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียนนี้"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ค้นหาคำร้องเรียนล้มเหลว: " + err.Error()})
+		api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนล้มเหลว", err))
 		return
 	}
 
 	// 2) รับไฟล์จาก multipart
 	form, _ := c.MultipartForm()
 	if form == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาแนบไฟล์อย่างน้อย 1 ไฟล์ในฟิลด์ attachments"})
+		api.Fail(c, api.BadRequest("", "กรุณาแนบไฟล์อย่างน้อย 1 ไฟล์ในฟิลด์ attachments"))
 		return
 	}
 	files := form.File["attachments"]
 	if len(files) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ไม่มีไฟล์ในฟิลด์ attachments"})
+		api.Fail(c, api.BadRequest("", "ไม่มีไฟล์ในฟิลด์ attachments"))
 		return
 	}
 
 	// 3) ตรวจจำนวนไฟล์รวม (ของเดิม + ใหม่)
+	limits := config.Current().Upload
+	maxFilesPerComplaint, maxFileSizeBytes := limits.MaxComplaintFiles, limits.MaxFileBytes()
 	var countExisting int64
 	if err := config.DB.Model(&entity.ComplaintAttachment{}).
 		Where("complaint_id = ?", comp.ID).
 		Count(&countExisting).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ตรวจจำนวนไฟล์เดิมไม่สำเร็จ: " + err.Error()})
+		api.Fail(c, api.InternalMsg("ตรวจจำนวนไฟล์เดิมไม่สำเร็จ", err))
 		return
 	}
 	if countExisting+int64(len(files)) > int64(maxFilesPerComplaint) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":           fmt.Sprintf("อัปโหลดได้สูงสุด %d ไฟล์ต่อคำร้องเรียน (เหลือได้อีก %d ไฟล์)", maxFilesPerComplaint, maxFilesPerComplaint-int(countExisting)),
-			"alreadyUploaded": countExisting,
-		})
+		api.Fail(c, api.BadRequest("", "").
+			Msgf("อัปโหลดได้สูงสุด %d ไฟล์ต่อคำร้องเรียน (เหลือได้อีก %d ไฟล์)", maxFilesPerComplaint, maxFilesPerComplaint-int(countExisting)).
+			With("alreadyUploaded", countExisting))
 		return
 	}
 
-	// 4) ตรวจนามสกุลที่อนุญาต
-	allowed := map[string]bool{
-		".png":  true,
-		".jpg":  true,
-		".jpeg": true,
-		".webp": true,
-		".pdf":  true,
+	// 4) อ่านไฟล์ + ตรวจชนิดจากเนื้อไฟล์ (allow-list) + ลบ EXIF + ทำรูปย่อ
+	type preparedFile struct {
+		Original string
+		Name     string
+		Mime     string
+		Data     []byte
+		Thumb    []byte
 	}
-
-	// เตรียมโฟลเดอร์ปลายทาง
-	baseDir := filepath.Join(".", "uploads", "complaints", publicID)
-	if err := os.MkdirAll(baseDir, 0o755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างโฟลเดอร์อัปโหลดไม่สำเร็จ: " + err.Error()})
-		return
-	}
-
-	type savedFile struct {
-		Att entity.ComplaintAttachment
-	}
-	var saved []savedFile
-
-	// ใช้ Transaction ชุดเดียว บันทึกเมตาดาต้าไฟล์ให้ครบ
-	tx := config.DB.Begin()
-	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เริ่มธุรกรรมไม่สำเร็จ: " + tx.Error.Error()})
-		return
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "เกิดข้อผิดพลาดไม่คาดคิด"})
-		}
-	}()
-
+	prepared := make([]preparedFile, 0, len(files))
 	for _, fh := range files {
 		if fh.Size > maxFileSizeBytes {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ไฟล์ %s มีขนาดเกิน %d MB", fh.Filename, maxFileSizeBytes/(1024*1024))})
+			api.Fail(c, api.BadRequest("", fmt.Sprintf("ไฟล์ %s มีขนาดเกิน %d MB", fh.Filename, maxFileSizeBytes/(1024*1024))))
 			return
 		}
-
-		ext := strings.ToLower(filepath.Ext(fh.Filename))
-		if !allowed[ext] {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ไม่รองรับชนิดไฟล์ %s (อนุญาต: .png .jpg .jpeg .webp .pdf)", ext)})
+		data, err := readUploadedFile(fh, maxFileSizeBytes)
+		if err != nil {
+			api.Fail(c, api.BadRequest("", fmt.Sprintf("อ่านไฟล์ %s ไม่สำเร็จ", fh.Filename)))
 			return
 		}
 
-		// กันชื่อชน
-		safe := sanitize(fh.Filename)
-		dstPath := filepath.Join(baseDir, safe)
-		if _, err := os.Stat(dstPath); err == nil {
-			name := strings.TrimSuffix(safe, ext)
-			safe = fmt.Sprintf("%s_%d%s", name, time.Now().UnixNano(), ext)
-			dstPath = filepath.Join(baseDir, safe)
-		}
-
-		// บันทึกไฟล์จริงลงดิสก์
-		if err := c.SaveUploadedFile(fh, dstPath); err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกไฟล์แนบไม่สำเร็จ: " + err.Error()})
+		mime := services.SniffContentType(data)
+		ext, ok := services.AllowedAttachmentTypes[mime]
+		if !ok {
+			api.Fail(c, api.BadRequest("", fmt.Sprintf("ไม่รองรับชนิดไฟล์ของ %s (อนุญาต: png jpg webp pdf)", fh.Filename)))
 			return
 		}
 
-		mime := guessMimeByExt(safe)
-		url := "/uploads/complaints/" + publicID + "/" + safe
-
-		att := entity.ComplaintAttachment{
-			ComplaintID:  &comp.ID,
-			OriginalName: fh.Filename,
-			FileName:     safe,
-			MimeType:     mime,
-			SizeBytes:    fh.Size,
-			Path:         dstPath,
-			URL:          url,
-			UploadedAt:   time.Now(),
+		pf := preparedFile{Original: fh.Filename, Mime: mime, Data: data}
+		if services.IsImageType(mime) {
+			if pf.Data, err = services.StripImageMetadata(data, mime); err != nil {
+				if errors.Is(err, services.ErrImageTooLarge) {
+					api.Fail(c, api.BadRequest("", fmt.Sprintf("ไฟล์ภาพ %s มีขนาดเกิน %d ล้านพิกเซล", fh.Filename, services.MaxImagePixels/1_000_000)))
+					return
+				}
+				api.Fail(c, api.BadRequest("", fmt.Sprintf("ไฟล์ภาพ %s เสียหาย", fh.Filename)))
+				return
+			}
+			if pf.Thumb, err = services.MakeThumbnail(pf.Data, mime, thumbnailMaxSide); err != nil {
+				api.Fail(c, api.BadRequest("", fmt.Sprintf("ไฟล์ภาพ %s เสียหาย", fh.Filename)))
+				return
+			}
 		}
 
-		if err := tx.Create(&att).Error; err != nil {
-			// หาก insert DB ไม่สำเร็จ ให้ลบไฟล์ที่เพิ่งเซฟ
-			_ = os.Remove(dstPath)
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกข้อมูลไฟล์แนบไม่สำเร็จ: " + err.Error()})
-			return
-		}
+		// ใช้นามสกุลตามชนิดจริง + เวลากันชื่อชน
+		base := strings.TrimSuffix(sanitize(fh.Filename), filepath.Ext(sanitize(fh.Filename)))
+		pf.Name = fmt.Sprintf("%d_%s%s", time.Now().UnixNano(), base, ext)
+		prepared = append(prepared, pf)
+	}
 
-		saved = append(saved, savedFile{Att: att})
+	// 5) เก็บไฟล์ลง BlobStore แล้วบันทึกเมตาดาต้าใน Transaction เดียว
+	ctx := c.Request.Context()
+	store := attachmentStore()
+	var storedKeys []string
+	cleanup := func() {
+		for _, k := range storedKeys {
+			_ = store.Delete(ctx, k)
+		}
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ยืนยันการบันทึกไม่สำเร็จ: " + err.Error()})
+	var saved []entity.ComplaintAttachment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, pf := range prepared {
+			key := "complaints/" + publicID + "/" + pf.Name
+			if err := store.Put(ctx, key, bytes.NewReader(pf.Data), pf.Mime); err != nil {
+				return err
+			}
+			storedKeys = append(storedKeys, key)
+
+			var thumbKey string
+			if pf.Thumb != nil {
+				thumbKey = "complaints/" + publicID + "/thumbs/" + strings.TrimSuffix(pf.Name, filepath.Ext(pf.Name)) + ".jpg"
+				if err := store.Put(ctx, thumbKey, bytes.NewReader(pf.Thumb), "image/jpeg"); err != nil {
+					return err
+				}
+				storedKeys = append(storedKeys, thumbKey)
+			}
+
+			att := entity.ComplaintAttachment{
+				ComplaintID:  &comp.ID,
+				OriginalName: pf.Original,
+				FileName:     pf.Name,
+				MimeType:     pf.Mime,
+				SizeBytes:    int64(len(pf.Data)),
+				StorageKey:   key,
+				ThumbKey:     thumbKey,
+				UploadedAt:   time.Now(),
+			}
+			if err := tx.Create(&att).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&att).Update("url", fmt.Sprintf("/attachments/%d", att.ID)).Error; err != nil {
+				return err
+			}
+			saved = append(saved, att)
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		api.Fail(c, api.InternalMsg("บันทึกไฟล์แนบไม่สำเร็จ", err))
 		return
 	}
 
-	// คืนผลไฟล์ที่บันทึกสำเร็จ
+	// คืนผลไฟล์ที่บันทึกสำเร็จ (ลิงก์ลงลายเซ็น มีวันหมดอายุ)
 	out := make([]gin.H, 0, len(saved))
-	for _, s := range saved {
+	for i := range saved {
+		a := &saved[i]
+		item := attachmentItemFor(c, a)
 		out = append(out, gin.H{
-			"id":           s.Att.ID,
-			"originalName": s.Att.OriginalName,
-			"fileName":     s.Att.FileName,
-			"mimeType":     s.Att.MimeType,
-			"sizeBytes":    s.Att.SizeBytes,
-			"url":          s.Att.URL,
-			"uploadedAt":   s.Att.UploadedAt,
+			"id":           a.ID,
+			"originalName": a.OriginalName,
+			"fileName":     a.FileName,
+			"mimeType":     a.MimeType,
+			"sizeBytes":    a.SizeBytes,
+			"url":          item.URL,
+			"thumbUrl":     item.Thumb,
+			"expiresAt":    item.ExpiresAt,
+			"uploadedAt":   a.UploadedAt,
 		})
 	}
 
@@ -1,14 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -39,6 +42,29 @@ func fromUIStatus(ui string) string {
 	}
 }
 
+func isUIStatus(ui string) bool {
+	switch strings.TrimSpace(ui) {
+	case "new", "in_progress", "resolved":
+		return true
+	}
+	return false
+}
+
+// ======================================================
+// การเปลี่ยนสถานะ (กฎ + ประวัติอยู่ที่ services.ComplaintService)
+// ======================================================
+
+func writeComplaintStatusError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidComplaintTransition):
+		api.Fail(c, api.Conflict("", "ไม่สามารถเปลี่ยนเป็นสถานะนี้ได้"))
+	case errors.Is(err, services.ErrComplaintReasonRequired):
+		api.Fail(c, api.BadRequest("", "กรุณาระบุเหตุผล (note) เมื่อเปิดงานใหม่หรือย้อนสถานะ"))
+	default:
+		api.Fail(c, api.InternalMsg("อัปเดตสถานะไม่สำเร็จ", err))
+	}
+}
+
 // ======================================================
 // Helper: สร้าง base URL แบบปลอดภัย (รองรับ proxy/HTTPS)
 // - ใช้ X-Forwarded-Proto ถ้ามี (เช่นหลัง Nginx/Cloud proxy)
@@ -83,69 +109,214 @@ func fullCustomerName(cus *entity.Customer) string {
 // ======================================================
 
 type complaintRow struct {
-	ID           string `json:"id"`                // PublicID
-	OrderRef     string `json:"orderId,omitempty"` // เช่น "#123"
-	CustomerName string `json:"customerName"`
-	Subject      string `json:"subject"`   // Title
-	Message      string `json:"message"`   // Description
-	CreatedAt    string `json:"createdAt"` // ISO8601
-	Status       string `json:"status"`    // new|in_progress|resolved
+	ID           string        `json:"id"`                // PublicID
+	OrderRef     string        `json:"orderId,omitempty"` // เช่น "#123"
+	CustomerName string        `json:"customerName"`
+	Subject      string        `json:"subject"`   // Title
+	Message      string        `json:"message"`   // Description
+	CreatedAt    string        `json:"createdAt"` // ISO8601
+	Status       string        `json:"status"`    // new|in_progress|resolved
+	Priority     string        `json:"priority,omitempty"`
+	Category     string        `json:"category,omitempty"`
+	AssignedTo   string        `json:"assignedTo,omitempty"` // ชื่อพนักงานผู้รับผิดชอบ
+	SLA          *complaintSLA `json:"sla,omitempty"`
+}
+
+type complaintSLA struct {
+	FirstResponseDueAt *time.Time `json:"firstResponseDueAt,omitempty"`
+	ResolutionDueAt    *time.Time `json:"resolutionDueAt,omitempty"`
+	NextDueAt          *time.Time `json:"nextDueAt,omitempty"`
+	SecondsToBreach    *int64     `json:"secondsToBreach,omitempty"` // ติดลบ = เกินกำหนดแล้ว
+	Overdue            bool       `json:"overdue"`
+	Escalated          bool       `json:"escalated"`
 }
 
 type replyItem struct {
-	At   string `json:"at"`   // ISO8601
-	By   string `json:"by"`   // ชื่อพนักงาน
-	Text string `json:"text"` // เนื้อหาตอบกลับ
+	At       string `json:"at"`   // ISO8601
+	By       string `json:"by"`   // ชื่อพนักงาน (หรือชื่อลูกค้า)
+	Text     string `json:"text"` // เนื้อหาตอบกลับ
+	Author   string `json:"author,omitempty"`   // employee | customer
+	Internal bool   `json:"internal,omitempty"` // บันทึกภายใน
+	Template *uint  `json:"templateId,omitempty"`
+}
+
+func replyAuthorName(r *entity.ReplyComplaint) string {
+	if r.AuthorType == "customer" {
+		return fullCustomerName(r.Customer)
+	}
+	return employeeDisplayName(r.Employee, r.EmpID)
+}
+
+func toReplyItem(r *entity.ReplyComplaint) replyItem {
+	author := r.AuthorType
+	if author == "" {
+		author = "employee"
+	}
+	return replyItem{
+		At:       r.CreateReplyDate.Format(time.RFC3339),
+		By:       replyAuthorName(r),
+		Text:     r.Reply,
+		Author:   author,
+		Internal: r.IsInternal,
+		Template: r.TemplateID,
+	}
 }
 
 type attachmentItem struct {
-	URL  string `json:"url"`           // ABSOLUTE URL -> http(s)://host/uploads/complaints/<PublicID>/<FileName>
-	Name string `json:"name"`          // FileName
-	Mime string `json:"mime,omitempty"`
-	Size int64  `json:"size,omitempty"`
+	URL       string     `json:"url"`             // ABSOLUTE URL ลงลายเซ็น -> http(s)://host/attachments/<ID>?exp=&sig=
+	Name      string     `json:"name"`            // FileName
+	Mime      string     `json:"mime,omitempty"`
+	Size      int64      `json:"size,omitempty"`
+	Thumb     string     `json:"thumb,omitempty"` // รูปย่อ (เฉพาะไฟล์ภาพ)
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 // ======================================================
-// GET /employee/complaints
+// SLA helpers
 // ======================================================
 
-func ListComplaintsForEmployee(c *gin.Context) {
-	q := strings.TrimSpace(c.Query("q"))
-	status := strings.TrimSpace(c.DefaultQuery("status", "all")) // all | new | in_progress | resolved
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "8"))
-	if page < 1 {
-		page = 1
+// กำหนดเวลาถัดไปที่ต้องทำให้ทัน (ยังไม่ตอบ -> กำหนดที่มาถึงก่อน, ตอบแล้ว -> กำหนดปิดงาน)
+func complaintNextDue(v *entity.Complaint) *time.Time {
+	if v.StatusComplaint == services.ComplaintStatusClosed {
+		return nil
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 8
+	next := v.ResolutionDueAt
+	if v.FirstRespondedAt == nil && v.FirstResponseDueAt != nil &&
+		(next == nil || v.FirstResponseDueAt.Before(*next)) {
+		next = v.FirstResponseDueAt
 	}
+	return next
+}
 
-	db := config.DB.Model(&entity.Complaint{}).
-		Preload("Customer").
-		Order("createdate DESC")
-
-	if q != "" {
-		like := "%" + q + "%"
-		db = db.Joins("LEFT JOIN customers ON customers.id = complaints.customer_id").
-			Where("(complaints.public_id LIKE ? OR complaints.title LIKE ? OR complaints.description LIKE ? OR "+
-				"customers.first_name LIKE ? OR customers.last_name LIKE ? OR customers.phone_number LIKE ?)",
-				like, like, like, like, like, like)
+func buildComplaintSLA(v *entity.Complaint, now time.Time) *complaintSLA {
+	if v.FirstResponseDueAt == nil && v.ResolutionDueAt == nil {
+		return nil
 	}
+	out := &complaintSLA{
+		FirstResponseDueAt: v.FirstResponseDueAt,
+		ResolutionDueAt:    v.ResolutionDueAt,
+		Escalated:          v.EscalatedAt != nil,
+	}
+	if next := complaintNextDue(v); next != nil {
+		secs := int64(next.Sub(now).Seconds())
+		out.NextDueAt = next
+		out.SecondsToBreach = &secs
+		out.Overdue = secs < 0
+	}
+	return out
+}
 
+// ======================================================
+// Filter ที่ใช้ร่วมกันระหว่างหน้ารายการและ export
+// ======================================================
+
+func applyComplaintStatusFilter(db *gorm.DB, status string) *gorm.DB {
 	if status != "" && status != "all" {
 		db = db.Where("status_complaint = ?", fromUIStatus(status))
 	}
+	return db
+}
+
+// category / priority / assigned (me | unassigned | <employeeId>)
+func applyComplaintRoutingFilters(db *gorm.DB, category, priority string, assignee *uint, unassigned bool) *gorm.DB {
+	if category != "" && category != "all" {
+		db = db.Where("complaints.category = ?", category)
+	}
+	if priority != "" && priority != "all" {
+		db = db.Where("complaints.priority = ?", services.NormalizePriority(priority))
+	}
+	if unassigned {
+		db = db.Where("complaints.assigned_to_id IS NULL")
+	} else if assignee != nil {
+		db = db.Where("complaints.assigned_to_id = ?", *assignee)
+	}
+	return db
+}
+
+// overdue=true: ยังไม่ปิดและเลยกำหนดตอบกลับครั้งแรก/ปิดงานแล้ว
+func applyComplaintOverdueFilter(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("complaints.status_complaint <> ?", services.ComplaintStatusClosed).
+		Where("((complaints.first_responded_at IS NULL AND complaints.first_response_due_at < ?) OR complaints.resolution_due_at < ?)", now, now)
+}
 
-	var total int64
-	if err := db.Count(&total).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถนับจำนวนรายการได้: " + err.Error()})
+// เรียงตามเวลาที่เหลือก่อนเกิน SLA (ใกล้ครบกำหนดก่อน, งานที่ปิดแล้ว/ไม่มี SLA ไว้ท้าย)
+const complaintBreachOrder = `CASE WHEN complaints.status_complaint = '` + services.ComplaintStatusClosed + `' ` +
+	`OR (complaints.first_response_due_at IS NULL AND complaints.resolution_due_at IS NULL) THEN 1 ELSE 0 END, ` +
+	`CASE WHEN complaints.first_responded_at IS NULL AND complaints.first_response_due_at IS NOT NULL ` +
+	`AND (complaints.resolution_due_at IS NULL OR complaints.first_response_due_at < complaints.resolution_due_at) ` +
+	`THEN complaints.first_response_due_at ELSE complaints.resolution_due_at END ASC`
+
+// ======================================================
+// GET /employee/complaints?q=&status=&category=&priority=&assigned=me&overdue=&sort=
+// ======================================================
+
+// q ค้นรหัส/หัวข้อ/รายละเอียด/ชื่อและเบอร์ลูกค้า; sort=newest | breach | createdAt | priority
+var employeeComplaintList = api.ListSpec{
+	Sorts: map[string]string{
+		"createdAt": "complaints.createdate",
+		"priority":  "complaints.priority",
+		"status":    "complaints.status_complaint",
+	},
+	Presets: map[string]string{
+		"newest": "complaints.createdate DESC",
+		"breach": complaintBreachOrder + ", complaints.createdate DESC",
+	},
+	DefaultSort: "newest",
+	Search: []string{"complaints.public_id", "complaints.title", "complaints.description",
+		"customers.first_name", "customers.last_name", "customers.phone_number"},
+	DefaultPageSize: 8,
+}
+
+// คำร้องตาม status/overdue/category/priority/assigned ของหน้ารายการพนักงาน (ใช้ร่วมกับ export)
+func employeeComplaintQuery(c *gin.Context, now time.Time) (*gorm.DB, *api.Error) {
+	status := strings.TrimSpace(c.DefaultQuery("status", "all")) // all | new | in_progress | resolved
+	overdue := c.Query("overdue") == "true" || c.Query("overdue") == "1"
+	category := strings.TrimSpace(c.Query("category"))
+	priority := strings.TrimSpace(c.Query("priority"))
+	assigned := strings.TrimSpace(c.Query("assigned")) // me | unassigned | <employeeId>
+
+	var assignee *uint
+	switch assigned {
+	case "", "all", "unassigned":
+	case "me":
+		if assignee = currentEmployeeID(c); assignee == nil {
+			return nil, api.Forbidden("", "ต้องเข้าสู่ระบบด้วยบัญชีพนักงาน")
+		}
+	default:
+		id, err := strconv.ParseUint(assigned, 10, 64)
+		if err != nil {
+			return nil, api.BadRequest("", "assigned ต้องเป็น me | unassigned | รหัสพนักงาน")
+		}
+		uid := uint(id)
+		assignee = &uid
+	}
+
+	db := applyComplaintStatusFilter(config.DB.Model(&entity.Complaint{}), status).
+		Joins("LEFT JOIN customers ON customers.id = complaints.customer_id")
+	db = applyComplaintRoutingFilters(db, category, priority, assignee, assigned == "unassigned")
+	if overdue {
+		db = applyComplaintOverdueFilter(db, now)
+	}
+	return db, nil
+}
+
+func ListComplaintsForEmployee(c *gin.Context) {
+	now := time.Now()
+	db, qerr := employeeComplaintQuery(c, now)
+	if qerr != nil {
+		api.Fail(c, qerr)
 		return
 	}
 
 	var comps []entity.Complaint
-	if err := db.Offset((page-1)*pageSize).Limit(pageSize).Find(&comps).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ดึงรายการคำร้องเรียนไม่สำเร็จ: " + err.Error()})
+	db, page, perr := employeeComplaintList.Apply(c, db.Preload("Customer").Preload("AssignedTo"), &comps)
+	if perr != nil {
+		api.Fail(c, perr)
+		return
+	}
+	meta, err := api.Paginate(db, page, &comps)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("ดึงรายการคำร้องเรียนไม่สำเร็จ", err))
 		return
 	}
 
@@ -155,6 +326,10 @@ func ListComplaintsForEmployee(c *gin.Context) {
 		if v.OrderID != nil {
 			orderRef = "#" + strconv.Itoa(int(*v.OrderID))
 		}
+		var assignedTo string
+		if v.AssignedToID != nil {
+			assignedTo = employeeDisplayName(v.AssignedTo, *v.AssignedToID)
+		}
 		rows = append(rows, complaintRow{
 			ID:           v.PublicID,
 			OrderRef:     orderRef,
@@ -163,15 +338,14 @@ func ListComplaintsForEmployee(c *gin.Context) {
 			Message:      v.Description,
 			CreatedAt:    v.CreateDate.Format(time.RFC3339),
 			Status:       toUIStatus(v.StatusComplaint),
+			Priority:     v.Priority,
+			Category:     v.Category,
+			AssignedTo:   assignedTo,
+			SLA:          buildComplaintSLA(&v, now),
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"items":    rows,
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
-	})
+	api.List(c, rows, meta)
 }
 
 // ======================================================
@@ -182,54 +356,46 @@ func ListComplaintsForEmployee(c *gin.Context) {
 func GetComplaintDetail(c *gin.Context) {
 	publicId := strings.TrimSpace(c.Param("publicId"))
 	if publicId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing publicId"})
+		api.Fail(c, api.BadRequest("", "ไม่พบ publicId"))
 		return
 	}
 
 	var comp entity.Complaint
 	err := config.DB.
 		Preload("Customer").
+		Preload("AssignedTo").
 		Preload("Replies", func(tx *gorm.DB) *gorm.DB { return tx.Order("created_at DESC") }).
 		Preload("Replies.Employee").
+		Preload("Replies.Customer").
 		Preload("Attachments").
+		Preload("SortedClothes").
 		First(&comp, "public_id = ?", publicId).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบคำร้องเรียน"})
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ดึงข้อมูลไม่สำเร็จ: " + err.Error()})
+			api.Fail(c, api.InternalMsg("ดึงข้อมูลไม่สำเร็จ", err))
 		}
 		return
 	}
 
+	// ข้อมูลออเดอร์ให้พนักงานตรวจสอบผ้าเสียหาย/สูญหาย
+	snapshot, err := buildOrderSnapshot(config.DB, &comp)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("ดึงข้อมูลออเดอร์ไม่สำเร็จ", err))
+		return
+	}
+
 	// replies
 	history := make([]replyItem, 0, len(comp.Replies))
 	for _, r := range comp.Replies {
-		by := "พนักงาน #" + strconv.Itoa(int(r.EmpID))
-		if r.Employee != nil {
-			full := strings.TrimSpace(r.Employee.FirstName + " " + r.Employee.LastName)
-			if full != "" {
-				by = full
-			}
-		}
-		history = append(history, replyItem{
-			At:   r.CreateReplyDate.Format(time.RFC3339),
-			By:   by,
-			Text: r.Reply,
-		})
+		history = append(history, toReplyItem(r))
 	}
 
 	// attachments -> absolute URL
-	base := absoluteBaseURL(c)
 	atts := make([]attachmentItem, 0, len(comp.Attachments))
 	for _, a := range comp.Attachments {
-		u := fmt.Sprintf("%s/uploads/complaints/%s/%s", base, comp.PublicID, a.FileName)
-		atts = append(atts, attachmentItem{
-			URL:  u,
-			Name: a.FileName,
-			Mime: strings.TrimSpace(a.MimeType),
-			Size: a.SizeBytes,
-		})
+		atts = append(atts, attachmentItemFor(c, a))
 	}
 
 	var orderRef string
@@ -237,6 +403,11 @@ func GetComplaintDetail(c *gin.Context) {
 		orderRef = "#" + strconv.Itoa(int(*comp.OrderID))
 	}
 
+	var assignedTo gin.H
+	if comp.AssignedToID != nil {
+		assignedTo = gin.H{"id": *comp.AssignedToID, "name": employeeDisplayName(comp.AssignedTo, *comp.AssignedToID), "at": comp.AssignedAt}
+	}
+
 	out := gin.H{
 		"id":           comp.PublicID,
 		"orderId":      orderRef,
@@ -245,6 +416,12 @@ func GetComplaintDetail(c *gin.Context) {
 		"message":      comp.Description,
 		"createdAt":    comp.CreateDate.Format(time.RFC3339),
 		"status":       toUIStatus(comp.StatusComplaint),
+		"priority":     comp.Priority,
+		"category":     comp.Category,
+		"assignedTo":   assignedTo,
+		"sla":          buildComplaintSLA(&comp, time.Now()),
+		"rating":       comp.SatisfactionRating,
+		"order":        snapshot,
 		"history":      history,
 		"attachments":  atts,
 	}
@@ -256,183 +433,239 @@ func GetComplaintDetail(c *gin.Context) {
 // - ดึงเฉพาะรายการไฟล์แนบ (absolute URL)
 // ======================================================
 
+// ?mime=image/png&q=ชื่อไฟล์&sort=-createdAt
+var attachmentList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"mime": {Column: "mime_type", Op: api.OpEq},
+	},
+	Sorts: map[string]string{
+		"id":        "id",
+		"name":      "original_name",
+		"size":      "size_bytes",
+		"createdAt": "created_at",
+	},
+	DefaultSort: "-createdAt",
+	Search:      []string{"original_name"},
+}
+
 func ListComplaintAttachments(c *gin.Context) {
 	publicId := strings.TrimSpace(c.Param("publicId"))
 
 	var comp entity.Complaint
 	if err := config.DB.First(&comp, "public_id = ?", publicId).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบคำร้องเรียน"})
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ค้นหาคำร้องเรียนไม่สำเร็จ: " + err.Error()})
+			api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนไม่สำเร็จ", err))
 		}
 		return
 	}
 
 	var raw []entity.ComplaintAttachment
-	if err := config.DB.Where("complaint_id = ?", comp.ID).Order("created_at DESC").Find(&raw).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ดึงไฟล์แนบไม่สำเร็จ: " + err.Error()})
+	meta, ok := attachmentList.Find(c, config.DB.Model(&entity.ComplaintAttachment{}).Where("complaint_id = ?", comp.ID), &raw)
+	if !ok {
 		return
 	}
 
-	base := absoluteBaseURL(c)
 	items := make([]attachmentItem, 0, len(raw))
-	for _, a := range raw {
-		u := fmt.Sprintf("%s/uploads/complaints/%s/%s", base, comp.PublicID, a.FileName)
-		items = append(items, attachmentItem{
-			URL:  u,
-			Name: a.FileName,
-			Mime: strings.TrimSpace(a.MimeType),
-			Size: a.SizeBytes,
-		})
+	for i := range raw {
+		items = append(items, attachmentItemFor(c, &raw[i]))
 	}
-	c.JSON(http.StatusOK, gin.H{"items": items})
+	api.List(c, items, meta)
 }
 
 // ======================================================
 // POST /employee/complaints/:publicId/replies
+// - ผู้ตอบ: พนักงานเจ้าของ token
 // ======================================================
 
 type addReplyIn struct {
-	EmpID     uint    `json:"empId"     binding:"required"`
-	Text      string  `json:"text"      binding:"required"`
-	NewStatus *string `json:"newStatus"` // optional: "new|in_progress|resolved"
+	Text       string            `json:"text"`       // ต้องมี ถ้าไม่ได้ใช้เทมเพลต
+	TemplateID *uint             `json:"templateId"` // optional: ใช้ข้อความจากเทมเพลต (text ที่ส่งมา = ฉบับแก้ไข)
+	Overrides  map[string]string `json:"overrides"`  // optional: ค่าตัวแปรเทมเพลตที่กำหนดเอง เช่น {"compensation": "ส่วนลด 50 บาท"}
+	NewStatus  *string           `json:"newStatus"`  // optional: "new|in_progress|resolved"
+	Note       string            `json:"note"`       // optional: เหตุผลการเปลี่ยนสถานะ (ถ้าว่างใช้ข้อความตอบกลับ)
+	Internal   bool              `json:"internal"`   // true = บันทึกภายใน ไม่แสดงให้ลูกค้าเห็น
 }
 
 func AddReplyToComplaint(c *gin.Context) {
 	publicId := strings.TrimSpace(c.Param("publicId"))
+	empID := currentEmployeeID(c)
+	if empID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
 
 	var in addReplyIn
-	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if strings.TrimSpace(in.Text) == "" && in.TemplateID == nil {
+		api.Fail(c, api.BadRequest("", "กรุณากรอกข้อความตอบกลับ"))
 		return
 	}
-	if strings.TrimSpace(in.Text) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "กรุณากรอกข้อความตอบกลับ"})
+	if in.NewStatus != nil && !isUIStatus(*in.NewStatus) {
+		api.Fail(c, api.BadRequest("", "สถานะไม่ถูกต้อง (new|in_progress|resolved)"))
 		return
 	}
 
 	var comp entity.Complaint
-	if err := config.DB.First(&comp, "public_id = ?", publicId).Error; err != nil {
+	if err := config.DB.Preload("Customer").First(&comp, "public_id = ?", publicId).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบคำร้องเรียน"})
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ค้นหาคำร้องเรียนไม่สำเร็จ: " + err.Error()})
+			api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนไม่สำเร็จ", err))
 		}
 		return
 	}
 
 	var emp entity.Employee
-	if err := config.DB.First(&emp, in.EmpID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "empId ไม่ถูกต้อง"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ตรวจสอบพนักงานไม่สำเร็จ: " + err.Error()})
-		}
+	if err := config.DB.First(&emp, *empID).Error; err != nil {
+		api.Fail(c, api.InternalMsg("ตรวจสอบพนักงานไม่สำเร็จ", err))
 		return
 	}
 
-	rep := entity.ReplyComplaint{
-		CreateReplyDate: time.Now(),
-		Reply:           in.Text,
-		EmpID:           in.EmpID,
-		ComplaintID:     comp.ID,
-	}
-	if err := config.DB.Create(&rep).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกการตอบกลับไม่สำเร็จ: " + err.Error()})
-		return
+	// เทมเพลต: เติมตัวแปรจากคำร้อง/ลูกค้า (+ overrides) ลงในเทมเพลตหรือข้อความที่แก้ไขแล้ว
+	text := in.Text
+	if in.TemplateID != nil {
+		var tpl entity.ReplyTemplate
+		if err := config.DB.Where("is_active = ?", true).First(&tpl, *in.TemplateID).Error; err != nil {
+			api.Fail(c, api.BadRequest("", "templateId ไม่ถูกต้อง"))
+			return
+		}
+		if strings.TrimSpace(text) == "" {
+			text = tpl.Body
+		}
+		rendered, missing := services.RenderReplyTemplate(text, replyTemplateVars(config.DB, &comp, &emp, in.Overrides))
+		if len(missing) > 0 {
+			api.Fail(c, api.Validation("กรุณาระบุค่าตัวแปรในเทมเพลตให้ครบ (overrides)").With("missing", missing))
+			return
+		}
+		text = rendered
 	}
 
+	// ตอบกลับครั้งแรก -> หยุดนับ SLA ตอบกลับ (บันทึกภายในไม่นับ)
+	reply := services.ComplaintReply{
+		EmployeeID: emp.ID,
+		Text:       text,
+		TemplateID: in.TemplateID,
+		Internal:   in.Internal,
+		Note:       in.Note,
+	}
 	if in.NewStatus != nil {
-		if err := config.DB.Model(&comp).
-			Update("status_complaint", fromUIStatus(*in.NewStatus)).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "อัปเดตสถานะไม่สำเร็จ: " + err.Error()})
-			return
-		}
+		reply.NewStatus = fromUIStatus(*in.NewStatus)
+	}
+	rep, err := services.NewComplaintService(config.DB).Reply(&comp, reply)
+	if err != nil {
+		writeComplaintStatusError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"ok": true,
 		"reply": gin.H{
-			"at":   rep.CreateReplyDate.Format(time.RFC3339),
-			"by":   strings.TrimSpace(emp.FirstName + " " + emp.LastName),
-			"text": rep.Reply,
+			"at":         rep.CreateReplyDate.Format(time.RFC3339),
+			"by":         strings.TrimSpace(emp.FirstName + " " + emp.LastName),
+			"text":       rep.Reply,
+			"internal":   rep.IsInternal,
+			"templateId": rep.TemplateID,
 		},
+		"status": toUIStatus(comp.StatusComplaint),
 	})
 }
 
 // ======================================================
 // PATCH /employee/complaints/:publicId/status
+// - ผู้ดำเนินการ: พนักงานเจ้าของ token
+// - ย้อนสถานะ/เปิดงานใหม่ ต้องระบุ note
 // ======================================================
 
 type setStatusIn struct {
 	Status string `json:"status" binding:"required"` // new|in_progress|resolved
+	Note   string `json:"note"`
 }
 
 func SetComplaintStatus(c *gin.Context) {
 	publicId := strings.TrimSpace(c.Param("publicId"))
+	actorID := currentEmployeeID(c)
+	if actorID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
 
 	var in setStatusIn
-	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &in) {
 		return
 	}
 	val := strings.TrimSpace(in.Status)
 	if val == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุสถานะ"})
+		api.Fail(c, api.BadRequest("", "กรุณาระบุสถานะ"))
+		return
+	}
+	if !isUIStatus(val) {
+		api.Fail(c, api.BadRequest("", "สถานะไม่ถูกต้อง (new|in_progress|resolved)"))
 		return
 	}
 
-	if err := config.DB.Model(&entity.Complaint{}).
-		Where("public_id = ?", publicId).
-		Update("status_complaint", fromUIStatus(val)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เปลี่ยนสถานะไม่สำเร็จ: " + err.Error()})
+	var comp entity.Complaint
+	if err := config.DB.First(&comp, "public_id = ?", publicId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
+		} else {
+			api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนไม่สำเร็จ", err))
+		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+
+	if err := services.NewComplaintService(config.DB).SetStatus(&comp, fromUIStatus(val), *actorID, in.Note); err != nil {
+		writeComplaintStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "status": toUIStatus(comp.StatusComplaint)})
 }
 
 // ======================================================
-// GET /employee/complaints/:publicId/replies
+// GET /employee/complaints/:publicId/replies?internal=&authorType=&q=&sort=-createdAt
 // ======================================================
 
+var replyList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"internal":   {Column: "is_internal", Op: api.OpBool},
+		"authorType": {Column: "author_type", Op: api.OpEq},
+	},
+	Sorts: map[string]string{
+		"id":        "id",
+		"createdAt": "created_at",
+	},
+	DefaultSort: "-createdAt",
+	Search:      []string{"reply"},
+}
+
 func ListReplies(c *gin.Context) {
 	publicId := strings.TrimSpace(c.Param("publicId"))
 
 	var comp entity.Complaint
 	if err := config.DB.First(&comp, "public_id = ?", publicId).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบคำร้องเรียน"})
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ค้นหาคำร้องเรียนไม่สำเร็จ: " + err.Error()})
+			api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนไม่สำเร็จ", err))
 		}
 		return
 	}
 
 	var reps []entity.ReplyComplaint
-	if err := config.DB.
+	meta, ok := replyList.Find(c, config.DB.Model(&entity.ReplyComplaint{}).
 		Preload("Employee").
-		Where("complaint_id = ?", comp.ID).
-		Order("created_at DESC").
-		Find(&reps).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ดึงประวัติการตอบกลับไม่สำเร็จ: " + err.Error()})
+		Preload("Customer").
+		Where("complaint_id = ?", comp.ID), &reps)
+	if !ok {
 		return
 	}
 
 	out := make([]replyItem, 0, len(reps))
-	for _, r := range reps {
-		by := "พนักงาน #" + strconv.Itoa(int(r.EmpID))
-		if r.Employee != nil {
-			full := strings.TrimSpace(r.Employee.FirstName + " " + r.Employee.LastName)
-			if full != "" {
-				by = full
-			}
-		}
-		out = append(out, replyItem{
-			At:   r.CreateReplyDate.Format(time.RFC3339),
-			By:   by,
-			Text: r.Reply,
-		})
+	for i := range reps {
+		out = append(out, toReplyItem(&reps[i]))
 	}
-	c.JSON(http.StatusOK, gin.H{"items": out})
+	api.List(c, out, meta)
 }
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// หมวดคำร้องเรียน
+// - GET    /complaint-categories            -> ใช้ทำ dropdown ฟอร์มลูกค้า (?all=true รวมที่ปิดใช้)
+// - POST   /complaint-categories            (admin)
+// - PUT    /complaint-categories/:id        (admin)
+// - DELETE /complaint-categories/:id        (admin)
+// ======================================================
+
+var categoryCodeRe = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+type complaintCategoryIn struct {
+	Code            string `json:"code" binding:"required"`
+	Name            string `json:"name" binding:"required"`
+	DefaultPriority string `json:"defaultPriority"`
+	OwnerPositionID *uint  `json:"ownerPositionId"`
+	IsActive        *bool  `json:"isActive"`
+}
+
+func (in *complaintCategoryIn) validate() string {
+	in.Code = strings.ToLower(strings.TrimSpace(in.Code))
+	in.Name = strings.TrimSpace(in.Name)
+	in.DefaultPriority = strings.ToLower(strings.TrimSpace(in.DefaultPriority))
+	if !categoryCodeRe.MatchString(in.Code) {
+		return "code ต้องเป็น a-z, 0-9 หรือ _ (2-50 ตัวอักษร)"
+	}
+	if in.Name == "" {
+		return "กรุณากรอกชื่อหมวด"
+	}
+	if in.DefaultPriority == "" {
+		in.DefaultPriority = "normal"
+	}
+	if services.NormalizePriority(in.DefaultPriority) != in.DefaultPriority {
+		return "defaultPriority ต้องเป็น low|normal|high|urgent"
+	}
+	if in.OwnerPositionID != nil {
+		var n int64
+		config.DB.Model(&entity.Position{}).Where("id = ?", *in.OwnerPositionID).Count(&n)
+		if n == 0 {
+			return "ไม่พบตำแหน่งที่รับผิดชอบ"
+		}
+	}
+	return ""
+}
+
+var complaintCategoryList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"priority":        {Column: "default_priority", Op: api.OpEq},
+		"ownerPositionId": {Column: "owner_position_id", Op: api.OpID},
+	},
+	Sorts:  map[string]string{"id": "id", "code": "code", "name": "name"},
+	Search: []string{"code", "name"},
+}
+
+func ListComplaintCategories(c *gin.Context) {
+	db := config.DB.Model(&entity.ComplaintCategory{}).Preload("OwnerPosition")
+	if c.Query("all") != "true" {
+		db = db.Where("is_active = ?", true)
+	}
+	var items []entity.ComplaintCategory
+	meta, ok := complaintCategoryList.Find(c, db, &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta)
+}
+
+func CreateComplaintCategory(c *gin.Context) {
+	var in complaintCategoryIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	var n int64
+	config.DB.Unscoped().Model(&entity.ComplaintCategory{}).Where("code = ?", in.Code).Count(&n)
+	if n > 0 {
+		api.Fail(c, api.Conflict("", "มีรหัสหมวดนี้แล้ว"))
+		return
+	}
+	cat := entity.ComplaintCategory{
+		Code:            in.Code,
+		Name:            in.Name,
+		DefaultPriority: in.DefaultPriority,
+		OwnerPositionID: in.OwnerPositionID,
+		IsActive:        in.IsActive == nil || *in.IsActive,
+	}
+	if err := config.DB.Create(&cat).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, cat)
+}
+
+func UpdateComplaintCategory(c *gin.Context) {
+	var cat entity.ComplaintCategory
+	if err := config.DB.First(&cat, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบหมวดคำร้องเรียน"))
+		return
+	}
+	var in complaintCategoryIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	// คำร้องเดิมเก็บรหัสหมวดไว้ จึงไม่ให้เปลี่ยน code
+	if in.Code != cat.Code {
+		api.Fail(c, api.BadRequest("", "ไม่สามารถเปลี่ยนรหัสหมวดได้"))
+		return
+	}
+	if !sameUintPtr(cat.OwnerPositionID, in.OwnerPositionID) {
+		cat.LastAssignedEmployeeID = nil // เปลี่ยนตำแหน่ง -> เริ่มวนรอบใหม่
+	}
+	cat.Name = in.Name
+	cat.DefaultPriority = in.DefaultPriority
+	cat.OwnerPositionID = in.OwnerPositionID
+	if in.IsActive != nil {
+		cat.IsActive = *in.IsActive
+	}
+	if err := config.DB.Save(&cat).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+func DeleteComplaintCategory(c *gin.Context) {
+	res := config.DB.Delete(&entity.ComplaintCategory{}, c.Param("id"))
+	if res.Error != nil {
+		api.Fail(c, api.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		api.Fail(c, api.NotFound("", "ไม่พบหมวดคำร้องเรียน"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+func sameUintPtr(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+/*
+คำร้องเรียนฝั่งลูกค้า (ต้องแนบ token ลูกค้า)
+- GET  /customer/complaints                        -> รายการของฉัน
+- POST /customer/complaints                        -> สร้างคำร้อง (เหมือน POST /complaints)
+- GET  /customer/complaints/:publicId              -> สถานะ + บทสนทนา + ไฟล์แนบ
+- POST /customer/complaints/:publicId/messages     -> ส่งข้อความเพิ่มเติม
+- POST /customer/complaints/:publicId/attachments  -> แนบไฟล์เพิ่ม
+- POST /customer/complaints/:publicId/rating       -> ให้คะแนนความพึงพอใจ (หลังปิดงาน)
+บันทึกภายในของพนักงานและหมายเหตุการเปลี่ยนสถานะจะไม่ถูกส่งให้ลูกค้า
+*/
+
+// หา complaint ของลูกค้าที่ล็อกอินอยู่ (ของคนอื่นตอบ 404)
+func findOwnComplaint(c *gin.Context, db *gorm.DB) (*entity.Complaint, bool) {
+	customerID := currentCustomerID(c)
+	if customerID == nil {
+		api.Fail(c, api.Forbidden("", "เฉพาะลูกค้าที่เข้าสู่ระบบเท่านั้น"))
+		return nil, false
+	}
+	var comp entity.Complaint
+	err := db.Where("public_id = ? AND customer_id = ?", strings.TrimSpace(c.Param("publicId")), *customerID).
+		First(&comp).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
+		} else {
+			api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนไม่สำเร็จ", err))
+		}
+		return nil, false
+	}
+	return &comp, true
+}
+
+type customerComplaintRow struct {
+	ID        string `json:"id"`
+	OrderRef  string `json:"orderId,omitempty"`
+	Subject   string `json:"subject"`
+	CreatedAt string `json:"createdAt"`
+	Status    string `json:"status"` // new|in_progress|resolved
+	Replies   int    `json:"replies"`
+	Rating    *int   `json:"rating,omitempty"`
+}
+
+// ======================================================
+// GET /customer/complaints?q=&status=&sort=-createdAt&page=&pageSize=
+// ======================================================
+
+// q ค้นรหัส/หัวข้อ
+var myComplaintList = api.ListSpec{
+	Sorts: map[string]string{
+		"createdAt": "complaints.createdate",
+		"status":    "complaints.status_complaint",
+	},
+	DefaultSort:     "-createdAt",
+	Search:          []string{"complaints.public_id", "complaints.title"},
+	DefaultPageSize: 10,
+}
+
+func ListMyComplaints(c *gin.Context) {
+	customerID := currentCustomerID(c)
+	if customerID == nil {
+		api.Fail(c, api.Forbidden("", "เฉพาะลูกค้าที่เข้าสู่ระบบเท่านั้น"))
+		return
+	}
+	status := strings.TrimSpace(c.DefaultQuery("status", "all"))
+
+	db := applyComplaintStatusFilter(config.DB.Model(&entity.Complaint{}), status).
+		Where("complaints.customer_id = ?", *customerID)
+
+	var comps []entity.Complaint
+	db, page, perr := myComplaintList.Apply(c, db.Preload("Replies", "is_internal = ?", false), &comps)
+	if perr != nil {
+		api.Fail(c, perr)
+		return
+	}
+	meta, err := api.Paginate(db, page, &comps)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("ดึงรายการคำร้องเรียนไม่สำเร็จ", err))
+		return
+	}
+
+	rows := make([]customerComplaintRow, 0, len(comps))
+	for _, v := range comps {
+		var orderRef string
+		if v.OrderID != nil {
+			orderRef = "#" + strconv.Itoa(int(*v.OrderID))
+		}
+		rows = append(rows, customerComplaintRow{
+			ID:        v.PublicID,
+			OrderRef:  orderRef,
+			Subject:   v.Title,
+			CreatedAt: v.CreateDate.Format(time.RFC3339),
+			Status:    toUIStatus(v.StatusComplaint),
+			Replies:   len(v.Replies),
+			Rating:    v.SatisfactionRating,
+		})
+	}
+
+	api.List(c, rows, meta)
+}
+
+// ======================================================
+// GET /customer/complaints/:publicId
+// ======================================================
+
+func GetMyComplaint(c *gin.Context) {
+	own, ok := findOwnComplaint(c, config.DB)
+	if !ok {
+		return
+	}
+
+	var comp entity.Complaint
+	if err := config.DB.
+		Preload("Customer").
+		Preload("Replies", func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("is_internal = ?", false).Order("created_at ASC")
+		}).
+		Preload("Replies.Employee").
+		Preload("Replies.Customer").
+		Preload("Histories", func(tx *gorm.DB) *gorm.DB { return tx.Order("changed_date ASC") }).
+		Preload("Attachments").
+		First(&comp, own.ID).Error; err != nil {
+		api.Fail(c, api.InternalMsg("ดึงข้อมูลไม่สำเร็จ", err))
+		return
+	}
+
+	messages := make([]replyItem, 0, len(comp.Replies))
+	for _, r := range comp.Replies {
+		messages = append(messages, toReplyItem(r))
+	}
+
+	// แสดงเฉพาะการเปลี่ยนสถานะ ไม่ส่งหมายเหตุ/ผู้เปลี่ยน และไม่รวมการ escalate ภายใน
+	statusHistory := make([]gin.H, 0, len(comp.Histories))
+	for _, h := range comp.Histories {
+		if h.StatusOld == h.StatusNew {
+			continue
+		}
+		statusHistory = append(statusHistory, gin.H{
+			"at":   h.ChangedDate.Format(time.RFC3339),
+			"from": toUIStatus(h.StatusOld),
+			"to":   toUIStatus(h.StatusNew),
+		})
+	}
+
+	atts := make([]attachmentItem, 0, len(comp.Attachments))
+	for _, a := range comp.Attachments {
+		atts = append(atts, attachmentItemFor(c, a))
+	}
+
+	var orderRef string
+	if comp.OrderID != nil {
+		orderRef = "#" + strconv.Itoa(int(*comp.OrderID))
+	}
+
+	var rating gin.H
+	if comp.SatisfactionRating != nil {
+		rating = gin.H{"score": *comp.SatisfactionRating, "comment": comp.SatisfactionComment, "at": comp.RatedAt}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":            comp.PublicID,
+		"orderId":       orderRef,
+		"subject":       comp.Title,
+		"message":       comp.Description,
+		"createdAt":     comp.CreateDate.Format(time.RFC3339),
+		"status":        toUIStatus(comp.StatusComplaint),
+		"messages":      messages,
+		"statusHistory": statusHistory,
+		"attachments":   atts,
+		"rating":        rating,
+		"canRate":       comp.StatusComplaint == services.ComplaintStatusClosed && comp.SatisfactionRating == nil,
+	})
+}
+
+// ======================================================
+// POST /customer/complaints/:publicId/messages
+// ======================================================
+
+type customerMessageIn struct {
+	Text string `json:"text" binding:"required"`
+}
+
+func PostMyComplaintMessage(c *gin.Context) {
+	var in customerMessageIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	text := strings.TrimSpace(in.Text)
+	if text == "" {
+		api.Fail(c, api.BadRequest("", "กรุณากรอกข้อความ"))
+		return
+	}
+
+	comp, ok := findOwnComplaint(c, config.DB)
+	if !ok {
+		return
+	}
+	msg, err := services.NewComplaintService(config.DB).PostCustomerMessage(comp, text)
+	if err != nil {
+		if errors.Is(err, services.ErrComplaintClosed) {
+			api.Fail(c, api.Conflict("", "คำร้องเรียนนี้ปิดงานแล้ว"))
+		} else {
+			api.Fail(c, api.InternalMsg("บันทึกข้อความไม่สำเร็จ", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"ok": true, "message": toReplyItem(msg)})
+}
+
+// ======================================================
+// POST /customer/complaints/:publicId/rating
+// ======================================================
+
+type ratingIn struct {
+	Score   int    `json:"score" binding:"required,min=1,max=5"`
+	Comment string `json:"comment"`
+}
+
+func RateMyComplaint(c *gin.Context) {
+	var in ratingIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		api.Fail(c, api.BadRequest("", "คะแนนต้องอยู่ระหว่าง 1-5"))
+		return
+	}
+
+	comp, ok := findOwnComplaint(c, config.DB)
+	if !ok {
+		return
+	}
+	now, err := services.NewComplaintService(config.DB).Rate(comp, in.Score, in.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrComplaintNotClosed):
+			api.Fail(c, api.Conflict("", "ให้คะแนนได้หลังปิดงานแล้วเท่านั้น"))
+		case errors.Is(err, services.ErrComplaintAlreadyRated):
+			api.Fail(c, api.Conflict("", "ให้คะแนนคำร้องนี้แล้ว"))
+		default:
+			api.Fail(c, api.InternalMsg("บันทึกคะแนนไม่สำเร็จ", err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "score": in.Score, "ratedAt": now})
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ผูกคำร้องเรียนกับออเดอร์ + ข้อมูลออเดอร์สำหรับพนักงานตรวจสอบ
+// ======================================================
+
+var (
+	ErrComplaintOrderInvalid = errors.New("invalid_order_id")
+	ErrComplaintItemInvalid  = errors.New("invalid_item")
+)
+
+// เลขออเดอร์จากฟอร์ม รองรับ "12" และ "#12"
+func parseComplaintOrderID(raw string) (*uint, error) {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "#")
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || v == 0 {
+		return nil, ErrComplaintOrderInvalid
+	}
+	u := uint(v)
+	return &u, nil
+}
+
+// itemIds ส่งได้ทั้งแบบหลายฟิลด์และคั่นด้วยจุลภาค
+func parseComplaintItemIDs(values []string) ([]uint, error) {
+	var out []uint
+	seen := map[uint]bool{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.ParseUint(part, 10, 64)
+			if err != nil || n == 0 {
+				return nil, ErrComplaintItemInvalid
+			}
+			if !seen[uint(n)] {
+				seen[uint(n)] = true
+				out = append(out, uint(n))
+			}
+		}
+	}
+	return out, nil
+}
+
+// ข้อมูลออเดอร์ ณ ปัจจุบัน: บริการ, ผ้าที่คัดแยก, เครื่องที่ใช้, พนักงานรับ-ส่ง
+type orderSnapshot struct {
+	ID        uint                 `json:"id"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Note      string               `json:"note,omitempty"`
+	Address   string               `json:"address,omitempty"`
+	Services  []snapshotService    `json:"services"`
+	Clothes   []snapshotCloth      `json:"sortedClothes"`
+	Processes []snapshotProcess    `json:"processes"`
+	Payment   *snapshotPayment     `json:"payment,omitempty"`
+	Drivers   []snapshotQueueStaff `json:"drivers"`
+}
+
+type snapshotService struct {
+	Type  string  `json:"type"`
+	Price float64 `json:"price"`
+}
+
+type snapshotCloth struct {
+	ID          uint   `json:"id"`
+	ClothType   string `json:"clothType"`
+	ServiceType string `json:"serviceType,omitempty"`
+	Quantity    int    `json:"quantity"`
+	Reported    bool   `json:"reported"` // ลูกค้าระบุว่ามีปัญหา
+}
+
+type snapshotProcess struct {
+	ID       uint              `json:"id"`
+	Status   string            `json:"status"`
+	Start    *time.Time        `json:"start,omitempty"`
+	End      *time.Time        `json:"end,omitempty"`
+	Employee string            `json:"employee,omitempty"`
+	Machines []snapshotMachine `json:"machines"`
+	Linked   bool              `json:"linked"` // process ที่ผูกกับคำร้อง
+}
+
+type snapshotMachine struct {
+	Number     uint   `json:"number"`
+	Type       string `json:"type"`
+	CapacityKg uint   `json:"capacityKg"`
+}
+
+type snapshotPayment struct {
+	ID     uint   `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Amount int    `json:"amount"`
+}
+
+type snapshotQueueStaff struct {
+	QueueType  string     `json:"queueType"` // pickup | delivery
+	Status     string     `json:"status"`
+	Employee   string     `json:"employee,omitempty"`
+	Phone      string     `json:"phone,omitempty"`
+	AssignedAt *time.Time `json:"assignedAt,omitempty"`
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func buildOrderSnapshot(db *gorm.DB, comp *entity.Complaint) (*orderSnapshot, error) {
+	if comp.OrderID == nil {
+		return nil, nil
+	}
+	var order entity.Order
+	err := db.
+		Preload("Address").
+		Preload("ServiceTypes").
+		Preload("SortingRecord.SortedClothes.ClothType").
+		Preload("SortingRecord.SortedClothes.ServiceType").
+		Preload("LaundryProcesses.Machines").
+		Preload("LaundryProcesses.Employee").
+		Preload("Payment", func(tx *gorm.DB) *gorm.DB { return tx.Omit("check_payment_b64") }).
+		Preload("Queues.Queueassignment.Employee").
+		First(&order, *comp.OrderID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reported := map[uint]bool{}
+	for _, sc := range comp.SortedClothes {
+		if sc != nil {
+			reported[sc.ID] = true
+		}
+	}
+
+	snap := &orderSnapshot{
+		ID:        order.ID,
+		CreatedAt: order.CreatedAt,
+		Note:      order.OrderNote,
+		Services:  []snapshotService{},
+		Clothes:   []snapshotCloth{},
+		Processes: []snapshotProcess{},
+		Drivers:   []snapshotQueueStaff{},
+	}
+	if order.Address != nil {
+		snap.Address = order.Address.AddressDetails
+	}
+	for _, st := range order.ServiceTypes {
+		if st != nil {
+			snap.Services = append(snap.Services, snapshotService{Type: st.Type, Price: st.Price})
+		}
+	}
+	if order.SortingRecord != nil {
+		for _, sc := range order.SortingRecord.SortedClothes {
+			if sc == nil {
+				continue
+			}
+			item := snapshotCloth{ID: sc.ID, Quantity: sc.SortedQuantity, Reported: reported[sc.ID]}
+			if sc.ClothType != nil {
+				item.ClothType = sc.ClothType.TypeName
+			}
+			if sc.ServiceType != nil {
+				item.ServiceType = sc.ServiceType.Type
+			}
+			snap.Clothes = append(snap.Clothes, item)
+		}
+	}
+	for _, p := range order.LaundryProcesses {
+		if p == nil {
+			continue
+		}
+		proc := snapshotProcess{
+			ID:       p.ID,
+			Status:   p.Status,
+			Start:    timePtr(p.Start_time),
+			End:      timePtr(p.End_time),
+			Machines: []snapshotMachine{},
+			Linked:   comp.LaundryProcessID != nil && *comp.LaundryProcessID == p.ID,
+		}
+		if p.Employee != nil {
+			proc.Employee = employeeDisplayName(p.Employee, p.EmployeeID)
+		}
+		for _, m := range p.Machines {
+			if m != nil {
+				proc.Machines = append(proc.Machines, snapshotMachine{Number: m.Machine_number, Type: m.Machine_type, CapacityKg: m.Capacity_kg})
+			}
+		}
+		snap.Processes = append(snap.Processes, proc)
+	}
+	sort.Slice(snap.Processes, func(i, j int) bool { return snap.Processes[i].ID < snap.Processes[j].ID })
+
+	if order.Payment != nil {
+		snap.Payment = &snapshotPayment{
+			ID:     order.Payment.ID,
+			Type:   order.Payment.PaymentType,
+			Status: order.Payment.PaymentStatus,
+			Amount: order.Payment.TotalAmount,
+		}
+	}
+	for _, q := range order.Queues {
+		if q == nil {
+			continue
+		}
+		d := snapshotQueueStaff{QueueType: q.Queue_type, Status: q.Status}
+		if a := q.Queueassignment; a != nil {
+			d.AssignedAt = timePtr(a.Assigned_time)
+			if a.Employee != nil {
+				d.Employee = employeeDisplayName(a.Employee, a.EmployeeID)
+				d.Phone = a.Employee.Phone
+			}
+		}
+		snap.Drivers = append(snap.Drivers, d)
+	}
+	return snap, nil
+}
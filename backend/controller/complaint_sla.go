@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// นโยบาย SLA คำร้องเรียน (admin)
+// - GET    /complaint-sla-policies
+// - POST   /complaint-sla-policies
+// - PUT    /complaint-sla-policies/:id
+// - DELETE /complaint-sla-policies/:id
+// - POST   /complaint-sla-policies/check  -> สั่งตรวจทันที (ปกติรันเองทุก SLA_CHECK_INTERVAL)
+// ======================================================
+
+type slaPolicyIn struct {
+	Name                 string `json:"name" binding:"required"`
+	Category             string `json:"category"`
+	Priority             string `json:"priority"` // ว่าง = ทุกระดับ
+	FirstResponseMinutes int    `json:"firstResponseMinutes"`
+	ResolutionMinutes    int    `json:"resolutionMinutes"`
+	IsActive             *bool  `json:"isActive"`
+}
+
+func (in *slaPolicyIn) validate() string {
+	if in.FirstResponseMinutes < 0 || in.ResolutionMinutes < 0 {
+		return "เวลาต้องไม่ติดลบ"
+	}
+	if in.FirstResponseMinutes == 0 && in.ResolutionMinutes == 0 {
+		return "กรุณากำหนดเวลาตอบกลับหรือเวลาปิดงานอย่างน้อย 1 ค่า"
+	}
+	if in.ResolutionMinutes > 0 && in.FirstResponseMinutes > in.ResolutionMinutes {
+		return "เวลาตอบกลับครั้งแรกต้องไม่เกินเวลาปิดงาน"
+	}
+	in.Category = strings.TrimSpace(in.Category)
+	in.Priority = strings.ToLower(strings.TrimSpace(in.Priority))
+	if in.Priority != "" && services.NormalizePriority(in.Priority) != in.Priority {
+		return "priority ต้องเป็น low|normal|high|urgent"
+	}
+	return ""
+}
+
+var slaPolicyList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"category": {Column: "category", Op: api.OpEq},
+		"priority": {Column: "priority", Op: api.OpEq},
+		"active":   {Column: "is_active", Op: api.OpBool},
+	},
+	Sorts:       map[string]string{"id": "id", "name": "name", "category": "category", "priority": "priority"},
+	DefaultSort: "category,priority",
+	Search:      []string{"name", "category"},
+}
+
+func ListSLAPolicies(c *gin.Context) {
+	var items []entity.ComplaintSLAPolicy
+	meta, ok := slaPolicyList.Find(c, config.DB.Model(&entity.ComplaintSLAPolicy{}), &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta)
+}
+
+func CreateSLAPolicy(c *gin.Context) {
+	var in slaPolicyIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	p := entity.ComplaintSLAPolicy{
+		Name:                 strings.TrimSpace(in.Name),
+		Category:             in.Category,
+		Priority:             in.Priority,
+		FirstResponseMinutes: in.FirstResponseMinutes,
+		ResolutionMinutes:    in.ResolutionMinutes,
+		IsActive:             in.IsActive == nil || *in.IsActive,
+	}
+	if err := config.DB.Create(&p).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+func UpdateSLAPolicy(c *gin.Context) {
+	var p entity.ComplaintSLAPolicy
+	if err := config.DB.First(&p, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบนโยบาย SLA"))
+		return
+	}
+	var in slaPolicyIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	// มีผลกับคำร้องที่สร้างใหม่เท่านั้น กำหนดเวลาของคำร้องเดิมไม่เปลี่ยน
+	p.Name = strings.TrimSpace(in.Name)
+	p.Category = in.Category
+	p.Priority = in.Priority
+	p.FirstResponseMinutes = in.FirstResponseMinutes
+	p.ResolutionMinutes = in.ResolutionMinutes
+	if in.IsActive != nil {
+		p.IsActive = *in.IsActive
+	}
+	if err := config.DB.Save(&p).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func DeleteSLAPolicy(c *gin.Context) {
+	res := config.DB.Delete(&entity.ComplaintSLAPolicy{}, c.Param("id"))
+	if res.Error != nil {
+		api.Fail(c, api.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		api.Fail(c, api.NotFound("", "ไม่พบนโยบาย SLA"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+func RunSLACheck(c *gin.Context) {
+	n, err := services.CheckComplaintSLA(config.DB.WithContext(c.Request.Context()), time.Now())
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"breached": n})
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// GET /employee/complaints/:publicId/timeline
+// - รวมการสร้างคำร้อง / ตอบกลับ / เปลี่ยนสถานะ / ไฟล์แนบ / SLA เรียงตามเวลา
+// ======================================================
+
+type timelineItem struct {
+	At        time.Time       `json:"at"`
+	Type      string          `json:"type"` // created | assigned | reply | customer_message | status | escalation | attachment | sla_breach | rating
+	By        string          `json:"by,omitempty"`
+	Text      string          `json:"text,omitempty"`
+	StatusOld string          `json:"statusOld,omitempty"` // new|in_progress|resolved
+	StatusNew string          `json:"statusNew,omitempty"`
+	File      *attachmentItem `json:"file,omitempty"`
+	Internal  bool            `json:"internal,omitempty"`
+}
+
+func employeeDisplayName(emp *entity.Employee, id uint) string {
+	if emp != nil {
+		if full := strings.TrimSpace(emp.FirstName + " " + emp.LastName); full != "" {
+			return full
+		}
+	}
+	return "พนักงาน #" + strconv.Itoa(int(id))
+}
+
+func GetComplaintTimeline(c *gin.Context) {
+	publicId := strings.TrimSpace(c.Param("publicId"))
+
+	var comp entity.Complaint
+	err := config.DB.
+		Preload("Customer").
+		Preload("AssignedTo").
+		Preload("Replies.Employee").
+		Preload("Replies.Customer").
+		Preload("Histories.Employee").
+		Preload("Histories.EscalatedTo").
+		Preload("Attachments").
+		First(&comp, "public_id = ?", publicId).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
+		} else {
+			api.Fail(c, api.InternalMsg("ดึงข้อมูลไม่สำเร็จ", err))
+		}
+		return
+	}
+
+	items := make([]timelineItem, 0, 1+len(comp.Replies)+len(comp.Histories)+len(comp.Attachments))
+	items = append(items, timelineItem{
+		At:   comp.CreateDate,
+		Type: "created",
+		By:   fullCustomerName(comp.Customer),
+		Text: comp.Title,
+	})
+	if comp.AssignedToID != nil && comp.AssignedAt != nil {
+		items = append(items, timelineItem{
+			At:   *comp.AssignedAt,
+			Type: "assigned",
+			Text: "มอบหมายให้ " + employeeDisplayName(comp.AssignedTo, *comp.AssignedToID),
+		})
+	}
+
+	for _, r := range comp.Replies {
+		it := timelineItem{
+			At:       r.CreateReplyDate,
+			Type:     "reply",
+			By:       replyAuthorName(r),
+			Text:     r.Reply,
+			Internal: r.IsInternal,
+		}
+		if r.AuthorType == "customer" {
+			it.Type = "customer_message"
+		}
+		items = append(items, it)
+	}
+
+	for _, h := range comp.Histories {
+		it := timelineItem{
+			At:   h.ChangedDate,
+			Type: "status",
+			By:   "ระบบ",
+			Text: h.Note,
+		}
+		if h.ChangedBy != nil {
+			it.By = employeeDisplayName(h.Employee, *h.ChangedBy)
+		}
+		// ตัวตรวจ SLA บันทึกการส่งต่อหัวหน้าโดยไม่เปลี่ยนสถานะ
+		if h.StatusOld == h.StatusNew {
+			it.Type = "escalation"
+			if h.EscalatedToID != nil {
+				it.Text += " (" + employeeDisplayName(h.EscalatedTo, *h.EscalatedToID) + ")"
+			}
+		} else {
+			it.StatusOld = toUIStatus(h.StatusOld)
+			it.StatusNew = toUIStatus(h.StatusNew)
+		}
+		items = append(items, it)
+	}
+
+	for _, a := range comp.Attachments {
+		at := a.UploadedAt
+		if at.IsZero() {
+			at = a.CreatedAt
+		}
+		file := attachmentItemFor(c, a)
+		items = append(items, timelineItem{
+			At:   at,
+			Type: "attachment",
+			Text: a.OriginalName,
+			File: &file,
+		})
+	}
+
+	if comp.FirstResponseBreachedAt != nil {
+		items = append(items, timelineItem{At: *comp.FirstResponseBreachedAt, Type: "sla_breach", Text: "เกินกำหนดตอบกลับครั้งแรก"})
+	}
+	if comp.ResolutionBreachedAt != nil {
+		items = append(items, timelineItem{At: *comp.ResolutionBreachedAt, Type: "sla_breach", Text: "เกินกำหนดปิดงาน"})
+	}
+
+	if comp.RatedAt != nil && comp.SatisfactionRating != nil {
+		items = append(items, timelineItem{
+			At:   *comp.RatedAt,
+			Type: "rating",
+			By:   fullCustomerName(comp.Customer),
+			Text: strings.TrimSpace(strconv.Itoa(*comp.SatisfactionRating) + "/5 " + comp.SatisfactionComment),
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].At.Before(items[j].At) })
+
+	api.ListAll(c, items, gin.H{
+		"id":     comp.PublicID,
+		"status": toUIStatus(comp.StatusComplaint),
+	})
+}
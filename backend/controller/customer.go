@@ -1,13 +1,14 @@
 package controller
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -22,32 +23,32 @@ type CustomerCreatePayload struct {
 }
 
 func CreateCustomer(c *gin.Context) {
+	db := auditDB(c)
 	var payload CustomerCreatePayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
 	// เช็ค email ซ้ำก่อนสร้าง user
 	var existingUser entity.User
-	if err := config.DB.Where("email = ? AND deleted_at IS NULL", payload.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email นี้ถูกใช้ไปแล้ว"})
+	if err := db.Where("email = ? AND deleted_at IS NULL", payload.Email).First(&existingUser).Error; err == nil {
+		api.Fail(c, api.BadRequest("", "Email นี้ถูกใช้ไปแล้ว"))
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), 14)
+	hashedPassword, err := services.HashPassword(payload.Password, payload.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		passwordErrorJSON(c, err)
 		return
 	}
 
 	user := entity.User{
 		Email:    payload.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		RoleID:   2, // ลูกค้าอัตโนมัติ
 	}
-	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Create(&user).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -59,14 +60,14 @@ func CreateCustomer(c *gin.Context) {
 		UserID:      user.ID,
 	}
 
-	if err := config.DB.Create(&customer).Error; err != nil {
-		fmt.Println("Create customer error:", err) // เพิ่มบรรทัดนี้
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Create(&customer).Error; err != nil {
+		slog.ErrorContext(c.Request.Context(), "create customer failed", "error", err)
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
 	var createdCustomer entity.Customer
-	config.DB.Preload("User").Preload("Gender").First(&createdCustomer, customer.ID)
+	db.Preload("User").Preload("Gender").First(&createdCustomer, customer.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Customer created successfully",
@@ -83,7 +84,7 @@ func GetCustomerByID(c *gin.Context) {
         return db.Order("created_at DESC") // ดึงทั้งหมด เรียงล่าสุดก่อน
     }).Preload("Orders.Customer").Preload("Orders.Customer.Addresses").
 		First(&customer, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 	// ถ้า User ยัง nil ให้ดึงใหม่
@@ -100,7 +101,7 @@ func GetCustomerByID(c *gin.Context) {
 func GetCustomerProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		api.Fail(c, api.Unauthorized("", ""))
 		return
 	}
 
@@ -110,30 +111,50 @@ func GetCustomerProfile(c *gin.Context) {
 		Preload("Addresses").
 		Preload("Gender").
 		First(&customer, "user_id = ?", userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 
 	c.JSON(http.StatusOK, customer)
 }
 
+// คืน CustomerID ของผู้ใช้ที่ล็อกอินอยู่ (nil ถ้าไม่มี token หรือไม่ใช่ลูกค้า)
+func currentCustomerID(c *gin.Context) *uint {
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		return nil
+	}
+	var cus entity.Customer
+	if err := config.DB.Select("id").Where("user_id = ?", uidVal.(uint)).First(&cus).Error; err != nil {
+		return nil
+	}
+	return &cus.ID
+}
+
+// GET /customers?q=&genderId=&sort=-createdAt (q ค้นชื่อ/เบอร์/อีเมล)
+var customerList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"genderId": {Column: "customers.gender_id", Op: api.OpID},
+	},
+	Sorts: map[string]string{
+		"id":        "customers.id",
+		"firstName": "customers.first_name",
+		"lastName":  "customers.last_name",
+		"createdAt": "customers.created_at",
+	},
+	Search: []string{"customers.first_name", "customers.last_name", "customers.phone_number", "users.email"},
+}
+
 // ดึงลูกค้าทั้งหมด (หน้า admin)
 func GetCustomers(c *gin.Context) {
 	var customers []entity.Customer
-	if err := config.DB.Preload("User").Preload("Gender").Find(&customers).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := customerList.Find(c, config.DB.Model(&entity.Customer{}).
+		Joins("LEFT JOIN users ON users.id = customers.user_id").
+		Preload("User").Preload("Gender"), &customers)
+	if !ok {
 		return
 	}
-	// Force reload User for each customer if missing
-	for i, cust := range customers {
-		if cust.User == nil && cust.UserID != 0 {
-			var user entity.User
-			if err := config.DB.First(&user, cust.UserID).Error; err == nil {
-				customers[i].User = &user
-			}
-		}
-	}
-	c.JSON(http.StatusOK, customers)
+	api.List(c, customers, meta)
 }
 
 // -------------------- UPDATE --------------------
@@ -146,17 +167,17 @@ type CustomerUpdatePayload struct {
 }
 
 func UpdateCustomer(c *gin.Context) {
+	db := auditDB(c)
 	id := c.Param("id")
 	var customer entity.Customer
 
-	if err := config.DB.First(&customer, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+	if err := db.First(&customer, id).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 
 	var payload CustomerUpdatePayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
@@ -165,26 +186,26 @@ func UpdateCustomer(c *gin.Context) {
 	customer.PhoneNumber = payload.Phone
 	customer.GenderID = payload.GenderID
 
-	if err := config.DB.Save(&customer).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Save(&customer).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
 	// Update email in User table if provided
 	if payload.Email != "" {
 		var user entity.User
-		if err := config.DB.First(&user, customer.UserID).Error; err == nil {
+		if err := db.First(&user, customer.UserID).Error; err == nil {
 			user.Email = payload.Email
-			if err := config.DB.Save(&user).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email: " + err.Error()})
+			if err := db.Save(&user).Error; err != nil {
+				api.Fail(c, api.InternalMsg("อัปเดตอีเมลไม่สำเร็จ", err))
 				return
 			}
 		}
 	}
 
 	var updatedCustomer entity.Customer
-	if err := config.DB.Preload("User").Preload("Gender").First(&updatedCustomer, customer.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Preload("User").Preload("Gender").First(&updatedCustomer, customer.ID).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -205,19 +226,18 @@ type CustomerEditProfilePayload struct {
 func EditCustomerProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		api.Fail(c, api.Unauthorized("", ""))
 		return
 	}
 
 	var payload CustomerEditProfilePayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
 	var customer entity.Customer
 	if err := config.DB.First(&customer, "user_id = ?", userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 
@@ -227,7 +247,7 @@ func EditCustomerProfile(c *gin.Context) {
 	customer.GenderID = payload.GenderID
 
 	if err := config.DB.Save(&customer).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -242,20 +262,21 @@ func EditCustomerProfile(c *gin.Context) {
 
 // -------------------- DELETE --------------------
 func DeleteCustomer(c *gin.Context) {
+	db := auditDB(c)
 	id := c.Param("id")
 	var customer entity.Customer
 
-	if err := config.DB.First(&customer, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+	if err := db.First(&customer, id).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 
-	if err := config.DB.Delete(&customer).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Delete(&customer).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
-	if err := config.DB.Delete(&entity.User{}, customer.UserID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Delete(&entity.User{}, customer.UserID).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ผลงานพนักงาน (คำนวณจากตารางกิจกรรมที่บันทึกอยู่แล้ว)
+// - GET /employees/:id/metrics?from=&to=
+// - GET /employees/leaderboard?from=&to=&sort=total|pickups|deliveries|processes|replies|resolved&limit=
+// ช่วงวันที่ใช้กติกาเดียวกับ /reports (ค่าเริ่มต้น 30 วันล่าสุด)
+// ======================================================
+
+// สถานะที่นับว่าเสร็จ
+const (
+	queueStatusPickupDone   = "done"
+	queueStatusDelivered    = "delivered"
+	laundryProcessFinished  = "เสร็จสิ้น"
+	employeeReplyAuthorType = "employee"
+)
+
+type employeeMetrics struct {
+	EmployeeID uint   `json:"employeeId"`
+	Name       string `json:"name"`
+	Position   string `json:"position,omitempty"`
+
+	Pickups             int     `json:"pickups"`
+	Deliveries          int     `json:"deliveries"`
+	AvgAcceptToDoneMins float64 `json:"avgAcceptToDoneMinutes"` // รับงาน -> กดเสร็จ (ทั้งรับและส่ง)
+
+	ProcessesFinished int     `json:"processesFinished"`
+	AvgProcessMins    float64 `json:"avgProcessMinutes"` // เริ่ม -> เสร็จสิ้น
+
+	Replies             int     `json:"replies"`       // ตอบลูกค้า (ไม่รวมบันทึกภายใน)
+	InternalNotes       int     `json:"internalNotes"` // บันทึกภายใน
+	ComplaintsResolved  int     `json:"complaintsResolved"`
+	AvgResolutionHours  float64 `json:"avgResolutionHours"` // สร้างคำร้อง -> ปิดงาน
+	DetergentUsed       int     `json:"detergentUsed"`
+	DetergentPerProcess float64 `json:"detergentPerProcess"`
+
+	acceptToDone time.Duration
+	nAcceptDone  int
+	process      time.Duration
+	nProcess     int
+	resolution   time.Duration
+	nResolution  int
+}
+
+func (m *employeeMetrics) finish() {
+	if m.nAcceptDone > 0 {
+		m.AvgAcceptToDoneMins = round2(m.acceptToDone.Minutes() / float64(m.nAcceptDone))
+	}
+	if m.nProcess > 0 {
+		m.AvgProcessMins = round2(m.process.Minutes() / float64(m.nProcess))
+	}
+	m.AvgResolutionHours = avgHours(m.resolution, m.nResolution)
+	if m.ProcessesFinished > 0 {
+		m.DetergentPerProcess = round2(float64(m.DetergentUsed) / float64(m.ProcessesFinished))
+	}
+}
+
+// buildEmployeeMetrics ดึงข้อมูลแต่ละตารางครั้งเดียวแล้วรวมยอดรายพนักงาน
+// employeeID = nil -> ทุกคน
+func buildEmployeeMetrics(db *gorm.DB, start, end time.Time, employeeID *uint) ([]employeeMetrics, error) {
+	empQ := db.Preload("Position").Order("id")
+	if employeeID != nil {
+		empQ = empQ.Where("id = ?", *employeeID)
+	}
+	var emps []entity.Employee
+	if err := empQ.Find(&emps).Error; err != nil {
+		return nil, err
+	}
+	out := make([]employeeMetrics, 0, len(emps))
+	idx := map[uint]int{}
+	byUser := map[uint]uint{} // UserID -> EmployeeID (ประวัติน้ำยาผูกกับ User)
+	for i, e := range emps {
+		m := employeeMetrics{EmployeeID: e.ID, Name: employeeDisplayName(&emps[i], e.ID)}
+		if e.Position != nil {
+			m.Position = e.Position.PositionName
+		}
+		idx[e.ID] = len(out)
+		out = append(out, m)
+		if e.UserID != 0 {
+			byUser[e.UserID] = e.ID
+		}
+	}
+	get := func(empID uint) *employeeMetrics {
+		if i, ok := idx[empID]; ok {
+			return &out[i]
+		}
+		return nil
+	}
+
+	// ---------- คิวรับ/ส่งผ้า ----------
+	// AcceptQueue สร้าง assignment แรก, Confirm*Done สร้าง assignment สุดท้าย (คนที่กดเสร็จ)
+	var assigns []entity.QueueAssignment
+	if err := db.Joins("JOIN queues ON queues.id = queue_assignments.queue_id").
+		Where("queues.status IN ? AND queues.deleted_at IS NULL", []string{queueStatusPickupDone, queueStatusDelivered}).
+		// ดึงทุก assignment ของคิวที่มีการกดเสร็จในช่วง (รวม assignment ตอนรับงานที่อาจอยู่ก่อนช่วง)
+		Where("queue_assignments.queue_id IN (?)", db.Model(&entity.QueueAssignment{}).
+			Select("queue_id").Where("assigned_time >= ?", start.AddDate(0, 0, -1))).
+		Preload("Queues").
+		Order("queue_assignments.queue_id, queue_assignments.assigned_time, queue_assignments.id").
+		Find(&assigns).Error; err != nil {
+		return nil, err
+	}
+	byQueue := map[uint][]entity.QueueAssignment{}
+	var queueOrder []uint
+	for _, a := range assigns {
+		if _, ok := byQueue[a.QueueID]; !ok {
+			queueOrder = append(queueOrder, a.QueueID)
+		}
+		byQueue[a.QueueID] = append(byQueue[a.QueueID], a)
+	}
+	for _, qid := range queueOrder {
+		list := byQueue[qid]
+		first, last := list[0], list[len(list)-1]
+		if last.Queues == nil || !inRange(last.Assigned_time, start, end) {
+			continue
+		}
+		m := get(last.EmployeeID)
+		if m == nil {
+			continue
+		}
+		switch strings.ToLower(last.Queues.Queue_type) {
+		case "pickup":
+			m.Pickups++
+		case "delivery":
+			m.Deliveries++
+		default:
+			continue
+		}
+		// มี assignment เดียว = กดเสร็จโดยไม่ได้กดรับ ไม่มีเวลาเริ่มให้วัด
+		if len(list) > 1 && last.Assigned_time.After(first.Assigned_time) {
+			m.acceptToDone += last.Assigned_time.Sub(first.Assigned_time)
+			m.nAcceptDone++
+		}
+	}
+
+	// ---------- ขั้นตอนซัก/อบ ----------
+	var procs []entity.LaundryProcess
+	if err := widenRange(db, "end_time", start, end).
+		Where("status = ? AND employee_id <> 0", laundryProcessFinished).
+		Find(&procs).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range procs {
+		if !inRange(p.End_time, start, end) {
+			continue
+		}
+		m := get(p.EmployeeID)
+		if m == nil {
+			continue
+		}
+		m.ProcessesFinished++
+		if !p.Start_time.IsZero() && p.End_time.After(p.Start_time) {
+			m.process += p.End_time.Sub(p.Start_time)
+			m.nProcess++
+		}
+	}
+
+	// ---------- ตอบคำร้อง ----------
+	var replies []entity.ReplyComplaint
+	if err := widenRange(db, "createdate_reply", start, end).
+		Where("author_type = ? AND emp_id <> 0", employeeReplyAuthorType).
+		Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range replies {
+		if !inRange(r.CreateReplyDate, start, end) {
+			continue
+		}
+		m := get(r.EmpID)
+		if m == nil {
+			continue
+		}
+		if r.IsInternal {
+			m.InternalNotes++
+		} else {
+			m.Replies++
+		}
+	}
+
+	// ปิดงานคำร้อง: นับให้คนที่เปลี่ยนสถานะเป็นปิดงาน (ปิดซ้ำนับครั้งล่าสุด)
+	var closes []entity.HistoryComplain
+	if err := widenRange(db, "changed_date", start, end).
+		Where("status_new = ? AND changed_by IS NOT NULL", services.ComplaintStatusClosed).
+		Preload("Complaint").
+		Order("changed_date, id").
+		Find(&closes).Error; err != nil {
+		return nil, err
+	}
+	closedBy := map[uint]entity.HistoryComplain{}
+	for _, h := range closes {
+		if inRange(h.ChangedDate, start, end) {
+			closedBy[h.ComplaintID] = h
+		}
+	}
+	for _, h := range closedBy {
+		m := get(*h.ChangedBy)
+		if m == nil {
+			continue
+		}
+		m.ComplaintsResolved++
+		if h.Complaint != nil && !h.Complaint.CreateDate.IsZero() && h.ChangedDate.After(h.Complaint.CreateDate) {
+			m.resolution += h.ChangedDate.Sub(h.Complaint.CreateDate)
+			m.nResolution++
+		}
+	}
+
+	// ---------- น้ำยาที่ใช้ ----------
+	if len(byUser) > 0 {
+		var usages []entity.DetergentUsageHistory
+		userIDs := make([]uint, 0, len(byUser))
+		for uid := range byUser {
+			userIDs = append(userIDs, uid)
+		}
+		if err := widenRange(db, "created_at", start, end).
+			Where("user_id IN ?", userIDs).
+			Find(&usages).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range usages {
+			if !inRange(u.CreatedAt, start, end) {
+				continue
+			}
+			if m := get(byUser[u.UserID]); m != nil {
+				m.DetergentUsed += u.QuantityUsed
+			}
+		}
+	}
+
+	for i := range out {
+		out[i].finish()
+	}
+	return out, nil
+}
+
+// GET /employees/:id/metrics
+func GetEmployeeMetrics(c *gin.Context) {
+	emp, ok := findEmployeeParam(c)
+	if !ok {
+		return
+	}
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+	items, err := buildEmployeeMetrics(config.DB, start, end, &emp.ID)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	var metrics *employeeMetrics
+	if len(items) > 0 {
+		metrics = &items[0]
+	}
+	c.JSON(http.StatusOK, gin.H{"range": reportRangeOut(start, end), "metrics": metrics})
+}
+
+var leaderboardSorts = map[string]func(m *employeeMetrics) float64{
+	"pickups":    func(m *employeeMetrics) float64 { return float64(m.Pickups) },
+	"deliveries": func(m *employeeMetrics) float64 { return float64(m.Deliveries) },
+	"processes":  func(m *employeeMetrics) float64 { return float64(m.ProcessesFinished) },
+	"replies":    func(m *employeeMetrics) float64 { return float64(m.Replies) },
+	"resolved":   func(m *employeeMetrics) float64 { return float64(m.ComplaintsResolved) },
+	// รวมงานทุกประเภท
+	"total": func(m *employeeMetrics) float64 {
+		return float64(m.Pickups + m.Deliveries + m.ProcessesFinished + m.Replies + m.ComplaintsResolved)
+	},
+}
+
+// GET /employees/leaderboard
+func GetEmployeeLeaderboard(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+	sortBy := strings.ToLower(strings.TrimSpace(c.DefaultQuery("sort", "total")))
+	score, ok := leaderboardSorts[sortBy]
+	if !ok {
+		api.Fail(c, api.BadRequest("", "sort ต้องเป็น total|pickups|deliveries|processes|replies|resolved"))
+		return
+	}
+	limit := 10
+	if s := strings.TrimSpace(c.Query("limit")); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			api.Fail(c, api.BadRequest("", "limit ไม่ถูกต้อง"))
+			return
+		}
+		limit = n
+	}
+
+	items, err := buildEmployeeMetrics(config.DB, start, end, nil)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	// ไม่มีผลงานเลยไม่ต้องขึ้นอันดับ
+	ranked := items[:0]
+	for i := range items {
+		if score(&items[i]) > 0 {
+			ranked = append(ranked, items[i])
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return score(&ranked[i]) > score(&ranked[j])
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	type row struct {
+		Rank  int     `json:"rank"`
+		Score float64 `json:"score"`
+		employeeMetrics
+	}
+	rows := make([]row, 0, len(ranked))
+	for i := range ranked {
+		rows = append(rows, row{Rank: i + 1, Score: score(&ranked[i]), employeeMetrics: ranked[i]})
+	}
+	api.ListAll(c, rows, gin.H{"range": reportRangeOut(start, end), "sort": sortBy})
+}
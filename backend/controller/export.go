@@ -0,0 +1,405 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+/*
+Export ข้อมูลสำหรับฝ่ายบัญชี
+- GET /exports/:dataset?format=csv|xlsx  (dataset: orders | payments | detergent-purchases | detergent-usage | complaints)
+- ใช้ ListSpec/filter เดียวกับหน้ารายการ (ผลลัพธ์ตรงกับที่หน้ารายการแสดง) from/to = YYYY-MM-DD ไม่บังคับ
+- ดึงข้อมูลทีละชุด (FindInBatches) แล้วเขียนลง response ทันที ไม่โหลดทั้งตารางเข้าหน่วยความจำ
+*/
+
+const exportBatchSize = 500
+
+// ---------- writer ----------
+
+type exportWriter interface {
+	Write(row []string) error
+	Close() error
+}
+
+// CSV: ใส่ UTF-8 BOM ให้ Excel อ่านภาษาไทยถูก
+type csvExportWriter struct {
+	w *csv.Writer
+	n int
+}
+
+func newCSVExportWriter(c *gin.Context, filename string) *csvExportWriter {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+	c.Status(http.StatusOK)
+	_, _ = c.Writer.Write([]byte{0xEF, 0xBB, 0xBF})
+	return &csvExportWriter{w: csv.NewWriter(c.Writer)}
+}
+
+// ค่าที่ขึ้นต้นด้วย = + - @ (หรือ tab/CR) Excel จะตีความเป็นสูตร -> เติม ' นำหน้าให้เป็นข้อความ
+// (ข้อความจากลูกค้า เช่น หัวข้อคำร้อง ถูกส่งออกตรง ๆ) ยกเว้นตัวเลข เช่น ยอดติดลบ ให้ Excel ยังคำนวณได้
+func csvSafeCell(v string) string {
+	if v == "" || !strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return v
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	return "'" + v
+}
+
+func (e *csvExportWriter) Write(row []string) error {
+	safe := make([]string, len(row))
+	for i, v := range row {
+		safe[i] = csvSafeCell(v)
+	}
+	if err := e.w.Write(safe); err != nil {
+		return err
+	}
+	e.n++
+	if e.n%exportBatchSize == 0 {
+		e.w.Flush()
+	}
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// XLSX: ใช้ StreamWriter ของ excelize แล้วเขียนไฟล์ลง response ตอนปิด
+type xlsxExportWriter struct {
+	c     *gin.Context
+	f     *excelize.File
+	sw    *excelize.StreamWriter
+	row   int
+	fname string
+}
+
+func newXLSXExportWriter(c *gin.Context, filename string) (*xlsxExportWriter, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxExportWriter{c: c, f: f, sw: sw, fname: filename}, nil
+}
+
+func (e *xlsxExportWriter) Write(row []string) error {
+	e.row++
+	cells := make([]interface{}, len(row))
+	for i, v := range row {
+		cells[i] = v
+	}
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.sw.SetRow(cell, cells)
+}
+
+func (e *xlsxExportWriter) Close() error {
+	defer e.f.Close()
+	if err := e.sw.Flush(); err != nil {
+		return err
+	}
+	e.c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	e.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, e.fname))
+	e.c.Status(http.StatusOK)
+	return e.f.Write(e.c.Writer)
+}
+
+// ---------- helpers ----------
+
+func fmtTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(shopLocation()).Format("2006-01-02 15:04:05")
+}
+
+func fmtTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return fmtTime(*t)
+}
+
+func fmtMoney(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+func exportFilename(dataset string) string {
+	return fmt.Sprintf("%s_%s", dataset, time.Now().In(shopLocation()).Format("20060102_150405"))
+}
+
+// ---------- datasets ----------
+
+type exportDataset struct {
+	header []string
+	query  func(c *gin.Context) (*gorm.DB, *api.Error) // สร้างก่อนเริ่มเขียนไฟล์ (filter ผิด = 400)
+	write  func(db *gorm.DB, w exportWriter) error
+}
+
+var exportDatasets = map[string]exportDataset{
+	"orders": {
+		header: []string{"เลขออเดอร์", "วันที่สร้าง", "รหัสลูกค้า", "ชื่อลูกค้า", "เบอร์โทร", "ที่อยู่", "บริการ", "ยอดรวมบริการ", "ยอดชำระ", "สถานะชำระ", "วิธีชำระ", "หมายเหตุ"},
+		query:  exportOrdersQuery,
+		write:  exportOrders,
+	},
+	"payments": {
+		header: []string{"รหัสชำระ", "เลขออเดอร์", "วันที่", "วิธีชำระ", "สถานะ", "ยอดที่ต้องชำระ", "ยอดที่ตรวจสอบ", "TransRef", "วันที่ในสลิป", "ตรวจสอบเมื่อ", "ผู้รับเงิน"},
+		query:  exportPaymentsQuery,
+		write:  exportPayments,
+	},
+	"detergent-purchases": {
+		header: []string{"รหัส", "วันที่", "รหัสน้ำยา", "ชื่อน้ำยา", "ประเภท", "จำนวน", "ราคา", "ผู้จำหน่าย", "ผู้บันทึก"},
+		query:  exportDetergentPurchasesQuery,
+		write:  exportDetergentPurchases,
+	},
+	"detergent-usage": {
+		header: []string{"รหัส", "วันที่", "รหัสน้ำยา", "ชื่อน้ำยา", "จำนวนที่ใช้", "เหตุผล", "ผู้ใช้"},
+		query:  exportDetergentUsageQuery,
+		write:  exportDetergentUsage,
+	},
+	"complaints": {
+		header: []string{"เลขที่คำร้อง", "วันที่", "สถานะ", "หัวข้อ", "รายละเอียด", "ลูกค้า", "อีเมล", "เลขออเดอร์", "จำนวนการตอบกลับ"},
+		query:  exportComplaintsQuery,
+		write:  exportComplaints,
+	},
+}
+
+// ออเดอร์: filter เดียวกับ /laundry-check/orders (customerId, from, to, q)
+func exportOrdersQuery(c *gin.Context) (*gorm.DB, *api.Error) {
+	return laundryOrderList.Filter(c, config.DB.Model(&entity.Order{}).
+		Joins("LEFT JOIN customers ON customers.id = orders.customer_id").
+		Preload("Customer").
+		Preload("Address").
+		Preload("ServiceTypes").
+		Preload("Payment", func(db *gorm.DB) *gorm.DB { return db.Omit("check_payment_b64") }))
+}
+
+func exportOrders(db *gorm.DB, w exportWriter) error {
+	var batch []entity.Order
+	return db.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, o := range batch {
+			names := make([]string, 0, len(o.ServiceTypes))
+			sum := 0.0
+			for _, st := range o.ServiceTypes {
+				if st != nil {
+					names = append(names, st.Type)
+					sum += st.Price
+				}
+			}
+			custID, custName, phone, addr := "", "", "", ""
+			if o.Customer != nil {
+				custID = strconv.Itoa(int(o.Customer.ID))
+				custName = fullCustomerName(o.Customer)
+				phone = o.Customer.PhoneNumber
+			}
+			if o.Address != nil {
+				addr = o.Address.AddressDetails
+			}
+			paid, status, method := "", "", ""
+			if o.Payment != nil {
+				paid = strconv.Itoa(o.Payment.TotalAmount)
+				status = o.Payment.PaymentStatus
+				method = o.Payment.PaymentType
+			}
+			if err := w.Write([]string{
+				strconv.Itoa(int(o.ID)), fmtTime(o.CreatedAt), custID, custName, phone, addr,
+				strings.Join(names, ", "), fmtMoney(sum), paid, status, method, o.OrderNote,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// การชำระเงินไม่มีหน้ารายการ: ตั้งชื่อ filter แบบเดียวกับรายการอื่น
+var paymentExportList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"status":  {Column: "payments.payment_status", Op: api.OpEq},
+		"type":    {Column: "payments.payment_type", Op: api.OpEq},
+		"orderId": {Column: "payments.order_id", Op: api.OpID},
+		"from":    {Column: "payments.created_at", Op: api.OpFrom},
+		"to":      {Column: "payments.created_at", Op: api.OpTo},
+	},
+	Search: []string{"payments.trans_ref"},
+}
+
+func exportPaymentsQuery(c *gin.Context) (*gorm.DB, *api.Error) {
+	return paymentExportList.Filter(c, config.DB.Model(&entity.Payment{}).Omit("check_payment_b64"))
+}
+
+func exportPayments(db *gorm.DB, w exportWriter) error {
+	var batch []entity.Payment
+	return db.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, p := range batch {
+			receivedBy := ""
+			if p.ReceivedBy != nil {
+				receivedBy = strconv.Itoa(int(*p.ReceivedBy))
+			}
+			if err := w.Write([]string{
+				strconv.Itoa(int(p.ID)), strconv.Itoa(int(p.OrderID)), fmtTime(p.CreatedAt),
+				p.PaymentType, p.PaymentStatus, strconv.Itoa(p.TotalAmount), strconv.Itoa(p.VerifiedAmount),
+				p.TransRef, fmtTimePtr(p.SlipDate), fmtTimePtr(p.SlipVerifiedAt), receivedBy,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// filter เดียวกับ /detergents/purchase-history
+func exportDetergentPurchasesQuery(c *gin.Context) (*gorm.DB, *api.Error) {
+	return purchaseDetergentList.Filter(c, config.DB.Model(&entity.PurchaseDetergent{}).
+		Joins("LEFT JOIN detergents ON detergents.id = purchase_detergents.detergent_id").
+		Preload("Detergent", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("User"))
+}
+
+func exportDetergentPurchases(db *gorm.DB, w exportWriter) error {
+	var batch []entity.PurchaseDetergent
+	return db.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, p := range batch {
+			name, typ, user := "", "", ""
+			if p.Detergent != nil {
+				name, typ = p.Detergent.Name, p.Detergent.Type
+			}
+			if p.User != nil {
+				user = p.User.Email
+			}
+			if err := w.Write([]string{
+				strconv.Itoa(int(p.ID)), fmtTime(p.CreatedAt), strconv.Itoa(int(p.DetergentID)), name, typ,
+				strconv.Itoa(p.Quantity), fmtMoney(p.Price), p.Supplier, user,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// filter เดียวกับ /detergents/usage-history
+func exportDetergentUsageQuery(c *gin.Context) (*gorm.DB, *api.Error) {
+	return detergentUsageList.Filter(c, config.DB.Model(&entity.DetergentUsageHistory{}).
+		Joins("LEFT JOIN detergents ON detergents.id = detergent_usage_histories.detergent_id").
+		Joins("LEFT JOIN employees ON employees.user_id = detergent_usage_histories.user_id AND employees.deleted_at IS NULL").
+		Preload("Detergent", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("User"))
+}
+
+func exportDetergentUsage(db *gorm.DB, w exportWriter) error {
+	var batch []entity.DetergentUsageHistory
+	return db.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, u := range batch {
+			name := ""
+			if u.Detergent != nil {
+				name = u.Detergent.Name
+			}
+			if err := w.Write([]string{
+				strconv.Itoa(int(u.ID)), fmtTime(u.CreatedAt), strconv.Itoa(int(u.DetergentID)), name,
+				strconv.Itoa(u.QuantityUsed), u.Reason, u.User.Email,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// คำร้อง: filter/q เดียวกับ /employee/complaints และเพิ่มช่วงวันที่แจ้ง
+var complaintExportList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"from": {Column: "complaints.createdate", Op: api.OpFrom},
+		"to":   {Column: "complaints.createdate", Op: api.OpTo},
+	},
+	Search: employeeComplaintList.Search,
+}
+
+func exportComplaintsQuery(c *gin.Context) (*gorm.DB, *api.Error) {
+	db, err := employeeComplaintQuery(c, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return complaintExportList.Filter(c, db.Preload("Customer").Preload("Replies"))
+}
+
+func exportComplaints(db *gorm.DB, w exportWriter) error {
+	var batch []entity.Complaint
+	return db.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, cp := range batch {
+			orderRef := ""
+			if cp.OrderID != nil {
+				orderRef = strconv.Itoa(int(*cp.OrderID))
+			}
+			if err := w.Write([]string{
+				cp.PublicID, fmtTime(cp.CreateDate), cp.StatusComplaint, cp.Title, cp.Description,
+				fullCustomerName(cp.Customer), cp.Email, orderRef, strconv.Itoa(len(cp.Replies)),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// ======================================================
+// GET /exports/:dataset?format=csv|xlsx
+// ======================================================
+
+func ExportDataset(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("dataset"))
+	ds, ok := exportDatasets[name]
+	if !ok {
+		api.Fail(c, api.NewError(http.StatusNotFound, "unknown_dataset"))
+		return
+	}
+	db, qerr := ds.query(c)
+	if qerr != nil {
+		api.Fail(c, qerr)
+		return
+	}
+
+	var w exportWriter
+	switch strings.ToLower(c.DefaultQuery("format", "csv")) {
+	case "csv":
+		w = newCSVExportWriter(c, exportFilename(name))
+	case "xlsx":
+		xw, err := newXLSXExportWriter(c, exportFilename(name))
+		if err != nil {
+			api.Fail(c, api.Internal(err))
+			return
+		}
+		w = xw
+	default:
+		api.Fail(c, api.BadRequest("", "format ต้องเป็น csv หรือ xlsx"))
+		return
+	}
+
+	if err := w.Write(ds.header); err == nil {
+		err = ds.write(db, w)
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			// CSV เริ่มส่ง header ไปแล้ว แจ้งได้แค่ยกเลิกการเชื่อมต่อ
+			_ = c.Error(err)
+			if !c.Writer.Written() {
+				api.Fail(c, api.Internal(err))
+			}
+		}
+	}
+}
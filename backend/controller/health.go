@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// สำหรับ container orchestrator
+//   GET /healthz  process ยังทำงาน (ไม่แตะฐานข้อมูล)
+//   GET /readyz   พร้อมรับคำขอ: ping ฐานข้อมูลได้ และยังไม่เริ่มปิดเซิร์ฟเวอร์
+// ======================================================
+
+var draining atomic.Bool
+
+// SetDraining เริ่มปิดเซิร์ฟเวอร์: /readyz ตอบ 503 ให้ load balancer เลิกส่งคำขอใหม่
+func SetDraining(v bool) { draining.Store(v) }
+
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func Readyz(c *gin.Context) {
+	if draining.Load() {
+		api.Fail(c, api.NewError(http.StatusServiceUnavailable, "shutting_down").With("status", "shutting_down"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+	sqlDB, err := config.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "readiness: database ping failed", "error", err)
+		api.Fail(c, api.NewError(http.StatusServiceUnavailable, "").With("status", "unavailable").With("database", "down"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "database": "up"})
+}
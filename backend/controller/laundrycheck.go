@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
 	"github.com/gin-gonic/gin"
@@ -87,15 +88,23 @@ type HistoryEntry struct {
 }
 
 // ===================== Helpers =====================
-func findDefaultAddressText(cust *entity.Customer) (addrID uint, addrText string) {
-	var addr entity.Address
-	if err := config.DB.Where("customer_id = ? AND is_default = ?", cust.ID, true).First(&addr).Error; err == nil {
-		return addr.ID, addr.AddressDetails
+// ที่อยู่หลักของลูกค้าหลายคนในคำสั่งเดียว (ไม่มีที่อยู่ default = ใช้ที่อยู่แรก)
+func defaultAddresses(customerIDs []uint) (map[uint]entity.Address, error) {
+	out := make(map[uint]entity.Address, len(customerIDs))
+	if len(customerIDs) == 0 {
+		return out, nil
+	}
+	var addrs []entity.Address
+	if err := config.DB.Where("customer_id IN ?", customerIDs).
+		Order("is_default DESC, id ASC").Find(&addrs).Error; err != nil {
+		return nil, err
 	}
-	if err := config.DB.Where("customer_id = ?", cust.ID).First(&addr).Error; err == nil {
-		return addr.ID, addr.AddressDetails
+	for _, a := range addrs {
+		if _, ok := out[a.CustomerID]; !ok {
+			out[a.CustomerID] = a
+		}
 	}
-	return 0, ""
+	return out, nil
 }
 
 func getOrCreateClothTypeByName(name string) (*entity.ClothType, error) {
@@ -118,23 +127,22 @@ func UpsertLaundryCheck(c *gin.Context) {
 	oidStr := c.Param("orderId")
 	oid, _ := strconv.ParseUint(oidStr, 10, 64)
 	if oid == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": "orderId ไม่ถูกต้อง"})
+		api.Fail(c, api.BadRequest("", "orderId ไม่ถูกต้อง"))
 		return
 	}
 
 	var input UpsertLaundryCheckInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+	if !api.BindJSON(c, &input) {
 		return
 	}
 	if len(input.Items) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": "ต้องมีรายการผ้าอย่างน้อย 1 รายการ"})
+		api.Fail(c, api.BadRequest("", "ต้องมีรายการผ้าอย่างน้อย 1 รายการ"))
 		return
 	}
 
 	var order entity.Order
 	if err := config.DB.Preload("Customer").First(&order, oid).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"Error": "ไม่พบ Order"})
+		api.Fail(c, api.NotFound("", "ไม่พบ Order"))
 		return
 	}
 
@@ -146,7 +154,7 @@ func UpsertLaundryCheck(c *gin.Context) {
 			OrderID:     order.ID,
 		}
 		   if err := config.DB.Create(&srec).Error; err != nil {
-			   c.JSON(http.StatusInternalServerError, gin.H{"Error": "บันทึก SortingRecord ไม่สำเร็จ"})
+			   api.Fail(c, api.InternalMsg("บันทึก SortingRecord ไม่สำเร็จ", err))
 			   return
 		   }
 		   // เชื่อม LaundryProcess ล่าสุดของ Order นี้กับ SortingRecord ที่เพิ่งสร้าง
@@ -170,12 +178,12 @@ func UpsertLaundryCheck(c *gin.Context) {
 	for _, it := range input.Items {
 		ct, err := getOrCreateClothTypeByName(it.ClothTypeName)
 		if err != nil || ct == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": "ประเภทผ้าไม่ถูกต้อง"})
+			api.Fail(c, api.BadRequest("", "ประเภทผ้าไม่ถูกต้อง"))
 			return
 		}
 		var st entity.ServiceType
 		if err := config.DB.First(&st, it.ServiceTypeID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"Error": "ไม่พบ ServiceType"})
+			api.Fail(c, api.BadRequest("", "ไม่พบ ServiceType"))
 			return
 		}
 
@@ -186,7 +194,7 @@ func UpsertLaundryCheck(c *gin.Context) {
 			SortingRecordID: srec.ID,
 		}
 		if err := config.DB.Create(&row).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"Error": "บันทึก SortedClothes ไม่สำเร็จ"})
+			api.Fail(c, api.InternalMsg("บันทึก SortedClothes ไม่สำเร็จ", err))
 			return
 		}
 
@@ -225,34 +233,38 @@ func UpsertLaundryCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, ok{OrderID: order.ID})
 }
 
-// GET /laundry-check/orders
+// GET /laundry-check/orders?q=&customerId=&from=&to=&sort=
+// ออเดอร์ที่ยังไม่ได้คัดแยก (ยังไม่มีผ้าที่บันทึกจำนวนไว้) q ค้นชื่อ/เบอร์ลูกค้าและหมายเหตุ
+var laundryOrderList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"customerId": {Column: "orders.customer_id", Op: api.OpID},
+		"from":       {Column: "orders.created_at", Op: api.OpFrom},
+		"to":         {Column: "orders.created_at", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":        "orders.id",
+		"createdAt": "orders.created_at",
+	},
+	Search: []string{"customers.first_name", "customers.last_name", "customers.phone_number", "orders.order_note"},
+}
+
 func ListLaundryOrders(c *gin.Context) {
+	// เดิมนับผ้าทีละออเดอร์ (2 COUNT ต่อออเดอร์) ตอนนี้กรองด้วย NOT EXISTS ในคำสั่งเดียว
+	sorted := config.DB.Table("sorting_records AS sr").Select("1").
+		Joins("JOIN sorted_clothes AS sc ON sc.sorting_record_id = sr.id AND sc.deleted_at IS NULL").
+		Where("sr.order_id = orders.id AND sr.deleted_at IS NULL AND sc.sorted_quantity > 0")
+
 	var orders []entity.Order
-	if err := config.DB.Preload("Customer").Preload("ServiceTypes").Find(&orders).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "ดึงออเดอร์ไม่สำเร็จ"})
+	meta, ok := laundryOrderList.Find(c, config.DB.Model(&entity.Order{}).
+		Joins("LEFT JOIN customers ON customers.id = orders.customer_id").
+		Where("NOT EXISTS (?)", sorted).
+		Preload("Customer").Preload("ServiceTypes"), &orders)
+	if !ok {
 		return
 	}
 
 	results := make([]OrderSummary, 0, len(orders))
 	for _, o := range orders {
-		var srec entity.SortingRecord
-		_ = config.DB.Where("order_id = ?", o.ID).First(&srec).Error
-
-		var itemCount int64
-		var qtySum int64
-		if srec.ID != 0 {
-			config.DB.Model(&entity.SortedClothes{}).
-				Where("sorting_record_id = ? AND sorted_quantity > 0", srec.ID).
-				Count(&itemCount)
-			config.DB.Model(&entity.SortedClothes{}).
-				Where("sorting_record_id = ? AND sorted_quantity > 0", srec.ID).
-				Select("COALESCE(SUM(sorted_quantity),0)").Scan(&qtySum)
-		}
-
-		if itemCount > 0 || qtySum > 0 {
-			continue
-		}
-
 		name, phone := "", ""
 		if o.Customer != nil {
 			name = o.Customer.FirstName + " " + o.Customer.LastName
@@ -266,20 +278,19 @@ func ListLaundryOrders(c *gin.Context) {
 			}
 		}
 
+		// ยังไม่คัดแยก: TotalItems/TotalQuantity เป็น 0 เสมอ
 		results = append(results, OrderSummary{
-			ID:            o.ID,
-			CreatedAt:     o.CreatedAt,
-			CustomerName:  name,
-			Phone:         phone,
-			OrderNote:     o.OrderNote,
-			TotalItems:    int(itemCount),
-			TotalQuantity: int(qtySum),
-			ServiceTypes:  stOut,
+			ID:           o.ID,
+			CreatedAt:    o.CreatedAt,
+			CustomerName: name,
+			Phone:        phone,
+			OrderNote:    o.OrderNote,
+			ServiceTypes: stOut,
 		})
 	}
 
 	c.Header("Cache-Control", "no-store")
-	c.JSON(http.StatusOK, results)
+	api.List(c, results, meta)
 }
 
 // GET /laundry-check/orders/:id
@@ -288,7 +299,7 @@ func GetLaundryOrderDetail(c *gin.Context) {
 
 	var order entity.Order
 	if err := config.DB.Preload("Customer").Preload("Address").Preload("ServiceTypes").First(&order, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"Error": "ไม่พบ Order"})
+		api.Fail(c, api.NotFound("", "ไม่พบ Order"))
 		return
 	}
 
@@ -374,19 +385,31 @@ func GetLaundryOrderDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GET /laundry-check/orders/:id/history?action=ADD,EDIT&sort=-recordedAt
+var sortingHistoryList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"action": {Column: "h.action", Op: api.OpEq},
+	},
+	Sorts: map[string]string{
+		"id":         "h.id",
+		"recordedAt": "h.recorded_at",
+	},
+	DefaultSort: "recordedAt,id",
+}
+
 // GET /laundry-check/orders/:id/history
 func GetOrderHistory(c *gin.Context) {
 	id := c.Param("id")
 
 	var order entity.Order
 	if err := config.DB.First(&order, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"Error": "ไม่พบ Order"})
+		api.Fail(c, api.NotFound("", "ไม่พบ Order"))
 		return
 	}
 
 	entries := []HistoryEntry{}
 	// ใช้ absolute ที่เก็บใน history เลย ไม่ต้องคำนวณ running sum
-	config.DB.
+	query := config.DB.
 		Table("sorting_histories AS h").
 		Select(`
 			h.id AS id,
@@ -404,12 +427,20 @@ func GetOrderHistory(c *gin.Context) {
 		Joins("LEFT JOIN sorting_records AS sr ON sr.id = sc.sorting_record_id").
 		Joins("LEFT JOIN cloth_types AS ct ON ct.id = h.cloth_type_id").
 		Joins("LEFT JOIN service_types AS st ON st.id = h.service_type_id").
-		Where("sr.order_id = ?", order.ID).
-		Order("h.recorded_at ASC, h.id ASC").
-		Scan(&entries)
+		Where("sr.order_id = ?", order.ID)
+	q, page, perr := sortingHistoryList.Apply(c, query, &entries)
+	if perr != nil {
+		api.Fail(c, perr)
+		return
+	}
+	meta, err := api.Paginate(q, page, &entries)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("ดึงประวัติการคัดแยกไม่สำเร็จ", err))
+		return
+	}
 
 	c.Header("Cache-Control", "no-store")
-	c.JSON(http.StatusOK, entries)
+	api.List(c, entries, meta)
 }
 
 // PUT /laundry-checks/:orderId/items/:itemId
@@ -417,25 +448,24 @@ func UpdateSortedClothes(c *gin.Context) {
 	orderID, _ := strconv.ParseUint(c.Param("orderId"), 10, 64)
 	itemID, _ := strconv.ParseUint(c.Param("itemId"), 10, 64)
 	if orderID == 0 || itemID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": "พารามิเตอร์ไม่ถูกต้อง"})
+		api.Fail(c, api.BadRequest("", "พารามิเตอร์ไม่ถูกต้อง"))
 		return
 	}
 
 	var row entity.SortedClothes
 	if err := config.DB.First(&row, itemID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"Error": "ไม่พบรายการผ้า"})
+		api.Fail(c, api.NotFound("", "ไม่พบรายการผ้า"))
 		return
 	}
 
 	var srec entity.SortingRecord
 	if err := config.DB.First(&srec, row.SortingRecordID).Error; err != nil || uint(orderID) != srec.OrderID {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": "รายการไม่อยู่ในออเดอร์นี้"})
+		api.Fail(c, api.BadRequest("", "รายการไม่อยู่ในออเดอร์นี้"))
 		return
 	}
 
 	var in UpdateItemInput
-	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+	if !api.BindJSON(c, &in) {
 		return
 	}
 
@@ -448,7 +478,7 @@ func UpdateSortedClothes(c *gin.Context) {
 
 	if err := tx.First(&row, itemID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusNotFound, gin.H{"Error": "ไม่พบรายการผ้า"})
+		api.Fail(c, api.NotFound("", "ไม่พบรายการผ้า"))
 		return
 	}
 
@@ -459,7 +489,7 @@ func UpdateSortedClothes(c *gin.Context) {
 		ct, err := getOrCreateClothTypeByName(*in.ClothTypeName)
 		if err != nil || ct == nil {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"Error": "ประเภทผ้าไม่ถูกต้อง"})
+			api.Fail(c, api.BadRequest("", "ประเภทผ้าไม่ถูกต้อง"))
 			return
 		}
 		if row.ClothTypeID != ct.ID {
@@ -471,7 +501,7 @@ func UpdateSortedClothes(c *gin.Context) {
 		var st entity.ServiceType
 		if err := tx.First(&st, *in.ServiceTypeID).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"Error": "ไม่พบ ServiceType"})
+			api.Fail(c, api.BadRequest("", "ไม่พบ ServiceType"))
 			return
 		}
 		if row.ServiceTypeID != *in.ServiceTypeID {
@@ -489,7 +519,7 @@ func UpdateSortedClothes(c *gin.Context) {
 	if in.Quantity != nil {
 		if *in.Quantity < 0 {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"Error": "จำนวนต้องไม่เป็นค่าติดลบ"})
+			api.Fail(c, api.BadRequest("", "จำนวนต้องไม่เป็นค่าติดลบ"))
 			return
 		}
 		if row.SortedQuantity != *in.Quantity {
@@ -512,7 +542,7 @@ func UpdateSortedClothes(c *gin.Context) {
 		}
 		if err := tx.Create(&h).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"Error": "บันทึกประวัติไม่สำเร็จ"})
+			api.Fail(c, api.InternalMsg("บันทึกประวัติไม่สำเร็จ", err))
 			return
 		}
 	} else {
@@ -524,11 +554,11 @@ func UpdateSortedClothes(c *gin.Context) {
 
 	if err := tx.Save(&row).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "อัปเดตรายการไม่สำเร็จ"})
+		api.Fail(c, api.InternalMsg("อัปเดตรายการไม่สำเร็จ", err))
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "อัปเดตรายการไม่สำเร็จ"})
+		api.Fail(c, api.InternalMsg("อัปเดตรายการไม่สำเร็จ", err))
 		return
 	}
 
@@ -555,18 +585,18 @@ func DeleteSortedClothes(c *gin.Context) {
 	orderID, _ := strconv.ParseUint(c.Param("orderId"), 10, 64)
 	itemID, _ := strconv.ParseUint(c.Param("itemId"), 10, 64)
 	if orderID == 0 || itemID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": "พารามิเตอร์ไม่ถูกต้อง"})
+		api.Fail(c, api.BadRequest("", "พารามิเตอร์ไม่ถูกต้อง"))
 		return
 	}
 
 	var row entity.SortedClothes
 	if err := config.DB.First(&row, itemID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"Error": "ไม่พบรายการผ้า"})
+		api.Fail(c, api.NotFound("", "ไม่พบรายการผ้า"))
 		return
 	}
 	var srec entity.SortingRecord
 	if err := config.DB.First(&srec, row.SortingRecordID).Error; err != nil || uint(orderID) != srec.OrderID {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": "รายการไม่อยู่ในออเดอร์นี้"})
+		api.Fail(c, api.BadRequest("", "รายการไม่อยู่ในออเดอร์นี้"))
 		return
 	}
 
@@ -585,7 +615,7 @@ func DeleteSortedClothes(c *gin.Context) {
 
 	row.SortedQuantity = 0
 	if err := config.DB.Save(&row).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "ลบรายการไม่สำเร็จ"})
+		api.Fail(c, api.InternalMsg("ลบรายการไม่สำเร็จ", err))
 		return
 	}
 
@@ -594,10 +624,20 @@ func DeleteSortedClothes(c *gin.Context) {
 }
 
 // ---------- Lookups ----------
+var clothTypeList = api.ListSpec{
+	Sorts:  map[string]string{"id": "id", "name": "type_name"},
+	Search: []string{"type_name"},
+}
+
+var serviceTypeList = api.ListSpec{
+	Sorts:  map[string]string{"id": "id", "name": "type", "price": "price", "capacity": "capacity"},
+	Search: []string{"type"},
+}
+
 func ListClothTypes(c *gin.Context) {
 	var list []entity.ClothType
-	if err := config.DB.Find(&list).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "ดึง ClothType ไม่สำเร็จ"})
+	meta, ok := clothTypeList.Find(c, config.DB.Model(&entity.ClothType{}), &list)
+	if !ok {
 		return
 	}
 	type V struct {
@@ -609,13 +649,13 @@ func ListClothTypes(c *gin.Context) {
 		out = append(out, V{ID: x.ID, Name: x.TypeName})
 	}
 	c.Header("Cache-Control", "no-store")
-	c.JSON(http.StatusOK, out)
+	api.List(c, out, meta)
 }
 
 func ListServiceTypes(c *gin.Context) {
 	var list []entity.ServiceType
-	if err := config.DB.Find(&list).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "ดึง ServiceType ไม่สำเร็จ"})
+	meta, ok := serviceTypeList.Find(c, config.DB.Model(&entity.ServiceType{}), &list)
+	if !ok {
 		return
 	}
 	type V struct {
@@ -627,13 +667,32 @@ func ListServiceTypes(c *gin.Context) {
 		out = append(out, V{ID: x.ID, Name: x.Type})
 	}
 	c.Header("Cache-Control", "no-store")
-	c.JSON(http.StatusOK, out)
+	api.List(c, out, meta)
+}
+
+// GET /laundry-check/customers?q=&sort=firstName (q ค้นชื่อ/เบอร์)
+var laundryCustomerList = api.ListSpec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"firstName": "first_name",
+		"lastName":  "last_name",
+	},
+	Search: []string{"first_name", "last_name", "phone_number"},
 }
 
 func GetLaundryCustomers(c *gin.Context) {
 	var custs []entity.Customer
-	if err := config.DB.Find(&custs).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "ดึงลูกค้าไม่สำเร็จ"})
+	meta, ok := laundryCustomerList.Find(c, config.DB.Model(&entity.Customer{}), &custs)
+	if !ok {
+		return
+	}
+	ids := make([]uint, 0, len(custs))
+	for i := range custs {
+		ids = append(ids, custs[i].ID)
+	}
+	addrs, err := defaultAddresses(ids)
+	if err != nil {
+		api.Fail(c, api.InternalMsg("ดึงที่อยู่ลูกค้าไม่สำเร็จ", err))
 		return
 	}
 	type V struct {
@@ -646,16 +705,16 @@ func GetLaundryCustomers(c *gin.Context) {
 	}
 	out := make([]V, 0, len(custs))
 	for i := range custs {
-		aID, aText := findDefaultAddressText(&custs[i])
+		addr := addrs[custs[i].ID]
 		out = append(out, V{
 			ID:        custs[i].ID,
 			Name:      custs[i].FirstName + " " + custs[i].LastName,
 			Phone:     custs[i].PhoneNumber,
-			AddressID: aID,
-			Address:   aText,
+			AddressID: addr.ID,
+			Address:   addr.AddressDetails,
 			Note:      "",
 		})
 	}
 	c.Header("Cache-Control", "no-store")
-	c.JSON(http.StatusOK, out)
+	api.List(c, out, meta)
 }
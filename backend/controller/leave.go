@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// คำขอลา
+// พนักงาน (token):
+// - POST /employee/me/leaves
+// - GET  /employee/me/leaves
+// - POST /employee/me/leaves/:id/cancel
+// หัวหน้า/admin:
+// - GET   /leave-requests?status=&employeeId=&type=&from=&to=&q=&sort=
+// - PATCH /leave-requests/:id   {action: approve|reject, note} (admin หรือหัวหน้างาน)
+// อนุมัติแล้ว -> EmployeeStatus = onleave ตลอดช่วงลา แล้วคืนสถานะเดิม (services.SyncLeaveStatuses)
+// ======================================================
+
+type leaveIn struct {
+	LeaveType string `json:"leaveType" binding:"required"` // sick | personal | vacation
+	StartDate string `json:"startDate" binding:"required"` // YYYY-MM-DD
+	EndDate   string `json:"endDate" binding:"required"`   // YYYY-MM-DD
+	Reason    string `json:"reason"`
+}
+
+func isLeaveType(t string) bool {
+	for _, v := range services.LeaveTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func CreateMyLeave(c *gin.Context) {
+	emp, ok := requireEmployee(c)
+	if !ok {
+		return
+	}
+	var in leaveIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	in.LeaveType = strings.ToLower(strings.TrimSpace(in.LeaveType))
+	if !isLeaveType(in.LeaveType) {
+		api.Fail(c, api.BadRequest("", "leaveType ต้องเป็น sick|personal|vacation"))
+		return
+	}
+	start, err1 := time.Parse("2006-01-02", in.StartDate)
+	end, err2 := time.Parse("2006-01-02", in.EndDate)
+	if err1 != nil || err2 != nil {
+		api.Fail(c, api.BadRequest("", "วันที่ต้องอยู่ในรูปแบบ YYYY-MM-DD"))
+		return
+	}
+	if end.Before(start) {
+		api.Fail(c, api.BadRequest("", "วันสิ้นสุดต้องไม่ก่อนวันเริ่มลา"))
+		return
+	}
+
+	var overlap int64
+	config.DB.Model(&entity.LeaveRequest{}).
+		Where("employee_id = ? AND status IN ? AND start_date <= ? AND end_date >= ?",
+			emp.ID, []string{services.LeavePending, services.LeaveApproved}, in.EndDate, in.StartDate).
+		Count(&overlap)
+	if overlap > 0 {
+		api.Fail(c, api.Conflict("", "มีคำขอลาในช่วงวันที่นี้อยู่แล้ว"))
+		return
+	}
+
+	lr := entity.LeaveRequest{
+		EmployeeID: emp.ID,
+		LeaveType:  in.LeaveType,
+		StartDate:  in.StartDate,
+		EndDate:    in.EndDate,
+		Reason:     strings.TrimSpace(in.Reason),
+		Status:     services.LeavePending,
+	}
+	if err := config.DB.Create(&lr).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, lr)
+}
+
+// รายการคำขอลา: from/to = ช่วงลาที่ทับกับช่วงนี้, q ค้นเหตุผล (หัวหน้าค้นชื่อ/รหัสพนักงานได้ด้วย)
+var myLeaveList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"status": {Column: "leave_requests.status", Op: api.OpEq},
+		"type":   {Column: "leave_requests.leave_type", Op: api.OpEq},
+		"from":   {Column: "leave_requests.end_date", Op: api.OpDayFrom},
+		"to":     {Column: "leave_requests.start_date", Op: api.OpDayTo},
+	},
+	Sorts: map[string]string{
+		"id":        "leave_requests.id",
+		"startDate": "leave_requests.start_date",
+		"createdAt": "leave_requests.created_at",
+	},
+	DefaultSort: "-startDate",
+	Search:      []string{"leave_requests.reason"},
+}
+
+var leaveRequestList = func() api.ListSpec {
+	s := myLeaveList
+	s.Filters = map[string]api.Filter{"employeeId": {Column: "leave_requests.employee_id", Op: api.OpID}}
+	for k, f := range myLeaveList.Filters {
+		s.Filters[k] = f
+	}
+	s.Search = append([]string{"employees.code", "employees.first_name", "employees.last_name"}, myLeaveList.Search...)
+	return s
+}()
+
+func ListMyLeaves(c *gin.Context) {
+	emp, ok := requireEmployee(c)
+	if !ok {
+		return
+	}
+	var items []entity.LeaveRequest
+	meta, ok := myLeaveList.Find(c, config.DB.Model(&entity.LeaveRequest{}).
+		Where("leave_requests.employee_id = ?", emp.ID), &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta)
+}
+
+func CancelMyLeave(c *gin.Context) {
+	emp, ok := requireEmployee(c)
+	if !ok {
+		return
+	}
+	var lr entity.LeaveRequest
+	if err := config.DB.Where("id = ? AND employee_id = ?", c.Param("id"), emp.ID).First(&lr).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบคำขอลา"))
+		return
+	}
+	today := businessDate(time.Now())
+	if (lr.Status != services.LeavePending && lr.Status != services.LeaveApproved) || lr.EndDate < today {
+		api.Fail(c, api.Conflict("", "ยกเลิกคำขอลานี้ไม่ได้"))
+		return
+	}
+	if err := config.DB.Model(&lr).Update("status", services.LeaveCancelled).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	// ยกเลิกระหว่างลา -> คืนสถานะทันที
+	if err := services.SyncLeaveStatuses(config.DB, time.Now()); err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	config.DB.First(&lr, lr.ID)
+	c.JSON(http.StatusOK, lr)
+}
+
+func ListLeaveRequests(c *gin.Context) {
+	var items []entity.LeaveRequest
+	meta, ok := leaveRequestList.Find(c, config.DB.Model(&entity.LeaveRequest{}).
+		Joins("LEFT JOIN employees ON employees.id = leave_requests.employee_id").
+		Preload("Employee").Preload("DecidedBy"), &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta)
+}
+
+type leaveDecisionIn struct {
+	Action string `json:"action" binding:"required"` // approve | reject
+	Note   string `json:"note"`
+}
+
+func DecideLeaveRequest(c *gin.Context) {
+	var in leaveDecisionIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	status := map[string]string{"approve": services.LeaveApproved, "reject": services.LeaveRejected}[strings.ToLower(strings.TrimSpace(in.Action))]
+	if status == "" {
+		api.Fail(c, api.BadRequest("", "action ต้องเป็น approve|reject"))
+		return
+	}
+
+	var lr entity.LeaveRequest
+	if err := config.DB.First(&lr, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบคำขอลา"))
+		return
+	}
+	if lr.Status != services.LeavePending {
+		api.Fail(c, api.Conflict("", "คำขอลานี้ถูกพิจารณาแล้ว"))
+		return
+	}
+
+	// ผู้พิจารณา: admin (ไม่ต้องมีข้อมูลพนักงาน) หรือหัวหน้างาน และต้องไม่ใช่คำขอของตนเอง
+	actorID := currentEmployeeID(c)
+	if currentRole(c) != services.RoleAdmin {
+		ok := false
+		if actorID != nil {
+			var err error
+			if ok, err = services.IsSupervisor(config.DB, *actorID); err != nil {
+				api.Fail(c, api.Internal(err))
+				return
+			}
+		}
+		if !ok {
+			api.Fail(c, api.NewError(http.StatusForbidden, "supervisor_only"))
+			return
+		}
+	}
+	if actorID != nil && *actorID == lr.EmployeeID {
+		api.Fail(c, api.Forbidden("", "ไม่สามารถอนุมัติคำขอลาของตนเองได้"))
+		return
+	}
+
+	// admin ที่ไม่มีข้อมูลพนักงาน: decided_by_id ว่าง ผู้พิจารณาดูได้จาก audit log
+	now := time.Now()
+	if err := auditDB(c).Model(&lr).Updates(map[string]interface{}{
+		"status":        status,
+		"decided_by_id": actorID,
+		"decided_at":    now,
+		"decision_note": strings.TrimSpace(in.Note),
+	}).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	// ลาวันนี้ -> เปลี่ยนสถานะทันที ไม่ต้องรอรอบตรวจ
+	if err := services.SyncLeaveStatuses(config.DB, now); err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	config.DB.Preload("Employee").Preload("DecidedBy").First(&lr, lr.ID)
+	c.JSON(http.StatusOK, lr)
+}
@@ -1,11 +1,14 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
-	"time"
+	"strings"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity" // ดูmodule at go.mod
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,132 +24,96 @@ func CreateOrder(c *gin.Context) {
 	}
 
 	// Bind JSON จาก request body
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &req) {
 		return
 	}
 
-	// สร้าง order object
-	order := entity.Order{
-		CustomerID: req.CustomerID,
-		OrderImage: req.OrderImage,
-		OrderNote:  req.OrderNote,
-		AddressID:  req.AddressID,
-	}
-
-	// บันทึก order
-	if err := config.DB.Create(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// map servicetypes
-	if len(req.ServiceTypeIDs) > 0 {
-		var servicetypes []entity.ServiceType
-		if err := config.DB.Find(&servicetypes, req.ServiceTypeIDs).Error; err == nil {
-			config.DB.Model(&order).Association("ServiceTypes").Append(servicetypes)
-		}
-	}
-
-	// ความสัมพันธ์ detergents
-	if len(req.DetergentIDs) > 0 {
-		var detergents []entity.Detergent
-		if err := config.DB.Find(&detergents, req.DetergentIDs).Error; err == nil {
-			config.DB.Model(&order).Association("Detergents").Append(detergents)
-		}
-	}
-
-	// history เริ่มต้น
-	history := entity.OrderHistory{
-		OrderID: order.ID,
-	}
-	// ส่ง response กลับ frontend
-	if err := config.DB.Create(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// preload ก่อนส่งกลับ
-	if err := config.DB.Preload("Customer").
-		Preload("ServiceTypes").
-		Preload("Detergents").
-		Preload("Address").
-		First(&order, order.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// หลังบันทึก Detergents ให้ลด stock ถ้าเลือกน้ำยาทางร้าน
-	if len(req.DetergentIDs) > 0 {
-		if err := DecreaseDetergentStock(req.DetergentIDs, req.CustomerID); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ลดจำนวนสต็อกน้ำยา/บันทึกการใช้งานไม่สำเร็จ"})
+	// บันทึก order + ตัดสต็อกน้ำยา + LaundryProcess + pickup queue (ดู services.OrderService)
+	order, err := services.NewOrderService(auditDB(c)).Create(services.NewOrder{
+		CustomerID:     req.CustomerID,
+		AddressID:      req.AddressID,
+		ServiceTypeIDs: req.ServiceTypeIDs,
+		DetergentIDs:   req.DetergentIDs,
+		OrderImage:     req.OrderImage,
+		OrderNote:      req.OrderNote,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrDetergentNotFound) {
+			api.Fail(c, api.BadRequest("", "ไม่พบน้ำยาที่เลือก"))
 			return
 		}
-	}
-	// --- PATCH: สร้าง LaundryProcess อัตโนมัติ ---
-	process := entity.LaundryProcess{
-		Status:     "รอดำเนินการ",
-		Start_time: time.Now(),
-		Order:      []*entity.Order{&order},
-	}
-	if err := config.DB.Create(&process).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้าง LaundryProcess ไม่สำเร็จ: " + err.Error()})
+		api.Fail(c, api.Internal(err))
 		return
 	}
-	// สร้าง pickup queue ทันทีหลังสร้าง order
-    pickupQueue := entity.Queue{
-	    Queue_type: "pickup",
-	    Status:     "waiting",
-	    OrderID:    order.ID,
-    }
-    config.DB.Create(&pickupQueue)
-
-
 	c.JSON(http.StatusOK, order)
 }
 
+// GET /order-histories?q=&customerId=&orderId=&from=&to=&sort=-createdAt
+// q ค้นชื่อ/เบอร์ลูกค้าและหมายเหตุออเดอร์
+var orderHistoryList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"customerId": {Column: "orders.customer_id", Op: api.OpID},
+		"orderId":    {Column: "order_histories.order_id", Op: api.OpID},
+		"from":       {Column: "order_histories.created_at", Op: api.OpFrom},
+		"to":         {Column: "order_histories.created_at", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":        "order_histories.id",
+		"orderId":   "order_histories.order_id",
+		"createdAt": "order_histories.created_at",
+	},
+	Search: []string{"customers.first_name", "customers.last_name", "customers.phone_number", "orders.order_note"},
+}
+
 // ดึงประวัติการสั่งซื้อทั้งหมด
 func GetOrderHistories(c *gin.Context) {
 	var histories []entity.OrderHistory
-	if err := config.DB.Preload("Order").
+	meta, ok := orderHistoryList.Find(c, config.DB.Model(&entity.OrderHistory{}).
+		Joins("LEFT JOIN orders ON orders.id = order_histories.order_id").
+		Joins("LEFT JOIN customers ON customers.id = orders.customer_id").
+		Preload("Order").
 		Preload("Order.Customer").
 		Preload("Order.ServiceTypes").
 		Preload("Order.Detergents").
 		Preload("Order.Address").
 		Preload("Order.Payment").
-		Preload("Order.LaundryProcesses"). // <-- แก้จาก .status เป็น preload ธรรมดา
-		Find(&histories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		Preload("Order.LaundryProcesses"), &histories)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, histories)
+	api.List(c, histories, meta)
 }
 // ดึงออเดอร์ทั้งหมด
 func GetOrders(c *gin.Context) {
 	var orders []entity.Order
 	if err := config.DB.Preload("Customer").Find(&orders).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		api.Fail(c, api.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, orders)
 }
 
+// GET /addresses?customer_id=&default=true&q=
+var addressList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"customer_id": {Column: "customer_id", Op: api.OpID},
+		"default":     {Column: "is_default", Op: api.OpBool},
+	},
+	Sorts: map[string]string{
+		"id":        "id",
+		"createdAt": "created_at",
+	},
+	Search: []string{"address_details"},
+}
+
 // ดึงที่อยู่ทั้งหมดของลูกค้าที่ใช้งาน
 func GetAddresses(c *gin.Context) {
-	customerID := c.Query("customer_id")
 	var addresses []entity.Address
-	if customerID != "" {
-		if err := config.DB.Where("customer_id = ?", customerID).Preload("Customer").Find(&addresses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-	} else {
-		if err := config.DB.Preload("Customer").Find(&addresses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	meta, ok := addressList.Find(c, config.DB.Model(&entity.Address{}).Preload("Customer"), &addresses)
+	if !ok {
+		return
 	}
-	c.JSON(http.StatusOK, addresses)
+	api.List(c, addresses, meta)
 }
 
 // ดึงชื่อ-นามสกุลลูกค้าจาก ID
@@ -154,7 +121,7 @@ func GetCustomerNameByID(c *gin.Context) {
 	id := c.Param("id")
 	var customer entity.Customer
 	if err := config.DB.Preload("User").First(&customer, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบลูกค้า"))
 		return
 	}
 	firstName := customer.FirstName
@@ -174,18 +141,17 @@ func CreateNewAddress(c *gin.Context) {
 		Longitude      float64 `json:"longitude"`
 		CustomerID     uint    `json:"customerId"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &req) {
 		return
 	}
-	address := entity.Address{
+	address, err := services.NewAddressService(config.DB).Create(services.NewAddress{
+		CustomerID:     req.CustomerID,
 		AddressDetails: req.AddressDetails,
 		Latitude:       req.Latitude,
 		Longitude:      req.Longitude,
-		CustomerID:     req.CustomerID,
-	}
-	if err := config.DB.Create(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	})
+	if err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, address)
@@ -197,64 +163,29 @@ func UpdateMainAddress(c *gin.Context) {
 		CustomerID uint `json:"customer_id"`
 		AddressID  uint `json:"address_id"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// อัพเดตที่อยู่ทั้งหมดของลูกค้าให้ไม่ใช่ที่อยู่หลัก
-	if err := config.DB.Model(&entity.Address{}).
-		Where("customer_id = ?", req.CustomerID).
-		Update("is_default", false).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &req) {
 		return
 	}
 
-	// อัพเดตที่อยู่ที่เลือกให้เป็นที่อยู่หลัก
-	if err := config.DB.Model(&entity.Address{}).
-		Where("id = ? AND customer_id = ?", req.AddressID, req.CustomerID).
-		Update("is_default", true).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := services.NewAddressService(config.DB).SetDefault(req.CustomerID, req.AddressID); err != nil {
+		if errors.Is(err, services.ErrAddressNotFound) {
+			api.Fail(c, api.NotFound("", "ไม่พบที่อยู่"))
+			return
+		}
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "อัพเดตที่อยู่หลักสำเร็จ"})
 }
 
-// ลดจำนวน InStock ของน้ำยาทางร้านเมื่อมีการสร้างออเดอร์
-func DecreaseDetergentStock(detergentIDs []uint, customerID uint) error {
-	for _, id := range detergentIDs {
-		var detergent entity.Detergent
-		if err := config.DB.First(&detergent, id).Error; err != nil {
-			return err
-		}
-		if detergent.InStock > 0 {
-			detergent.InStock -= 1
-			if err := config.DB.Save(&detergent).Error; err != nil {
-				return err
-			}
-			// เพิ่มบันทึกการใช้งาน
-			history := entity.DetergentUsageHistory{
-				UserID: customerID,
-				DetergentID: detergent.ID,
-				QuantityUsed: 1,
-				Reason: "ใช้จากออเดอร์ลูกค้า",
-			}
-			if err := config.DB.Create(&history).Error; err != nil {
-				return err
-			}
-		}
-	}
-	return nil
-}
-
 // GetDetergentsByType handles GET /detergents/type/:type for fetching detergents by type (e.g. Liquid, Softener)
 func GetDetergentsByType(c *gin.Context) {
-	detType := c.Param("type")
+	detType := strings.ToLower(strings.TrimSpace(c.Param("type")))
 	var detergents []entity.Detergent
-	if err := config.DB.Where("LOWER(type) = ?", detType).Find(&detergents).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := detergentList.Find(c, config.DB.Model(&entity.Detergent{}).Where("LOWER(type) = ?", detType), &detergents)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": detergents})
+	api.List(c, detergents, meta)
 }
\ No newline at end of file
@@ -8,6 +8,7 @@ import (
 	"time"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
 )
@@ -25,17 +26,17 @@ func GetLatestOrderForCustomer(c *gin.Context) {
     customerIdStr := c.Param("customer_id")	
 	customerID, err := strconv.ParseUint(customerIdStr,10,32)
 	if err != nil{
-		c.JSON(http.StatusBadRequest,gin.H{"error": "Invalid CustomerId"})
+		api.Fail(c, api.BadRequest("", "customerId ไม่ถูกต้อง"))
 		return
 	}
 	// ดึงออเดอร์ล่าสุด
 	var orders entity.Order
 	if err := config.DB.Where("customer_id = ?", customerID).Order("created_at DESC").First(&orders).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no_orders"})
+			api.Fail(c, api.NewError(http.StatusNotFound, "no_orders"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db_error"})
+		api.Fail(c, api.NewError(http.StatusInternalServerError, "db_error"))
 		return
 	}
 	
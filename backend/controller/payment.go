@@ -7,14 +7,15 @@ import (
 	"fmt"
 	"math"
 	"net/http"
-	"os"
 	
 	// "strconv"
 	"strings"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )	
@@ -23,7 +24,6 @@ import (
 // ========================= EasySlip verify (ของเดิม) =========================
 
 const easySlipURL = "https://developer.easyslip.com/api/v1/verify"
-var ErrDuplicateSlip = errors.New("duplicate_slip")
 
 type verifySlipIn struct {
 	Base64 string  `json:"base64" binding:"required"`
@@ -75,26 +75,43 @@ func stripDataURLPrefix(s string) string {
 }
 
 func VerifySlipBase64(c *gin.Context) {
+	// metrics: ตั้ง failReason ก่อนตอบ error ทุกกรณี (ว่าง = ไม่นับ เช่น ปิดฟีเจอร์)
+	var failReason string
+	defer func() {
+		if failReason != "" {
+			services.RecordSlipVerification(services.SlipFailed, failReason)
+		}
+	}()
+
 	var in verifySlipIn
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		failReason = "invalid_request"
+		api.Fail(c, api.BindError(err))
+		return
+	}
+	settings := config.Current()
+	if !settings.Features.SlipVerification {
+		api.Fail(c, api.NewError(http.StatusNotFound, "slip_verification_disabled"))
 		return
 	}
-	token := strings.TrimSpace(os.Getenv("EASYSLIP_TOKEN"))
+	token := settings.Payment.EasySlipToken
 	if token == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_not_configured"})
+		failReason = "server_not_configured"
+		api.Fail(c, api.NewError(http.StatusInternalServerError, "server_not_configured"))
 		return
 	}
 
 	// 1) Normalize base64
 	raw := stripDataURLPrefix(strings.TrimSpace(in.Base64))
 	if len(raw) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "empty_image"})
+		failReason = "empty_image"
+		api.Fail(c, api.NewError(http.StatusBadRequest, "empty_image"))
 		return
 	}
 	// 2) Size guard
-	if len(raw) > 2*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "image_too_large"})
+	if len(raw) > settings.Upload.MaxSlipBytes() {
+		failReason = "image_too_large"
+		api.Fail(c, api.NewError(http.StatusBadRequest, "image_too_large"))
 		return
 	}
 
@@ -106,7 +123,8 @@ func VerifySlipBase64(c *gin.Context) {
 
 	resp, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "easyslip_unreachable"})
+		failReason = "easyslip_unreachable"
+		api.Fail(c, api.NewError(http.StatusBadRequest, "easyslip_unreachable"))
 		return
 	}
 	defer resp.Body.Close()
@@ -114,101 +132,75 @@ func VerifySlipBase64(c *gin.Context) {
 
 	var out esVerifyResp
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "easyslip_bad_response"})
+		failReason = "easyslip_bad_response"
+		api.Fail(c, api.NewError(http.StatusBadRequest, "easyslip_bad_response"))
 		return
 	}
 	if out.Status != 200 || out.Data == nil {
+    failReason = services.EasySlipFailureReason(out.Message)
     if strings.EqualFold(out.Message, "application_expired") {
-        c.JSON(http.StatusServiceUnavailable, gin.H{
-            "error":   "easyslip_application_expired",
-            "message": "EasySlip application expired",
-        })
+        api.Fail(c, api.NewError(http.StatusServiceUnavailable, "easyslip_application_expired"))
         return
     }
     if strings.Contains(strings.ToLower(out.Message), "duplicate") {
-        c.JSON(http.StatusConflict, gin.H{"error": "duplicate_slip"})
+        api.Fail(c, api.NewError(http.StatusConflict, "duplicate_slip"))
         return
     }
-    c.JSON(http.StatusBadRequest, gin.H{"error": "easyslip_verify_failed", "message": out.Message})
+    api.Fail(c, api.NewError(http.StatusBadRequest, "easyslip_verify_failed").With("message", out.Message))
     return
 }
 
 	// 4) Amount must match (±0.01)
 	ea := out.Data.Amount.Amount
 	if math.Abs(ea-in.Amount) > 0.01 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_mismatch"})
+		failReason = "amount_mismatch"
+		api.Fail(c, api.NewError(http.StatusBadRequest, "amount_mismatch"))
 		return
 	}
 	vamount := int(math.Round(ea))
 	totalInt := int(math.Round(in.Amount))
 
 	// 5) Save to DB (upsert by order, unique by trans_ref)
-	db := config.DB
-	var savedPaymentID uint
-	var paidAtISO string
-
-	txErr := db.Transaction(func(tx *gorm.DB) error {
-		var pay entity.Payment
-		if err := tx.Where("order_id = ?", in.OrderID).First(&pay).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				pay = entity.Payment{
-					OrderID:       in.OrderID,
-					PaymentType:   "PromptPay",
-					
-					TotalAmount:   totalInt,
-					PaymentStatus: "pending",
-				}
-				if err := tx.Create(&pay).Error; err != nil {
-					return err
-				}
-			} else {
-				return err
-			}
-		}
-
-		// parse slip time
-		var slipTime *time.Time
-		if t, err := time.Parse(time.RFC3339, out.Data.Date); err == nil {
-			slipTime = &t
-		}
-		now := time.Now()
-
-		pay.TransRef = out.Data.TransRef
-		// pay.ReceiverBankID = out.Data.Receiver.Bank.ID
-		// pay.CountryCode = out.Data.Country
-		pay.VerifiedAmount = vamount
-		pay.SlipDate = slipTime
-		pay.SlipVerifiedAt = &now
-		pay.PaymentStatus = "paid"
-		pay.CheckPaymentB64 = raw
-
-		if err := tx.Save(&pay).Error; err != nil {
-			// unique trans_ref
-			if strings.Contains(strings.ToLower(err.Error()), "unique") {
-				return ErrDuplicateSlip
-			}
-			return err
-		}
-
-		savedPaymentID = pay.ID
-		if pay.SlipVerifiedAt != nil {
-			paidAtISO = pay.SlipVerifiedAt.Format(time.RFC3339)
-		}
-		return nil
+	var slipTime *time.Time
+	if t, err := time.Parse(time.RFC3339, out.Data.Date); err == nil {
+		slipTime = &t
+	}
+	pay, receipt, err := services.NewPaymentService(config.DB).RecordSlip(services.VerifiedSlip{
+		OrderID:  in.OrderID,
+		Total:    totalInt,
+		Amount:   vamount,
+		TransRef: out.Data.TransRef,
+		SlipDate: slipTime,
+		Image:    raw,
 	})
-
-	if txErr != nil {
-		if errors.Is(txErr, ErrDuplicateSlip) {
-			c.JSON(http.StatusConflict, gin.H{"error": "duplicate_slip"})
-			return
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDuplicateSlip):
+			failReason = "duplicate_trans_ref"
+			api.Fail(c, api.NewError(http.StatusConflict, "duplicate_slip"))
+		case errors.Is(err, services.ErrPaymentAlreadyPaid):
+			failReason = "already_paid"
+			api.Fail(c, api.NewError(http.StatusConflict, "payment_already_paid"))
+		case errors.Is(err, services.ErrDayClosed):
+			failReason = "day_closed"
+			api.Fail(c, api.NewError(http.StatusConflict, "day_closed"))
+		default:
+			failReason = "save_payment_failed"
+			api.Fail(c, api.NewError(http.StatusInternalServerError, "save_payment_failed"))
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "save_payment_failed"})
 		return
 	}
+	paidAtISO := ""
+	if pay.SlipVerifiedAt != nil {
+		paidAtISO = pay.SlipVerifiedAt.Format(time.RFC3339)
+	}
+
+	services.RecordSlipVerification(services.SlipVerified, "")
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":         "ok",
-		"paymentId":      savedPaymentID,
+		"paymentId":      pay.ID,
+		"receiptNo":      receipt.DocumentNo,
 		"orderId":        in.OrderID,
 		"transRef":       out.Data.TransRef,
 		"paidAt":         paidAtISO,
@@ -285,7 +277,7 @@ func GetCheckoutData(c *gin.Context) {
 			// return db.Select("service_types.id", "service_types.service_name", "service_types.amount")
 		}).
 		First(&order, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "order_not_found"})
+		api.Fail(c, api.NewError(http.StatusNotFound, "order_not_found"))
 		return
 	}
 
@@ -339,49 +331,49 @@ func GetCheckoutData(c *gin.Context) {
 
 // POST /payments/cash
 func PayByCashSimple(c *gin.Context) {
+	// ผู้รับเงินคือพนักงานเจ้าของ token เท่านั้น
+	empID := currentEmployeeID(c)
+	if empID == nil {
+		api.Fail(c, api.NewError(http.StatusForbidden, "employee_only"))
+		return
+	}
 	var req PayCashRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &req) {
 		return
 	}
 
-	// ✅ ถ้า frontend ส่ง amount มาด้วย → ใช้เลย (ถือว่าเป็นยอดสุทธิหลังหักโปร)
-	total := 0
-	if req.Amount != nil && *req.Amount > 0 {
-		total = *req.Amount
-	} else {
-		// ❌ ไม่ส่ง amount → fallback: sum ของ ServiceTypes
-		var order entity.Order
-		if err := config.DB.Preload("ServiceTypes").First(&order, req.OrderID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "order_not_found"})
-			return
-		}
-		sum := 0.0
-		for _, st := range order.ServiceTypes {
-			sum += st.Price
+	// amount จาก frontend = ยอดสุทธิหลังหักโปร, ไม่ส่งมา = รวมราคา ServiceTypes
+	payment, receipt, err := services.NewPaymentService(config.DB).PayCash(services.CashPayment{
+		OrderID:    req.OrderID,
+		Amount:     req.Amount,
+		EmployeeID: empID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOrderNotFound):
+			api.Fail(c, api.NewError(http.StatusNotFound, "order_not_found"))
+		case errors.Is(err, services.ErrPaymentAlreadyPaid):
+			api.Fail(c, api.NewError(http.StatusConflict, "payment_already_paid"))
+		case errors.Is(err, services.ErrDayClosed), errors.Is(err, services.ErrNoOpenCashSession):
+			writeCashError(c, err)
+		default:
+			api.Fail(c, api.NewError(http.StatusInternalServerError, "cannot_create_payment"))
 		}
-		total = int(math.Round(sum))
-	}
-
-	// gen trans_ref กันชน UNIQUE
-	transRef := fmt.Sprintf("CASH-%d-%d", req.OrderID, time.Now().UnixNano())
-
-	payment := entity.Payment{
-		OrderID:        req.OrderID,
-		PaymentType:    "cash",
-		PaymentStatus:  "paid",
-		VerifiedAmount: total,
-		TotalAmount:    total,
-		TransRef:       transRef,
+		return
 	}
+	total := payment.TotalAmount
+	transRef := payment.TransRef
 
-	if err := config.DB.Create(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot_create_payment"})
-		return
+	// เงินสดที่รอเก็บยังไม่มีใบเสร็จ
+	receiptNo := ""
+	if receipt != nil {
+		receiptNo = receipt.DocumentNo
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"ok":         true,
+		"status":     payment.PaymentStatus,
+		"receipt_no": receiptNo,
 		"orderId":    req.OrderID,
 		"total":      total,
 		"payment_id": payment.ID,
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
 	"github.com/gin-gonic/gin"
@@ -28,9 +29,9 @@ type PromotionConditionPayload struct {
 }
 
 func CreatePromotion(c *gin.Context) {
+	db := auditDB(c)
 	var payload PromotionPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
@@ -48,8 +49,8 @@ func CreatePromotion(c *gin.Context) {
 		DiscountTypeID: payload.DiscountTypeID,
 	}
 
-	if err := config.DB.Create(&promotion).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Create(&promotion).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -60,27 +61,47 @@ func CreatePromotion(c *gin.Context) {
 			Value:         cond.Value,
 			PromotionID:   promotion.ID,
 		}
-		config.DB.Create(&condition)
+		db.Create(&condition)
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": promotion})
 }
 
 // -------------------- READ --------------------
+// GET /promotions?q=&status=&discountTypeId=&from=&to=&sort=-startDate
+// from/to = โปรโมชันที่ช่วงใช้งานทับกับช่วงนี้
+var promotionList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"status":         {Column: "status", Op: api.OpEq},
+		"discountTypeId": {Column: "discount_type_id", Op: api.OpID},
+		"from":           {Column: "end_date", Op: api.OpFrom},
+		"to":             {Column: "start_date", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":            "id",
+		"name":          "promotion_name",
+		"startDate":     "start_date",
+		"endDate":       "end_date",
+		"discountValue": "discount_value",
+	},
+	Search: []string{"promotion_name", "description"},
+}
+
 func GetPromotions(c *gin.Context) {
 	var promotions []entity.Promotion
-	if err := config.DB.Preload("DiscountType").Preload("PromotionCondition").Find(&promotions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := promotionList.Find(c, config.DB.Model(&entity.Promotion{}).
+		Preload("DiscountType").Preload("PromotionCondition"), &promotions)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, promotions)
+	api.List(c, promotions, meta)
 }
 
 func GetPromotionByID(c *gin.Context) {
 	id := c.Param("id")
 	var promotion entity.Promotion
 	if err := config.DB.Preload("DiscountType").Preload("PromotionCondition").First(&promotion, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		api.Fail(c, api.NotFound("", "ไม่พบโปรโมชัน"))
 		return
 	}
 	c.JSON(http.StatusOK, promotion)
@@ -88,16 +109,16 @@ func GetPromotionByID(c *gin.Context) {
 
 // -------------------- UPDATE --------------------
 func UpdatePromotion(c *gin.Context) {
+	db := auditDB(c)
 	id := c.Param("id")
 	var payload PromotionPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &payload) {
 		return
 	}
 
 	var promotion entity.Promotion
-	if err := config.DB.First(&promotion, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+	if err := db.First(&promotion, id).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบโปรโมชัน"))
 		return
 	}
 
@@ -113,20 +134,20 @@ func UpdatePromotion(c *gin.Context) {
 	promotion.PromoImage = payload.PromoImage
 	promotion.DiscountTypeID = payload.DiscountTypeID
 
-	if err := config.DB.Save(&promotion).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Save(&promotion).Error; err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
 	// ลบเงื่อนไขเดิมและเพิ่มใหม่
-	config.DB.Where("promotion_id = ?", promotion.ID).Delete(&entity.PromotionCondition{})
+	db.Where("promotion_id = ?", promotion.ID).Delete(&entity.PromotionCondition{})
 	for _, cond := range payload.Conditions {
 		condition := entity.PromotionCondition{
 			ConditionType: cond.ConditionType,
 			Value:         cond.Value,
 			PromotionID:   promotion.ID,
 		}
-		config.DB.Create(&condition)
+		db.Create(&condition)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": promotion})
@@ -134,13 +155,14 @@ func UpdatePromotion(c *gin.Context) {
 
 // -------------------- DELETE --------------------
 func DeletePromotion(c *gin.Context) {
+	db := auditDB(c)
 	id := c.Param("id")
 	var promotion entity.Promotion
-	if err := config.DB.First(&promotion, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+	if err := db.First(&promotion, id).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบโปรโมชัน"))
 		return
 	}
-	config.DB.Where("promotion_id = ?", promotion.ID).Delete(&entity.PromotionCondition{})
-	config.DB.Delete(&promotion)
+	db.Where("promotion_id = ?", promotion.ID).Delete(&entity.PromotionCondition{})
+	db.Delete(&promotion)
 	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted"})
 }
@@ -4,19 +4,41 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
 	"github.com/gin-gonic/gin"
 )
 
+// GET /promotion-usages?q=&promotionId=&customerId=&status=&from=&to=&sort=-usageDate
+// q ค้นชื่อลูกค้า/ชื่อโปรโมชัน
+var promotionUsageList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"promotionId": {Column: "promotion_usages.promotion_id", Op: api.OpID},
+		"customerId":  {Column: "promotion_usages.customer_id", Op: api.OpID},
+		"orderId":     {Column: "promotion_usages.order_id", Op: api.OpID},
+		"status":      {Column: "promotion_usages.status", Op: api.OpEq},
+		"from":        {Column: "promotion_usages.usage_date", Op: api.OpFrom},
+		"to":          {Column: "promotion_usages.usage_date", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":        "promotion_usages.id",
+		"usageDate": "promotion_usages.usage_date",
+	},
+	Search: []string{"customers.first_name", "customers.last_name", "promotions.promotion_name"},
+}
+
 func GetPromotionUsages(c *gin.Context) {
 	var usages []entity.PromotionUsage
-	if err := config.DB.Preload("Customer").Preload("Promotion").Preload("Order").Find(&usages).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := promotionUsageList.Find(c, config.DB.Model(&entity.PromotionUsage{}).
+		Joins("LEFT JOIN customers ON customers.id = promotion_usages.customer_id").
+		Joins("LEFT JOIN promotions ON promotions.id = promotion_usages.promotion_id").
+		Preload("Customer").Preload("Promotion").Preload("Order"), &usages)
+	if !ok {
 		return
 	}
 	// Map to response struct for frontend
-	var resp []map[string]interface{}
+	resp := make([]map[string]interface{}, 0, len(usages))
 	for _, u := range usages {
 		CustomerName := "-"
 		if u.Customer != nil {
@@ -44,7 +66,7 @@ func GetPromotionUsages(c *gin.Context) {
 			"Status":        status,
 		})
 	}
-	c.JSON(http.StatusOK, resp)
+	api.List(c, resp, meta)
 }
 
 func CreatePromotionUsage(c *gin.Context) {
@@ -56,13 +78,13 @@ func CreatePromotionUsage(c *gin.Context) {
 		CustomerID  uint   `json:"CustomerID"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลที่ส่งมาไม่ถูกต้อง"})
+		api.Fail(c, api.BadRequest("", "ข้อมูลที่ส่งมาไม่ถูกต้อง"))
 		return
 	}
 
 	usageDate, err := time.Parse("2006-01-02", input.UsageDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รูปแบบวันที่ไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)"})
+		api.Fail(c, api.BadRequest("", "รูปแบบวันที่ไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)"))
 		return
 	}
 
@@ -75,7 +97,7 @@ func CreatePromotionUsage(c *gin.Context) {
 	}
 
 	if err := config.DB.Create(&usage).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกข้อมูลไม่สำเร็จ: " + err.Error()})
+		api.Fail(c, api.InternalMsg("บันทึกข้อมูลไม่สำเร็จ", err))
 		return
 	}
 
@@ -1,257 +1,148 @@
 package controller
 
 import (
-	"fmt"
-)
-
-import (
+	"errors"
 	"net/http"
-	"strings"
-	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 )
 
+// GET /queues?type=pickup|delivery&status=&q=&sort=
+// q ค้นชื่อ/เบอร์ลูกค้า
+var queueList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"type":       {Column: "queues.queue_type", Op: api.OpEq},
+		"status":     {Column: "queues.status", Op: api.OpEq},
+		"orderId":    {Column: "queues.order_id", Op: api.OpID},
+		"timeSlotId": {Column: "queues.time_slot_id", Op: api.OpID},
+	},
+	Sorts: map[string]string{
+		"id":        "queues.id",
+		"createdAt": "queues.created_at",
+		"status":    "queues.status",
+	},
+	Search: []string{"customers.first_name", "customers.last_name", "customers.phone_number"},
+}
+
 // Get queues by type (pickup / delivery)
 func GetQueues(c *gin.Context) {
-	queueType := c.Query("type") // pickup หรือ delivery
-
 	var queues []entity.Queue
-	if err := config.DB.Preload("Order.Customer").Preload("Order.Address").
-		Where("queue_type = ?", queueType).
-		Find(&queues).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := queueList.Find(c, config.DB.Model(&entity.Queue{}).
+		Joins("LEFT JOIN orders ON orders.id = queues.order_id").
+		Joins("LEFT JOIN customers ON customers.id = orders.customer_id").
+		Preload("Order.Customer").Preload("Order.Address"), &queues)
+	if !ok {
 		return
 	}
-
-	c.JSON(http.StatusOK, queues)
+	api.List(c, queues, meta)
 }
 
 // Create Pickup Queue เมื่อมี Order ใหม่ (ยังไม่เลือก TimeSlot)
 func CreatePickupQueue(c *gin.Context) {
-       var input struct {
-	       OrderID uint `json:"order_id"`
-       }
-       if err := c.ShouldBindJSON(&input); err != nil {
-	       c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	       return
-       }
-       queue := entity.Queue{
-	       Queue_type: strings.ToLower(strings.TrimSpace("pickup")),
-	       Status:     strings.ToLower(strings.TrimSpace("waiting")),
-	       OrderID:    input.OrderID,
-	       // ยังไม่ assign TimeSlot
-       }
-       if err := config.DB.Create(&queue).Error; err != nil {
-	       c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	       return
-       }
-       c.JSON(http.StatusOK, queue)
+	var input struct {
+		OrderID uint `json:"order_id"`
+	}
+	if !api.BindJSON(c, &input) {
+		return
+	}
+	queue, err := services.NewQueueService(auditDB(c)).CreatePickup(input.OrderID)
+	if err != nil {
+		writeQueueError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, queue)
 }
 
 // Assign TimeSlot ให้ Queue (ตรวจสอบ capacity ก่อน assign)
 func AssignTimeSlotToQueue(c *gin.Context) {
-       id := c.Param("id")
-       var input struct {
-	       TimeSlotID *uint `json:"time_slot_id"`
-       }
-       if err := c.ShouldBindJSON(&input); err != nil {
-	       c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	       return
-       }
-       if input.TimeSlotID == nil {
-	       c.JSON(http.StatusBadRequest, gin.H{"error": "TimeSlotID is required"})
-	       return
-       }
-       var queue entity.Queue
-       if err := config.DB.First(&queue, id).Error; err != nil {
-	       c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
-	       return
-       }
-       var timeslot entity.TimeSlot
-       if err := config.DB.First(&timeslot, *input.TimeSlotID).Error; err != nil {
-	       c.JSON(http.StatusNotFound, gin.H{"error": "TimeSlot not found"})
-	       return
-       }
-       // ตรวจสอบ capacity
-       var count int64
-       config.DB.Model(&entity.Queue{}).Where("time_slot_id = ?", *input.TimeSlotID).Count(&count)
-       if int(count) >= timeslot.Capacity {
-	       c.JSON(http.StatusBadRequest, gin.H{"error": "TimeSlot is full"})
-	       return
-       }
-       queue.TimeSlotID = input.TimeSlotID
-       if err := config.DB.Save(&queue).Error; err != nil {
-	       c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	       return
-       }
-       // อัปเดต status ของ TimeSlot ถ้าเต็ม
-       if int(count+1) >= timeslot.Capacity {
-	       timeslot.Status = "full"
-	       config.DB.Save(&timeslot)
-       }
-       c.JSON(http.StatusOK, queue)
-}
-
-// Accept Queue (pickup หรือ delivery)
-func AcceptQueue(c *gin.Context) {
-	id := c.Param("id")
-	var queue entity.Queue
-	if err := config.DB.First(&queue, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
 	var input struct {
-		EmployeeID uint `json:"employee_id"`
+		TimeSlotID *uint `json:"time_slot_id"`
 	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &input) {
 		return
 	}
-
-       t := strings.ToLower(strings.TrimSpace(queue.Queue_type))
-       if t == "pickup" {
-	       queue.Status = "pickup_in_progress"
-       } else if t == "delivery" {
-	       queue.Status = "delivery_in_progress"
-       }
-
-	assignment := entity.QueueAssignment{
-		QueueID:      queue.ID,
-		EmployeeID:   input.EmployeeID,
-		Assigned_time: time.Now(),
-	}
-
-	if err := config.DB.Save(&queue).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if input.TimeSlotID == nil {
+		api.Fail(c, api.BadRequest("", "กรุณาเลือกช่วงเวลา (TimeSlotID)"))
 		return
 	}
-	if err := config.DB.Create(&assignment).Error; err != nil {
-		// log error และแจ้งกลับ frontend
-		fmt.Println("Error creating QueueAssignment:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create QueueAssignment", "details": err.Error()})
+	queue, err := services.NewQueueService(auditDB(c)).AssignTimeSlot(id, *input.TimeSlotID)
+	if err != nil {
+		writeQueueError(c, err)
 		return
 	}
-
 	c.JSON(http.StatusOK, queue)
 }
 
-// Confirm Pickup Done → เปลี่ยนสถานะ และสร้าง Delivery Queue
-func ConfirmPickupDone(c *gin.Context) {
-	id := c.Param("id")
-	var queue entity.Queue
-	if err := config.DB.First(&queue, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+// Accept Queue (pickup หรือ delivery)
+func AcceptQueue(c *gin.Context) {
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
-	// รับ employee_id จาก body
-	var input struct {
-		EmployeeID uint `json:"employee_id"`
-	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input queueEmployeeIn
+	if !api.BindJSON(c, &input) {
 		return
 	}
-
-	queue.Status = "done"
-	if err := config.DB.Save(&queue).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	queue, err := services.NewQueueService(auditDB(c)).Accept(id, input.EmployeeID)
+	if err != nil {
+		writeQueueError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, queue)
+}
 
-	// สร้าง QueueAssignment ใหม่ (ถ้ายังไม่มี)
-	assignment := entity.QueueAssignment{
-		QueueID:      queue.ID,
-		EmployeeID:   input.EmployeeID,
-		Assigned_time: time.Now(),
-	}
-	if err := config.DB.Create(&assignment).Error; err != nil {
-		fmt.Println("Error creating QueueAssignment (pickup_done):", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create QueueAssignment", "details": err.Error()})
+// Confirm Pickup Done → เปลี่ยนสถานะ และสร้าง Delivery Queue
+func ConfirmPickupDone(c *gin.Context) {
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
-	// เพิ่มประวัติลง QueueHistory เมื่อ Pickup เสร็จ
-	history := entity.QueueHistory{
-		QueueID: queue.ID,
-	}
-	if err := config.DB.Create(&history).Error; err != nil {
-		fmt.Println("Error creating QueueHistory (pickup_done):", err)
+	var input queueEmployeeIn
+	if !api.BindJSON(c, &input) {
+		return
 	}
-
-	// อัปเดต LaundryProcess ล่าสุดของ order นี้เป็น 'รับผ้าเรียบร้อย'
-	var process entity.LaundryProcess
-	if err := config.DB.Joins("JOIN process_order ON process_order.laundry_process_id = laundry_processes.id").
-		Where("process_order.order_id = ?", queue.OrderID).
-		Order("laundry_processes.created_at desc").
-		First(&process).Error; err == nil {
-		process.Status = "รับผ้าเรียบร้อย"
-		config.DB.Save(&process)
+	queue, err := services.NewQueueService(auditDB(c)).ConfirmPickupDone(id, input.EmployeeID)
+	if err != nil {
+		writeQueueError(c, err)
+		return
 	}
-
 	c.JSON(http.StatusOK, gin.H{"pickup_done": queue})
 }
 
 // Confirm Delivery Done
 func ConfirmDeliveryDone(c *gin.Context) {
-	id := c.Param("id")
-	var queue entity.Queue
-	if err := config.DB.First(&queue, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
-	// รับ employee_id จาก body
-	var input struct {
-		EmployeeID uint `json:"employee_id"`
-	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input queueEmployeeIn
+	if !api.BindJSON(c, &input) {
 		return
 	}
-
-	queue.Status = "delivered"
-	if err := config.DB.Save(&queue).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	queue, err := services.NewQueueService(auditDB(c)).ConfirmDeliveryDone(id, input.EmployeeID)
+	if err != nil {
+		writeQueueError(c, err)
 		return
 	}
-
-	// สร้าง QueueAssignment ใหม่ (ถ้ายังไม่มี)
-	assignment := entity.QueueAssignment{
-		QueueID:      queue.ID,
-		EmployeeID:   input.EmployeeID,
-		Assigned_time: time.Now(),
-	}
-	if err := config.DB.Create(&assignment).Error; err != nil {
-		fmt.Println("Error creating QueueAssignment (delivery_done):", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create QueueAssignment", "details": err.Error()})
-		return
-	}
-
-	// เพิ่มประวัติลง QueueHistory เมื่อ Delivery เสร็จ
-	history := entity.QueueHistory{
-		QueueID: queue.ID,
-	}
-	if err := config.DB.Create(&history).Error; err != nil {
-		fmt.Println("Error creating QueueHistory (delivery_done):", err)
-	}
-
 	c.JSON(http.StatusOK, queue)
 }
 // Delete Queue by ID
 func DeleteQueue(c *gin.Context) {
-	id := c.Param("id")
-	var queue entity.Queue
-	if err := config.DB.First(&queue, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-	if err := config.DB.Delete(&queue).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	queue, err := services.NewQueueService(auditDB(c)).Delete(id)
+	if err != nil {
+		writeQueueError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"deleted": queue})
@@ -259,52 +150,95 @@ func DeleteQueue(c *gin.Context) {
 
 // Update Queue by ID (status, employee)
 func UpdateQueue(c *gin.Context) {
-       id := c.Param("id")
-       var queue entity.Queue
-       if err := config.DB.First(&queue, id).Error; err != nil {
-	       c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
-	       return
-       }
-       var input struct {
-	       Status     *string `json:"status"`
-	       EmployeeID *uint   `json:"employee_id"`
-       }
-       if err := c.ShouldBindJSON(&input); err != nil {
-	       c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	       return
-       }
-       if input.Status != nil {
-	       queue.Status = *input.Status
-       }
-       if input.EmployeeID != nil && queue.Queueassignment != nil {
-	       queue.Queueassignment.EmployeeID = *input.EmployeeID
-       }
-       if err := config.DB.Save(&queue).Error; err != nil {
-	       c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	       return
-       }
-       c.JSON(http.StatusOK, queue)
+	id, ok := api.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	var input struct {
+		Status     *string `json:"status"`
+		EmployeeID *uint   `json:"employee_id"`
+	}
+	if !api.BindJSON(c, &input) {
+		return
+	}
+	queue, err := services.NewQueueService(auditDB(c)).Update(id, services.QueueUpdate{
+		Status:     input.Status,
+		EmployeeID: input.EmployeeID,
+	})
+	if err != nil {
+		writeQueueError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// พนักงานที่รับ/ปิดคิว
+type queueEmployeeIn struct {
+	EmployeeID uint `json:"employee_id"`
 }
-// GET /timeslots?type=pickup|delivery
+
+func writeQueueError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrQueueNotFound):
+		api.Fail(c, api.NotFound("", "ไม่พบคิว"))
+	case errors.Is(err, services.ErrTimeSlotNotFound):
+		api.Fail(c, api.NotFound("", "ไม่พบช่วงเวลา"))
+	case errors.Is(err, services.ErrTimeSlotFull):
+		api.Fail(c, api.BadRequest("", "ช่วงเวลานี้เต็มแล้ว"))
+	default:
+		api.Fail(c, api.Internal(err))
+	}
+}
+// GET /timeslots?type=pickup|delivery&status=&from=&to=&sort=startTime
+var timeSlotList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"type":   {Column: "slot_type", Op: api.OpEq},
+		"status": {Column: "status", Op: api.OpEq},
+		"from":   {Column: "start_time", Op: api.OpFrom},
+		"to":     {Column: "start_time", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":        "id",
+		"startTime": "start_time",
+		"capacity":  "capacity",
+	},
+}
+
 func GetTimeSlots(c *gin.Context) {
-	typeParam := c.Query("type")
 	var timeslots []entity.TimeSlot
-	db := config.DB
-	if typeParam != "" {
-		db = db.Where("slot_type = ?", typeParam)
-	}
-	if err := db.Find(&timeslots).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := timeSlotList.Find(c, config.DB.Model(&entity.TimeSlot{}), &timeslots)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, timeslots)
+	api.List(c, timeslots, meta)
+}
+// GET /queue_histories?type=&status=&q=&from=&to=&sort=-createdAt
+var queueHistoryList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"type":    {Column: "queues.queue_type", Op: api.OpEq},
+		"status":  {Column: "queues.status", Op: api.OpEq},
+		"queueId": {Column: "queue_histories.queue_id", Op: api.OpID},
+		"from":    {Column: "queue_histories.created_at", Op: api.OpFrom},
+		"to":      {Column: "queue_histories.created_at", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":        "queue_histories.id",
+		"createdAt": "queue_histories.created_at",
+	},
+	DefaultSort: "-createdAt",
+	Search:      []string{"customers.first_name", "customers.last_name", "customers.phone_number"},
 }
+
 // Get all queue histories
 func GetQueueHistories(c *gin.Context) {
-       var histories []entity.QueueHistory
-       if err := config.DB.Preload("Queues.Order.Customer").Preload("Queues.Order.Address").Order("created_at desc").Find(&histories).Error; err != nil {
-	       c.JSON(500, gin.H{"error": err.Error()})
-	       return
-       }
-       c.JSON(200, histories)
-}
\ No newline at end of file
+	var histories []entity.QueueHistory
+	meta, ok := queueHistoryList.Find(c, config.DB.Model(&entity.QueueHistory{}).
+		Joins("LEFT JOIN queues ON queues.id = queue_histories.queue_id").
+		Joins("LEFT JOIN orders ON orders.id = queues.order_id").
+		Joins("LEFT JOIN customers ON customers.id = orders.customer_id").
+		Preload("Queues.Order.Customer").Preload("Queues.Order.Address"), &histories)
+	if !ok {
+		return
+	}
+	api.List(c, histories, meta)
+}
@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// ตรวจสิทธิ์ดูใบเสร็จของออเดอร์: พนักงาน/admin ดูได้ทุกออเดอร์ ลูกค้าดูได้เฉพาะออเดอร์ของตัวเอง
+// (ตอบ error ให้แล้วเมื่อคืน false)
+func authorizeOrderReceipts(c *gin.Context, orderID uint) bool {
+	var order entity.Order
+	if err := config.DB.Select("id", "customer_id").First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			api.Fail(c, api.NewError(http.StatusNotFound, "order_not_found"))
+			return false
+		}
+		api.Fail(c, api.Internal(err))
+		return false
+	}
+	if services.IsStaffRole(currentRole(c)) {
+		return true
+	}
+	if cid := currentCustomerID(c); cid != nil && *cid == order.CustomerID {
+		return true
+	}
+	api.Fail(c, api.NewError(http.StatusForbidden, ""))
+	return false
+}
+
+// ประกอบข้อมูลสำหรับพิมพ์ PDF
+func buildReceiptDocument(rc *entity.Receipt) (services.ReceiptDocument, error) {
+	var order entity.Order
+	if err := config.DB.
+		Preload("Customer").
+		Preload("Address").
+		Preload("ServiceTypes").
+		Preload("SortingRecord.SortedClothes.ClothType").
+		Preload("PromotionUsage.Promotion").
+		First(&order, rc.OrderID).Error; err != nil {
+		return services.ReceiptDocument{}, err
+	}
+	var pay entity.Payment
+	_ = config.DB.First(&pay, rc.PaymentID).Error
+
+	shop := config.Current().Shop
+	doc := services.ReceiptDocument{
+		DocumentNo:    rc.DocumentNo,
+		IssuedAt:      rc.IssuedAt,
+		Cancelled:     rc.Status == services.ReceiptCancelled,
+		CancelReason:  rc.CancelReason,
+		ShopName:      shop.Name,
+		ShopTaxID:     shop.TaxID,
+		ShopAddress:   shop.Address,
+		CustomerName:  fullCustomerName(order.Customer),
+		OrderID:       order.ID,
+		PaymentMethod: rc.PaymentMethod,
+		TransRef:      pay.TransRef,
+		Subtotal:      rc.Subtotal,
+		Discount:      rc.Discount,
+		VatRate:       services.VatRatePct,
+		VatAmount:     rc.VatAmount,
+		Total:         rc.Total,
+	}
+	if order.Customer != nil {
+		doc.CustomerPhone = order.Customer.PhoneNumber
+	}
+	if order.Address != nil {
+		doc.CustomerAddr = order.Address.AddressDetails
+	}
+	if order.PromotionUsage != nil && order.PromotionUsage.Promotion != nil {
+		doc.DiscountLabel = order.PromotionUsage.Promotion.PromotionName
+	}
+	for _, st := range order.ServiceTypes {
+		if st == nil {
+			continue
+		}
+		doc.Lines = append(doc.Lines, services.ReceiptLine{
+			Description: st.Type,
+			Quantity:    1,
+			UnitPrice:   st.Price,
+			Amount:      st.Price,
+		})
+	}
+	if order.SortingRecord != nil {
+		for _, sc := range order.SortingRecord.SortedClothes {
+			if sc == nil || sc.SortedQuantity <= 0 {
+				continue
+			}
+			name := fmt.Sprintf("#%d", sc.ClothTypeID)
+			if sc.ClothType != nil {
+				name = sc.ClothType.TypeName
+			}
+			doc.Lines = append(doc.Lines, services.ReceiptLine{
+				Description: name,
+				Quantity:    sc.SortedQuantity,
+				InfoOnly:    true,
+			})
+		}
+	}
+	return doc, nil
+}
+
+func receiptView(rc *entity.Receipt) gin.H {
+	return gin.H{
+		"id":            rc.ID,
+		"documentNo":    rc.DocumentNo,
+		"status":        rc.Status,
+		"orderId":       rc.OrderID,
+		"paymentId":     rc.PaymentID,
+		"subtotal":      rc.Subtotal,
+		"discount":      rc.Discount,
+		"vatAmount":     rc.VatAmount,
+		"total":         rc.Total,
+		"paymentMethod": rc.PaymentMethod,
+		"issuedAt":      rc.IssuedAt.Format(time.RFC3339),
+		"cancelReason":  rc.CancelReason,
+		"replacedById":  rc.ReplacedByID,
+	}
+}
+
+// ======================================================
+// GET /orders/:id/receipt.pdf  (ลูกค้าเจ้าของออเดอร์ / พนักงาน)
+// - อ่านอย่างเดียว: ใบเสร็จออกตอนชำระเงิน (หรือพนักงานออกใหม่ที่ /orders/:id/receipt/reissue)
+// - ?documentNo= เพื่อพิมพ์ใบเก่า (รวมใบที่ยกเลิก)
+// ======================================================
+
+func GetOrderReceiptPDF(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "invalid_order_id"))
+		return
+	}
+	if !authorizeOrderReceipts(c, uint(orderID)) {
+		return
+	}
+
+	q := config.DB.Where("order_id = ?", orderID)
+	if docNo := strings.TrimSpace(c.Query("documentNo")); docNo != "" {
+		q = q.Where("document_no = ?", docNo)
+	} else {
+		q = q.Where("status = ?", services.ReceiptIssued)
+	}
+	var rc entity.Receipt
+	if err := q.First(&rc).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			api.Fail(c, api.NewError(http.StatusInternalServerError, "load_receipt_failed").WithCause(err))
+			return
+		}
+		// ยังไม่มีใบเสร็จ: แยกกรณีออเดอร์ยังไม่ชำระ
+		var paid int64
+		config.DB.Model(&entity.Payment{}).Where("order_id = ? AND LOWER(payment_status) = ?", orderID, services.PaymentPaid).Count(&paid)
+		if paid == 0 {
+			api.Fail(c, api.NewError(http.StatusConflict, "order_not_paid"))
+			return
+		}
+		api.Fail(c, api.NewError(http.StatusNotFound, "receipt_not_found"))
+		return
+	}
+
+	doc, err := buildReceiptDocument(&rc)
+	if err != nil {
+		api.Fail(c, api.NewError(http.StatusInternalServerError, "load_receipt_failed"))
+		return
+	}
+	pdf, err := services.RenderReceiptPDF(doc)
+	if err != nil {
+		api.Fail(c, api.NewError(http.StatusInternalServerError, "render_receipt_failed"))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, rc.DocumentNo))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// ======================================================
+// GET /orders/:id/receipts?status=&sort=  (ประวัติใบเสร็จของออเดอร์; ลูกค้าเจ้าของออเดอร์ / พนักงาน)
+// ======================================================
+
+var receiptList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"status": {Column: "status", Op: api.OpEq},
+	},
+	Sorts: map[string]string{"id": "id", "issuedAt": "issued_at", "total": "total"},
+}
+
+func ListOrderReceipts(c *gin.Context) {
+	orderID, ok := api.ParamID(c, "id")
+	if !ok || !authorizeOrderReceipts(c, orderID) {
+		return
+	}
+	var list []entity.Receipt
+	meta, ok := receiptList.Find(c, config.DB.Model(&entity.Receipt{}).Where("order_id = ?", orderID), &list)
+	if !ok {
+		return
+	}
+	out := make([]gin.H, 0, len(list))
+	for i := range list {
+		out = append(out, receiptView(&list[i]))
+	}
+	api.List(c, out, meta)
+}
+
+// ======================================================
+// POST /orders/:id/receipt/reissue  (พนักงาน)
+// - ยกเลิกใบเดิม (คงเลขที่ไว้) แล้วออกใบใหม่ด้วยเลขถัดไป
+// - ยกเลิกก่อนออกใบใหม่ เพราะออเดอร์มีใบ issued ได้ใบเดียว
+// ======================================================
+
+type receiptCancelIn struct {
+	Reason string `json:"reason"`
+}
+
+func ReissueOrderReceipt(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "invalid_order_id"))
+		return
+	}
+	var in receiptCancelIn
+	_ = c.ShouldBindJSON(&in)
+	reason := strings.TrimSpace(in.Reason)
+	if reason == "" {
+		reason = "ออกใบเสร็จใหม่"
+	}
+	newRc, err := services.NewReceiptService(config.DB).Reissue(uint(orderID), currentEmployeeID(c), reason)
+	if err != nil {
+		writeReceiptError(c, err, "reissue_receipt_failed")
+		return
+	}
+	c.JSON(http.StatusCreated, receiptView(newRc))
+}
+
+// ======================================================
+// POST /receipts/:id/cancel  (พนักงาน)
+// ======================================================
+
+func CancelReceipt(c *gin.Context) {
+	var in receiptCancelIn
+	if err := c.ShouldBindJSON(&in); err != nil || strings.TrimSpace(in.Reason) == "" {
+		api.Fail(c, api.NewError(http.StatusBadRequest, "reason_required"))
+		return
+	}
+	id, ok := api.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	rc, err := services.NewReceiptService(config.DB).Cancel(id, strings.TrimSpace(in.Reason))
+	if err != nil {
+		writeReceiptError(c, err, "cancel_receipt_failed")
+		return
+	}
+	c.JSON(http.StatusOK, receiptView(rc))
+}
+
+// แปลง error ของใบเสร็จเป็นคำตอบ HTTP (fallback = code ของ 500)
+func writeReceiptError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotPaid):
+		api.Fail(c, api.NewError(http.StatusConflict, "order_not_paid"))
+	case errors.Is(err, services.ErrOrderNotFound):
+		api.Fail(c, api.NewError(http.StatusNotFound, "order_not_found"))
+	case errors.Is(err, services.ErrReceiptNotFound):
+		api.Fail(c, api.NewError(http.StatusNotFound, "receipt_not_found"))
+	case errors.Is(err, services.ErrReceiptAlreadyCancelled):
+		api.Fail(c, api.NewError(http.StatusConflict, "receipt_already_cancelled"))
+	case errors.Is(err, services.ErrReceiptConflict): // มีคนออกใบใหม่ให้ออเดอร์นี้พร้อมกัน
+		api.Fail(c, api.NewError(http.StatusConflict, ""))
+	default:
+		api.Fail(c, api.NewError(http.StatusInternalServerError, fallback).WithCause(err))
+	}
+}
@@ -2,12 +2,13 @@
 package controller
 
 import (
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type RegisterInput struct {
@@ -23,24 +24,31 @@ type RegisterInput struct {
 }
 
 func Register(c *gin.Context) {
+	if !config.Current().Features.SelfRegistration {
+		api.Fail(c, api.Forbidden("", "ปิดการสมัครสมาชิกด้วยตนเอง"))
+		return
+	}
 	var input RegisterInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &input) {
 		return
 	}
 
-	// hash password
-	hashed, _ := bcrypt.GenerateFromPassword([]byte(input.Password), 12)
+	// hash password (ตรวจกติการหัสผ่านก่อน)
+	hashed, err := services.HashPassword(input.Password, input.Email)
+	if err != nil {
+		passwordErrorJSON(c, err)
+		return
+	}
 
 	// create user
 	user := entity.User{
 		Email:    input.Email,
-		Password: string(hashed),
+		Password: hashed,
 		RoleID:   2, // default role = customer
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email already used"})
+		api.Fail(c, api.BadRequest("", "อีเมลนี้ถูกใช้แล้ว").MsgEN("Email already exists"))
 		return
 	}
 
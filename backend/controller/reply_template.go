@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// เทมเพลตตอบกลับคำร้องเรียน
+// - GET    /reply-templates?category=&all=true
+// - POST   /reply-templates
+// - PUT    /reply-templates/:id
+// - DELETE /reply-templates/:id
+// - GET    /reply-templates/stats                      -> ใช้กี่ครั้ง / ปิดงานได้กี่เรื่อง / คะแนนเฉลี่ย
+// - GET    /employee/complaints/:publicId/reply-templates -> เทมเพลตของหมวดนี้ พร้อมข้อความที่เติมค่าแล้ว
+// ตัวแปร: {customerName} {orderId} {publicId} {subject} {category} {employeeName}
+// ======================================================
+
+type replyTemplateIn struct {
+	Title    string `json:"title" binding:"required"`
+	Category string `json:"category"` // ว่าง = ทุกหมวด
+	Body     string `json:"body" binding:"required"`
+	IsActive *bool  `json:"isActive"`
+}
+
+func (in *replyTemplateIn) validate() string {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Category = strings.TrimSpace(in.Category)
+	in.Body = strings.TrimSpace(in.Body)
+	if in.Title == "" || in.Body == "" {
+		return "กรุณากรอกชื่อและข้อความของเทมเพลต"
+	}
+	if in.Category != "" {
+		var n int64
+		config.DB.Model(&entity.ComplaintCategory{}).Where("code = ?", in.Category).Count(&n)
+		if n == 0 {
+			return "ไม่พบหมวดคำร้องเรียนนี้"
+		}
+	}
+	return ""
+}
+
+// ค่าตัวแปรจากคำร้อง/ลูกค้า/พนักงาน; overrides ทับค่าที่ระบบเติมให้ได้
+func replyTemplateVars(db *gorm.DB, comp *entity.Complaint, emp *entity.Employee, overrides map[string]string) map[string]string {
+	vars := map[string]string{
+		"customerName": fullCustomerName(comp.Customer),
+		"publicId":     comp.PublicID,
+		"subject":      comp.Title,
+	}
+	if comp.OrderID != nil {
+		vars["orderId"] = "#" + strconv.Itoa(int(*comp.OrderID))
+	}
+	if comp.Category != "" {
+		var cat entity.ComplaintCategory
+		if err := db.Select("name").Where("code = ?", comp.Category).First(&cat).Error; err == nil {
+			vars["category"] = cat.Name
+		}
+	}
+	if emp != nil {
+		vars["employeeName"] = strings.TrimSpace(emp.FirstName + " " + emp.LastName)
+	}
+	for k, v := range overrides {
+		vars[k] = v
+	}
+	return vars
+}
+
+// ?q= ค้นหัวข้อ/เนื้อหา; sort=category,title (ค่าเริ่มต้น)
+var replyTemplateList = api.ListSpec{
+	Sorts:       map[string]string{"id": "id", "title": "title", "category": "category"},
+	DefaultSort: "category,title",
+	Search:      []string{"title", "body"},
+}
+
+func ListReplyTemplates(c *gin.Context) {
+	db := config.DB.Model(&entity.ReplyTemplate{})
+	if c.Query("all") != "true" {
+		db = db.Where("is_active = ?", true)
+	}
+	// หมวดที่ระบุ + เทมเพลตทั่วไป
+	if cat := strings.TrimSpace(c.Query("category")); cat != "" {
+		db = db.Where("category = ? OR category = ''", cat)
+	}
+	var items []entity.ReplyTemplate
+	meta, ok := replyTemplateList.Find(c, db, &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta, gin.H{"placeholders": services.ReplyTemplatePlaceholders})
+}
+
+func CreateReplyTemplate(c *gin.Context) {
+	var in replyTemplateIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	t := entity.ReplyTemplate{
+		Title:    in.Title,
+		Category: in.Category,
+		Body:     in.Body,
+		IsActive: in.IsActive == nil || *in.IsActive,
+	}
+	if err := config.DB.Create(&t).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+func UpdateReplyTemplate(c *gin.Context) {
+	var t entity.ReplyTemplate
+	if err := config.DB.First(&t, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบเทมเพลตข้อความ"))
+		return
+	}
+	var in replyTemplateIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	// ข้อความที่ตอบไปแล้วเก็บเป็นข้อความจริง แก้เทมเพลตไม่กระทบของเดิม
+	t.Title = in.Title
+	t.Category = in.Category
+	t.Body = in.Body
+	if in.IsActive != nil {
+		t.IsActive = *in.IsActive
+	}
+	if err := config.DB.Save(&t).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func DeleteReplyTemplate(c *gin.Context) {
+	res := config.DB.Delete(&entity.ReplyTemplate{}, c.Param("id"))
+	if res.Error != nil {
+		api.Fail(c, api.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		api.Fail(c, api.NotFound("", "ไม่พบเทมเพลตข้อความ"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+// ======================================================
+// GET /employee/complaints/:publicId/reply-templates
+// ======================================================
+
+type renderedTemplate struct {
+	ID       uint     `json:"id"`
+	Title    string   `json:"title"`
+	Category string   `json:"category,omitempty"`
+	Text     string   `json:"text"`              // เติมค่าแล้ว
+	Missing  []string `json:"missing,omitempty"` // ตัวแปรที่ต้องส่งมาใน overrides
+}
+
+func ListComplaintReplyTemplates(c *gin.Context) {
+	var comp entity.Complaint
+	if err := config.DB.Preload("Customer").First(&comp, "public_id = ?", strings.TrimSpace(c.Param("publicId"))).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			api.Fail(c, api.NotFound("", "ไม่พบคำร้องเรียน"))
+		} else {
+			api.Fail(c, api.InternalMsg("ค้นหาคำร้องเรียนไม่สำเร็จ", err))
+		}
+		return
+	}
+
+	var emp *entity.Employee
+	if id := currentEmployeeID(c); id != nil {
+		var e entity.Employee
+		if err := config.DB.First(&e, *id).Error; err == nil {
+			emp = &e
+		}
+	}
+
+	// เทมเพลตของหมวดนี้ก่อนเทมเพลตทั่วไป
+	var tpls []entity.ReplyTemplate
+	spec := replyTemplateList
+	spec.DefaultSort = "-category,title"
+	meta, ok := spec.Find(c, config.DB.Model(&entity.ReplyTemplate{}).
+		Where("is_active = ? AND (category = ? OR category = '')", true, comp.Category), &tpls)
+	if !ok {
+		return
+	}
+
+	vars := replyTemplateVars(config.DB, &comp, emp, nil)
+	items := make([]renderedTemplate, 0, len(tpls))
+	for _, t := range tpls {
+		text, missing := services.RenderReplyTemplate(t.Body, vars)
+		items = append(items, renderedTemplate{ID: t.ID, Title: t.Title, Category: t.Category, Text: text, Missing: missing})
+	}
+	api.List(c, items, meta)
+}
+
+// ======================================================
+// GET /reply-templates/stats
+// - uses: จำนวนครั้งที่ใช้, complaints: จำนวนคำร้องที่ใช้,
+//   resolved: คำร้องที่ปิดงานแล้ว, avgRating: คะแนนความพึงพอใจเฉลี่ยของคำร้องเหล่านั้น
+// ======================================================
+
+type replyTemplateStat struct {
+	TemplateID     uint     `json:"templateId"`
+	Title          string   `json:"title"`
+	Category       string   `json:"category,omitempty"`
+	Uses           int64    `json:"uses"`
+	Complaints     int64    `json:"complaints"`
+	Resolved       int64    `json:"resolved"`
+	ResolutionRate float64  `json:"resolutionRate"` // resolved / complaints
+	AvgRating      *float64 `json:"avgRating,omitempty"`
+}
+
+func ReplyTemplateStats(c *gin.Context) {
+	var rows []replyTemplateStat
+	// นับต่อคำร้องก่อน (ใช้เทมเพลตเดิมซ้ำในเรื่องเดียวไม่ทำให้อัตราปิดงานเพี้ยน)
+	err := config.DB.Raw(`
+		SELECT t.id AS template_id, t.title, t.category,
+		       COALESCE(SUM(x.n), 0) AS uses,
+		       COUNT(cp.id) AS complaints,
+		       COALESCE(SUM(CASE WHEN cp.status_complaint = ? THEN 1 ELSE 0 END), 0) AS resolved,
+		       AVG(cp.satisfaction_rating) AS avg_rating
+		FROM reply_templates t
+		LEFT JOIN (
+			SELECT template_id, complaint_id, COUNT(*) AS n
+			FROM reply_complaints
+			WHERE template_id IS NOT NULL AND deleted_at IS NULL
+			GROUP BY template_id, complaint_id
+		) x ON x.template_id = t.id
+		LEFT JOIN complaints cp ON cp.id = x.complaint_id AND cp.deleted_at IS NULL
+		WHERE t.deleted_at IS NULL
+		GROUP BY t.id, t.title, t.category
+		ORDER BY uses DESC, t.id ASC`, services.ComplaintStatusClosed).Scan(&rows).Error
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	for i := range rows {
+		if rows[i].Complaints > 0 {
+			rows[i].ResolutionRate = float64(rows[i].Resolved) / float64(rows[i].Complaints)
+		}
+	}
+	api.ListAll(c, rows)
+}
@@ -0,0 +1,523 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+/*
+รายงานสำหรับหน้า Dashboard ของ admin
+- ทุก endpoint รับ ?from=YYYY-MM-DD&to=YYYY-MM-DD (รวมวันสุดท้าย, ค่าเริ่มต้น 30 วันล่าสุด)
+- รวมยอดฝั่ง server แทนการดึงข้อมูลดิบทั้งหมดไปบวกใน browser
+*/
+
+// ช่วงวันที่ของรายงาน [start, end)
+func parseReportRange(c *gin.Context) (time.Time, time.Time, error) {
+	loc := shopLocation()
+	today, _ := time.ParseInLocation("2006-01-02", time.Now().In(loc).Format("2006-01-02"), loc)
+	start, end := today.AddDate(0, 0, -29), today.AddDate(0, 0, 1)
+
+	if s := strings.TrimSpace(c.Query("from")); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, loc)
+		if err != nil {
+			return start, end, api.Invalid("from", "date", "from ต้องอยู่ในรูปแบบ YYYY-MM-DD")
+		}
+		start = t
+	}
+	if s := strings.TrimSpace(c.Query("to")); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, loc)
+		if err != nil {
+			return start, end, api.Invalid("to", "date", "to ต้องอยู่ในรูปแบบ YYYY-MM-DD")
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return start, end, api.Invalid("from", "range", "from ต้องไม่เกิน to")
+	}
+	return start, end, nil
+}
+
+// กรองเวลาในโค้ด (SQLite เปรียบเทียบเวลาเป็นข้อความ offset ต่างกันจะคลาดเคลื่อน)
+func inRange(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// คอลัมน์เวลาที่ดึงกว้างกว่าช่วงจริง 1 วันเพื่อให้กรองซ้ำในโค้ดได้ครบ
+func widenRange(db *gorm.DB, column string, start, end time.Time) *gorm.DB {
+	return db.Where(column+" >= ? AND "+column+" < ?", start.AddDate(0, 0, -1), end.AddDate(0, 0, 1))
+}
+
+func periodKey(t time.Time, groupBy string) string {
+	t = t.In(shopLocation())
+	switch groupBy {
+	case "week":
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	case "month":
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+func avgHours(total time.Duration, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return round2(total.Hours() / float64(n))
+}
+
+func reportRangeOut(start, end time.Time) gin.H {
+	return gin.H{"from": start.Format("2006-01-02"), "to": end.AddDate(0, 0, -1).Format("2006-01-02")}
+}
+
+// ======================================================
+// GET /reports/revenue?groupBy=day|week|month
+// ======================================================
+
+type revenueBucket struct {
+	Period string  `json:"period"`
+	Count  int     `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+func GetRevenueReport(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+	groupBy := strings.ToLower(c.DefaultQuery("groupBy", "day"))
+	if groupBy != "day" && groupBy != "week" && groupBy != "month" {
+		api.Fail(c, api.BadRequest("", "groupBy ต้องเป็น day, week หรือ month"))
+		return
+	}
+
+	pays, err := paymentsPaidBetween(config.DB, start, end)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	buckets := map[string]*revenueBucket{}
+	byType := map[string]*revenueBucket{}
+	total := 0.0
+	for _, p := range pays {
+		at := p.CreatedAt
+		if p.SlipVerifiedAt != nil {
+			at = *p.SlipVerifiedAt
+		}
+		amt := float64(p.VerifiedAmount)
+		k := periodKey(at, groupBy)
+		if buckets[k] == nil {
+			buckets[k] = &revenueBucket{Period: k}
+		}
+		buckets[k].Count++
+		buckets[k].Amount += amt
+
+		pt := p.PaymentType
+		if byType[pt] == nil {
+			byType[pt] = &revenueBucket{Period: pt}
+		}
+		byType[pt].Count++
+		byType[pt].Amount += amt
+		total += amt
+	}
+
+	series := make([]revenueBucket, 0, len(buckets))
+	for _, b := range buckets {
+		series = append(series, *b)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Period < series[j].Period })
+
+	types := make([]gin.H, 0, len(byType))
+	for k, b := range byType {
+		types = append(types, gin.H{"paymentType": k, "count": b.Count, "amount": b.Amount})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i]["paymentType"].(string) < types[j]["paymentType"].(string) })
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":         reportRangeOut(start, end),
+		"groupBy":       groupBy,
+		"total":         total,
+		"paymentCount":  len(pays),
+		"series":        series,
+		"byPaymentType": types,
+	})
+}
+
+// ======================================================
+// GET /reports/orders-by-service
+// ======================================================
+
+func GetOrdersByServiceReport(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	var orders []entity.Order
+	if err := widenRange(config.DB, "created_at", start, end).
+		Preload("ServiceTypes").
+		Find(&orders).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	type row struct {
+		ServiceTypeID uint    `json:"serviceTypeId"`
+		ServiceType   string  `json:"serviceType"`
+		Orders        int     `json:"orders"`
+		Amount        float64 `json:"amount"`
+	}
+	rows := map[uint]*row{}
+	totalOrders := 0
+	for _, o := range orders {
+		if !inRange(o.CreatedAt, start, end) {
+			continue
+		}
+		totalOrders++
+		for _, st := range o.ServiceTypes {
+			if st == nil {
+				continue
+			}
+			if rows[st.ID] == nil {
+				rows[st.ID] = &row{ServiceTypeID: st.ID, ServiceType: st.Type}
+			}
+			rows[st.ID].Orders++
+			rows[st.ID].Amount += st.Price
+		}
+	}
+	out := make([]row, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Orders > out[j].Orders })
+
+	api.ListAll(c, out, gin.H{"range": reportRangeOut(start, end), "totalOrders": totalOrders})
+}
+
+// ======================================================
+// GET /reports/turnaround
+// - สร้างออเดอร์ -> รับผ้า -> ซักเสร็จ -> ส่งถึงลูกค้า (ชั่วโมงเฉลี่ย)
+// ======================================================
+
+func GetTurnaroundReport(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	var orders []entity.Order
+	if err := widenRange(config.DB, "created_at", start, end).
+		Preload("LaundryProcesses").
+		Preload("Queues.Queuehistory").
+		Find(&orders).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	var (
+		toPickup, processing, toDelivery, endToEnd time.Duration
+		nPickup, nProcess, nDelivery, nEnd         int
+		ordersInRange                              int
+	)
+	for _, o := range orders {
+		if !inRange(o.CreatedAt, start, end) {
+			continue
+		}
+		ordersInRange++
+
+		// เวลาที่คิวเสร็จ = QueueHistory แรกของคิวนั้น
+		var pickedUp, delivered *time.Time
+		for _, q := range o.Queues {
+			if q == nil || len(q.Queuehistory) == 0 {
+				continue
+			}
+			first := q.Queuehistory[0].CreatedAt
+			for _, h := range q.Queuehistory {
+				if h.CreatedAt.Before(first) {
+					first = h.CreatedAt
+				}
+			}
+			t := first
+			switch strings.ToLower(q.Queue_type) {
+			case "pickup":
+				pickedUp = &t
+			case "delivery":
+				delivered = &t
+			}
+		}
+
+		var washStart, washEnd *time.Time
+		for _, p := range o.LaundryProcesses {
+			if p == nil {
+				continue
+			}
+			if !p.Start_time.IsZero() && (washStart == nil || p.Start_time.Before(*washStart)) {
+				t := p.Start_time
+				washStart = &t
+			}
+			if !p.End_time.IsZero() && (washEnd == nil || p.End_time.After(*washEnd)) {
+				t := p.End_time
+				washEnd = &t
+			}
+		}
+
+		if pickedUp != nil {
+			toPickup += pickedUp.Sub(o.CreatedAt)
+			nPickup++
+		}
+		if washStart != nil && washEnd != nil && washEnd.After(*washStart) {
+			processing += washEnd.Sub(*washStart)
+			nProcess++
+		}
+		if delivered != nil && washEnd != nil && delivered.After(*washEnd) {
+			toDelivery += delivered.Sub(*washEnd)
+			nDelivery++
+		}
+		if delivered != nil {
+			endToEnd += delivered.Sub(o.CreatedAt)
+			nEnd++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":  reportRangeOut(start, end),
+		"orders": ordersInRange,
+		"avgHours": gin.H{
+			"createdToPickup":  avgHours(toPickup, nPickup),
+			"processing":       avgHours(processing, nProcess),
+			"doneToDelivered":  avgHours(toDelivery, nDelivery),
+			"createdToDeliver": avgHours(endToEnd, nEnd),
+		},
+		"samples": gin.H{
+			"createdToPickup":  nPickup,
+			"processing":       nProcess,
+			"doneToDelivered":  nDelivery,
+			"createdToDeliver": nEnd,
+		},
+	})
+}
+
+// ======================================================
+// GET /reports/promotions  (ต้นทุนส่วนลดโปรโมชัน)
+// ======================================================
+
+func GetPromotionCostReport(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	var usages []entity.PromotionUsage
+	if err := widenRange(config.DB, "usage_date", start, end).
+		Preload("Promotion").
+		Preload("Order.ServiceTypes").
+		Preload("Order.Payment", func(db *gorm.DB) *gorm.DB { return db.Omit("check_payment_b64") }).
+		Find(&usages).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	type row struct {
+		PromotionID   uint    `json:"promotionId"`
+		PromotionName string  `json:"promotionName"`
+		Uses          int     `json:"uses"`
+		DiscountTotal float64 `json:"discountTotal"`
+		GrossSales    float64 `json:"grossSales"`
+		NetSales      float64 `json:"netSales"`
+	}
+	rows := map[uint]*row{}
+	totalDiscount := 0.0
+	for _, u := range usages {
+		if !inRange(u.UsageDate, start, end) {
+			continue
+		}
+		if rows[u.PromotionID] == nil {
+			name := fmt.Sprintf("#%d", u.PromotionID)
+			if u.Promotion != nil {
+				name = u.Promotion.PromotionName
+			}
+			rows[u.PromotionID] = &row{PromotionID: u.PromotionID, PromotionName: name}
+		}
+		r := rows[u.PromotionID]
+		r.Uses++
+		if u.Order != nil && u.Order.Payment != nil {
+			gross, discount, _, net := services.ReceiptAmounts(u.Order, u.Order.Payment)
+			r.GrossSales += gross
+			r.NetSales += net
+			r.DiscountTotal += discount
+			totalDiscount += discount
+		}
+	}
+	out := make([]row, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DiscountTotal > out[j].DiscountTotal })
+
+	api.ListAll(c, out, gin.H{"range": reportRangeOut(start, end), "totalDiscount": round2(totalDiscount)})
+}
+
+// ======================================================
+// GET /reports/detergent-usage
+// ======================================================
+
+func GetDetergentUsageReport(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	var usage []entity.DetergentUsageHistory
+	if err := widenRange(config.DB, "created_at", start, end).
+		Preload("Detergent", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Find(&usage).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	var purchases []entity.PurchaseDetergent
+	if err := widenRange(config.DB, "created_at", start, end).Find(&purchases).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	type row struct {
+		DetergentID       uint    `json:"detergentId"`
+		Name              string  `json:"name"`
+		Type              string  `json:"type"`
+		QuantityUsed      int     `json:"quantityUsed"`
+		QuantityPurchased int     `json:"quantityPurchased"`
+		PurchaseCost      float64 `json:"purchaseCost"`
+		InStock           int     `json:"inStock"`
+	}
+	rows := map[uint]*row{}
+	get := func(id uint, d *entity.Detergent) *row {
+		if rows[id] == nil {
+			rows[id] = &row{DetergentID: id, Name: fmt.Sprintf("#%d", id)}
+		}
+		if d != nil {
+			rows[id].Name, rows[id].Type, rows[id].InStock = d.Name, d.Type, d.InStock
+		}
+		return rows[id]
+	}
+	totalUsed := 0
+	for _, u := range usage {
+		if !inRange(u.CreatedAt, start, end) {
+			continue
+		}
+		get(u.DetergentID, u.Detergent).QuantityUsed += u.QuantityUsed
+		totalUsed += u.QuantityUsed
+	}
+	totalCost := 0.0
+	for _, p := range purchases {
+		if !inRange(p.CreatedAt, start, end) {
+			continue
+		}
+		r := get(p.DetergentID, nil)
+		r.QuantityPurchased += p.Quantity
+		r.PurchaseCost += p.Price
+		totalCost += p.Price
+	}
+	out := make([]row, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].QuantityUsed > out[j].QuantityUsed })
+
+	api.ListAll(c, out, gin.H{
+		"range":         reportRangeOut(start, end),
+		"totalUsed":     totalUsed,
+		"totalPurchase": totalCost,
+	})
+}
+
+// ======================================================
+// GET /reports/complaints  (จำนวน + เวลาตอบกลับ/ปิดงานเฉลี่ย)
+// ======================================================
+
+func GetComplaintReport(c *gin.Context) {
+	start, end, err := parseReportRange(c)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	var comps []entity.Complaint
+	if err := widenRange(config.DB, "createdate", start, end).
+		Preload("Replies").
+		Preload("Histories").
+		Find(&comps).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+
+	byStatus := map[string]int{"new": 0, "in_progress": 0, "resolved": 0}
+	byDay := map[string]int{}
+	var firstResp, resolve time.Duration
+	nResp, nResolve, total := 0, 0, 0
+	for _, cp := range comps {
+		if !inRange(cp.CreateDate, start, end) {
+			continue
+		}
+		total++
+		byStatus[toUIStatus(cp.StatusComplaint)]++
+		byDay[periodKey(cp.CreateDate, "day")]++
+
+		var first *time.Time
+		for _, r := range cp.Replies {
+			if r == nil || r.AuthorType == "customer" || r.IsInternal {
+				continue
+			}
+			if first == nil || r.CreateReplyDate.Before(*first) {
+				t := r.CreateReplyDate
+				first = &t
+			}
+		}
+		if first != nil {
+			firstResp += first.Sub(cp.CreateDate)
+			nResp++
+		}
+
+		if toUIStatus(cp.StatusComplaint) == "resolved" {
+			resolvedAt := cp.UpdatedAt
+			for _, h := range cp.Histories {
+				if h != nil && toUIStatus(h.StatusNew) == "resolved" && h.ChangedDate.After(cp.CreateDate) {
+					resolvedAt = h.ChangedDate
+				}
+			}
+			resolve += resolvedAt.Sub(cp.CreateDate)
+			nResolve++
+		}
+	}
+
+	daily := make([]gin.H, 0, len(byDay))
+	for k, v := range byDay {
+		daily = append(daily, gin.H{"date": k, "count": v})
+	}
+	sort.Slice(daily, func(i, j int) bool { return daily[i]["date"].(string) < daily[j]["date"].(string) })
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":                 reportRangeOut(start, end),
+		"total":                 total,
+		"byStatus":              byStatus,
+		"daily":                 daily,
+		"avgFirstResponseHours": avgHours(firstResp, nResp),
+		"avgResolutionHours":    avgHours(resolve, nResolve),
+	})
+}
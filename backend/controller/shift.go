@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// กะการทำงาน + ตารางกะรายสัปดาห์
+// - GET/POST /shift-templates, PUT/DELETE /shift-templates/:id
+// - GET /employees/:id/roster, PUT /employees/:id/roster (แทนที่ทั้งสัปดาห์)
+// - GET /employees/on-duty?at=RFC3339   -> ใครอยู่ในเวลางาน (ใช้จ่ายงาน/มอบหมายคำร้อง)
+// ======================================================
+
+type shiftTemplateIn struct {
+	Name         string `json:"name" binding:"required"`
+	StartTime    string `json:"startTime" binding:"required"` // HH:MM
+	EndTime      string `json:"endTime" binding:"required"`   // HH:MM
+	BreakMinutes int    `json:"breakMinutes"`
+	IsActive     *bool  `json:"isActive"`
+}
+
+func (in *shiftTemplateIn) validate() string {
+	in.Name = strings.TrimSpace(in.Name)
+	in.StartTime = strings.TrimSpace(in.StartTime)
+	in.EndTime = strings.TrimSpace(in.EndTime)
+	start, err1 := services.ParseClock(in.StartTime)
+	end, err2 := services.ParseClock(in.EndTime)
+	if err1 != nil || err2 != nil {
+		return "เวลาเริ่ม/เลิกกะต้องอยู่ในรูปแบบ HH:MM"
+	}
+	if start == end {
+		return "เวลาเริ่มและเลิกกะต้องไม่ตรงกัน"
+	}
+	length := end - start
+	if length < 0 {
+		length += 24 * 60
+	}
+	if in.BreakMinutes < 0 || in.BreakMinutes >= length {
+		return "เวลาพักต้องไม่ติดลบและน้อยกว่าความยาวกะ"
+	}
+	return ""
+}
+
+var shiftTemplateList = api.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "startTime": "start_time"},
+	DefaultSort: "startTime",
+	Search:      []string{"name"},
+}
+
+func ListShiftTemplates(c *gin.Context) {
+	db := config.DB.Model(&entity.ShiftTemplate{})
+	if c.Query("all") != "true" {
+		db = db.Where("is_active = ?", true)
+	}
+	var items []entity.ShiftTemplate
+	meta, ok := shiftTemplateList.Find(c, db, &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta)
+}
+
+func CreateShiftTemplate(c *gin.Context) {
+	var in shiftTemplateIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	t := entity.ShiftTemplate{
+		Name:         in.Name,
+		StartTime:    in.StartTime,
+		EndTime:      in.EndTime,
+		BreakMinutes: in.BreakMinutes,
+		IsActive:     in.IsActive == nil || *in.IsActive,
+	}
+	if err := config.DB.Create(&t).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+func UpdateShiftTemplate(c *gin.Context) {
+	var t entity.ShiftTemplate
+	if err := config.DB.First(&t, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบกะ"))
+		return
+	}
+	var in shiftTemplateIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	if msg := in.validate(); msg != "" {
+		api.Fail(c, api.Validation(msg))
+		return
+	}
+	// การลงเวลาที่บันทึกแล้วเก็บเวลากะไว้เอง แก้กะไม่กระทบย้อนหลัง
+	t.Name = in.Name
+	t.StartTime = in.StartTime
+	t.EndTime = in.EndTime
+	t.BreakMinutes = in.BreakMinutes
+	if in.IsActive != nil {
+		t.IsActive = *in.IsActive
+	}
+	if err := config.DB.Save(&t).Error; err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func DeleteShiftTemplate(c *gin.Context) {
+	var used int64
+	config.DB.Model(&entity.EmployeeRoster{}).Where("shift_template_id = ?", c.Param("id")).Count(&used)
+	if used > 0 {
+		api.Fail(c, api.Conflict("", "กะนี้ยังอยู่ในตารางงานของพนักงาน"))
+		return
+	}
+	res := config.DB.Delete(&entity.ShiftTemplate{}, c.Param("id"))
+	if res.Error != nil {
+		api.Fail(c, api.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		api.Fail(c, api.NotFound("", "ไม่พบกะ"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+// ======================================================
+// ตารางกะรายสัปดาห์
+// ======================================================
+
+type rosterDayIn struct {
+	Weekday         int  `json:"weekday"` // 0 = อาทิตย์ ... 6 = เสาร์
+	ShiftTemplateID uint `json:"shiftTemplateId" binding:"required"`
+}
+
+type rosterIn struct {
+	Days []rosterDayIn `json:"days"` // ว่าง = ล้างตาราง
+}
+
+func findEmployeeParam(c *gin.Context) (*entity.Employee, bool) {
+	var emp entity.Employee
+	if err := config.DB.First(&emp, c.Param("id")).Error; err != nil {
+		api.Fail(c, api.NotFound("", "ไม่พบพนักงาน"))
+		return nil, false
+	}
+	return &emp, true
+}
+
+var rosterList = api.ListSpec{
+	Sorts:       map[string]string{"id": "id", "weekday": "weekday"},
+	DefaultSort: "weekday",
+}
+
+func GetEmployeeRoster(c *gin.Context) {
+	emp, ok := findEmployeeParam(c)
+	if !ok {
+		return
+	}
+	var items []entity.EmployeeRoster
+	meta, ok := rosterList.Find(c, config.DB.Model(&entity.EmployeeRoster{}).Preload("ShiftTemplate").
+		Where("employee_id = ?", emp.ID), &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta, gin.H{"employeeId": emp.ID})
+}
+
+func SetEmployeeRoster(c *gin.Context) {
+	emp, ok := findEmployeeParam(c)
+	if !ok {
+		return
+	}
+	var in rosterIn
+	if !api.BindJSON(c, &in) {
+		return
+	}
+	seen := map[int]bool{}
+	for _, d := range in.Days {
+		if d.Weekday < 0 || d.Weekday > 6 {
+			api.Fail(c, api.BadRequest("", "weekday ต้องอยู่ระหว่าง 0-6"))
+			return
+		}
+		if seen[d.Weekday] {
+			api.Fail(c, api.BadRequest("", "ระบุวันเดียวกันซ้ำ (1 วันมีได้ 1 กะ)"))
+			return
+		}
+		seen[d.Weekday] = true
+		var n int64
+		config.DB.Model(&entity.ShiftTemplate{}).Where("id = ? AND is_active = ?", d.ShiftTemplateID, true).Count(&n)
+		if n == 0 {
+			api.Fail(c, api.BadRequest("", "ไม่พบกะ #" + strconv.Itoa(int(d.ShiftTemplateID))))
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("employee_id = ?", emp.ID).Delete(&entity.EmployeeRoster{}).Error; err != nil {
+			return err
+		}
+		for _, d := range in.Days {
+			if err := tx.Create(&entity.EmployeeRoster{
+				EmployeeID:      emp.ID,
+				Weekday:         d.Weekday,
+				ShiftTemplateID: d.ShiftTemplateID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	GetEmployeeRoster(c)
+}
+
+// ======================================================
+// GET /employees/on-duty?at=&positionId=&q=
+// ======================================================
+
+var onDutyList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"positionId": {Column: "employees.position_id", Op: api.OpID},
+	},
+	Sorts:  map[string]string{"id": "employees.id", "code": "employees.code", "firstName": "employees.first_name"},
+	Search: []string{"employees.code", "employees.first_name", "employees.last_name"},
+}
+
+func ListOnDutyEmployees(c *gin.Context) {
+	at := time.Now()
+	if s := strings.TrimSpace(c.Query("at")); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			api.Fail(c, api.BadRequest("", "at ต้องเป็น RFC3339"))
+			return
+		}
+		at = t
+	}
+	onDuty, err := services.OnDutyEmployeeIDs(config.DB, at)
+	if err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	ids := make([]uint, 0, len(onDuty))
+	for id := range onDuty {
+		ids = append(ids, id)
+	}
+	// ไม่มีใครเข้ากะ: IN (NULL) ได้รายการว่าง
+	items := []entity.Employee{}
+	meta, ok := onDutyList.Find(c, config.DB.Model(&entity.Employee{}).Preload("Position").Preload("EmployeeStatus").
+		Where("employees.id IN ?", ids), &items)
+	if !ok {
+		return
+	}
+	api.List(c, items, meta, gin.H{"at": at})
+}
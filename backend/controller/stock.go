@@ -1,20 +1,25 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
-	"github.com/OnpreeyaMi/project-sa/config"	
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/services"
 	"github.com/gin-gonic/gin"
 )
+
 func CreateDetergent(c *gin.Context) {
 	var detergent entity.Detergent
-	if err := c.ShouldBindJSON(&detergent); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &detergent) {
 		return
 	}
+	detergent.UserID = c.MustGet("userID").(uint)
 
-	if err := config.DB.Create(&detergent).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := services.NewStockService(auditDB(c)).CreateDetergent(&detergent); err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
@@ -24,170 +29,202 @@ func CreateDetergent(c *gin.Context) {
 // CreateDetergentWithPurchase handles POST /detergents/purchase for adding a new detergent and its purchase record
 func CreateDetergentWithPurchase(c *gin.Context) {
 	type DetergentPurchaseRequest struct {
-		Detergent entity.Detergent           `json:"detergent"`
-		Purchase  entity.PurchaseDetergent   `json:"purchase"`
+		Detergent entity.Detergent         `json:"detergent"`
+		Purchase  entity.PurchaseDetergent `json:"purchase"`
 	}
 
 	var req DetergentPurchaseRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// 1. Create Detergent
-	if err := config.DB.Create(&req.Detergent).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &req) {
 		return
 	}
 
-	// 2. Create PurchaseDetergent (link to new Detergent)
+	// Detergent + PurchaseDetergent (ผูกกับน้ำยาใหม่) ในธุรกรรมเดียว ผู้บันทึกคือเจ้าของ token
+	uid := c.MustGet("userID").(uint)
+	req.Detergent.UserID = uid
 	purchase := req.Purchase
-	purchase.DetergentID = req.Detergent.ID
-	if err := config.DB.Create(&purchase).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	purchase.UserID = uid
+	if err := services.NewStockService(auditDB(c)).CreateWithPurchase(&req.Detergent, &purchase); err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"detergent": req.Detergent, "purchase": purchase})
 }
 
+// GET /detergents?q=&type=&categoryId=&sort=name
+// (ใช้กับ /detergents/deleted และ /detergents/type/:type ด้วย)
+var detergentList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"type":       {Column: "type", Op: api.OpEq},
+		"categoryId": {Column: "category_id", Op: api.OpID},
+	},
+	Sorts: map[string]string{
+		"id":        "id",
+		"name":      "name",
+		"inStock":   "in_stock",
+		"updatedAt": "updated_at",
+	},
+	Search: []string{"name", "type"},
+}
+
 // GetDetergents handles GET /detergents for fetching all detergents
 func GetDetergents(c *gin.Context) {
 	var detergents []entity.Detergent
-	if err := config.DB.Find(&detergents).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := detergentList.Find(c, config.DB.Model(&entity.Detergent{}), &detergents)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": detergents})
+	api.List(c, detergents, meta)
 }
 
 // Delete detergent by ID
 func DeleteDetergent(c *gin.Context) {
-	id := c.Param("id")
-	if err := config.DB.Delete(&entity.Detergent{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	id, ok := api.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	if err := services.NewStockService(auditDB(c)).DeleteDetergent(id); err != nil {
+		api.Fail(c, api.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Detergent deleted successfully"})
 }
 
+// q ค้นชื่อน้ำยา/ผู้ขาย
+var purchaseDetergentList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"detergentId": {Column: "purchase_detergents.detergent_id", Op: api.OpID},
+		"userId":      {Column: "purchase_detergents.user_id", Op: api.OpID},
+		"from":        {Column: "purchase_detergents.created_at", Op: api.OpFrom},
+		"to":          {Column: "purchase_detergents.created_at", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":        "purchase_detergents.id",
+		"quantity":  "purchase_detergents.quantity",
+		"price":     "purchase_detergents.price",
+		"createdAt": "purchase_detergents.created_at",
+	},
+	Search: []string{"detergents.name", "purchase_detergents.supplier"},
+}
+
 // ดึงประวัติการสั่งซื้อน้ำยาทั้งหมด
 func GetPurchaseDetergentHistory(c *gin.Context) {
 	var purchases []entity.PurchaseDetergent
-	if err := config.DB.Preload("Detergent").Preload("User").Find(&purchases).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := purchaseDetergentList.Find(c, config.DB.Model(&entity.PurchaseDetergent{}).
+		Joins("LEFT JOIN detergents ON detergents.id = purchase_detergents.detergent_id").
+		Preload("Detergent").Preload("User"), &purchases)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": purchases})
+	api.List(c, purchases, meta)
 }
-// ประวัติการใช้น้ำยา
+// ประวัติการใช้น้ำยา (ผู้ใช้คือเจ้าของ token)
 // POST /detergents/use
 func UseDetergent(c *gin.Context) {
 	var req struct {
-		UserID      uint   `json:"user_id"`
-		DetergentID uint   `json:"detergent_id"`
-		QuantityUsed    int    `json:"quantity_used"`
-		Reason      string `json:"reason"`
+		DetergentID  uint   `json:"detergent_id"`
+		QuantityUsed int    `json:"quantity_used"`
+		Reason       string `json:"reason"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !api.BindJSON(c, &req) {
 		return
 	}
 
-	var detergent entity.Detergent
-	if err := config.DB.First(&detergent, req.DetergentID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบสินค้า"})
+	// ลด stock + บันทึกลง DetergentUsageHistory
+	history, err := services.NewStockService(auditDB(c)).Use(services.DetergentUse{
+		UserID:      c.MustGet("userID").(uint),
+		DetergentID: req.DetergentID,
+		Quantity:    req.QuantityUsed,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		writeStockError(c, err)
 		return
 	}
-	if req.QuantityUsed <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "จำนวนต้องมากกว่า 0"})
-		return
+
+	c.JSON(http.StatusOK, gin.H{"message": "ใช้สินค้าและบันทึกประวัติเรียบร้อย", "history": history})
+}
+
+// แปลง error ของ StockService เป็นคำตอบ HTTP
+func writeStockError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDetergentNotFound):
+		api.Fail(c, api.NotFound("", "ไม่พบสินค้า"))
+	case errors.Is(err, services.ErrInvalidQuantity):
+		api.Fail(c, api.BadRequest("", "จำนวนต้องมากกว่า 0"))
+	case errors.Is(err, services.ErrOutOfStock):
+		api.Fail(c, api.BadRequest("", "สินค้าไม่เพียงพอ"))
+	default:
+		api.Fail(c, api.Internal(err))
 	}
-	if detergent.InStock < req.QuantityUsed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "สินค้าไม่เพียงพอ"})
+}
+
+// GET /detergents/usage-history?q=&detergentId=&userId=&from=&to=&sort=-createdAt
+// q ค้นชื่อน้ำยา/เหตุผล/ชื่อพนักงาน
+var detergentUsageList = api.ListSpec{
+	Filters: map[string]api.Filter{
+		"detergentId": {Column: "detergent_usage_histories.detergent_id", Op: api.OpID},
+		"userId":      {Column: "detergent_usage_histories.user_id", Op: api.OpID},
+		"from":        {Column: "detergent_usage_histories.created_at", Op: api.OpFrom},
+		"to":          {Column: "detergent_usage_histories.created_at", Op: api.OpTo},
+	},
+	Sorts: map[string]string{
+		"id":           "detergent_usage_histories.id",
+		"quantityUsed": "detergent_usage_histories.quantity_used",
+		"createdAt":    "detergent_usage_histories.created_at",
+	},
+	Search: []string{"detergents.name", "detergent_usage_histories.reason", "employees.first_name", "employees.last_name"},
+}
+
+func GetDetergentUsageHistory(c *gin.Context) {
+	var histories []entity.DetergentUsageHistory
+	meta, ok := detergentUsageList.Find(c, config.DB.Model(&entity.DetergentUsageHistory{}).
+		Joins("LEFT JOIN detergents ON detergents.id = detergent_usage_histories.detergent_id").
+		Joins("LEFT JOIN employees ON employees.user_id = detergent_usage_histories.user_id AND employees.deleted_at IS NULL").
+		Preload("User").Preload("User.Employee").Preload("Detergent"), &histories)
+	if !ok {
 		return
 	}
+	api.List(c, histories, meta)
+}
 
-	// ลด stock
-	detergent.InStock -= req.QuantityUsed
-	if err := config.DB.Save(&detergent).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ลด stock ไม่สำเร็จ"})
+// PUT /detergents/:id/update-stock (ผู้ซื้อคือเจ้าของ token)
+func UpdateDetergentStock(c *gin.Context) {
+	id, ok := api.ParamID(c, "id")
+	if !ok {
 		return
 	}
-
-	// บันทึกลง DetergentUsageHistory
-	history := entity.DetergentUsageHistory{
-		UserID: req.UserID,
-		DetergentID: req.DetergentID,
-		QuantityUsed: req.QuantityUsed,
-		Reason: req.Reason,
+	var req struct {
+		Quantity int     `json:"quantity"`
+		Price    float64 `json:"price"`    // เพิ่มราคา
+		Supplier string  `json:"supplier"` // เพิ่ม supplier
+		Image    string  `json:"image"`    // เพิ่มรูปภาพ
 	}
-	if err := config.DB.Create(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกประวัติไม่สำเร็จ"})
+	if !api.BindJSON(c, &req) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "ใช้สินค้าและบันทึกประวัติเรียบร้อย", "history": history})
-}
-// GET /detergents/usage-history
-func GetDetergentUsageHistory(c *gin.Context) {
-	var histories []entity.DetergentUsageHistory
-	if err := config.DB.Preload("User").Preload("User.Employee").Preload("Detergent").Find(&histories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// เพิ่ม stock + ประวัติการซื้อ
+	detergent, purchase, err := services.NewStockService(auditDB(c)).Restock(id, services.DetergentRestock{
+		Quantity: req.Quantity,
+		Price:    req.Price,
+		Supplier: req.Supplier,
+		UserID:   c.MustGet("userID").(uint),
+		Image:    req.Image,
+	})
+	if err != nil {
+		writeStockError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": histories})
-}
 
-// PUT /detergents/:id/update-stock
-func UpdateDetergentStock(c *gin.Context) {
-    id := c.Param("id")
-    var req struct {
-        Quantity int `json:"quantity"`
-        Price    float64 `json:"price"` // เพิ่มราคา
-        Supplier string  `json:"supplier"` // เพิ่ม supplier
-        UserID   uint    `json:"user_id"` // เพิ่มผู้ใช้
-		Image    string  `json:"image"` // เพิ่มรูปภาพ
-    }
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    var detergent entity.Detergent
-    if err := config.DB.First(&detergent, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบสินค้า"})
-        return
-    }
-
-    detergent.InStock += req.Quantity
-    if err := config.DB.Save(&detergent).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "อัพเดทจำนวนสินค้าไม่สำเร็จ"})
-        return
-    }
-
-    // เพิ่มประวัติการซื้อ
-    purchase := entity.PurchaseDetergent{
-        DetergentID: detergent.ID,
-        Quantity:    req.Quantity,
-        Price:       req.Price,
-        Supplier:    req.Supplier,
-        UserID:      req.UserID,
-		Image:       req.Image,
-    }
-    if err := config.DB.Create(&purchase).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกประวัติการซื้อไม่สำเร็จ"})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{"message": "อัพเดทจำนวนสินค้าและบันทึกประวัติเรียบร้อย", "data": detergent, "purchase": purchase})
+	c.JSON(http.StatusOK, gin.H{"message": "อัพเดทจำนวนสินค้าและบันทึกประวัติเรียบร้อย", "data": detergent, "purchase": purchase})
 }
 //ดึงรายการที่ถูกลบ
 func GetDeletedDetergents(c *gin.Context) {
 	var detergents []entity.Detergent
-	if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").Find(&detergents).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	meta, ok := detergentList.Find(c, config.DB.Unscoped().Model(&entity.Detergent{}).Where("deleted_at IS NOT NULL"), &detergents)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": detergents})
+	api.List(c, detergents, meta)
 }
\ No newline at end of file
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// การลงเวลาเข้า-ออกงาน (1 แถวต่อการเข้างาน 1 ครั้ง)
+type Attendance struct {
+	gorm.Model
+	EmployeeID uint      `gorm:"index"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID"`
+	WorkDate   string    `gorm:"size:10;index"` // วันทำงานตามกะ (YYYY-MM-DD) กะข้ามคืนนับเป็นวันที่เริ่มกะ
+
+	ShiftTemplateID *uint
+	ShiftTemplate   *ShiftTemplate `gorm:"foreignKey:ShiftTemplateID"`
+	ScheduledStart  *time.Time
+	ScheduledEnd    *time.Time
+
+	ClockInAt         time.Time
+	ClockOutAt        *time.Time
+	LateMinutes       int
+	EarlyLeaveMinutes int
+	WorkedMinutes     int
+	Note              string
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// บันทึกการเปลี่ยนข้อมูล (services/audit.go) เก็บเฉพาะคอลัมน์ที่เปลี่ยนเป็น JSON
+type AuditLog struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `gorm:"index" json:"createdAt"`
+	ActorUserID *uint     `gorm:"index" json:"actorUserId"` // nil = ระบบ/ไม่ทราบผู้ทำ
+	ActorUser   *User     `gorm:"foreignKey:ActorUserID" json:"-"`
+	ActorIP     string    `gorm:"size:64" json:"actorIp,omitempty"`
+	Route       string    `gorm:"size:128" json:"route,omitempty"`
+	EntityType  string    `gorm:"index:idx_audit_entity;size:64" json:"entity"`
+	EntityID    uint      `gorm:"index:idx_audit_entity" json:"entityId"`
+	Action      string    `gorm:"size:16" json:"action"` // create | update | delete
+	Before      string    `gorm:"column:before_json;type:text" json:"-"`
+	After       string    `gorm:"column:after_json;type:text" json:"-"`
+}
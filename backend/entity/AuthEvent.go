@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// ประวัติการยืนยันตัวตน (ไม่ใช้ soft delete; เก็บเป็น log)
+type AuthEvent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	UserID    *uint     `gorm:"index" json:"userId"`
+	User      *User     `gorm:"foreignKey:UserID" json:"-"`
+	Email     string    `gorm:"index;size:255" json:"email"`
+	EventType string    `gorm:"index;size:32" json:"eventType"` // login_success | login_failure | ...
+	Reason    string    `gorm:"size:64" json:"reason,omitempty"`
+	IP        string    `gorm:"size:64" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"userAgent"`
+	ActorID   *uint     `json:"actorId,omitempty"` // ผู้ทำรายการแทน เช่น admin ปลดล็อก
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// รอบลิ้นชักเงินสดของพนักงาน (เปิดพร้อมเงินทอนตั้งต้น -> ปิดพร้อมยอดนับจริง)
+type CashSession struct {
+	gorm.Model
+	BusinessDate string `gorm:"size:10;index"` // YYYY-MM-DD ตามเวลาไทย
+	Status       string `gorm:"size:10;index"` // open | closed
+
+	OpenedAt     time.Time
+	OpeningFloat float64
+
+	ClosedAt       *time.Time
+	CountedAmount  *float64
+	ExpectedAmount float64
+	Difference     float64 // counted - expected (บวก = เงินเกิน, ลบ = เงินขาด)
+	Note           string
+
+	EmployeeID uint      `gorm:"index"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID"`
+
+	Payments []*Payment `gorm:"foreignKey:CashSessionID"`
+}
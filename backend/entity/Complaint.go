@@ -18,6 +18,38 @@ type Complaint struct {
 	PublicID string `gorm:"column:public_id;size:50;uniqueIndex"` // คืนให้ UI ใช้อ้างอิง
 	Email    string `gorm:"column:email"`                         // อีเมลจากฟอร์ม (อาจว่างได้)
 	OrderID  *uint  `gorm:"column:order_id"`                      // เลขคำสั่งซื้อ (อาจว่าง)
+	Order    *Order `gorm:"foreignKey:OrderID;references:ID"`
+
+	// ผูกกับกระบวนการซัก / รายการผ้า / การชำระเงินของออเดอร์ (ตั้งค่าตอนสร้างคำร้อง)
+	LaundryProcessID *uint            `gorm:"column:laundry_process_id"`
+	LaundryProcess   *LaundryProcess  `gorm:"foreignKey:LaundryProcessID;references:ID"`
+	PaymentID        *uint            `gorm:"column:payment_id"`
+	Payment          *Payment         `gorm:"foreignKey:PaymentID;references:ID"`
+	SortedClothes    []*SortedClothes `gorm:"many2many:complaint_sorted_clothes;"` // รายการผ้าที่มีปัญหา
+
+	// SLA
+	Category                string     `gorm:"column:category;size:50;index"`
+	Priority                string     `gorm:"column:priority;size:20;default:normal"` // low | normal | high | urgent
+	SLAPolicyID             *uint      `gorm:"column:sla_policy_id"`
+	FirstResponseDueAt      *time.Time `gorm:"column:first_response_due_at"`
+	ResolutionDueAt         *time.Time `gorm:"column:resolution_due_at"`
+	FirstRespondedAt        *time.Time `gorm:"column:first_responded_at"`
+	ResolvedAt              *time.Time `gorm:"column:resolved_at"`
+	FirstResponseBreachedAt *time.Time `gorm:"column:first_response_breached_at"`
+	ResolutionBreachedAt    *time.Time `gorm:"column:resolution_breached_at"`
+	EscalatedAt             *time.Time `gorm:"column:escalated_at"`
+	EscalatedToID           *uint      `gorm:"column:escalated_to_id"`
+	EscalatedTo             *Employee  `gorm:"foreignKey:EscalatedToID;references:ID"`
+
+	// ผู้รับผิดชอบ (มอบหมายอัตโนมัติตามหมวด)
+	AssignedToID *uint      `gorm:"column:assigned_to_id;index"`
+	AssignedTo   *Employee  `gorm:"foreignKey:AssignedToID;references:ID"`
+	AssignedAt   *time.Time `gorm:"column:assigned_at"`
+
+	// ความพึงพอใจของลูกค้าหลังปิดงาน (1-5)
+	SatisfactionRating  *int       `gorm:"column:satisfaction_rating"`
+	SatisfactionComment string     `gorm:"column:satisfaction_comment"`
+	RatedAt             *time.Time `gorm:"column:rated_at"`
 
 	// FK -> Customer
 	CustomerID uint      `gorm:"column:customer_id;not null"`
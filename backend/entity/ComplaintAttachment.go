@@ -13,7 +13,9 @@ type ComplaintAttachment struct {
 	FileName     string `gorm:"column:file_name"`     // ชื่อไฟล์ที่บันทึก (sanitize แล้ว)
 	MimeType     string `gorm:"column:mime_type"`
 	SizeBytes    int64  `gorm:"column:size_bytes"`
-	Path         string `gorm:"column:path"`          // path ในเครื่อง เช่น ./uploads/complaints/CMP-.../xxx.png
-	URL          string `gorm:"column:url"`           // เส้นทาง Static ที่ให้ front โหลด เช่น /uploads/complaints/CMP-.../xxx.png
+	Path         string `gorm:"column:path"`          // (เดิม) path ในเครื่อง เช่น ./uploads/complaints/CMP-.../xxx.png
+	URL          string `gorm:"column:url"`           // เส้นทางดาวน์โหลด /attachments/:id (ต้องลงลายเซ็นก่อนใช้)
+	StorageKey   string `gorm:"column:storage_key"`   // key ใน BlobStore เช่น complaints/CMP-.../xxx.png
+	ThumbKey     string `gorm:"column:thumb_key"`     // รูปย่อ (เฉพาะไฟล์ภาพ)
 	UploadedAt   time.Time `gorm:"column:uploaded_at"`
 }
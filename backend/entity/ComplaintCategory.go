@@ -0,0 +1,19 @@
+package entity
+
+import "gorm.io/gorm"
+
+// หมวดคำร้องเรียน: กำหนดระดับความสำคัญเริ่มต้น และตำแหน่งที่รับผิดชอบ
+// คำร้องใหม่จะถูกมอบหมายให้พนักงาน active ในตำแหน่งนั้นแบบวนรอบ (round-robin)
+type ComplaintCategory struct {
+	gorm.Model
+	Code            string `gorm:"uniqueIndex;size:50"` // เช่น damaged_clothes (เก็บใน Complaint.Category)
+	Name            string // ชื่อที่แสดง เช่น "ผ้าเสียหาย"
+	DefaultPriority string `gorm:"size:20"` // low | normal | high | urgent
+
+	OwnerPositionID *uint
+	OwnerPosition   *Position `gorm:"foreignKey:OwnerPositionID"`
+
+	// พนักงานที่ได้รับมอบหมายล่าสุดของหมวดนี้ (ใช้หาคนถัดไป)
+	LastAssignedEmployeeID *uint
+	IsActive               bool
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "gorm.io/gorm"
+
+// นโยบาย SLA ของคำร้องเรียน (กำหนดเวลาตอบกลับครั้งแรก/ปิดงาน)
+// Category/Priority ว่าง = ใช้ได้กับทุกหมวด/ทุกระดับ
+type ComplaintSLAPolicy struct {
+	gorm.Model
+	Name     string
+	Category string `gorm:"size:50;index"`
+	Priority string `gorm:"size:20;index"` // low | normal | high | urgent
+
+	FirstResponseMinutes int
+	ResolutionMinutes    int
+	IsActive             bool
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ปิดยอดสิ้นวัน (Z-report) หลังปิดแล้วห้ามแก้ไขการชำระเงินของวันนั้น
+type DayClosing struct {
+	gorm.Model
+	BusinessDate string `gorm:"size:10;uniqueIndex"`
+	ClosedAt     time.Time
+
+	PaymentCount  int
+	TotalSales    float64
+	CashSales     float64
+	TransferSales float64
+	CashOverShort float64
+	Note          string
+
+	// พนักงานผู้ปิดยอด (nil = ผู้ดูแลระบบที่ไม่มีข้อมูลพนักงาน ดูผู้ปิดได้จาก audit log)
+	ClosedBy *uint
+	Employee *Employee `gorm:"foreignKey:ClosedBy"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ตารางกะรายสัปดาห์ของพนักงาน (1 แถว = 1 วันในสัปดาห์ที่ต้องทำงาน)
+type EmployeeRoster struct {
+	gorm.Model
+	EmployeeID uint      `gorm:"uniqueIndex:idx_roster_employee_weekday"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID"`
+	Weekday    int       `gorm:"uniqueIndex:idx_roster_employee_weekday"` // 0 = อาทิตย์ ... 6 = เสาร์
+
+	ShiftTemplateID uint
+	ShiftTemplate   *ShiftTemplate `gorm:"foreignKey:ShiftTemplateID"`
+}
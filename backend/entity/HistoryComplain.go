@@ -13,9 +13,13 @@ type HistoryComplain struct {
     Note        string    `gorm:"column:note"`
     ChangedDate time.Time `gorm:"column:changed_date"`
 
-    ChangedBy uint     `gorm:"column:changed_by"`
+    ChangedBy *uint     `gorm:"column:changed_by"` // nil = ระบบ (เช่น ตัวตรวจ SLA)
     Employee  *Employee `gorm:"foreignKey:ChangedBy;references:ID"` // <-- เปลี่ยน references:ID
 
+    // หัวหน้างานที่รับเรื่องเมื่อถูกส่งต่อ (escalate)
+    EscalatedToID *uint     `gorm:"column:escalated_to_id"`
+    EscalatedTo   *Employee `gorm:"foreignKey:EscalatedToID;references:ID"`
+
     ComplaintID uint
     Complaint   *Complaint `gorm:"foreignKey:ComplaintID;references:ID"`
 }
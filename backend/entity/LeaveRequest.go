@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// คำขอลา: อนุมัติแล้วระบบจะตั้ง EmployeeStatus เป็น onleave ตลอดช่วงลา แล้วคืนสถานะเดิมเมื่อครบกำหนด
+type LeaveRequest struct {
+	gorm.Model
+	EmployeeID uint      `gorm:"index"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID"`
+	LeaveType  string    `gorm:"size:20"`       // sick | personal | vacation
+	StartDate  string    `gorm:"size:10;index"` // YYYY-MM-DD
+	EndDate    string    `gorm:"size:10;index"` // YYYY-MM-DD (รวมวันสุดท้าย)
+	Reason     string
+
+	Status       string    `gorm:"size:20;index"` // pending | approved | rejected | cancelled
+	DecidedByID  *uint     // nil = admin ที่ไม่มีข้อมูลพนักงาน (ผู้พิจารณาดูได้จาก audit log)
+	DecidedBy    *Employee `gorm:"foreignKey:DecidedByID"`
+	DecidedAt    *time.Time
+	DecisionNote string
+
+	// สถานะพนักงานก่อนลา และเวลาที่ระบบเปลี่ยน/คืนสถานะ
+	PrevStatusID *uint
+	AppliedAt    *time.Time
+	RestoredAt   *time.Time
+}
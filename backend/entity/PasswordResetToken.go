@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// โทเค็นรีเซ็ตรหัสผ่าน: เก็บเฉพาะค่าแฮช ใช้ได้ครั้งเดียวและมีวันหมดอายุ
+type PasswordResetToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"index" json:"userId"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+	TokenHash string     `gorm:"uniqueIndex;size:64" json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
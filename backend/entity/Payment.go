@@ -38,6 +38,10 @@ type Payment struct {
 	// เวลาที่เราตรวจและยืนยันสลิปผ่าน
 	SlipVerifiedAt *time.Time `json:"slip_verified_at"`
 
+	// เงินสด: รอบลิ้นชักที่รับเงิน + พนักงานผู้รับเงิน
+	CashSessionID *uint `json:"cash_session_id" gorm:"index"`
+	ReceivedBy    *uint `json:"received_by"`
+
 	// ถ้าคุณมี History อยู่แล้วก็ใช้ต่อได้เลย
 	Histories []*History `gorm:"foreignKey:PaymentID"`
 }
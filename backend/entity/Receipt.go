@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ใบเสร็จรับเงิน/ใบกำกับภาษี (เลขที่เอกสารไม่ซ้ำ เรียงต่อเนื่องรายปี)
+type Receipt struct {
+	gorm.Model
+	DocumentNo string `gorm:"uniqueIndex;size:32"` // เช่น RC2025-000001
+	Year       int    `gorm:"index"`
+	SeqNo      int
+
+	// issued | cancelled (ยกเลิกแล้วยังคงเลขที่เดิมไว้)
+	Status       string `gorm:"size:16;default:'issued'"`
+	CancelReason string
+	CancelledAt  *time.Time
+
+	Subtotal      float64
+	Discount      float64
+	VatAmount     float64
+	Total         float64
+	PaymentMethod string
+	IssuedAt      time.Time
+	IssuedBy      *uint // EmployeeID ของผู้ออกใบเสร็จ (nil = ระบบออกอัตโนมัติ)
+
+	// ใบเสร็จที่ออกแทนใบนี้ (กรณีออกใหม่)
+	ReplacedByID *uint
+
+	OrderID   uint     `gorm:"index"`
+	Order     *Order   `gorm:"foreignKey:OrderID"`
+	PaymentID uint     `gorm:"index"`
+	Payment   *Payment `gorm:"foreignKey:PaymentID"`
+}
+
+// เลขที่เอกสารล่าสุดของแต่ละปี ใช้ล็อกในธุรกรรมเพื่อให้เลขต่อเนื่องไม่ขาดช่วง
+type DocumentSequence struct {
+	gorm.Model
+	Prefix string `gorm:"uniqueIndex:idx_doc_seq;size:8"`
+	Year   int    `gorm:"uniqueIndex:idx_doc_seq"`
+	LastNo int
+}
@@ -15,6 +15,16 @@ type ReplyComplaint struct {
     EmpID       uint     `gorm:"column:emp_id"`
     Employee *Employee `gorm:"foreignKey:EmpID;references:ID"` // <-- เปลี่ยน references:ID
 
+    // ข้อความจากลูกค้า (AuthorType = "customer") ไม่มี EmpID
+    AuthorType string    `gorm:"column:author_type;size:10;default:employee"` // employee | customer
+    CustomerID *uint     `gorm:"column:customer_id"`
+    Customer   *Customer `gorm:"foreignKey:CustomerID;references:ID"`
+    IsInternal bool      `gorm:"column:is_internal"` // บันทึกภายในของพนักงาน ลูกค้ามองไม่เห็น
+
+    // เทมเพลตที่ใช้ตอบ (ใช้วัดว่าเทมเพลตไหนปิดงานได้จริง)
+    TemplateID *uint          `gorm:"column:template_id;index"`
+    Template   *ReplyTemplate `gorm:"foreignKey:TemplateID;references:ID"`
+
     ComplaintID uint
     Complaint   *Complaint `gorm:"foreignKey:ComplaintID;references:ID"`
 }
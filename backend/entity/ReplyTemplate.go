@@ -0,0 +1,13 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ข้อความตอบกลับสำเร็จรูปสำหรับคำร้องเรียน
+// Body ใส่ตัวแปรได้ เช่น {customerName} {orderId} {publicId}
+type ReplyTemplate struct {
+	gorm.Model
+	Title    string
+	Category string `gorm:"size:50;index"` // รหัสหมวดคำร้อง (ว่าง = ใช้ได้ทุกหมวด)
+	Body     string `gorm:"type:text"`
+	IsActive bool
+}
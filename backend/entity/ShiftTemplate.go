@@ -0,0 +1,14 @@
+package entity
+
+import "gorm.io/gorm"
+
+// กะการทำงาน เช่น "กะเช้า" 08:00-17:00
+// EndTime น้อยกว่า StartTime = กะข้ามเที่ยงคืน
+type ShiftTemplate struct {
+	gorm.Model
+	Name         string
+	StartTime    string `gorm:"size:5"` // HH:MM
+	EndTime      string `gorm:"size:5"` // HH:MM
+	BreakMinutes int
+	IsActive     bool
+}
@@ -1,12 +1,20 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
 	Email    string `gorm:"uniqueIndex" json:"Email"`
 	Password string `json:"Password"` // ส่งกลับ “ค่าแฮช”
 
+	// ล็อกอินผิดติดกัน -> ล็อกชั่วคราว (รีเซ็ตเมื่อเข้าสู่ระบบสำเร็จหรือ admin ปลดล็อก)
+	FailedLoginCount int        `json:"FailedLoginCount"`
+	LockedUntil      *time.Time `json:"LockedUntil,omitempty"`
+
 	RoleID uint  `json:"RoleID"`
 	Role   *Role `gorm:"foreignKey:RoleID" json:"Role,omitempty"`
 
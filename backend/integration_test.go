@@ -0,0 +1,1579 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/controller"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/migrations"
+	"github.com/OnpreeyaMi/project-sa/openapi"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// Integration test ผ่าน router จริง + ฐานข้อมูลที่รัน migration แล้ว
+// (เลือกฐานข้อมูลด้วย TEST_DB_DRIVER / TEST_DB_DSN ดู testdb_test.go)
+// ======================================================
+
+func doJSON(t *testing.T, h http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return doJSONAs(t, h, "", method, path, body)
+}
+
+// doJSONAs แนบ Bearer token (ว่าง = ไม่แนบ)
+func doJSONAs(t *testing.T, h http.Handler, token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// login คืน token ของผู้ใช้
+func login(t *testing.T, h http.Handler, email, password string) string {
+	t.Helper()
+	w := doJSON(t, h, http.MethodPost, "/login", map[string]string{"email": email, "password": password})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login %s: status = %d, body = %s", email, w.Code, w.Body)
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	decodeJSON(t, w, &out)
+	return out.Token
+}
+
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder, out interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+func TestMigrationsDownAndUpAgain(t *testing.T) {
+	db := openTestDB(t)
+
+	for {
+		m, err := migrations.Down(db)
+		if err != nil {
+			t.Fatalf("down: %v", err)
+		}
+		if m == nil {
+			break
+		}
+	}
+	if db.Migrator().HasTable("users") {
+		t.Fatal("users table still exists after migrating down")
+	}
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	st, err := migrations.StatusOf(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range st {
+		if s.AppliedAt == nil || s.Modified {
+			t.Errorf("%04d_%s: applied=%v modified=%v", s.Version, s.Name, s.AppliedAt != nil, s.Modified)
+		}
+	}
+	var roles int64
+	db.Model(&entity.Role{}).Count(&roles)
+	if roles != 3 {
+		t.Errorf("roles = %d, want 3 (reference data)", roles)
+	}
+}
+
+func TestLoginIgnoresEmailCase(t *testing.T) {
+	openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+
+	w := doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "Customer1@Example.COM", "password": "1234"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	var out struct {
+		Token string `json:"token"`
+		Role  string `json:"role"`
+	}
+	decodeJSON(t, w, &out)
+	if out.Token == "" || out.Role != "customer" {
+		t.Errorf("unexpected login response %s", w.Body)
+	}
+}
+
+func TestLoginLocksAccountAfterRepeatedFailures(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+
+	var last int
+	for i := 0; i < 5; i++ {
+		last = doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "customer2@example.com", "password": "wrong-pass1"}).Code
+	}
+	if last != http.StatusLocked {
+		t.Fatalf("5th failure status = %d, want %d", last, http.StatusLocked)
+	}
+	var u entity.User
+	if err := db.Where("email = ?", "customer2@example.com").First(&u).Error; err != nil {
+		t.Fatal(err)
+	}
+	// ล็อกแล้วเริ่มนับใหม่
+	if u.LockedUntil == nil || u.FailedLoginCount != 0 {
+		t.Errorf("failed=%d lockedUntil=%v", u.FailedLoginCount, u.LockedUntil)
+	}
+
+	// รหัสถูกแต่ยังถูกล็อก
+	if w := doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "customer2@example.com", "password": "1234"}); w.Code != http.StatusLocked {
+		t.Errorf("login while locked = %d, want %d", w.Code, http.StatusLocked)
+	}
+}
+
+// employeeToken ให้ admin สร้างพนักงานใหม่แล้วคืน token และ id ของพนักงานคนนั้น
+func employeeToken(t *testing.T, h http.Handler, admin, email string) (string, uint) {
+	t.Helper()
+	w := doJSONAs(t, h, admin, http.MethodPost, "/employees", map[string]interface{}{
+		"Email": email, "Password": "staff1234", "FirstName": "พนักงาน",
+		"StartDate": "2025-01-01", "Status": "active", "PositionID": 1,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create employee %s: status = %d, body = %s", email, w.Code, w.Body)
+	}
+	var emp entity.Employee
+	decodeJSON(t, w, &emp)
+	return login(t, h, email, "staff1234"), emp.ID
+}
+
+func TestLoginLimitsCountFailuresAndAreLogged(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+
+	// ล็อกอินสำเร็จไม่กินโควตาต่อบัญชี (10 ครั้ง/15 นาที)
+	for i := 0; i < 12; i++ {
+		if w := doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "customer1@example.com", "password": "1234"}); w.Code != http.StatusOK {
+			t.Fatalf("login #%d: status = %d, body = %s", i+1, w.Code, w.Body)
+		}
+	}
+
+	// ล้มเหลวครบโควตาแล้วถูกจำกัดต่อบัญชี (router ใหม่ = โควตาต่อ IP ใหม่ แต่โควตาต่อบัญชีใช้ร่วมกัน)
+	r = setupRouter()
+	for i := 0; i < 10; i++ {
+		if w := doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "ghost@example.com", "password": "wrong-pass1"}); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure #%d: status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+	}
+	if w := doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "ghost@example.com", "password": "wrong-pass1"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("after 10 failures: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// เกินโควตาต่อ IP (20 ครั้ง/นาที) ถูกปฏิเสธและบันทึกลง auth_events
+	r = setupRouter()
+	for i := 0; i <= 20; i++ {
+		w := doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": fmt.Sprintf("ip%d@example.com", i), "password": "wrong-pass1"})
+		want := http.StatusUnauthorized
+		if i == 20 {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Errorf("ip request #%d: status = %d, want %d", i+1, w.Code, want)
+		}
+	}
+
+	for _, reason := range []string{"account", "ip:login"} {
+		var n int64
+		db.Model(&entity.AuthEvent{}).Where("event_type = ? AND reason = ?", controller.AuthEventRateLimited, reason).Count(&n)
+		if n != 1 {
+			t.Errorf("rate_limited events with reason %q = %d, want 1", reason, n)
+		}
+	}
+}
+
+func TestAuthAdminEndpointsRequireAdmin(t *testing.T) {
+	openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/auth-events"},
+		{http.MethodGet, "/users/locked"},
+		{http.MethodPost, "/users/2/unlock"},
+	} {
+		for _, want := range []struct {
+			token  string
+			status int
+		}{
+			{"", http.StatusUnauthorized},
+			{customer, http.StatusForbidden},
+			{admin, http.StatusOK},
+		} {
+			if w := doJSONAs(t, r, want.token, tc.method, tc.path, nil); w.Code != want.status {
+				t.Errorf("%s %s: status = %d, want %d (body %s)", tc.method, tc.path, w.Code, want.status, w.Body)
+			}
+		}
+	}
+}
+
+func TestPayCashTakesCashierFromEmployeeToken(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, empID := employeeToken(t, r, admin, "cashier@example.com")
+
+	order, err := services.NewOrderService(db).Create(services.NewOrder{CustomerID: 1, AddressID: 1, ServiceTypeIDs: []uint{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// employee_id ใน body ไม่มีผลแล้ว
+	body := map[string]interface{}{"order_id": order.ID, "employee_id": 999}
+
+	for _, tc := range []struct {
+		name, token string
+		status      int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"customer", customer, http.StatusForbidden},
+		{"admin without employee record", admin, http.StatusForbidden},
+	} {
+		if w := doJSONAs(t, r, tc.token, http.MethodPost, "/payments/cash", body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	if w := doJSONAs(t, r, staff, http.MethodPost, "/cash/sessions/open", map[string]interface{}{"openingFloat": 500}); w.Code != http.StatusCreated {
+		t.Fatalf("open cash session: status = %d, body = %s", w.Code, w.Body)
+	}
+	if w := doJSONAs(t, r, staff, http.MethodPost, "/payments/cash", body); w.Code != http.StatusOK {
+		t.Fatalf("pay cash: status = %d, body = %s", w.Code, w.Body)
+	}
+	var pay entity.Payment
+	if err := db.Where("order_id = ?", order.ID).First(&pay).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pay.PaymentStatus != services.PaymentPaid || pay.ReceivedBy == nil || *pay.ReceivedBy != empID {
+		t.Errorf("payment status=%s receivedBy=%v, want paid by %d", pay.PaymentStatus, pay.ReceivedBy, empID)
+	}
+}
+
+func TestCashDrawerRequiresStaffAndDayCloseRequiresAdmin(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, _ := employeeToken(t, r, admin, "drawer@example.com")
+
+	today := services.BusinessDate(time.Now())
+	tomorrow := services.BusinessDate(time.Now().AddDate(0, 0, 1))
+	for _, tc := range []struct {
+		name, token, method, path string
+		body                      interface{}
+		status                    int
+	}{
+		{"z-report without token", "", http.MethodGet, "/cash/z-report", nil, http.StatusUnauthorized},
+		{"z-report as customer", customer, http.MethodGet, "/cash/z-report", nil, http.StatusForbidden},
+		{"sessions as customer", customer, http.MethodGet, "/cash/sessions", nil, http.StatusForbidden},
+		{"z-report as employee", staff, http.MethodGet, "/cash/z-report", nil, http.StatusOK},
+		{"day-close as employee", staff, http.MethodPost, "/cash/day-close", map[string]string{"date": today}, http.StatusForbidden},
+		{"day-close future date", admin, http.MethodPost, "/cash/day-close", map[string]string{"date": tomorrow}, http.StatusBadRequest},
+		// ผู้ดูแลระบบไม่มีข้อมูลพนักงานก็ปิดยอดได้
+		{"day-close as admin", admin, http.MethodPost, "/cash/day-close", map[string]string{"date": today}, http.StatusCreated},
+		{"day-close twice", admin, http.MethodPost, "/cash/day-close", map[string]string{"date": today}, http.StatusConflict},
+	} {
+		if w := doJSONAs(t, r, tc.token, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	var closing entity.DayClosing
+	if err := db.Where("business_date = ?", today).First(&closing).Error; err != nil {
+		t.Fatal(err)
+	}
+	var audit entity.AuditLog
+	if err := db.Where("entity_type = ? AND entity_id = ?", "DayClosing", closing.ID).First(&audit).Error; err != nil {
+		t.Fatalf("day closing not audited: %v", err)
+	}
+	if closing.ClosedBy != nil || audit.ActorUserID == nil || *audit.ActorUserID != 1 {
+		t.Errorf("closedBy = %v, audit actor = %v, want nil employee and admin user 1", closing.ClosedBy, audit.ActorUserID)
+	}
+}
+
+func TestReceiptEndpointsCheckOwnershipAndDoNotIssue(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	owner := login(t, r, "customer1@example.com", "1234")
+	other := login(t, r, "customer2@example.com", "1234")
+
+	order, err := services.NewOrderService(db).Create(services.NewOrder{CustomerID: 1, AddressID: 1, ServiceTypeIDs: []uint{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf := fmt.Sprintf("/orders/%d/receipt.pdf", order.ID)
+	list := fmt.Sprintf("/orders/%d/receipts", order.ID)
+	reissue := fmt.Sprintf("/orders/%d/receipt/reissue", order.ID)
+
+	for _, tc := range []struct {
+		name, token, path string
+		status            int
+	}{
+		{"pdf without token", "", pdf, http.StatusUnauthorized},
+		{"pdf of other customer", other, pdf, http.StatusForbidden},
+		{"list of other customer", other, list, http.StatusForbidden},
+		{"pdf of unpaid order", owner, pdf, http.StatusConflict},
+		{"list as owner", owner, list, http.StatusOK},
+	} {
+		if w := doJSONAs(t, r, tc.token, http.MethodGet, tc.path, nil); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	// ชำระแล้วแต่ยังไม่มีใบเสร็จ: GET ไม่ออกใบให้
+	if err := db.Create(&entity.Payment{OrderID: order.ID, PaymentType: "cash", PaymentStatus: services.PaymentPaid, TotalAmount: 50, TransRef: "T-RC"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if w := doJSONAs(t, r, owner, http.MethodGet, pdf, nil); w.Code != http.StatusNotFound {
+		t.Errorf("pdf before issue: status = %d, want 404", w.Code)
+	}
+	var n int64
+	db.Model(&entity.Receipt{}).Count(&n)
+	if n != 0 {
+		t.Fatalf("GET issued %d receipts", n)
+	}
+
+	// ออกใหม่/ยกเลิกได้เฉพาะพนักงาน
+	if w := doJSONAs(t, r, owner, http.MethodPost, reissue, map[string]string{}); w.Code != http.StatusForbidden {
+		t.Errorf("reissue as customer: status = %d, want 403", w.Code)
+	}
+	if w := doJSONAs(t, r, owner, http.MethodPost, "/receipts/1/cancel", map[string]string{"reason": "x"}); w.Code != http.StatusForbidden {
+		t.Errorf("cancel as customer: status = %d, want 403", w.Code)
+	}
+	if w := doJSONAs(t, r, admin, http.MethodPost, reissue, map[string]string{}); w.Code != http.StatusCreated {
+		t.Fatalf("reissue as admin: status = %d, body = %s", w.Code, w.Body)
+	}
+	w := doJSONAs(t, r, owner, http.MethodGet, pdf, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" {
+		t.Errorf("pdf as owner: status = %d, content-type = %s", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestEmployeeComplaintActionsTakeActorFromToken(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, empID := employeeToken(t, r, admin, "support@example.com")
+
+	comp := entity.Complaint{PublicID: "CMP-T1", Title: "ผ้าหาย", StatusComplaint: "รอดำเนินการ", CustomerID: 1, CreateDate: time.Now()}
+	if err := db.Create(&comp).Error; err != nil {
+		t.Fatal(err)
+	}
+	replies := "/employee/complaints/CMP-T1/replies"
+	status := "/employee/complaints/CMP-T1/status"
+	// empId ใน body ไม่มีผลแล้ว
+	replyBody := map[string]interface{}{"text": "รับเรื่องแล้วครับ", "empId": 999}
+	statusBody := map[string]interface{}{"status": "in_progress", "empId": 999}
+
+	for _, tc := range []struct {
+		name, token, method, path string
+		body                      interface{}
+		status                    int
+	}{
+		{"list without token", "", http.MethodGet, "/employee/complaints", nil, http.StatusUnauthorized},
+		{"list as customer", customer, http.MethodGet, "/employee/complaints", nil, http.StatusForbidden},
+		{"reply without token", "", http.MethodPost, replies, replyBody, http.StatusUnauthorized},
+		{"reply as customer", customer, http.MethodPost, replies, replyBody, http.StatusForbidden},
+		{"reply as admin without employee record", admin, http.MethodPost, replies, replyBody, http.StatusForbidden},
+		{"status without token", "", http.MethodPatch, status, statusBody, http.StatusUnauthorized},
+		{"status as customer", customer, http.MethodPatch, status, statusBody, http.StatusForbidden},
+		{"reply as employee", staff, http.MethodPost, replies, replyBody, http.StatusCreated},
+		{"status as employee", staff, http.MethodPatch, status, map[string]interface{}{"status": "resolved", "empId": 999}, http.StatusOK},
+	} {
+		if w := doJSONAs(t, r, tc.token, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	var reply entity.ReplyComplaint
+	if err := db.Where("complaint_id = ?", comp.ID).First(&reply).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reply.EmpID != empID {
+		t.Errorf("reply by employee %d, want %d", reply.EmpID, empID)
+	}
+	var others int64
+	db.Model(&entity.HistoryComplain{}).Where("complaint_id = ? AND changed_by <> ?", comp.ID, empID).Count(&others)
+	if others != 0 {
+		t.Errorf("%d status changes recorded for someone other than the token's employee", others)
+	}
+}
+
+func TestLeaveDecisionRequiresSupervisorAndScheduleRoutesRequireToken(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	requester, requesterID := employeeToken(t, r, admin, "leave@example.com")
+	supervisor, supervisorID := employeeToken(t, r, admin, "approver@example.com")
+	// ตำแหน่ง 3 = หัวหน้างาน (SLA_SUPERVISOR_POSITION ค่าเริ่มต้น)
+	if err := db.Model(&entity.Employee{}).Where("id = ?", supervisorID).Update("position_id", 3).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	newLeave := func(empID uint) string {
+		lr := entity.LeaveRequest{EmployeeID: empID, LeaveType: "personal", StartDate: "2099-01-01", EndDate: "2099-01-02", Status: services.LeavePending}
+		if err := db.Create(&lr).Error; err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("/leave-requests/%d", lr.ID)
+	}
+	decide, decideByAdmin, ownLeave := newLeave(requesterID), newLeave(requesterID), newLeave(supervisorID)
+	// empId ใน body ไม่มีผลแล้ว
+	body := map[string]interface{}{"action": "approve", "empId": supervisorID}
+	shift := map[string]interface{}{"name": "เช้า", "startTime": "08:00", "endTime": "17:00"}
+	roster := fmt.Sprintf("/employees/%d/roster", requesterID)
+
+	for _, tc := range []struct {
+		name, token, method, path string
+		body                      interface{}
+		status                    int
+	}{
+		{"decide without token", "", http.MethodPatch, decide, body, http.StatusUnauthorized},
+		{"decide as customer", customer, http.MethodPatch, decide, body, http.StatusForbidden},
+		{"decide as employee", requester, http.MethodPatch, ownLeave, body, http.StatusForbidden},
+		{"decide own leave as supervisor", supervisor, http.MethodPatch, ownLeave, body, http.StatusForbidden},
+		{"list leaves without token", "", http.MethodGet, "/leave-requests", nil, http.StatusUnauthorized},
+		{"list leaves as customer", customer, http.MethodGet, "/leave-requests", nil, http.StatusForbidden},
+		{"list leaves as employee", requester, http.MethodGet, "/leave-requests", nil, http.StatusOK},
+		{"on-duty without token", "", http.MethodGet, "/employees/on-duty", nil, http.StatusUnauthorized},
+		{"on-duty as customer", customer, http.MethodGet, "/employees/on-duty", nil, http.StatusForbidden},
+		{"on-duty as employee", requester, http.MethodGet, "/employees/on-duty", nil, http.StatusOK},
+		{"get roster without token", "", http.MethodGet, roster, nil, http.StatusUnauthorized},
+		{"get roster as customer", customer, http.MethodGet, roster, nil, http.StatusForbidden},
+		{"get roster as employee", requester, http.MethodGet, roster, nil, http.StatusOK},
+		{"attendance report without token", "", http.MethodGet, "/attendance/report", nil, http.StatusUnauthorized},
+		{"attendance report as employee", requester, http.MethodGet, "/attendance/report", nil, http.StatusForbidden},
+		{"attendance report as admin", admin, http.MethodGet, "/attendance/report", nil, http.StatusOK},
+		{"list shifts without token", "", http.MethodGet, "/shift-templates", nil, http.StatusUnauthorized},
+		{"create shift without token", "", http.MethodPost, "/shift-templates", shift, http.StatusUnauthorized},
+		{"create shift as employee", supervisor, http.MethodPost, "/shift-templates", shift, http.StatusForbidden},
+		{"create shift as admin", admin, http.MethodPost, "/shift-templates", shift, http.StatusCreated},
+		{"set roster without token", "", http.MethodPut, roster, map[string]interface{}{}, http.StatusUnauthorized},
+		{"set roster as employee", supervisor, http.MethodPut, roster, map[string]interface{}{}, http.StatusForbidden},
+		{"decide as supervisor", supervisor, http.MethodPatch, decide, body, http.StatusOK},
+		{"decide as admin without employee record", admin, http.MethodPatch, decideByAdmin, body, http.StatusOK},
+	} {
+		if w := doJSONAs(t, r, tc.token, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	var bySupervisor, byAdmin entity.LeaveRequest
+	if err := db.First(&bySupervisor, strings.TrimPrefix(decide, "/leave-requests/")).Error; err != nil {
+		t.Fatal(err)
+	}
+	if bySupervisor.Status != services.LeaveApproved || bySupervisor.DecidedByID == nil || *bySupervisor.DecidedByID != supervisorID {
+		t.Errorf("leave = %s decided by %v, want approved by %d", bySupervisor.Status, bySupervisor.DecidedByID, supervisorID)
+	}
+	if err := db.First(&byAdmin, strings.TrimPrefix(decideByAdmin, "/leave-requests/")).Error; err != nil {
+		t.Fatal(err)
+	}
+	var audit entity.AuditLog
+	if err := db.Where("entity_type = ? AND entity_id = ? AND action = ?", "LeaveRequest", byAdmin.ID, "update").First(&audit).Error; err != nil {
+		t.Fatalf("admin decision not audited: %v", err)
+	}
+	if byAdmin.Status != services.LeaveApproved || byAdmin.DecidedByID != nil || audit.ActorUserID == nil || *audit.ActorUserID != 1 {
+		t.Errorf("leave = %s decided by %v (audit actor %v), want approved by admin user 1", byAdmin.Status, byAdmin.DecidedByID, audit.ActorUserID)
+	}
+}
+
+func TestExportRequiresAdminAndMatchesListFilters(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+
+	for _, cp := range []entity.Complaint{
+		{PublicID: "CMP-X1", Title: "=1+2", Description: "@SUM(A1)", StatusComplaint: services.ComplaintStatusNew, CustomerID: 1, CreateDate: time.Now()},
+		{PublicID: "CMP-X2", Title: "ผ้าหาย", StatusComplaint: services.ComplaintStatusClosed, CustomerID: 1, CreateDate: time.Now()},
+	} {
+		if err := db.Create(&cp).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []entity.Payment{
+		{PaymentType: "cash", PaymentStatus: "refunded", TotalAmount: -50, VerifiedAmount: -50, OrderID: 7, TransRef: "EXP-1"},
+		{PaymentType: "cash", PaymentStatus: "paid", TotalAmount: 80, VerifiedAmount: 80, OrderID: 8, TransRef: "EXP-2"},
+	} {
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	order, err := services.NewOrderService(db).Create(services.NewOrder{CustomerID: 1, AddressID: 1, ServiceTypeIDs: []uint{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderRow := fmt.Sprintf("\n%d,", order.ID)
+
+	if w := doJSON(t, r, http.MethodGet, "/exports/complaints", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", w.Code)
+	}
+	if w := doJSONAs(t, r, customer, http.MethodGet, "/exports/complaints", nil); w.Code != http.StatusForbidden {
+		t.Errorf("as customer: status = %d, want 403", w.Code)
+	}
+	// filter ผิดรูปแบบตอบ 400 ก่อนเริ่มเขียนไฟล์
+	if w := doJSONAs(t, r, admin, http.MethodGet, "/exports/payments?orderId=abc", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid orderId: status = %d, want 400", w.Code)
+	}
+
+	for _, tc := range []struct {
+		path          string
+		want, notWant []string
+	}{
+		// สูตรถูกเติม ' นำหน้า
+		{"/exports/complaints", []string{",'=1+2,", ",'@SUM(A1),", "CMP-X1,", "CMP-X2,"}, nil},
+		{"/exports/complaints?status=resolved", []string{"CMP-X2,"}, []string{"CMP-X1,"}},
+		{"/exports/complaints?q=cmp-x1", []string{"CMP-X1,"}, []string{"CMP-X2,"}},
+		// ยอดติดลบเป็นตัวเลข ไม่ถูกเติม '
+		{"/exports/payments?orderId=7", []string{",-50,-50,EXP-1,"}, []string{"EXP-2", "'-50"}},
+		{"/exports/payments?status=paid", []string{"EXP-2"}, []string{"EXP-1"}},
+		// ชุดที่ join ตารางอื่นเพื่อค้นหา
+		{"/exports/orders?customerId=1", []string{orderRow}, nil},
+		{"/exports/orders?customerId=2", nil, []string{orderRow}},
+		{"/exports/detergent-purchases?q=a", nil, nil},
+		{"/exports/detergent-usage?q=a", nil, nil},
+	} {
+		w := doJSONAs(t, r, admin, http.MethodGet, tc.path, nil)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body = %s", tc.path, w.Code, w.Body)
+			continue
+		}
+		body := w.Body.String()
+		for _, want := range tc.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: missing %q:\n%s", tc.path, want, body)
+			}
+		}
+		for _, bad := range tc.notWant {
+			if strings.Contains(body, bad) {
+				t.Errorf("%s: unexpected %q:\n%s", tc.path, bad, body)
+			}
+		}
+	}
+}
+
+func TestEmployeeMetricsRequireStaffAndCountLastAction(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, empA := employeeToken(t, r, admin, "rider-a@example.com")
+	_, empB := employeeToken(t, r, admin, "rider-b@example.com")
+
+	// ช่วงรายงาน = วันที่ 10 ทั้งวันตามเวลาร้าน: [start, end)
+	start, err := time.ParseInLocation("2006-01-02", "2025-03-10", config.Current().Location())
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := start.AddDate(0, 0, 1)
+	rangeQuery := "?from=2025-03-10&to=2025-03-10"
+
+	order, err := services.NewOrderService(db).Create(services.NewOrder{CustomerID: 1, AddressID: 1, ServiceTypeIDs: []uint{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue := func(typ, status string, assigns ...entity.QueueAssignment) {
+		t.Helper()
+		q := entity.Queue{Queue_type: typ, Status: status, OrderID: order.ID}
+		if err := db.Create(&q).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range assigns {
+			a.QueueID = q.ID
+			if err := db.Create(&a).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// A รับงานก่อนช่วง (อยู่ในวันที่ดึงกว้างไว้) แล้ว B กดเสร็จในช่วง = นับให้ B, รับ -> เสร็จ 4 ชม.
+	queue("pickup", "done",
+		entity.QueueAssignment{EmployeeID: empA, Assigned_time: start.Add(-2 * time.Hour)},
+		entity.QueueAssignment{EmployeeID: empB, Assigned_time: start.Add(2 * time.Hour)})
+	// กดเสร็จโดยไม่ได้กดรับ = นับงานแต่ไม่มีเวลาให้วัด
+	queue("delivery", "delivered", entity.QueueAssignment{EmployeeID: empA, Assigned_time: start.Add(3 * time.Hour)})
+	// กดเสร็จหลังช่วง = ไม่นับ
+	queue("pickup", "done", entity.QueueAssignment{EmployeeID: empB, Assigned_time: end.Add(time.Hour)})
+
+	for _, p := range []entity.LaundryProcess{
+		{Status: "เสร็จสิ้น", EmployeeID: empA, Start_time: start.Add(time.Hour), End_time: start.Add(2 * time.Hour)},
+		// อยู่ในวันที่ดึงกว้างไว้แต่นอกช่วงจริง
+		{Status: "เสร็จสิ้น", EmployeeID: empA, Start_time: start.Add(-3 * time.Hour), End_time: start.Add(-time.Hour)},
+		{Status: "เสร็จสิ้น", EmployeeID: empA, Start_time: end.Add(-time.Hour), End_time: end},
+	} {
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	comp := entity.Complaint{PublicID: "CMP-M1", Title: "ผ้าหาย", StatusComplaint: services.ComplaintStatusClosed, CustomerID: 1, CreateDate: start.Add(-time.Hour)}
+	early := entity.Complaint{PublicID: "CMP-M2", Title: "ผ้าเสีย", StatusComplaint: services.ComplaintStatusClosed, CustomerID: 1, CreateDate: start.Add(-5 * time.Hour)}
+	if err := db.Create(&comp).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&early).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []entity.HistoryComplain{
+		// ปิดซ้ำ: A ปิดก่อน ถูกเปิดใหม่ แล้ว B ปิด = นับให้ B คนเดียว (สร้าง -> ปิด 6 ชม.)
+		{ComplaintID: comp.ID, StatusOld: "กำลังดำเนินการ", StatusNew: services.ComplaintStatusClosed, ChangedBy: &empA, ChangedDate: start.Add(time.Hour)},
+		{ComplaintID: comp.ID, StatusOld: services.ComplaintStatusClosed, StatusNew: "กำลังดำเนินการ", ChangedBy: &empB, ChangedDate: start.Add(2 * time.Hour)},
+		{ComplaintID: comp.ID, StatusOld: "กำลังดำเนินการ", StatusNew: services.ComplaintStatusClosed, ChangedBy: &empB, ChangedDate: start.Add(5 * time.Hour)},
+		// ปิดก่อนช่วง = ไม่นับ
+		{ComplaintID: early.ID, StatusOld: "กำลังดำเนินการ", StatusNew: services.ComplaintStatusClosed, ChangedBy: &empA, ChangedDate: start.Add(-2 * time.Hour)},
+	} {
+		if err := db.Create(&h).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	metricsA := fmt.Sprintf("/employees/%d/metrics%s", empA, rangeQuery)
+	for _, path := range []string{metricsA, "/employees/leaderboard" + rangeQuery} {
+		if w := doJSON(t, r, http.MethodGet, path, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s without token: status = %d, want 401", path, w.Code)
+		}
+		if w := doJSONAs(t, r, customer, http.MethodGet, path, nil); w.Code != http.StatusForbidden {
+			t.Errorf("%s as customer: status = %d, want 403", path, w.Code)
+		}
+	}
+
+	type metrics struct {
+		EmployeeID          uint    `json:"employeeId"`
+		Pickups             int     `json:"pickups"`
+		Deliveries          int     `json:"deliveries"`
+		AvgAcceptToDoneMins float64 `json:"avgAcceptToDoneMinutes"`
+		ProcessesFinished   int     `json:"processesFinished"`
+		AvgProcessMins      float64 `json:"avgProcessMinutes"`
+		ComplaintsResolved  int     `json:"complaintsResolved"`
+		AvgResolutionHours  float64 `json:"avgResolutionHours"`
+	}
+	for _, tc := range []struct {
+		empID uint
+		want  metrics
+	}{
+		{empA, metrics{EmployeeID: empA, Deliveries: 1, ProcessesFinished: 1, AvgProcessMins: 60}},
+		{empB, metrics{EmployeeID: empB, Pickups: 1, AvgAcceptToDoneMins: 240, ComplaintsResolved: 1, AvgResolutionHours: 6}},
+	} {
+		w := doJSONAs(t, r, staff, http.MethodGet, fmt.Sprintf("/employees/%d/metrics%s", tc.empID, rangeQuery), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("metrics %d: status = %d, body = %s", tc.empID, w.Code, w.Body)
+		}
+		var out struct {
+			Metrics metrics `json:"metrics"`
+		}
+		decodeJSON(t, w, &out)
+		if out.Metrics != tc.want {
+			t.Errorf("metrics %d = %+v, want %+v", tc.empID, out.Metrics, tc.want)
+		}
+	}
+
+	w := doJSONAs(t, r, staff, http.MethodGet, "/employees/leaderboard"+rangeQuery+"&sort=resolved", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("leaderboard: status = %d, body = %s", w.Code, w.Body)
+	}
+	var board struct {
+		Data []struct {
+			Rank       int  `json:"rank"`
+			EmployeeID uint `json:"employeeId"`
+		} `json:"data"`
+	}
+	decodeJSON(t, w, &board)
+	if len(board.Data) != 1 || board.Data[0].EmployeeID != empB || board.Data[0].Rank != 1 {
+		t.Errorf("resolved leaderboard = %+v, want only employee %d", board.Data, empB)
+	}
+}
+
+func TestSLAPoliciesRequireAdminAndCheckEscalates(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, _ := employeeToken(t, r, admin, "sla@example.com")
+
+	policy := map[string]interface{}{"name": "ด่วนมาก", "category": "ทดสอบ", "priority": "urgent", "firstResponseMinutes": 30, "resolutionMinutes": 120}
+	for _, tc := range []struct {
+		name, token, method, path string
+		body                      interface{}
+		status                    int
+	}{
+		{"list without token", "", http.MethodGet, "/complaint-sla-policies", nil, http.StatusUnauthorized},
+		{"list as customer", customer, http.MethodGet, "/complaint-sla-policies", nil, http.StatusForbidden},
+		{"create without token", "", http.MethodPost, "/complaint-sla-policies", policy, http.StatusUnauthorized},
+		{"create as employee", staff, http.MethodPost, "/complaint-sla-policies", policy, http.StatusForbidden},
+		{"update as employee", staff, http.MethodPut, "/complaint-sla-policies/1", policy, http.StatusForbidden},
+		{"delete as customer", customer, http.MethodDelete, "/complaint-sla-policies/1", nil, http.StatusForbidden},
+		{"check without token", "", http.MethodPost, "/complaint-sla-policies/check", nil, http.StatusUnauthorized},
+		{"check as employee", staff, http.MethodPost, "/complaint-sla-policies/check", nil, http.StatusForbidden},
+		{"create invalid", admin, http.MethodPost, "/complaint-sla-policies",
+			map[string]interface{}{"name": "ผิด", "firstResponseMinutes": 90, "resolutionMinutes": 60}, http.StatusBadRequest},
+	} {
+		if w := doJSONAs(t, r, tc.token, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	w := doJSONAs(t, r, admin, http.MethodPost, "/complaint-sla-policies", policy)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %s", w.Code, w.Body)
+	}
+	var created entity.ComplaintSLAPolicy
+	decodeJSON(t, w, &created)
+
+	w = doJSONAs(t, r, staff, http.MethodGet, "/complaint-sla-policies?category=ทดสอบ", nil)
+	var list struct {
+		Data []entity.ComplaintSLAPolicy `json:"data"`
+	}
+	decodeJSON(t, w, &list)
+	if w.Code != http.StatusOK || len(list.Data) != 1 || list.Data[0].ID != created.ID {
+		t.Errorf("list as employee: status = %d, data = %+v", w.Code, list.Data)
+	}
+
+	// คำร้องตามนโยบายใหม่ที่เลยกำหนดแล้ว: ตรวจครั้งแรกพบ 1, ครั้งต่อไปไม่นับซ้ำ
+	comp := entity.Complaint{PublicID: "CMP-S1", Title: "ผ้าหาย", StatusComplaint: "รอดำเนินการ", CustomerID: 1,
+		Category: "ทดสอบ", Priority: "urgent", CreateDate: time.Now().Add(-3 * time.Hour)}
+	if err := services.ApplyComplaintSLA(db, &comp); err != nil {
+		t.Fatal(err)
+	}
+	if comp.SLAPolicyID == nil || *comp.SLAPolicyID != created.ID {
+		t.Fatalf("complaint policy = %v, want %d", comp.SLAPolicyID, created.ID)
+	}
+	if err := db.Create(&comp).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{1, 0} {
+		w := doJSONAs(t, r, admin, http.MethodPost, "/complaint-sla-policies/check", nil)
+		var out struct {
+			Breached int `json:"breached"`
+		}
+		decodeJSON(t, w, &out)
+		if w.Code != http.StatusOK || out.Breached != want {
+			t.Errorf("check #%d: status = %d, breached = %d, want %d", i+1, w.Code, out.Breached, want)
+		}
+	}
+
+	policy["isActive"] = false
+	path := fmt.Sprintf("/complaint-sla-policies/%d", created.ID)
+	if w := doJSONAs(t, r, admin, http.MethodPut, path, policy); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"IsActive":false`) {
+		t.Errorf("update: status = %d, body = %s", w.Code, w.Body)
+	}
+	if w := doJSONAs(t, r, admin, http.MethodDelete, path, nil); w.Code != http.StatusOK {
+		t.Errorf("delete: status = %d, body = %s", w.Code, w.Body)
+	}
+	if w := doJSONAs(t, r, admin, http.MethodDelete, path, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete again: status = %d, want 404", w.Code)
+	}
+}
+
+func TestComplaintCategoriesRequireAdminAndRouteRoundRobin(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, empA := employeeToken(t, r, admin, "route-a@example.com")
+	_, empB := employeeToken(t, r, admin, "route-b@example.com")
+
+	category := map[string]interface{}{"code": "lost_test", "name": "ของหาย (ทดสอบ)", "defaultPriority": "high", "ownerPositionId": 1}
+	for _, tc := range []struct {
+		name, token, method, path string
+		body                      interface{}
+		status                    int
+	}{
+		{"create without token", "", http.MethodPost, "/complaint-categories", category, http.StatusUnauthorized},
+		{"create as customer", customer, http.MethodPost, "/complaint-categories", category, http.StatusForbidden},
+		{"create as employee", staff, http.MethodPost, "/complaint-categories", category, http.StatusForbidden},
+		{"update as customer", customer, http.MethodPut, "/complaint-categories/1", category, http.StatusForbidden},
+		{"delete without token", "", http.MethodDelete, "/complaint-categories/1", nil, http.StatusUnauthorized},
+		// ฟอร์มลูกค้าใช้รายการหมวดโดยไม่ต้องเข้าสู่ระบบ
+		{"list without token", "", http.MethodGet, "/complaint-categories", nil, http.StatusOK},
+	} {
+		if w := doJSONAs(t, r, tc.token, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	w := doJSONAs(t, r, admin, http.MethodPost, "/complaint-categories", category)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %s", w.Code, w.Body)
+	}
+	var cat entity.ComplaintCategory
+	decodeJSON(t, w, &cat)
+
+	// ไม่มีใครอยู่ในกะ = วนทุกคนที่ active ในตำแหน่งตามลำดับ id
+	for i, want := range []uint{empA, empB, empA} {
+		comp := entity.Complaint{PublicID: fmt.Sprintf("CMP-R%d", i), Title: "ของหาย", StatusComplaint: "รอดำเนินการ",
+			CustomerID: 1, Category: "lost_test", CreateDate: time.Now()}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			c, err := services.ApplyComplaintCategory(tx, &comp)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&comp).Error; err != nil {
+				return err
+			}
+			return services.AssignComplaint(tx, &comp, c, time.Now())
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if comp.Priority != "high" || comp.AssignedToID == nil || *comp.AssignedToID != want {
+			t.Errorf("complaint %d: priority = %q, assigned = %v, want high/%d", i, comp.Priority, comp.AssignedToID, want)
+		}
+	}
+
+	// เปลี่ยนตำแหน่งที่รับผิดชอบ = เริ่มวนใหม่
+	category["ownerPositionId"] = 2
+	path := fmt.Sprintf("/complaint-categories/%d", cat.ID)
+	if w := doJSONAs(t, r, admin, http.MethodPut, path, category); w.Code != http.StatusOK {
+		t.Errorf("update: status = %d, body = %s", w.Code, w.Body)
+	}
+	if err := db.First(&cat, cat.ID).Error; err != nil || cat.LastAssignedEmployeeID != nil {
+		t.Errorf("round-robin pointer after owner change = %v, err = %v", cat.LastAssignedEmployeeID, err)
+	}
+	if w := doJSONAs(t, r, admin, http.MethodDelete, path, nil); w.Code != http.StatusOK {
+		t.Errorf("delete: status = %d, body = %s", w.Code, w.Body)
+	}
+	if _, err := services.FindComplaintCategory(db, "lost_test"); !errors.Is(err, services.ErrUnknownComplaintCategory) {
+		t.Errorf("deleted category still routable: err = %v", err)
+	}
+}
+
+func TestDuplicateKeyIsDetected(t *testing.T) {
+	db := openTestDB(t)
+
+	// unique constraint (genders.name มีจาก migration 0002 แล้ว)
+	err := db.Create(&entity.Gender{Name: "ชาย"}).Error
+	if !config.IsDuplicateKey(err) {
+		t.Errorf("duplicate gender: err = %v, want duplicated key", err)
+	}
+
+	// unique index (users.email)
+	if err := db.Create(&entity.User{Email: "dup@example.com", RoleID: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	err = db.Create(&entity.User{Email: "dup@example.com", RoleID: 2}).Error
+	if !config.IsDuplicateKey(err) {
+		t.Errorf("duplicate email: err = %v, want duplicated key", err)
+	}
+	if config.IsDuplicateKey(db.Create(&entity.User{Email: "other@example.com", RoleID: 2}).Error) {
+		t.Error("distinct email reported as duplicate")
+	}
+}
+
+func TestDetergentsByTypeIgnoresCase(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.Create(&entity.Detergent{Name: "ซักผ้าขาว", Type: "Liquid", InStock: 3, CategoryID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+
+	for _, typ := range []string{"liquid", "LIQUID", "Liquid"} {
+		w := doJSON(t, r, http.MethodGet, "/detergents/type/"+typ, nil)
+		var out struct {
+			Data []entity.Detergent `json:"data"`
+		}
+		decodeJSON(t, w, &out)
+		if w.Code != http.StatusOK || len(out.Data) != 1 {
+			t.Errorf("type %q: status=%d items=%d", typ, w.Code, len(out.Data))
+		}
+	}
+}
+
+func TestCreateEmployeeUpdatesPositionCountAndAudit(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+
+	for i, email := range []string{"emp1@example.com", "emp2@example.com"} {
+		w := doJSONAs(t, r, admin, http.MethodPost, "/employees", map[string]interface{}{
+			"Email": email, "Password": "staff1234", "FirstName": "พนักงาน",
+			"StartDate": "2025-01-01", "Status": "active", "PositionID": 1,
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create employee %d: status = %d, body = %s", i+1, w.Code, w.Body)
+		}
+	}
+
+	var pc entity.PositionCount
+	if err := db.Where("position_id = ?", 1).First(&pc).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pc.TotalEmployee != 2 {
+		t.Errorf("total_employee = %d, want 2", pc.TotalEmployee)
+	}
+
+	// audit อ่านได้เฉพาะ admin
+	if w := doJSON(t, r, http.MethodGet, "/audit", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("audit without token: status = %d, want 401", w.Code)
+	}
+	if w := doJSONAs(t, r, customer, http.MethodGet, "/audit", nil); w.Code != http.StatusForbidden {
+		t.Errorf("audit as customer: status = %d, want 403", w.Code)
+	}
+
+	w := doJSONAs(t, r, admin, http.MethodGet, "/audit?entity=employee&action=create", nil)
+	var audit struct {
+		Data []struct {
+			Action string `json:"action"`
+			Actor  struct {
+				UserID uint `json:"userId"`
+			} `json:"actor"`
+		} `json:"data"`
+		Meta api.PageMeta `json:"meta"`
+	}
+	decodeJSON(t, w, &audit)
+	if w.Code != http.StatusOK || len(audit.Data) != 2 || audit.Meta.Total != 2 {
+		t.Fatalf("audit: status=%d items=%d body=%s", w.Code, len(audit.Data), w.Body)
+	}
+	var adminUser entity.User
+	if err := db.Where("email = ?", "admin@example.com").First(&adminUser).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range audit.Data {
+		if item.Actor.UserID != adminUser.ID {
+			t.Errorf("audit actor = %d, want admin %d", item.Actor.UserID, adminUser.ID)
+		}
+	}
+}
+
+func TestAuditedWritesRequireToken(t *testing.T) {
+	openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	customer := login(t, r, "customer1@example.com", "1234")
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/promotions"},
+		{http.MethodDelete, "/customers/1"},
+		{http.MethodPost, "/detergents"},
+		{http.MethodPost, "/detergents/use"},
+		{http.MethodDelete, "/employees/1"},
+	} {
+		if w := doJSON(t, r, tc.method, tc.path, map[string]interface{}{}); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without token: status = %d, want 401", tc.method, tc.path, w.Code)
+		}
+		if w := doJSONAs(t, r, customer, tc.method, tc.path, map[string]interface{}{}); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s as customer: status = %d, want 403", tc.method, tc.path, w.Code)
+		}
+	}
+}
+
+func TestReportsRequireAdminAndSumPaidPayments(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, _ := employeeToken(t, r, admin, "report@example.com")
+
+	for _, path := range []string{"/reports/revenue", "/reports/orders-by-service", "/reports/turnaround",
+		"/reports/promotions", "/reports/detergent-usage", "/reports/complaints"} {
+		for _, tc := range []struct {
+			name, token string
+			status      int
+		}{
+			{"no token", "", http.StatusUnauthorized},
+			{"customer", customer, http.StatusForbidden},
+			{"employee", staff, http.StatusForbidden},
+			{"admin", admin, http.StatusOK},
+		} {
+			if w := doJSONAs(t, r, tc.token, http.MethodGet, path, nil); w.Code != tc.status {
+				t.Errorf("%s as %s: status = %d, want %d (body %s)", path, tc.name, w.Code, tc.status, w.Body)
+			}
+		}
+	}
+
+	// รวมเฉพาะที่ชำระแล้ว: เงินสด 100 + สลิป 250 (รายการรอชำระไม่นับ)
+	now := time.Now()
+	for _, p := range []entity.Payment{
+		{PaymentType: "cash", PaymentStatus: "paid", TotalAmount: 100, VerifiedAmount: 100, OrderID: 1, TransRef: "RPT-1"},
+		{PaymentType: "PromptPay", PaymentStatus: "paid", TotalAmount: 250, VerifiedAmount: 250, OrderID: 2, TransRef: "RPT-2", SlipVerifiedAt: &now},
+		{PaymentType: "PromptPay", PaymentStatus: "pending", TotalAmount: 999, OrderID: 3, TransRef: "RPT-3"},
+	} {
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := doJSONAs(t, r, admin, http.MethodGet, "/reports/revenue", nil)
+	var out struct {
+		Total        float64 `json:"total"`
+		PaymentCount int     `json:"paymentCount"`
+	}
+	decodeJSON(t, w, &out)
+	if w.Code != http.StatusOK || out.Total != 350 || out.PaymentCount != 2 {
+		t.Errorf("revenue: status = %d, total = %v, count = %d, want 350 from 2 payments", w.Code, out.Total, out.PaymentCount)
+	}
+}
+
+func TestReplyTemplatesRequireStaffAndStatsCountPerComplaint(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	admin := login(t, r, "admin@example.com", "1234")
+	customer := login(t, r, "customer1@example.com", "1234")
+	staff, empID := employeeToken(t, r, admin, "tpl@example.com")
+
+	tpl := map[string]interface{}{"title": "ขอโทษที่ล่าช้า", "category": "lost_item", "body": "ขออภัยค่ะ", "isActive": true}
+	for _, tc := range []struct {
+		name, token, method, path string
+		body                      interface{}
+		status                    int
+	}{
+		{"list without token", "", http.MethodGet, "/reply-templates", nil, http.StatusUnauthorized},
+		{"list as customer", customer, http.MethodGet, "/reply-templates", nil, http.StatusForbidden},
+		{"stats without token", "", http.MethodGet, "/reply-templates/stats", nil, http.StatusUnauthorized},
+		{"stats as customer", customer, http.MethodGet, "/reply-templates/stats", nil, http.StatusForbidden},
+		{"create as employee", staff, http.MethodPost, "/reply-templates", tpl, http.StatusForbidden},
+		{"update as employee", staff, http.MethodPut, "/reply-templates/1", tpl, http.StatusForbidden},
+		{"delete as customer", customer, http.MethodDelete, "/reply-templates/1", nil, http.StatusForbidden},
+		{"list as employee", staff, http.MethodGet, "/reply-templates", nil, http.StatusOK},
+	} {
+		if w := doJSONAs(t, r, tc.token, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tc.name, w.Code, tc.status, w.Body)
+		}
+	}
+
+	w := doJSONAs(t, r, admin, http.MethodPost, "/reply-templates", tpl)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %s", w.Code, w.Body)
+	}
+	var created entity.ReplyTemplate
+	decodeJSON(t, w, &created)
+
+	// ใช้เทมเพลตสองครั้งในเรื่องที่ปิดแล้ว (คะแนน 4) และหนึ่งครั้งในเรื่องที่ยังเปิดอยู่
+	rating := 4
+	closed := entity.Complaint{PublicID: "CMP-T1", Title: "ล่าช้า", StatusComplaint: services.ComplaintStatusClosed,
+		CustomerID: 1, CreateDate: time.Now(), SatisfactionRating: &rating}
+	open := entity.Complaint{PublicID: "CMP-T2", Title: "ล่าช้า", StatusComplaint: services.ComplaintStatusNew,
+		CustomerID: 1, CreateDate: time.Now()}
+	for _, c := range []*entity.Complaint{&closed, &open} {
+		if err := db.Create(c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, cid := range []uint{closed.ID, closed.ID, open.ID} {
+		reply := entity.ReplyComplaint{CreateReplyDate: time.Now(), Reply: "ขออภัยค่ะ", EmpID: empID,
+			ComplaintID: cid, TemplateID: &created.ID}
+		if err := db.Create(&reply).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w = doJSONAs(t, r, staff, http.MethodGet, "/reply-templates/stats", nil)
+	var stats struct {
+		Data []struct {
+			TemplateID     uint     `json:"templateId"`
+			Uses           int64    `json:"uses"`
+			Complaints     int64    `json:"complaints"`
+			Resolved       int64    `json:"resolved"`
+			ResolutionRate float64  `json:"resolutionRate"`
+			AvgRating      *float64 `json:"avgRating"`
+		} `json:"data"`
+	}
+	decodeJSON(t, w, &stats)
+	if w.Code != http.StatusOK || len(stats.Data) != 4 {
+		t.Fatalf("stats: status = %d, rows = %d, want 4 (body %s)", w.Code, len(stats.Data), w.Body)
+	}
+	got := stats.Data[0]
+	if got.TemplateID != created.ID || got.Uses != 3 || got.Complaints != 2 || got.Resolved != 1 || got.ResolutionRate != 0.5 {
+		t.Errorf("stats = %+v, want template %d used 3 times in 2 complaints, 1 resolved", got, created.ID)
+	}
+	if got.AvgRating == nil || *got.AvgRating != 4 {
+		t.Errorf("avgRating = %v, want 4", got.AvgRating)
+	}
+}
+
+func TestHealthAndReadiness(t *testing.T) {
+	openTestDB(t)
+	r := setupRouter()
+
+	if w := doJSON(t, r, http.MethodGet, "/healthz", nil); w.Code != http.StatusOK {
+		t.Errorf("healthz = %d", w.Code)
+	}
+	w := doJSON(t, r, http.MethodGet, "/readyz", nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("readyz = %d, body = %s", w.Code, w.Body)
+	}
+	if w.Header().Get("X-Request-ID") == "" {
+		t.Error("response has no X-Request-ID")
+	}
+
+	controller.SetDraining(true)
+	defer controller.SetDraining(false)
+	if w := doJSON(t, r, http.MethodGet, "/readyz", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyz while draining = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestRequestIDIsPropagated(t *testing.T) {
+	openTestDB(t)
+	r := setupRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("X-Request-ID", "trace-abc.123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get("X-Request-ID"); got != "trace-abc.123" {
+		t.Errorf("X-Request-ID = %q, want caller's id", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get("X-Request-ID"); got == "" || strings.Contains(got, " ") {
+		t.Errorf("unsafe id was not replaced: %q", got)
+	}
+}
+
+func TestMetricsExposeHTTPAndDomainValues(t *testing.T) {
+	db := openTestDB(t)
+	for _, m := range []entity.Machine{
+		{Machine_type: "washing", Machine_number: 1, Status: "available"},
+		{Machine_type: "washing", Machine_number: 2, Status: "in_use"},
+		{Machine_type: "washing", Machine_number: 3, Status: "in_use"},
+	} {
+		if err := db.Create(&m).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&entity.Queue{Queue_type: "pickup", Status: "waiting"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&entity.Detergent{Name: "น้ำยาซักผ้า", Type: "liquid", InStock: 7, CategoryID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	doJSON(t, r, http.MethodGet, "/healthz", nil)
+	doJSON(t, r, http.MethodPost, "/verify-slip-base64", map[string]string{})
+
+	w := doJSON(t, r, http.MethodGet, "/metrics", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`sa_http_request_duration_seconds_count{method="GET",route="/healthz",status="200"} `,
+		`sa_machines{status="in_use",type="washing"} 2`,
+		`sa_machines{status="available",type="washing"} 1`,
+		`sa_queues{status="waiting",type="pickup"} 1`,
+		`sa_detergent_stock{detergent="น้ำยาซักผ้า",type="liquid"} 7`,
+		`sa_payment_slip_verifications_total{reason="invalid_request",result="failed"} `,
+		`sa_domain_metrics_scrape_errors 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestErrorAndListEnvelope(t *testing.T) {
+	db := openTestDB(t)
+	for i := 0; i < 3; i++ {
+		if err := db.Create(&entity.Detergent{Name: "น้ำยา", Type: "liquid", InStock: 1, CategoryID: 1}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := setupRouter()
+
+	type errorBody struct {
+		Error     string           `json:"error"`
+		Code      string           `json:"code"`
+		Details   []api.FieldError `json:"details"`
+		RequestID string           `json:"requestId"`
+	}
+
+	// validation: รายช่อง + ภาษาตาม Accept-Language
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"a@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var e errorBody
+	decodeJSON(t, w, &e)
+	if w.Code != http.StatusBadRequest || e.Code != api.CodeValidation || e.Error != "Validation failed" || e.RequestID == "" {
+		t.Errorf("login validation: status=%d body=%s", w.Code, w.Body)
+	}
+	if len(e.Details) != 1 || e.Details[0].Field != "password" || e.Details[0].Code != "required" {
+		t.Errorf("details = %+v", e.Details)
+	}
+
+	// code เดิมของ handler ยังเป็น code, ข้อความเริ่มต้นเป็นภาษาไทย
+	w = doJSON(t, r, http.MethodPost, "/login", map[string]string{"email": "nobody@example.com", "password": "x"})
+	e = errorBody{}
+	decodeJSON(t, w, &e)
+	if w.Code != http.StatusUnauthorized || e.Code != "invalid_credentials" || e.Error != "อีเมลหรือรหัสผ่านไม่ถูกต้อง" {
+		t.Errorf("login failure: status=%d body=%s", w.Code, w.Body)
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/no-such-route", nil)
+	e = errorBody{}
+	decodeJSON(t, w, &e)
+	if w.Code != http.StatusNotFound || e.Code != "route_not_found" {
+		t.Errorf("unknown route: status=%d body=%s", w.Code, w.Body)
+	}
+
+	// รายการ: ไม่ระบุขนาด (MaxPageSize) / แบ่งหน้า / พารามิเตอร์ผิด
+	var list struct {
+		Data []entity.Detergent `json:"data"`
+		Meta api.PageMeta       `json:"meta"`
+	}
+	w = doJSON(t, r, http.MethodGet, "/detergents", nil)
+	decodeJSON(t, w, &list)
+	if w.Code != http.StatusOK || len(list.Data) != 3 || list.Meta != (api.PageMeta{Page: 1, PageSize: api.MaxPageSize, Total: 3, TotalPages: 1}) {
+		t.Errorf("all: status=%d meta=%+v items=%d", w.Code, list.Meta, len(list.Data))
+	}
+	w = doJSON(t, r, http.MethodGet, "/detergents?page=2&pageSize=2", nil)
+	decodeJSON(t, w, &list)
+	if w.Code != http.StatusOK || len(list.Data) != 1 || list.Meta != (api.PageMeta{Page: 2, PageSize: 2, Total: 3, TotalPages: 2}) {
+		t.Errorf("page 2: status=%d meta=%+v items=%d", w.Code, list.Meta, len(list.Data))
+	}
+	w = doJSON(t, r, http.MethodGet, "/audit?pageSize=0", nil)
+	e = errorBody{}
+	decodeJSON(t, w, &e)
+	if w.Code != http.StatusBadRequest || len(e.Details) != 1 || e.Details[0].Field != "pageSize" {
+		t.Errorf("bad pageSize: status=%d body=%s", w.Code, w.Body)
+	}
+}
+
+func TestListFilterSortAndSearch(t *testing.T) {
+	db := openTestDB(t)
+	mustCreate := func(v interface{}) {
+		t.Helper()
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	somchai := entity.Customer{FirstName: "Somchai", LastName: "Jaidee", PhoneNumber: "0810000001"}
+	malee := entity.Customer{FirstName: "Malee", LastName: "Rakdee", PhoneNumber: "0810000002"}
+	mustCreate(&somchai)
+	mustCreate(&malee)
+	mustCreate(&entity.Address{CustomerID: malee.ID, AddressDetails: "ที่อยู่รอง"})
+	mustCreate(&entity.Address{CustomerID: malee.ID, AddressDetails: "ที่อยู่หลัก", IsDefault: true})
+
+	// ออเดอร์ที่คัดแยกแล้วต้องไม่อยู่ในรายการรอคัดแยก
+	pending := entity.Order{CustomerID: somchai.ID, OrderNote: "100% cotton"}
+	sorted := entity.Order{CustomerID: malee.ID}
+	mustCreate(&pending)
+	mustCreate(&sorted)
+	rec := entity.SortingRecord{OrderID: sorted.ID}
+	mustCreate(&rec)
+	mustCreate(&entity.SortedClothes{SortingRecordID: rec.ID, SortedQuantity: 3})
+	r := setupRouter()
+
+	type order struct{ ID uint }
+	var orders struct {
+		Data []order      `json:"data"`
+		Meta api.PageMeta `json:"meta"`
+	}
+	w := doJSON(t, r, http.MethodGet, "/laundry-check/orders", nil)
+	decodeJSON(t, w, &orders)
+	if w.Code != http.StatusOK || len(orders.Data) != 1 || orders.Data[0].ID != pending.ID || orders.Meta.Total != 1 {
+		t.Fatalf("pending orders: status=%d body=%s", w.Code, w.Body)
+	}
+	// % ที่ผู้ใช้พิมพ์ต้องเป็นตัวอักษรธรรมดา ไม่ใช่ wildcard
+	for q, want := range map[string]int{"somchai": 1, "100%25": 1, "%25%25": 0, "malee": 0} {
+		w = doJSON(t, r, http.MethodGet, "/laundry-check/orders?q="+q, nil)
+		decodeJSON(t, w, &orders)
+		if w.Code != http.StatusOK || len(orders.Data) != want {
+			t.Errorf("q=%s: status=%d body=%s", q, w.Code, w.Body)
+		}
+	}
+
+	type customer struct {
+		ID      uint
+		Name    string
+		Address string
+	}
+	var customers struct {
+		Data []customer   `json:"data"`
+		Meta api.PageMeta `json:"meta"`
+	}
+	w = doJSON(t, r, http.MethodGet, "/laundry-check/customers?sort=-firstName&pageSize=1", nil)
+	decodeJSON(t, w, &customers)
+	if w.Code != http.StatusOK || len(customers.Data) != 1 || customers.Data[0].ID != somchai.ID || customers.Meta.TotalPages != 2 {
+		t.Errorf("sorted page: status=%d body=%s", w.Code, w.Body)
+	}
+	w = doJSON(t, r, http.MethodGet, "/laundry-check/customers?q=rak%20MAL", nil)
+	decodeJSON(t, w, &customers)
+	if len(customers.Data) != 1 || customers.Data[0].Address != "ที่อยู่หลัก" {
+		t.Errorf("search + default address: body=%s", w.Body)
+	}
+
+	var e struct {
+		Code    string           `json:"code"`
+		Details []api.FieldError `json:"details"`
+	}
+	w = doJSON(t, r, http.MethodGet, "/laundry-check/customers?sort=password", nil)
+	decodeJSON(t, w, &e)
+	if w.Code != http.StatusBadRequest || len(e.Details) != 1 || e.Details[0].Field != "sort" {
+		t.Errorf("unknown sort: status=%d body=%s", w.Code, w.Body)
+	}
+	w = doJSON(t, r, http.MethodGet, "/laundry-check/orders?customerId=abc", nil)
+	decodeJSON(t, w, &e)
+	if w.Code != http.StatusBadRequest || len(e.Details) != 1 || e.Details[0].Field != "customerId" {
+		t.Errorf("bad filter: status=%d body=%s", w.Code, w.Body)
+	}
+}
+
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := map[string]bool{}
+	for _, rt := range setupRouter().Routes() {
+		routes[rt.Method+" "+openapi.PathFromGin(rt.Path)] = true
+		if openapi.Operation(doc, rt.Method, rt.Path) == nil {
+			t.Errorf("%s %s ไม่มีใน openapi/openapi.yaml", rt.Method, rt.Path)
+		}
+	}
+	// กลับกัน: เอกสารต้องไม่มี route ที่ถูกลบไปแล้ว
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !routes[method+" "+path] {
+				t.Errorf("%s %s อยู่ในเอกสารแต่ไม่มีใน router", method, path)
+			}
+		}
+	}
+}
+
+func TestRequestValidationAgainstOpenAPI(t *testing.T) {
+	openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+
+	type errorBody struct {
+		Code    string           `json:"code"`
+		Details []api.FieldError `json:"details"`
+	}
+	cases := []struct {
+		name, method, path, body string
+		status                   int
+		code                     string
+		fields                   []string
+	}{
+		{"missing + wrong type", http.MethodPost, "/complaint-categories", `{"code":5}`, 400, api.CodeValidation, []string{"code", "name"}},
+		{"nested array item", http.MethodPost, "/laundry-checks/1", `{"Items":[{"ClothTypeName":"เสื้อ","Quantity":"two"}]}`, 400, api.CodeValidation, []string{"Items[0].Quantity"}},
+		{"query bound", http.MethodGet, "/customers?pageSize=500", "", 400, api.CodeValidation, []string{"pageSize"}},
+		{"query date", http.MethodGet, "/order-histories?from=yesterday", "", 400, api.CodeValidation, []string{"from"}},
+		{"path id", http.MethodGet, "/customers/abc", "", 400, api.CodeInvalidID, []string{"id"}},
+		{"broken json", http.MethodPost, "/complaint-categories", `{"code":`, 400, api.CodeInvalidJSON, nil},
+		{"rating range", http.MethodPost, "/customer/complaints/C-1/rating", `{"score":9}`, 400, api.CodeValidation, []string{"score"}},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var e errorBody
+		decodeJSON(t, w, &e)
+		var fields []string
+		for _, d := range e.Details {
+			fields = append(fields, d.Field)
+		}
+		if w.Code != tc.status || e.Code != tc.code || strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+			t.Errorf("%s: status=%d body=%s", tc.name, w.Code, w.Body)
+		}
+	}
+
+	// ผ่าน schema แล้ว handler ได้ body เดิมครบ (ตรวจ schema ก่อนตรวจสิทธิ์ กรณีข้างบนจึงไม่ต้องมี token)
+	admin := login(t, r, "admin@example.com", "1234")
+	w := doJSONAs(t, r, admin, http.MethodPost, "/complaint-categories", map[string]any{"code": "late", "name": "ส่งช้า"})
+	if w.Code != http.StatusCreated {
+		t.Errorf("valid body: status=%d body=%s", w.Code, w.Body)
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/openapi.json", nil)
+	var spec struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	decodeJSON(t, w, &spec)
+	if w.Code != http.StatusOK || spec.OpenAPI == "" || spec.Paths["/login"] == nil {
+		t.Errorf("openapi.json: status=%d", w.Code)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/docs", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "openapi.json") {
+		t.Errorf("docs: status=%d", w.Code)
+	}
+}
+
+type failingMailer struct{}
+
+func (failingMailer) Send(context.Context, services.MailMessage) error {
+	return errors.New("smtp: connection refused")
+}
+
+var resetTokenRe = regexp.MustCompile(`token=([0-9a-f]+)`)
+
+func TestPasswordResetFlow(t *testing.T) {
+	db := openTestDB(t)
+	if err := config.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	r := setupRouter()
+	mailer := services.NewLocalMailer("")
+	controller.SetMailer(mailer)
+	t.Cleanup(func() { controller.SetMailer(services.NewLocalMailer("")) })
+
+	const email = "customer1@example.com"
+	forgot := func(addr string) *httptest.ResponseRecorder {
+		t.Helper()
+		return doJSON(t, r, http.MethodPost, "/password/forgot", map[string]string{"email": addr})
+	}
+	// ขอลิงก์แล้วคืนโทเค็นจากอีเมลล่าสุด
+	requestToken := func() string {
+		t.Helper()
+		if w := forgot(email); w.Code != http.StatusOK {
+			t.Fatalf("forgot: status = %d, body = %s", w.Code, w.Body)
+		}
+		sent := mailer.Sent()
+		if len(sent) == 0 || sent[len(sent)-1].To != email {
+			t.Fatalf("no reset mail sent to %s: %+v", email, sent)
+		}
+		m := resetTokenRe.FindStringSubmatch(sent[len(sent)-1].Body)
+		if m == nil {
+			t.Fatalf("no reset link in mail:\n%s", sent[len(sent)-1].Body)
+		}
+		return m[1]
+	}
+	reset := func(token, pw string) *httptest.ResponseRecorder {
+		t.Helper()
+		return doJSON(t, r, http.MethodPost, "/password/reset", map[string]string{"token": token, "newPassword": pw})
+	}
+	wantInvalid := func(name string, w *httptest.ResponseRecorder) {
+		t.Helper()
+		var e struct {
+			Code string `json:"code"`
+		}
+		decodeJSON(t, w, &e)
+		if w.Code != http.StatusBadRequest || e.Code != "reset_token_invalid" {
+			t.Errorf("%s: status = %d, body = %s, want 400 reset_token_invalid", name, w.Code, w.Body)
+		}
+	}
+
+	// อีเมลที่ไม่มีในระบบ / ส่งอีเมลไม่สำเร็จ ได้คำตอบเดียวกับกรณีปกติ
+	known := forgot(email)
+	unknown := forgot("nobody@example.com")
+	controller.SetMailer(failingMailer{})
+	failed := forgot(email)
+	controller.SetMailer(mailer)
+	for name, w := range map[string]*httptest.ResponseRecorder{"unknown email": unknown, "mail failure": failed} {
+		if w.Code != known.Code || w.Body.String() != known.Body.String() {
+			t.Errorf("%s: %d %s, want same as known email: %d %s", name, w.Code, w.Body, known.Code, known.Body)
+		}
+	}
+	if n := len(mailer.Sent()); n != 1 {
+		t.Errorf("%d mails sent, want 1 (unknown email must not get mail)", n)
+	}
+
+	// ขอใหม่แล้วลิงก์เก่าใช้ไม่ได้; ลิงก์ใหม่ใช้ได้ครั้งเดียว
+	old := requestToken()
+	token := requestToken()
+	wantInvalid("superseded token", reset(old, "Laundry2025x"))
+	if w := reset(token, "Laundry2025x"); w.Code != http.StatusOK {
+		t.Fatalf("reset: status = %d, body = %s", w.Code, w.Body)
+	}
+	login(t, r, email, "Laundry2025x")
+	wantInvalid("reused token", reset(token, "Laundry2026y"))
+
+	// หมดอายุ
+	token = requestToken()
+	if err := db.Model(&entity.PasswordResetToken{}).Where("used_at IS NULL").
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	wantInvalid("expired token", reset(token, "Laundry2026y"))
+
+	// เปลี่ยนรหัสผ่านเองแล้ว ลิงก์ที่ค้างอยู่ใช้ไม่ได้
+	token = requestToken()
+	session := login(t, r, email, "Laundry2025x")
+	if w := doJSONAs(t, r, session, http.MethodPost, "/me/password",
+		map[string]string{"oldPassword": "Laundry2025x", "newPassword": "Laundry2026y"}); w.Code != http.StatusOK {
+		t.Fatalf("change password: status = %d, body = %s", w.Code, w.Body)
+	}
+	wantInvalid("token after password change", reset(token, "Laundry2027z"))
+	login(t, r, email, "Laundry2026y")
+}
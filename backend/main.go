@@ -1,32 +1,200 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/OnpreeyaMi/project-sa/api"
 	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
     "github.com/gin-gonic/gin"
     "github.com/OnpreeyaMi/project-sa/controller"
 	"github.com/OnpreeyaMi/project-sa/middlewares"
-	 "github.com/joho/godotenv"
+	"github.com/OnpreeyaMi/project-sa/openapi"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"gorm.io/gorm"
 	
 )
 
-const port = 8000
+// ตารางที่เก็บ audit log การแก้ข้อมูลฝั่ง admin
+var auditedModels = []interface{}{
+	&entity.Promotion{}, &entity.PromotionCondition{},
+	&entity.Employee{}, &entity.Customer{}, &entity.User{},
+	&entity.Detergent{}, &entity.ServiceType{},
+	&entity.DayClosing{}, &entity.LeaveRequest{},
+}
 
 func main() {
-	
-	_ = godotenv.Load() // โหลด .env จากโฟลเดอร์เดียวกับ binary/โค้ด
-	config.ConnectDatabase()
-	config.SetupDatabase()
+	// ค่าตั้งค่าทั้งหมด (env / .env / config.yaml) ผิดตรงไหนแจ้งแล้วไม่เปิด
+	settings, err := config.Load()
+	if err != nil {
+		fatal("config", err)
+	}
+	services.SetupLogging(os.Stderr, services.ParseLogLevel(settings.Log.Level))
+	if settings.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+	if _, err := openapi.Load(); err != nil {
+		fatal("openapi", err)
+	}
+	if err := config.ConnectDatabase(); err != nil {
+		fatal("database", err)
+	}
+	if err := config.SetupDatabase(); err != nil {
+		fatal("database", err)
+	}
+	if err := services.RegisterAuditCallbacks(config.DB, auditedModels...); err != nil {
+		fatal("audit", err)
+	}
+	if settings.Features.Metrics {
+		if err := services.RegisterDomainMetrics(func() *gorm.DB { return config.DB }); err != nil {
+			fatal("metrics", err)
+		}
+	}
+	services.SetShopLocation(settings.Location())
+
+	store, err := services.NewBlobStore(settings.Blob)
+	if err != nil {
+		fatal("blob store", err)
+	}
+	controller.SetAttachmentStore(store)
+
+	mailer, err := services.NewMailer(settings.Mail)
+	if err != nil {
+		fatal("mailer", err)
+	}
+	controller.SetMailer(mailer)
+
+	// งานเบื้องหลัง (หยุดพร้อมเซิร์ฟเวอร์)
+	jobs := services.NewJobs()
+	if settings.Features.SLAChecker {
+		services.StartComplaintSLAChecker(jobs, config.DB, settings.Features.SLACheckInterval)
+	}
+	if settings.Features.LeaveStatusSync {
+		services.StartLeaveStatusSync(jobs, config.DB, 15*time.Minute)
+	}
 
-	router := gin.Default()
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", settings.Port),
+		Handler:           setupRouter(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := serve(srv, jobs, settings.DrainDelay, settings.ShutdownTimeout); err != nil {
+		fatal("server", err)
+	}
+}
+
+// serve รันเซิร์ฟเวอร์จนได้ SIGINT/SIGTERM แล้วปิดแบบรอคำขอที่ค้าง:
+// /readyz ตอบ 503 -> รอ drainDelay ให้ load balancer ถอดออก -> หยุดรับ connection ใหม่และรอคำขอเดิม (รวม export ที่ stream อยู่) -> หยุดงานเบื้องหลัง -> ปิดฐานข้อมูล
+func serve(srv *http.Server, jobs *services.Jobs, drainDelay, timeout time.Duration) error {
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("server started", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return err
+		}
+	case <-stop.Done():
+	}
+	cancel() // สัญญาณครั้งที่สอง = ปิดทันทีตามปกติของ Go
+
+	slog.Info("shutting down", "drainDelay", drainDelay.String(), "timeout", timeout.String())
+	controller.SetDraining(true)
+	ctx, done := context.WithTimeout(context.Background(), timeout)
+	defer done()
+	// ยังรับคำขอใหม่ระหว่างนี้ จนกว่า load balancer จะเห็น /readyz 503
+	select {
+	case <-time.After(drainDelay):
+	case <-ctx.Done():
+	}
+
+	var errs []error
+	if err := srv.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http: %w", err))
+	}
+	if err := jobs.Stop(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("background jobs: %w", err))
+	}
+	if sqlDB, err := config.DB.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("database: %w", err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+func fatal(what string, err error) {
+	slog.Error(what+": "+err.Error())
+	os.Exit(1)
+}
+
+// setupRouter ประกอบ route ทั้งหมด (ใช้ทั้งตอนรันจริงและใน integration test)
+func setupRouter() *gin.Engine {
+	router := gin.New()
 	_ = router.SetTrustedProxies(nil)
+	router.Use(middlewares.RequestID(), middlewares.RequestLogger(), middlewares.Metrics(), middlewares.Recovery())
 	router.Use(CORSMiddleware())
+	// ตรวจ path/query/body กับเอกสาร OpenAPI ก่อนถึง handler
+	if config.Current().Features.RequestValidation {
+		router.Use(middlewares.ValidateRequest(openapi.MustLoad()))
+	}
+	router.NoRoute(func(c *gin.Context) { api.Fail(c, api.NewError(http.StatusNotFound, "route_not_found")) })
 
-	// Login
-	router.POST("/login", controller.Login)
+	// health/readiness (container orchestrator)
+	router.GET("/healthz", controller.Healthz)
+	router.GET("/readyz", controller.Readyz)
+	if config.Current().Features.Metrics {
+		router.GET("/metrics", gin.WrapH(services.MetricsHandler()))
+	}
+	// เอกสาร API (OpenAPI 3)
+	router.GET("/openapi.json", openapi.Spec)
+	router.GET("/docs", openapi.Docs)
+
+	// Login (จำกัดจำนวนครั้งต่อ IP; ต่อบัญชีตรวจใน controller.Login)
+	authLimiter := services.NewRateLimiter(20, time.Minute)
+	router.POST("/login", middlewares.RateLimitByIP("login", authLimiter, controller.RecordIPRateLimited), controller.Login)
+	router.POST("/token/refresh", middlewares.AuthMiddleware(), controller.RefreshToken)
+	router.POST("/logout", middlewares.AuthMiddleware(), controller.Logout)
 
 	router.POST("/register", controller.Register)
+	// รหัสผ่าน (ทุก role)
+	router.POST("/password/forgot", middlewares.RateLimitByIP("password", authLimiter, controller.RecordIPRateLimited), controller.ForgotPassword)
+	router.POST("/password/reset", middlewares.RateLimitByIP("password", authLimiter, controller.RecordIPRateLimited), controller.ResetPassword)
+	router.POST("/me/password", middlewares.AuthMiddleware(), controller.ChangeMyPassword)
+	// admin: audit log การแก้ข้อมูล / ประวัติการเข้าสู่ระบบ / ปลดล็อกบัญชี
+	adminRoutes := router.Group("", middlewares.AuthMiddleware(), middlewares.AdminOnly())
+	{
+		adminRoutes.GET("/audit", controller.ListAuditLogs)
+		adminRoutes.GET("/audit/entities", controller.ListAuditEntities)
+		adminRoutes.GET("/auth-events", controller.ListAuthEvents)
+		adminRoutes.GET("/users/locked", controller.ListLockedUsers)
+		adminRoutes.POST("/users/:id/unlock", controller.UnlockUser)
+	}
 	// TimeSlot
 	router.GET("/timeslots", controller.GetTimeSlots)
 
@@ -40,28 +208,46 @@ func main() {
 		customerRoutes.PUT("/addresses/:id", controller.UpdateAddress)
 		customerRoutes.PUT("/addresses/:id/main", controller.SetMainAddress)
 		customerRoutes.DELETE("/addresses/:id", controller.DeleteAddress)
+		// คำร้องเรียนของฉัน
+		customerRoutes.GET("/complaints", controller.ListMyComplaints)
+		customerRoutes.POST("/complaints", controller.CreateComplaint)
+		customerRoutes.GET("/complaints/:publicId", controller.GetMyComplaint)
+		customerRoutes.POST("/complaints/:publicId/messages", controller.PostMyComplaintMessage)
+		customerRoutes.POST("/complaints/:publicId/attachments", controller.AddComplaintAttachments)
+		customerRoutes.POST("/complaints/:publicId/rating", controller.RateMyComplaint)
 	}
 	
 	
 	
 	// Convenience endpoint for employee (need token)
 	router.GET("/employee/me", middlewares.AuthMiddleware(), controller.GetEmployeeMe)
+	router.PUT("/employee/me", middlewares.AuthMiddleware(), controller.UpdateEmployeeMe)
+	// ลงเวลาเข้า-ออกงาน + คำขอลาของพนักงานที่ล็อกอินอยู่
+	me := router.Group("/employee/me", middlewares.AuthMiddleware())
+	{
+		me.POST("/clock-in", controller.ClockIn)
+		me.POST("/clock-out", controller.ClockOut)
+		me.GET("/attendance", controller.GetMyAttendance)
+		me.POST("/leaves", controller.CreateMyLeave)
+		me.GET("/leaves", controller.ListMyLeaves)
+		me.POST("/leaves/:id/cancel", controller.CancelMyLeave)
+	}
 
 	// Admin customers
 	adminCustomerRoutes := router.Group("/customers")
 	{
-		adminCustomerRoutes.POST("", controller.CreateCustomer)
+		adminCustomerRoutes.POST("", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.CreateCustomer)
 		adminCustomerRoutes.GET("", controller.GetCustomers)
 		adminCustomerRoutes.GET("/:id", controller.GetCustomerByID)
-		adminCustomerRoutes.PUT("/:id", controller.UpdateCustomer)
-		adminCustomerRoutes.DELETE("/:id", controller.DeleteCustomer)
+		adminCustomerRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.UpdateCustomer)
+		adminCustomerRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.DeleteCustomer)
 	}
 
 	// Promotion CRUD
-	router.POST("/promotions", controller.CreatePromotion)
+	router.POST("/promotions", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.CreatePromotion)
 	router.GET("/promotions", controller.GetPromotions)
-	router.PUT("/promotions/:id", controller.UpdatePromotion)
-	router.DELETE("/promotions/:id", controller.DeletePromotion)
+	router.PUT("/promotions/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.UpdatePromotion)
+	router.DELETE("/promotions/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.DeletePromotion)
 	router.GET("/promotion-usages", controller.GetPromotionUsages)
 	router.POST("/promotion-usages", controller.CreatePromotionUsage)
 
@@ -76,14 +262,14 @@ func main() {
 	router.PUT("/addresses/set-main", controller.UpdateMainAddress)
 
 	// Detergents
-	router.POST("/detergents", controller.CreateDetergent)
-	router.POST("/detergents/purchase", controller.CreateDetergentWithPurchase)
+	router.POST("/detergents", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.CreateDetergent)
+	router.POST("/detergents/purchase", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.CreateDetergentWithPurchase)
 	router.GET("/detergents", controller.GetDetergents)
-	router.DELETE("/detergents/:id", controller.DeleteDetergent)
+	router.DELETE("/detergents/:id", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.DeleteDetergent)
 	router.GET("/detergents/purchase-history", controller.GetPurchaseDetergentHistory)
-	router.POST("/detergents/use", controller.UseDetergent)
+	router.POST("/detergents/use", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.UseDetergent)
 	router.GET("/detergents/usage-history", controller.GetDetergentUsageHistory)
-	router.PUT("/detergents/:id/update-stock", controller.UpdateDetergentStock)
+	router.PUT("/detergents/:id/update-stock", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.UpdateDetergentStock)
 	router.GET("/detergents/deleted", controller.GetDeletedDetergents) // ดึงรายการที่ถูกลบ
 
 	// router.POST("/detergents", controller.CreateDetergent)
@@ -92,11 +278,28 @@ func main() {
 	// router.DELETE("/detergents/:id", controller.DeleteDetergent)
 
 	// Employee CRUD
-	router.POST("/employees", controller.CreateEmployee)
+	router.POST("/employees", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.CreateEmployee)
 	router.GET("/employees", controller.ListEmployees)
 	router.GET("/employees/:id", controller.GetEmployee)
-	router.PUT("/employees/:id", controller.UpdateEmployee)
-	router.DELETE("/employees/:id", controller.DeleteEmployee)
+	router.PUT("/employees/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.UpdateEmployee)
+	router.DELETE("/employees/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.DeleteEmployee)
+	router.GET("/employees/on-duty", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.ListOnDutyEmployees)
+	router.GET("/employees/leaderboard", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.GetEmployeeLeaderboard)
+	router.GET("/employees/:id/metrics", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.GetEmployeeMetrics)
+	router.GET("/employees/:id/roster", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.GetEmployeeRoster)
+	router.PUT("/employees/:id/roster", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.SetEmployeeRoster)
+
+	// กะการทำงาน / การลา / รายงานเข้างาน
+	shift := router.Group("/shift-templates", middlewares.AuthMiddleware(), middlewares.StaffOnly())
+	{
+		shift.GET("", controller.ListShiftTemplates)
+		shift.POST("", middlewares.AdminOnly(), controller.CreateShiftTemplate)
+		shift.PUT("/:id", middlewares.AdminOnly(), controller.UpdateShiftTemplate)
+		shift.DELETE("/:id", middlewares.AdminOnly(), controller.DeleteShiftTemplate)
+	}
+	router.GET("/leave-requests", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.ListLeaveRequests)
+	router.PATCH("/leave-requests/:id", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.DecideLeaveRequest)
+	router.GET("/attendance/report", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.AttendanceReport)
 
 	// Laundry Check (employee)
 	router.POST("/laundry-checks/:orderId", controller.UpsertLaundryCheck)
@@ -140,41 +343,111 @@ func main() {
 	// router.GET("/orders/latest", middlewares.AuthRequired().controller.GetLatestOrderForCustomer)
 	router.GET("/orders/latest/:customer_id", controller.GetLatestOrderForCustomer)
 	router.POST("/verify-slip-base64", controller.VerifySlipBase64)
-	router.POST("/payments/cash", controller.PayByCashSimple)
+	router.POST("/payments/cash", middlewares.AuthMiddleware(), controller.PayByCashSimple)
+	router.POST("/payments/:id/collect-cash", middlewares.AuthMiddleware(), controller.CollectCashPayment)
+	// Receipt / ใบกำกับภาษี
+	router.GET("/orders/:id/receipt.pdf", middlewares.AuthMiddleware(), controller.GetOrderReceiptPDF)
+	router.GET("/orders/:id/receipts", middlewares.AuthMiddleware(), controller.ListOrderReceipts)
+	router.POST("/orders/:id/receipt/reissue", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.ReissueOrderReceipt)
+	router.POST("/receipts/:id/cancel", middlewares.AuthMiddleware(), middlewares.StaffOnly(), controller.CancelReceipt)
+
+	// Cash drawer / ปิดยอดสิ้นวัน (พนักงาน; ปิดยอดเฉพาะผู้ดูแลระบบ)
+	cash := router.Group("/cash")
+	cash.Use(middlewares.AuthMiddleware(), middlewares.StaffOnly())
+	{
+		cash.POST("/sessions/open", controller.OpenCashSession)
+		cash.GET("/sessions/current", controller.GetCurrentCashSession)
+		cash.POST("/sessions/:id/close", controller.CloseCashSession)
+		cash.GET("/sessions", controller.ListCashSessions)
+		cash.GET("/z-report", controller.GetZReport)
+		cash.POST("/day-close", middlewares.AdminOnly(), controller.CloseBusinessDay)
+	}
+
+	// Reports (admin dashboard) ?from=YYYY-MM-DD&to=YYYY-MM-DD
+	reports := router.Group("/reports", middlewares.AuthMiddleware(), middlewares.AdminOnly())
+	{
+		reports.GET("/revenue", controller.GetRevenueReport)
+		reports.GET("/orders-by-service", controller.GetOrdersByServiceReport)
+		reports.GET("/turnaround", controller.GetTurnaroundReport)
+		reports.GET("/promotions", controller.GetPromotionCostReport)
+		reports.GET("/detergent-usage", controller.GetDetergentUsageReport)
+		reports.GET("/complaints", controller.GetComplaintReport)
+	}
+
+	// Export CSV/Excel: orders | payments | detergent-purchases | detergent-usage | complaints
+	router.GET("/exports/:dataset", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.ExportDataset)
 
 	//complaintCreate
-	// ให้ไฟล์แนบถูกเสิร์ฟแบบสาธารณะ
+	// ไฟล์แนบไม่เสิร์ฟแบบสาธารณะแล้ว ต้องใช้ลิงก์ลงลายเซ็นที่ได้จาก API
 	
-	router.POST("/complaints", controller.CreateComplaint)
-	router.POST("/complaints/:publicId/attachments", controller.AddComplaintAttachments)
-	router.Static("/uploads", "./uploads")
+	router.POST("/complaints", middlewares.AuthMiddleware(), controller.CreateComplaint)
+	router.POST("/complaints/:publicId/attachments", middlewares.AuthMiddleware(), controller.AddComplaintAttachments)
+	router.GET("/attachments/:id", controller.ServeAttachment)
 	
 	//complaintReply
 	emp := router.Group("/employee")
+	emp.Use(middlewares.AuthMiddleware(), middlewares.StaffOnly())
 	{
 		emp.GET("/complaints", controller.ListComplaintsForEmployee)
 		emp.GET("/complaints/:publicId", controller.GetComplaintDetail)
 		emp.GET("/complaints/:publicId/replies", controller.ListReplies)
+		emp.GET("/complaints/:publicId/timeline", controller.GetComplaintTimeline)
 		emp.POST("/complaints/:publicId/replies", controller.AddReplyToComplaint)
 		emp.PATCH("/complaints/:publicId/status", controller.SetComplaintStatus)
 		emp.GET("/complaints/:publicId/attachments", controller.ListComplaintAttachments) // (option)
+		emp.GET("/complaints/:publicId/reply-templates", controller.ListComplaintReplyTemplates)
 	}
 
-	router.POST("/queues/:id/assign_timeslot", controller.AssignTimeSlotToQueue) // assign timeslot ให้คิว
-	router.POST("/queues/:id/accept", controller.AcceptQueue)
+	// SLA คำร้องเรียน (พนักงานดูได้, แก้ไข/สั่งตรวจเฉพาะผู้ดูแลระบบ)
+	sla := router.Group("/complaint-sla-policies", middlewares.AuthMiddleware(), middlewares.StaffOnly())
+	{
+		sla.GET("", controller.ListSLAPolicies)
+		sla.POST("", middlewares.AdminOnly(), controller.CreateSLAPolicy)
+		sla.PUT("/:id", middlewares.AdminOnly(), controller.UpdateSLAPolicy)
+		sla.DELETE("/:id", middlewares.AdminOnly(), controller.DeleteSLAPolicy)
+		sla.POST("/check", middlewares.AdminOnly(), controller.RunSLACheck)
+	}
 
+	// หมวดคำร้องเรียน (ระดับความสำคัญเริ่มต้น + ตำแหน่งที่รับผิดชอบ; รายการเปิดให้ฟอร์มลูกค้า แก้ไขเฉพาะผู้ดูแลระบบ)
+	cat := router.Group("/complaint-categories")
+	{
+		cat.GET("", controller.ListComplaintCategories)
+		cat.POST("", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.CreateComplaintCategory)
+		cat.PUT("/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.UpdateComplaintCategory)
+		cat.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.AdminOnly(), controller.DeleteComplaintCategory)
+	}
 
+	// เทมเพลตตอบกลับคำร้องเรียน
+	tpl := router.Group("/reply-templates", middlewares.AuthMiddleware(), middlewares.StaffOnly())
+	{
+		tpl.GET("", controller.ListReplyTemplates)
+		tpl.GET("/stats", controller.ReplyTemplateStats)
+		tpl.POST("", middlewares.AdminOnly(), controller.CreateReplyTemplate)
+		tpl.PUT("/:id", middlewares.AdminOnly(), controller.UpdateReplyTemplate)
+		tpl.DELETE("/:id", middlewares.AdminOnly(), controller.DeleteReplyTemplate)
+	}
 
-	// รัน server
-	router.Run(fmt.Sprintf(":%d", port))
+	router.POST("/queues/:id/assign_timeslot", controller.AssignTimeSlotToQueue) // assign timeslot ให้คิว
+	router.POST("/queues/:id/accept", controller.AcceptQueue)
 
+	return router
 }
 
+// CORS ตาม CORS_ALLOWED_ORIGINS (ตอบ origin ที่อนุญาตกลับไปตรง ๆ เพราะใช้ credentials ร่วมกับ "*" ไม่ได้)
 func CORSMiddleware() gin.HandlerFunc {
+	allowed := map[string]bool{}
+	for _, o := range config.Current().CORS.AllowedOrigins {
+		allowed[strings.TrimSuffix(o, "/")] = true
+	}
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		origin := c.GetHeader("Origin")
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin != "" && (allowed[origin] || allowed["*"]) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -184,4 +457,3 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-
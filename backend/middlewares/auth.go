@@ -4,42 +4,41 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtSecret = []byte("your_secret_key") 
+// secret เดียวกับที่ controller ใช้ออก token (JWT_SECRET)
+func jwtSecret() []byte { return []byte(config.Current().Auth.JWTSecret) }
 
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid token"})
-			c.Abort()
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "missing_token"))
 			return
 		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
+			return jwtSecret(), nil
 		})
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token"))
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token"))
 			return
 		}
 
 		userID, ok := claims["user_id"].(float64)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in token"})
-			c.Abort()
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token"))
 			return
 		}
 		
@@ -49,3 +48,23 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// แนบ userID ถ้ามี token ที่ถูกต้อง แต่ไม่บังคับ (ใช้กับ endpoint ที่ทั้งลูกค้าและพนักงานเรียกได้)
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			token, err := jwt.Parse(strings.TrimPrefix(authHeader, "Bearer "), func(token *jwt.Token) (interface{}, error) {
+				return jwtSecret(), nil
+			})
+			if err == nil && token.Valid {
+				if claims, ok := token.Claims.(jwt.MapClaims); ok {
+					if userID, ok := claims["user_id"].(float64); ok {
+						c.Set("userID", uint(userID))
+					}
+				}
+			}
+		}
+		c.Next()
+	}
+}
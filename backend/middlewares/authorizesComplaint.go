@@ -5,8 +5,9 @@ import (
     "github.com/gin-gonic/gin"
     "strings"
     "errors"
-    "os"
-    
+
+    "github.com/OnpreeyaMi/project-sa/api"
+    "github.com/OnpreeyaMi/project-sa/config"
     "github.com/golang-jwt/jwt/v5"
     
 )
@@ -29,14 +30,14 @@ func Authorizes() gin.HandlerFunc {
         auth := c.GetHeader("Authorization")
         parts := strings.Fields(auth)
         if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Incorrect Format of Authorization Token"})
+            api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token"))
             return
         }
         tokenStr := parts[1]
 
-        secret := os.Getenv("JWT_SECRET")
+        secret := config.Current().Auth.JWTSecret
         if secret == "" {
-            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "server jwt secret is not set"})
+            api.Fail(c, api.NewError(http.StatusInternalServerError, "server_not_configured"))
             return
         }
 
@@ -47,13 +48,13 @@ func Authorizes() gin.HandlerFunc {
             return []byte(secret), nil
         })
         if err != nil {
-            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token").WithCause(err))
             return
         }
 
         claims, ok := token.Claims.(*jwtClaims)
         if !ok || !token.Valid {
-            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+            api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token"))
             return
         }
 
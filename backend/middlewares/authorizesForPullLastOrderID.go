@@ -2,9 +2,10 @@ package middlewares
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -29,15 +30,15 @@ func extractToken(c *gin.Context) (string, bool) {
 }
 
 func AuthRequired() gin.HandlerFunc {
-	secret := strings.TrimSpace(os.Getenv("JWT_SECRET"))
 	return func(c *gin.Context) {
+		secret := config.Current().Auth.JWTSecret
 		tokenStr, ok := extractToken(c)
 		if !ok || tokenStr == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing_token"})
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "missing_token"))
 			return
 		}
 		if secret == "" {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "server_not_configured"})
+			api.Fail(c, api.NewError(http.StatusInternalServerError, "server_not_configured"))
 			return
 		}
 
@@ -51,13 +52,13 @@ func AuthRequired() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_token"))
 			return
 		}
 
 		// ต้องมี customer_id > 0
 		if claims.CustomerID == 0 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid_customer"})
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "invalid_customer"))
 			return
 		}
 
@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics เก็บ latency/สถานะของทุกคำขอ แยกตาม route template (ไม่ตรง route = "unmatched")
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := services.HTTPRequestStarted()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(route, c.Request.Method, c.Writer.Status())
+	}
+}
@@ -0,0 +1,209 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateRequest ตรวจ path/query/body ของคำขอกับเอกสาร OpenAPI ก่อนถึง handler
+// - หา operation จาก route template ของ gin (c.FullPath) ไม่ match path ซ้ำ
+// - route ที่ไม่มีในเอกสาร/ไม่ตรง route ผ่านไปตามเดิม (NoRoute ตอบ 404 เอง)
+// - ตรวจ body เฉพาะ JSON; multipart/form ให้ handler ตรวจเอง (ไม่อ่านไฟล์แนบเข้าหน่วยความจำซ้ำ)
+// - ไม่ตรวจ security ที่นี่ (AuthMiddleware/OptionalAuth ตรวจ token เอง)
+// - operation ที่มี x-skip-request-validation: true ข้ามไป (handler ตรวจเอง)
+// ไม่ผ่าน = 400 validation_failed พร้อม details รายช่อง แบบเดียวกับ api.BindJSON
+func ValidateRequest(doc *openapi3.T) gin.HandlerFunc {
+	// ข้อความ error ไม่แนบ schema/ค่าที่ส่งมา (กันรหัสผ่านหลุดลง log)
+	openapi3.SchemaErrorDetailsDisabled = true
+
+	routes := map[string]*routers.Route{}
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			if skip, _ := op.Extensions["x-skip-request-validation"].(bool); skip {
+				continue
+			}
+			routes[method+" "+path] = &routers.Route{Spec: doc, Path: path, PathItem: item, Method: method, Operation: op}
+		}
+	}
+	return func(c *gin.Context) {
+		route := routes[c.Request.Method+" "+openapi.PathFromGin(c.FullPath())]
+		if route == nil {
+			c.Next()
+			return
+		}
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		opts := &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		}
+
+		// ShouldBindJSON ไม่สน Content-Type: ไม่ส่งมาถือเป็น JSON เหมือนกัน
+		req := c.Request
+		switch ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); {
+		case ct == "" && route.Operation.RequestBody != nil:
+			req = req.Clone(req.Context())
+			req.Header.Set("Content-Type", "application/json")
+		case ct != "" && ct != "application/json":
+			opts.ExcludeRequestBody = true
+		}
+
+		err := openapi3filter.ValidateRequest(req.Context(), &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: params,
+			Route:      route,
+			Options:    opts,
+		})
+		c.Request.Body = req.Body // ตัวตรวจอ่าน body ไปแล้วใส่กลับให้ใน req
+		if err != nil {
+			api.Fail(c, requestError(err))
+			return
+		}
+		c.Next()
+	}
+}
+
+// แปลง error ของ openapi3filter เป็น api.Error (details ใช้รหัสกฎเดียวกับ validator เช่น required/min/type)
+func requestError(err error) *api.Error {
+	var details []api.FieldError
+	var badID string
+	status, code := http.StatusBadRequest, api.CodeValidation
+
+	var walk func(err error, field string)
+	walk = func(err error, field string) {
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, sub := range e {
+				walk(sub, field)
+			}
+		case *openapi3filter.RequestError:
+			switch {
+			case e.Parameter != nil && e.Parameter.In == openapi3.ParameterInPath && schemaType(e.Parameter.Schema) == "integer":
+				badID = e.Parameter.Name // เลข id ใน path ผิด = invalid_id เหมือน handler
+			case e.Parameter != nil:
+				switch e.Err.(type) {
+				case *openapi3.SchemaError, openapi3.MultiError:
+					walk(e.Err, e.Parameter.Name)
+				default:
+					if errors.Is(e.Err, openapi3filter.ErrInvalidRequired) {
+						details = append(details, api.RuleError(e.Parameter.Name, "required", ""))
+					} else {
+						details = append(details, api.RuleError(e.Parameter.Name, "type", schemaType(e.Parameter.Schema)))
+					}
+				}
+			case e.RequestBody != nil && e.Err == nil:
+				status, code = http.StatusUnsupportedMediaType, "" // Content-Type ไม่ตรงที่ endpoint รับ
+			case e.RequestBody != nil:
+				if _, ok := e.Err.(*openapi3filter.ParseError); ok || errors.Is(e.Err, openapi3filter.ErrInvalidRequired) {
+					code = api.CodeInvalidJSON
+					return
+				}
+				walk(e.Err, "")
+			}
+		case *openapi3.SchemaError:
+			rule, param := schemaRule(e)
+			details = append(details, api.RuleError(fieldName(field, e.JSONPointer()), rule, param))
+		}
+	}
+	walk(err, "")
+
+	if badID != "" && len(details) == 0 {
+		return api.InvalidID(badID).WithCause(err)
+	}
+	return api.NewError(status, code).WithCause(err).WithDetails(details...)
+}
+
+// กฎของ JSON Schema -> รหัสกฎแบบ validator + param สำหรับข้อความ
+func schemaRule(e *openapi3.SchemaError) (string, string) {
+	s := e.Schema
+	switch e.SchemaField {
+	case "type", "nullable":
+		return "type", schemaTypeOf(s)
+	case "minimum":
+		return "min", number(s.Min)
+	case "maximum":
+		return "max", number(s.Max)
+	case "exclusiveMinimum":
+		return "gt", number(s.Min)
+	case "exclusiveMaximum":
+		return "lt", number(s.Max)
+	case "minLength":
+		return "min", strconv.FormatUint(s.MinLength, 10)
+	case "maxLength":
+		return "max", count(s.MaxLength)
+	case "minItems":
+		return "min", strconv.FormatUint(s.MinItems, 10)
+	case "maxItems":
+		return "max", count(s.MaxItems)
+	case "enum":
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		return "oneof", strings.Join(values, " ")
+	case "format":
+		if s.Format == "date" || s.Format == "email" {
+			return s.Format, ""
+		}
+	}
+	return e.SchemaField, ""
+}
+
+func schemaType(ref *openapi3.SchemaRef) string {
+	if ref == nil {
+		return ""
+	}
+	return schemaTypeOf(ref.Value)
+}
+
+func schemaTypeOf(s *openapi3.Schema) string {
+	if s == nil || s.Type == nil {
+		return ""
+	}
+	return strings.Join(s.Type.Slice(), "|")
+}
+
+func number(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func count(n *uint64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatUint(*n, 10)
+}
+
+// ["Items","0","Quantity"] -> Items[0].Quantity (ต่อท้ายชื่อ parameter ถ้ามี)
+func fieldName(field string, pointer []string) string {
+	var b strings.Builder
+	b.WriteString(field)
+	for _, p := range pointer {
+		if _, err := strconv.Atoi(p); err == nil {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
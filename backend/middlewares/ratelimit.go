@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitByIP จำกัดจำนวนคำขอต่อ IP (ใช้กับ endpoint ยืนยันตัวตน เช่น /login)
+// name ใช้แยกโควตาของแต่ละกลุ่ม endpoint ที่ใช้ limiter ตัวเดียวกัน
+// onLimited (ถ้ามี) ถูกเรียกก่อนตอบ 429 เช่น บันทึก auth_events
+func RateLimitByIP(name string, l *services.RateLimiter, onLimited func(c *gin.Context, name string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := l.Allow(name+"|"+c.ClientIP(), time.Now())
+		if !ok {
+			secs := int(wait/time.Second) + 1
+			slog.WarnContext(c.Request.Context(), "rate limited", "limiter", name, "ip", c.ClientIP(), "path", c.FullPath())
+			if onLimited != nil {
+				onLimited(c, name)
+			}
+			c.Header("Retry-After", strconv.Itoa(secs))
+			api.Fail(c, api.NewError(http.StatusTooManyRequests, ""))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// ตรวจ role ของผู้ใช้ (ใช้ต่อจาก AuthMiddleware)
+// - อ่าน role จากฐานข้อมูลทุกครั้ง (ถูกถอดสิทธิ์แล้ว token เก่าใช้ต่อไม่ได้)
+// - ไม่มี userID = 401, role ไม่อยู่ในรายการ = 403
+// - ผ่านแล้วตั้ง "role" ใน context ให้ handler ใช้ต่อ
+// ======================================================
+
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, r := range roles {
+		allowed[r] = true
+	}
+	return func(c *gin.Context) {
+		uidVal, ok := c.Get("userID")
+		if !ok {
+			api.Fail(c, api.NewError(http.StatusUnauthorized, "missing_token"))
+			return
+		}
+		role, err := services.UserRole(config.DB, uidVal.(uint))
+		if err != nil {
+			api.Fail(c, api.Internal(err))
+			return
+		}
+		if !allowed[role] {
+			api.Fail(c, api.NewError(http.StatusForbidden, ""))
+			return
+		}
+		c.Set("role", role)
+		c.Next()
+	}
+}
+
+// เฉพาะผู้ดูแลระบบ
+func AdminOnly() gin.HandlerFunc { return RequireRole(services.RoleAdmin) }
+
+// พนักงานหรือผู้ดูแลระบบ
+func StaffOnly() gin.HandlerFunc { return RequireRole(services.RoleAdmin, services.RoleEmployee) }
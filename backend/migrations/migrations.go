@@ -0,0 +1,339 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// Migration ฐานข้อมูลแบบมีเวอร์ชัน (แทน AutoMigrate ตอนเปิดเซิร์ฟเวอร์)
+// - ไฟล์ SQL อยู่ใน migrations/<dialect>/NNNN_ชื่อ.up.sql (+ .down.sql ถ้าย้อนได้)
+// - เวอร์ชันที่รันแล้วเก็บในตาราง schema_migrations พร้อม checksum
+// - เดินหน้าอย่างเดียว: ห้ามแก้ไฟล์ที่รันไปแล้ว ให้เพิ่มไฟล์เวอร์ชันใหม่แทน
+//   (ตรวจ checksum ทุกครั้งที่ up / status)
+// - ทุกเวอร์ชันต้องมีไฟล์ครบทุก dialect (sqlite, postgres, mysql)
+// - MySQL commit DDL ทันที ถ้าไฟล์ไหนพังกลางทางต้องเก็บกวาดเองก่อนรันซ้ำ
+// ======================================================
+
+//go:embed sqlite/*.sql postgres/*.sql mysql/*.sql
+var files embed.FS
+
+const versionTable = "schema_migrations"
+
+// ฐานข้อมูลเดิมที่สร้างด้วย AutoMigrate + MockData มีทั้งโครงสร้าง (0001) และข้อมูลอ้างอิง (0002) อยู่แล้ว
+const baselineVersion = 2
+
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string // ว่าง = ย้อนไม่ได้
+	Checksum string
+}
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+	Modified  bool // ไฟล์ถูกแก้หลังรันไปแล้ว
+}
+
+var ErrNoDown = errors.New("migration นี้ไม่มีไฟล์ down ย้อนไม่ได้")
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load อ่าน migration ของ dialect (ชื่อจาก gorm เช่น "sqlite") เรียงตามเวอร์ชัน
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("ไม่มี migration สำหรับฐานข้อมูล %q", dialect)
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("ชื่อไฟล์ migration ไม่ถูกต้อง: %s", e.Name())
+		}
+		v, _ := strconv.Atoi(m[1])
+		b, err := files.ReadFile(path.Join(dialect, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[v]
+		if mig == nil {
+			mig = &Migration{Version: v, Name: m[2]}
+			byVersion[v] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %04d มีหลายชื่อ: %s, %s", v, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(b)
+			sum := sha256.Sum256(b)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(b)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s ไม่มีไฟล์ up", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+type appliedRow struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func ensureVersionTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+}
+
+func applied(db *gorm.DB) (map[int]appliedRow, error) {
+	var rows []appliedRow
+	if err := db.Table(versionTable).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int]appliedRow, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+func recordVersion(db *gorm.DB, m Migration, at time.Time) error {
+	return db.Table(versionTable).Create(map[string]interface{}{
+		"version": m.Version, "name": m.Name, "checksum": m.Checksum, "applied_at": at,
+	}).Error
+}
+
+// ฐานข้อมูลที่เคยรันด้วย AutoMigrate (มีตารางครบแต่ยังไม่มี schema_migrations) -> บันทึกว่ารันถึง baseline แล้ว
+func baseline(db *gorm.DB, all []Migration, done map[int]appliedRow) (bool, error) {
+	if len(done) > 0 || len(all) == 0 || !db.Migrator().HasTable("users") {
+		return false, nil
+	}
+	for _, t := range createdTables(all[0].Up) {
+		if !db.Migrator().HasTable(t) {
+			return false, fmt.Errorf("ฐานข้อมูลเดิมไม่มีตาราง %s (สร้างจากโค้ดรุ่นเก่า) กรุณาสร้างฐานข้อมูลใหม่", t)
+		}
+	}
+	now := time.Now()
+	return true, db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range all {
+			if m.Version > baselineVersion {
+				break
+			}
+			if err := recordVersion(tx, m, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var createTable = regexp.MustCompile("(?i)CREATE TABLE (?:IF NOT EXISTS )?[`\"]?([a-z0-9_]+)")
+
+func createdTables(sql string) []string {
+	var out []string
+	for _, m := range createTable.FindAllStringSubmatch(sql, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// โหลด migration + สถานะ (ตรวจว่าไม่มีเวอร์ชันในฐานข้อมูลที่โค้ดนี้ไม่รู้จัก)
+func prepare(db *gorm.DB) ([]Migration, map[int]appliedRow, error) {
+	all, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureVersionTable(db); err != nil {
+		return nil, nil, err
+	}
+	done, err := applied(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok, err := baseline(db, all, done); err != nil {
+		return nil, nil, err
+	} else if ok {
+		if done, err = applied(db); err != nil {
+			return nil, nil, err
+		}
+	}
+	known := make(map[int]bool, len(all))
+	for _, m := range all {
+		known[m.Version] = true
+	}
+	for v, r := range done {
+		if !known[v] {
+			return nil, nil, fmt.Errorf("ฐานข้อมูลอยู่ที่ migration %04d_%s ซึ่งโค้ดนี้ไม่มี (โค้ดเก่ากว่าฐานข้อมูล?)", v, r.Name)
+		}
+	}
+	return all, done, nil
+}
+
+// StatusOf สถานะทุก migration
+func StatusOf(db *gorm.DB) ([]Status, error) {
+	all, done, err := prepare(db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(all))
+	for _, m := range all {
+		s := Status{Migration: m}
+		if r, ok := done[m.Version]; ok {
+			at := r.AppliedAt
+			s.AppliedAt = &at
+			s.Modified = r.Checksum != m.Checksum
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Pending migration ที่ยังไม่ได้รัน
+func Pending(db *gorm.DB) ([]Migration, error) {
+	st, err := StatusOf(db)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for _, s := range st {
+		if s.AppliedAt == nil {
+			out = append(out, s.Migration)
+		}
+	}
+	return out, nil
+}
+
+// Up รัน migration ที่ค้างทั้งหมดตามลำดับ (ทีละไฟล์ในธุรกรรมของตัวเอง)
+func Up(db *gorm.DB) ([]Migration, error) {
+	st, err := StatusOf(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range st {
+		if s.Modified {
+			return nil, fmt.Errorf("migration %04d_%s ถูกแก้หลังรันไปแล้ว ให้เพิ่ม migration ใหม่แทนการแก้ไฟล์เดิม", s.Version, s.Name)
+		}
+	}
+	var ran []Migration
+	for _, s := range st {
+		if s.AppliedAt != nil {
+			continue
+		}
+		m := s.Migration
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, m.Up); err != nil {
+				return err
+			}
+			return recordVersion(tx, m, time.Now())
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Down ย้อน migration ล่าสุดที่รันไปแล้ว 1 เวอร์ชัน (nil = ไม่มีอะไรให้ย้อน)
+func Down(db *gorm.DB) (*Migration, error) {
+	st, err := StatusOf(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(st) - 1; i >= 0; i-- {
+		if st[i].AppliedAt == nil {
+			continue
+		}
+		m := st[i].Migration
+		if strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("%04d_%s: %w", m.Version, m.Name, ErrNoDown)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, m.Down); err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM "+versionTable+" WHERE version = ?", m.Version).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("migration %04d_%s (down): %w", m.Version, m.Name, err)
+		}
+		return &m, nil
+	}
+	return nil, nil
+}
+
+func execScript(tx *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// แยกคำสั่ง SQL ด้วย ; (ไม่นับ ; ในสตริงหรือคอมเมนต์ --) เพื่อไม่ต้องพึ่ง multi-statement ของ driver
+func splitStatements(script string) []string {
+	var (
+		out   []string
+		cur   strings.Builder
+		quote rune
+	)
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			out = append(out, s)
+		}
+		cur.Reset()
+	}
+	rs := []rune(script)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case quote != 0:
+			cur.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			cur.WriteRune(r)
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			cur.WriteRune('\n')
+		case r == ';':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return out
+}
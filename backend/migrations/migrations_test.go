@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ทุก dialect ต้องมี migration ชุดเดียวกัน (เวอร์ชัน + ชื่อ + มี down เหมือนกัน)
+func TestDialectsHaveSameMigrations(t *testing.T) {
+	type key struct {
+		Version int
+		Name    string
+		HasDown bool
+	}
+	list := func(dialect string) []key {
+		ms, err := Load(dialect)
+		if err != nil {
+			t.Fatalf("%s: %v", dialect, err)
+		}
+		out := make([]key, 0, len(ms))
+		for _, m := range ms {
+			out = append(out, key{m.Version, m.Name, m.Down != ""})
+		}
+		return out
+	}
+	want := list("sqlite")
+	for _, d := range []string{"postgres", "mysql"} {
+		if got := list(d); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s migrations = %v, want %v (same as sqlite)", d, got, want)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment; not a statement
+CREATE TABLE "a" ("x" text);
+INSERT INTO a VALUES ('semi;colon', 'it''s');  -- trailing; comment
+INSERT INTO ` + "`b`" + ` VALUES ("q;q")`
+	want := []string{
+		`CREATE TABLE "a" ("x" text)`,
+		`INSERT INTO a VALUES ('semi;colon', 'it''s')`,
+		"INSERT INTO `b` VALUES (\"q;q\")",
+	}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements =\n%q\nwant\n%q", got, want)
+	}
+}
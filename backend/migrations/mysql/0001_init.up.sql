@@ -0,0 +1,796 @@
+-- 0001 โครงสร้างตั้งต้น (mysql; ตรงกับ sqlite/0001_init.up.sql)
+-- ไม่สร้าง FOREIGN KEY: โค้ดหลายจุดใช้ id = 0 แทนค่าว่าง และ SQLite ก็ไม่ได้บังคับ foreign key อยู่แล้ว
+
+CREATE TABLE `genders` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` varchar(191) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_genders_deleted_at` (`deleted_at`),
+    CONSTRAINT `uni_genders_name` UNIQUE (`name`)
+);
+
+CREATE TABLE `roles` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_roles_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `users` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `email` varchar(191),
+    `password` longtext,
+    `failed_login_count` bigint,
+    `locked_until` datetime(3) NULL,
+    `role_id` bigint unsigned,
+    `purchase_detergent` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_users_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_users_email` (`email`)
+);
+
+CREATE TABLE `customers` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `first_name` longtext,
+    `last_name` longtext,
+    `phone_number` longtext,
+    `gender_id` bigint unsigned,
+    `user_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_customers_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `addresses` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `address_details` longtext,
+    `latitude` double,
+    `longitude` double,
+    `is_default` boolean,
+    `customer_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_addresses_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `cloth_types` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `type_name` varchar(191) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_cloth_types_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_cloth_types_type_name` (`type_name`)
+);
+
+CREATE TABLE `orders` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `customer_id` bigint unsigned,
+    `order_image` longtext,
+    `order_note` longtext,
+    `address_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_orders_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `positions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `position_name` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_positions_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `employee_statuses` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `status_name` longtext,
+    `status_description` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_employee_statuses_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `employees` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `code` varchar(16),
+    `first_name` longtext,
+    `last_name` longtext,
+    `phone` longtext,
+    `gender` longtext,
+    `start_date` datetime(3) NULL,
+    `user_id` bigint unsigned,
+    `position_id` bigint unsigned,
+    `employee_status_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_employees_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_employees_code` (`code`)
+);
+
+CREATE TABLE `sorting_records` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `sorting_date` datetime(3) NULL,
+    `sorting_note` longtext,
+    `order_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_sorting_records_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `laundry_processes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `status` longtext,
+    `end_time` datetime(3) NULL,
+    `start_time` datetime(3) NULL,
+    `description` longtext,
+    `employee_id` bigint unsigned,
+    `sorting_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_laundry_processes_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `cash_sessions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `business_date` varchar(10),
+    `status` varchar(10),
+    `opened_at` datetime(3) NULL,
+    `opening_float` double,
+    `closed_at` datetime(3) NULL,
+    `counted_amount` double,
+    `expected_amount` double,
+    `difference` double,
+    `note` longtext,
+    `employee_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_cash_sessions_deleted_at` (`deleted_at`),
+    INDEX `idx_cash_sessions_business_date` (`business_date`),
+    INDEX `idx_cash_sessions_status` (`status`),
+    INDEX `idx_cash_sessions_employee_id` (`employee_id`)
+);
+
+CREATE TABLE `payments` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `payment_type` longtext,
+    `check_payment_b64` text,
+    `total_amount` bigint,
+    `payment_status` longtext,
+    `order_id` bigint unsigned,
+    `trans_ref` varchar(64),
+    `verified_amount` bigint,
+    `slip_date` datetime(3) NULL,
+    `slip_verified_at` datetime(3) NULL,
+    `cash_session_id` bigint unsigned,
+    `received_by` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_payments_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_payments_trans_ref` (`trans_ref`),
+    INDEX `idx_payments_cash_session_id` (`cash_session_id`)
+);
+
+CREATE TABLE `complaints` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `status_complaint` longtext,
+    `title` longtext,
+    `description` longtext,
+    `createdate` datetime(3) NULL,
+    `public_id` varchar(50),
+    `email` longtext,
+    `order_id` bigint unsigned,
+    `laundry_process_id` bigint unsigned,
+    `payment_id` bigint unsigned,
+    `category` varchar(50),
+    `priority` varchar(20) DEFAULT 'normal',
+    `sla_policy_id` bigint unsigned,
+    `first_response_due_at` datetime(3) NULL,
+    `resolution_due_at` datetime(3) NULL,
+    `first_responded_at` datetime(3) NULL,
+    `resolved_at` datetime(3) NULL,
+    `first_response_breached_at` datetime(3) NULL,
+    `resolution_breached_at` datetime(3) NULL,
+    `escalated_at` datetime(3) NULL,
+    `escalated_to_id` bigint unsigned,
+    `assigned_to_id` bigint unsigned,
+    `assigned_at` datetime(3) NULL,
+    `satisfaction_rating` bigint,
+    `satisfaction_comment` longtext,
+    `rated_at` datetime(3) NULL,
+    `customer_id` bigint unsigned NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_complaints_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_complaints_public_id` (`public_id`),
+    INDEX `idx_complaints_category` (`category`),
+    INDEX `idx_complaints_assigned_to_id` (`assigned_to_id`)
+);
+
+CREATE TABLE `service_types` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `type` longtext,
+    `price` double,
+    `capacity` bigint,
+    PRIMARY KEY (`id`),
+    INDEX `idx_service_types_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `sorted_clothes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `sorted_quantity` bigint,
+    `cloth_type_id` bigint unsigned,
+    `sorting_record_id` bigint unsigned,
+    `service_type_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_sorted_clothes_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `complaint_sorted_clothes` (
+    `complaint_id` bigint unsigned,
+    `sorted_clothes_id` bigint unsigned,
+    PRIMARY KEY (`complaint_id`,`sorted_clothes_id`)
+);
+
+CREATE TABLE `detergent_categories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` longtext,
+    `description` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_detergent_categories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `detergents` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` longtext,
+    `type` longtext,
+    `in_stock` bigint,
+    `image` longtext,
+    `user_id` bigint unsigned,
+    `category_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_detergents_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `order_detergents` (
+    `order_id` bigint unsigned,
+    `detergent_id` bigint unsigned,
+    PRIMARY KEY (`order_id`,`detergent_id`)
+);
+
+CREATE TABLE `histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `payment_status` longtext,
+    `payment_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_histories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `history_complains` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `status_old` longtext,
+    `status_new` longtext,
+    `note` longtext,
+    `changed_date` datetime(3) NULL,
+    `changed_by` bigint unsigned,
+    `complaint_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_history_complains_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `machines` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `machine_type` longtext,
+    `capacity_kg` bigint unsigned,
+    `status` longtext,
+    `machine_number` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_machines_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `machine_process` (
+    `machine_id` bigint unsigned,
+    `laundry_process_id` bigint unsigned,
+    PRIMARY KEY (`machine_id`,`laundry_process_id`)
+);
+
+CREATE TABLE `process_order` (
+    `order_id` bigint unsigned,
+    `laundry_process_id` bigint unsigned,
+    PRIMARY KEY (`order_id`,`laundry_process_id`)
+);
+
+CREATE TABLE `order_service_types` (
+    `service_type_id` bigint unsigned,
+    `order_id` bigint unsigned,
+    PRIMARY KEY (`service_type_id`,`order_id`)
+);
+
+CREATE TABLE `order_histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `order_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_order_histories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `position_counts` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `total_employee` bigint,
+    `position_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_position_counts_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `purchase_detergents` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `detergent_id` bigint unsigned,
+    `quantity` bigint,
+    `price` double,
+    `supplier` longtext,
+    `user_id` bigint unsigned,
+    `image` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_purchase_detergents_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `time_slots` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `start_time` datetime(3) NULL,
+    `end_time` datetime(3) NULL,
+    `slot_type` longtext NOT NULL,
+    `capacity` bigint DEFAULT 5,
+    `status` varchar(191) DEFAULT 'available',
+    PRIMARY KEY (`id`),
+    INDEX `idx_time_slots_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `queues` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `queue_type` longtext,
+    `status` longtext,
+    `time_slot_id` bigint unsigned,
+    `order_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_queues_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `queue_assignments` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `assigned_time` datetime(3) NULL,
+    `queue_id` bigint unsigned,
+    `employee_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_queue_assignments_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `queue_histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `queue_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_queue_histories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `reply_templates` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `title` longtext,
+    `category` varchar(50),
+    `body` text,
+    `is_active` boolean,
+    PRIMARY KEY (`id`),
+    INDEX `idx_reply_templates_deleted_at` (`deleted_at`),
+    INDEX `idx_reply_templates_category` (`category`)
+);
+
+CREATE TABLE `reply_complaints` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `createdate_reply` datetime(3) NULL,
+    `reply` longtext,
+    `emp_id` bigint unsigned,
+    `author_type` varchar(10) DEFAULT 'employee',
+    `customer_id` bigint unsigned,
+    `is_internal` boolean,
+    `template_id` bigint unsigned,
+    `complaint_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_reply_complaints_deleted_at` (`deleted_at`),
+    INDEX `idx_reply_complaints_template_id` (`template_id`)
+);
+
+CREATE TABLE `sorting_histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `his_quantity` bigint,
+    `recorded_at` datetime(3) NULL,
+    `action` varchar(10),
+    `sorted_clothes_id` bigint unsigned,
+    `cloth_type_id` bigint unsigned,
+    `service_type_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_sorting_histories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `discount_types` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `type_name` longtext,
+    `description` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_discount_types_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `promotions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `promotion_name` longtext,
+    `description` longtext,
+    `discount_value` bigint unsigned,
+    `start_date` datetime(3) NULL,
+    `end_date` datetime(3) NULL,
+    `status` longtext,
+    `promo_image` longtext,
+    `discount_type_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_promotions_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `promotion_conditions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `condition_type` longtext,
+    `value` longtext,
+    `promotion_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_promotion_conditions_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `promotion_usages` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `usage_date` datetime(3) NULL,
+    `status` longtext,
+    `promotion_id` bigint unsigned,
+    `order_id` bigint unsigned,
+    `customer_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_promotion_usages_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `complaint_attachments` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `complaint_id` bigint unsigned,
+    `original_name` longtext,
+    `file_name` longtext,
+    `mime_type` longtext,
+    `size_bytes` bigint,
+    `path` longtext,
+    `url` longtext,
+    `storage_key` longtext,
+    `thumb_key` longtext,
+    `uploaded_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_complaint_attachments_deleted_at` (`deleted_at`),
+    INDEX `idx_complaint_attachments_complaint_id` (`complaint_id`)
+);
+
+CREATE TABLE `detergent_usage_histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `user_id` bigint unsigned,
+    `detergent_id` bigint unsigned,
+    `quantity_used` bigint,
+    `reason` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_detergent_usage_histories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `receipts` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `document_no` varchar(32),
+    `year` bigint,
+    `seq_no` bigint,
+    `status` varchar(16) DEFAULT 'issued',
+    `cancel_reason` longtext,
+    `cancelled_at` datetime(3) NULL,
+    `subtotal` double,
+    `discount` double,
+    `vat_amount` double,
+    `total` double,
+    `payment_method` longtext,
+    `issued_at` datetime(3) NULL,
+    `issued_by` bigint unsigned,
+    `replaced_by_id` bigint unsigned,
+    `order_id` bigint unsigned,
+    `payment_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_receipts_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_receipts_document_no` (`document_no`),
+    INDEX `idx_receipts_year` (`year`),
+    INDEX `idx_receipts_order_id` (`order_id`),
+    INDEX `idx_receipts_payment_id` (`payment_id`)
+);
+
+CREATE TABLE `document_sequences` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `prefix` varchar(8),
+    `year` bigint,
+    `last_no` bigint,
+    PRIMARY KEY (`id`),
+    INDEX `idx_document_sequences_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_doc_seq` (`prefix`,`year`)
+);
+
+CREATE TABLE `day_closings` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `business_date` varchar(10),
+    `closed_at` datetime(3) NULL,
+    `payment_count` bigint,
+    `total_sales` double,
+    `cash_sales` double,
+    `transfer_sales` double,
+    `cash_over_short` double,
+    `note` longtext,
+    `closed_by` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_day_closings_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_day_closings_business_date` (`business_date`)
+);
+
+CREATE TABLE `complaint_sla_policies` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` longtext,
+    `category` varchar(50),
+    `priority` varchar(20),
+    `first_response_minutes` bigint,
+    `resolution_minutes` bigint,
+    `is_active` boolean,
+    PRIMARY KEY (`id`),
+    INDEX `idx_complaint_sla_policies_deleted_at` (`deleted_at`),
+    INDEX `idx_complaint_sla_policies_category` (`category`),
+    INDEX `idx_complaint_sla_policies_priority` (`priority`)
+);
+
+CREATE TABLE `complaint_categories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `code` varchar(50),
+    `name` longtext,
+    `default_priority` varchar(20),
+    `owner_position_id` bigint unsigned,
+    `last_assigned_employee_id` bigint unsigned,
+    `is_active` boolean,
+    PRIMARY KEY (`id`),
+    INDEX `idx_complaint_categories_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_complaint_categories_code` (`code`)
+);
+
+CREATE TABLE `shift_templates` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` longtext,
+    `start_time` varchar(5),
+    `end_time` varchar(5),
+    `break_minutes` bigint,
+    `is_active` boolean,
+    PRIMARY KEY (`id`),
+    INDEX `idx_shift_templates_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `employee_rosters` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `employee_id` bigint unsigned,
+    `weekday` bigint,
+    `shift_template_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_employee_rosters_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_roster_employee_weekday` (`employee_id`,`weekday`)
+);
+
+CREATE TABLE `attendances` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `employee_id` bigint unsigned,
+    `work_date` varchar(10),
+    `shift_template_id` bigint unsigned,
+    `scheduled_start` datetime(3) NULL,
+    `scheduled_end` datetime(3) NULL,
+    `clock_in_at` datetime(3) NULL,
+    `clock_out_at` datetime(3) NULL,
+    `late_minutes` bigint,
+    `early_leave_minutes` bigint,
+    `worked_minutes` bigint,
+    `note` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_attendances_deleted_at` (`deleted_at`),
+    INDEX `idx_attendances_employee_id` (`employee_id`),
+    INDEX `idx_attendances_work_date` (`work_date`)
+);
+
+CREATE TABLE `leave_requests` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `employee_id` bigint unsigned,
+    `leave_type` varchar(20),
+    `start_date` varchar(10),
+    `end_date` varchar(10),
+    `reason` longtext,
+    `status` varchar(20),
+    `decided_by_id` bigint unsigned,
+    `decided_at` datetime(3) NULL,
+    `decision_note` longtext,
+    `prev_status_id` bigint unsigned,
+    `applied_at` datetime(3) NULL,
+    `restored_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_leave_requests_deleted_at` (`deleted_at`),
+    INDEX `idx_leave_requests_employee_id` (`employee_id`),
+    INDEX `idx_leave_requests_start_date` (`start_date`),
+    INDEX `idx_leave_requests_end_date` (`end_date`),
+    INDEX `idx_leave_requests_status` (`status`)
+);
+
+CREATE TABLE `password_reset_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `user_id` bigint unsigned,
+    `token_hash` varchar(64),
+    `expires_at` datetime(3) NULL,
+    `used_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_password_reset_tokens_deleted_at` (`deleted_at`),
+    INDEX `idx_password_reset_tokens_user_id` (`user_id`),
+    UNIQUE INDEX `idx_password_reset_tokens_token_hash` (`token_hash`)
+);
+
+CREATE TABLE `auth_events` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `user_id` bigint unsigned,
+    `email` varchar(255),
+    `event_type` varchar(32),
+    `reason` varchar(64),
+    `ip` varchar(64),
+    `user_agent` varchar(255),
+    `actor_id` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_events_created_at` (`created_at`),
+    INDEX `idx_auth_events_user_id` (`user_id`),
+    INDEX `idx_auth_events_email` (`email`),
+    INDEX `idx_auth_events_event_type` (`event_type`)
+);
+
+CREATE TABLE `audit_logs` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `actor_user_id` bigint unsigned,
+    `actor_ip` varchar(64),
+    `route` varchar(128),
+    `entity_type` varchar(64),
+    `entity_id` bigint unsigned,
+    `action` varchar(16),
+    `before_json` text,
+    `after_json` text,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_logs_created_at` (`created_at`),
+    INDEX `idx_audit_logs_actor_user_id` (`actor_user_id`),
+    INDEX `idx_audit_entity` (`entity_type`,`entity_id`)
+);
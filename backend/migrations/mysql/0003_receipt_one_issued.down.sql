@@ -0,0 +1,3 @@
+-- ย้อน 0003: ลบ unique index และคอลัมน์ generated
+
+ALTER TABLE `receipts` DROP INDEX `idx_receipts_issued_order`, DROP COLUMN `issued_order_id`;
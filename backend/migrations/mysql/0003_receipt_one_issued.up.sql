@@ -0,0 +1,10 @@
+-- 0003 ออเดอร์หนึ่งมีใบเสร็จสถานะ issued ได้ใบเดียว (mysql; ตรงกับ sqlite/0003_receipt_one_issued.up.sql)
+-- mysql ไม่มี partial index จึงใช้คอลัมน์ generated ที่เป็น NULL เมื่อไม่ใช่ใบ issued (NULL ซ้ำได้ใน unique index)
+
+UPDATE `receipts` SET `status` = 'cancelled', `cancel_reason` = 'ออกซ้ำ', `cancelled_at` = CURRENT_TIMESTAMP(3)
+WHERE `status` = 'issued' AND `deleted_at` IS NULL
+  AND `id` NOT IN (SELECT `id` FROM (SELECT MAX(`id`) AS `id` FROM `receipts` WHERE `status` = 'issued' AND `deleted_at` IS NULL GROUP BY `order_id`) AS `keep`);
+
+ALTER TABLE `receipts`
+    ADD COLUMN `issued_order_id` bigint unsigned AS (CASE WHEN `status` = 'issued' AND `deleted_at` IS NULL THEN `order_id` END) STORED,
+    ADD UNIQUE INDEX `idx_receipts_issued_order` (`issued_order_id`);
@@ -0,0 +1,7 @@
+-- 0004 ประวัติคำร้อง: การส่งต่อโดยตัวตรวจ SLA เป็นการกระทำของระบบ (changed_by = NULL)
+-- หัวหน้างานที่รับเรื่องเก็บแยกใน escalated_to_id
+
+ALTER TABLE `history_complains` ADD COLUMN `escalated_to_id` bigint unsigned;
+
+UPDATE `history_complains` SET `escalated_to_id` = `changed_by`, `changed_by` = NULL
+WHERE `status_old` = `status_new` AND `note` LIKE 'SLA:%';
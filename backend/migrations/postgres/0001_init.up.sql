@@ -0,0 +1,796 @@
+-- 0001 โครงสร้างตั้งต้น (postgres; ตรงกับ sqlite/0001_init.up.sql)
+-- ไม่สร้าง FOREIGN KEY: โค้ดหลายจุดใช้ id = 0 แทนค่าว่าง และ SQLite ก็ไม่ได้บังคับ foreign key อยู่แล้ว
+
+CREATE TABLE "genders" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_genders_name" UNIQUE ("name")
+);
+CREATE INDEX IF NOT EXISTS "idx_genders_deleted_at" ON "genders" ("deleted_at");
+
+CREATE TABLE "roles" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_roles_deleted_at" ON "roles" ("deleted_at");
+
+CREATE TABLE "users" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "email" text,
+    "password" text,
+    "failed_login_count" bigint,
+    "locked_until" timestamptz,
+    "role_id" bigint,
+    "purchase_detergent" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE "customers" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "first_name" text,
+    "last_name" text,
+    "phone_number" text,
+    "gender_id" bigint,
+    "user_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_customers_deleted_at" ON "customers" ("deleted_at");
+
+CREATE TABLE "addresses" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "address_details" text,
+    "latitude" decimal,
+    "longitude" decimal,
+    "is_default" boolean,
+    "customer_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_addresses_deleted_at" ON "addresses" ("deleted_at");
+
+CREATE TABLE "cloth_types" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "type_name" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cloth_types_type_name" ON "cloth_types" ("type_name");
+CREATE INDEX IF NOT EXISTS "idx_cloth_types_deleted_at" ON "cloth_types" ("deleted_at");
+
+CREATE TABLE "positions" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "position_name" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_positions_deleted_at" ON "positions" ("deleted_at");
+
+CREATE TABLE "employee_statuses" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "status_name" text,
+    "status_description" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_employee_statuses_deleted_at" ON "employee_statuses" ("deleted_at");
+
+CREATE TABLE "employees" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "code" varchar(16),
+    "first_name" text,
+    "last_name" text,
+    "phone" text,
+    "gender" text,
+    "start_date" timestamptz,
+    "user_id" bigint,
+    "position_id" bigint,
+    "employee_status_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_employees_code" ON "employees" ("code");
+CREATE INDEX IF NOT EXISTS "idx_employees_deleted_at" ON "employees" ("deleted_at");
+
+CREATE TABLE "orders" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "customer_id" bigint,
+    "order_image" text,
+    "order_note" text,
+    "address_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_deleted_at" ON "orders" ("deleted_at");
+
+CREATE TABLE "sorting_records" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "sorting_date" timestamptz,
+    "sorting_note" text,
+    "order_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sorting_records_deleted_at" ON "sorting_records" ("deleted_at");
+
+CREATE TABLE "laundry_processes" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "status" text,
+    "end_time" timestamptz,
+    "start_time" timestamptz,
+    "description" text,
+    "employee_id" bigint,
+    "sorting_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_laundry_processes_deleted_at" ON "laundry_processes" ("deleted_at");
+
+CREATE TABLE "cash_sessions" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "business_date" varchar(10),
+    "status" varchar(10),
+    "opened_at" timestamptz,
+    "opening_float" decimal,
+    "closed_at" timestamptz,
+    "counted_amount" decimal,
+    "expected_amount" decimal,
+    "difference" decimal,
+    "note" text,
+    "employee_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_cash_sessions_employee_id" ON "cash_sessions" ("employee_id");
+CREATE INDEX IF NOT EXISTS "idx_cash_sessions_status" ON "cash_sessions" ("status");
+CREATE INDEX IF NOT EXISTS "idx_cash_sessions_business_date" ON "cash_sessions" ("business_date");
+CREATE INDEX IF NOT EXISTS "idx_cash_sessions_deleted_at" ON "cash_sessions" ("deleted_at");
+
+CREATE TABLE "payments" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "payment_type" text,
+    "check_payment_b64" text,
+    "total_amount" bigint,
+    "payment_status" text,
+    "order_id" bigint,
+    "trans_ref" varchar(64),
+    "verified_amount" bigint,
+    "slip_date" timestamptz,
+    "slip_verified_at" timestamptz,
+    "cash_session_id" bigint,
+    "received_by" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_cash_session_id" ON "payments" ("cash_session_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_payments_trans_ref" ON "payments" ("trans_ref");
+CREATE INDEX IF NOT EXISTS "idx_payments_deleted_at" ON "payments" ("deleted_at");
+
+CREATE TABLE "complaints" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "status_complaint" text,
+    "title" text,
+    "description" text,
+    "createdate" timestamptz,
+    "public_id" varchar(50),
+    "email" text,
+    "order_id" bigint,
+    "laundry_process_id" bigint,
+    "payment_id" bigint,
+    "category" varchar(50),
+    "priority" varchar(20) DEFAULT 'normal',
+    "sla_policy_id" bigint,
+    "first_response_due_at" timestamptz,
+    "resolution_due_at" timestamptz,
+    "first_responded_at" timestamptz,
+    "resolved_at" timestamptz,
+    "first_response_breached_at" timestamptz,
+    "resolution_breached_at" timestamptz,
+    "escalated_at" timestamptz,
+    "escalated_to_id" bigint,
+    "assigned_to_id" bigint,
+    "assigned_at" timestamptz,
+    "satisfaction_rating" bigint,
+    "satisfaction_comment" text,
+    "rated_at" timestamptz,
+    "customer_id" bigint NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_complaints_assigned_to_id" ON "complaints" ("assigned_to_id");
+CREATE INDEX IF NOT EXISTS "idx_complaints_category" ON "complaints" ("category");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_complaints_public_id" ON "complaints" ("public_id");
+CREATE INDEX IF NOT EXISTS "idx_complaints_deleted_at" ON "complaints" ("deleted_at");
+
+CREATE TABLE "service_types" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "type" text,
+    "price" decimal,
+    "capacity" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_service_types_deleted_at" ON "service_types" ("deleted_at");
+
+CREATE TABLE "sorted_clothes" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "sorted_quantity" bigint,
+    "cloth_type_id" bigint,
+    "sorting_record_id" bigint,
+    "service_type_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sorted_clothes_deleted_at" ON "sorted_clothes" ("deleted_at");
+
+CREATE TABLE "complaint_sorted_clothes" (
+    "complaint_id" bigint,
+    "sorted_clothes_id" bigint,
+    PRIMARY KEY ("complaint_id","sorted_clothes_id")
+);
+
+CREATE TABLE "detergent_categories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "description" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_detergent_categories_deleted_at" ON "detergent_categories" ("deleted_at");
+
+CREATE TABLE "detergents" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "type" text,
+    "in_stock" bigint,
+    "image" text,
+    "user_id" bigint,
+    "category_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_detergents_deleted_at" ON "detergents" ("deleted_at");
+
+CREATE TABLE "order_detergents" (
+    "order_id" bigint,
+    "detergent_id" bigint,
+    PRIMARY KEY ("order_id","detergent_id")
+);
+
+CREATE TABLE "histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "payment_status" text,
+    "payment_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_histories_deleted_at" ON "histories" ("deleted_at");
+
+CREATE TABLE "history_complains" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "status_old" text,
+    "status_new" text,
+    "note" text,
+    "changed_date" timestamptz,
+    "changed_by" bigint,
+    "complaint_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_history_complains_deleted_at" ON "history_complains" ("deleted_at");
+
+CREATE TABLE "process_order" (
+    "order_id" bigint,
+    "laundry_process_id" bigint,
+    PRIMARY KEY ("order_id","laundry_process_id")
+);
+
+CREATE TABLE "machines" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "machine_type" text,
+    "capacity_kg" bigint,
+    "status" text,
+    "machine_number" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_machines_deleted_at" ON "machines" ("deleted_at");
+
+CREATE TABLE "machine_process" (
+    "machine_id" bigint,
+    "laundry_process_id" bigint,
+    PRIMARY KEY ("machine_id","laundry_process_id")
+);
+
+CREATE TABLE "order_service_types" (
+    "service_type_id" bigint,
+    "order_id" bigint,
+    PRIMARY KEY ("service_type_id","order_id")
+);
+
+CREATE TABLE "order_histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "order_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_histories_deleted_at" ON "order_histories" ("deleted_at");
+
+CREATE TABLE "position_counts" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "total_employee" bigint,
+    "position_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_position_counts_deleted_at" ON "position_counts" ("deleted_at");
+
+CREATE TABLE "purchase_detergents" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "detergent_id" bigint,
+    "quantity" bigint,
+    "price" decimal,
+    "supplier" text,
+    "user_id" bigint,
+    "image" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_purchase_detergents_deleted_at" ON "purchase_detergents" ("deleted_at");
+
+CREATE TABLE "time_slots" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "start_time" timestamptz,
+    "end_time" timestamptz,
+    "slot_type" text NOT NULL,
+    "capacity" bigint DEFAULT 5,
+    "status" text DEFAULT 'available',
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_time_slots_deleted_at" ON "time_slots" ("deleted_at");
+
+CREATE TABLE "queues" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "queue_type" text,
+    "status" text,
+    "time_slot_id" bigint,
+    "order_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_queues_deleted_at" ON "queues" ("deleted_at");
+
+CREATE TABLE "queue_assignments" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "assigned_time" timestamptz,
+    "queue_id" bigint,
+    "employee_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_queue_assignments_deleted_at" ON "queue_assignments" ("deleted_at");
+
+CREATE TABLE "queue_histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "queue_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_queue_histories_deleted_at" ON "queue_histories" ("deleted_at");
+
+CREATE TABLE "reply_templates" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "title" text,
+    "category" varchar(50),
+    "body" text,
+    "is_active" boolean,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reply_templates_category" ON "reply_templates" ("category");
+CREATE INDEX IF NOT EXISTS "idx_reply_templates_deleted_at" ON "reply_templates" ("deleted_at");
+
+CREATE TABLE "reply_complaints" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "createdate_reply" timestamptz,
+    "reply" text,
+    "emp_id" bigint,
+    "author_type" varchar(10) DEFAULT 'employee',
+    "customer_id" bigint,
+    "is_internal" boolean,
+    "template_id" bigint,
+    "complaint_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reply_complaints_template_id" ON "reply_complaints" ("template_id");
+CREATE INDEX IF NOT EXISTS "idx_reply_complaints_deleted_at" ON "reply_complaints" ("deleted_at");
+
+CREATE TABLE "sorting_histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "his_quantity" bigint,
+    "recorded_at" timestamptz,
+    "action" varchar(10),
+    "sorted_clothes_id" bigint,
+    "cloth_type_id" bigint,
+    "service_type_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sorting_histories_deleted_at" ON "sorting_histories" ("deleted_at");
+
+CREATE TABLE "discount_types" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "type_name" text,
+    "description" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_discount_types_deleted_at" ON "discount_types" ("deleted_at");
+
+CREATE TABLE "promotions" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "promotion_name" text,
+    "description" text,
+    "discount_value" bigint,
+    "start_date" timestamptz,
+    "end_date" timestamptz,
+    "status" text,
+    "promo_image" text,
+    "discount_type_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_promotions_deleted_at" ON "promotions" ("deleted_at");
+
+CREATE TABLE "promotion_conditions" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "condition_type" text,
+    "value" text,
+    "promotion_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_promotion_conditions_deleted_at" ON "promotion_conditions" ("deleted_at");
+
+CREATE TABLE "promotion_usages" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "usage_date" timestamptz,
+    "status" text,
+    "promotion_id" bigint,
+    "order_id" bigint,
+    "customer_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_promotion_usages_deleted_at" ON "promotion_usages" ("deleted_at");
+
+CREATE TABLE "complaint_attachments" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "complaint_id" bigint,
+    "original_name" text,
+    "file_name" text,
+    "mime_type" text,
+    "size_bytes" bigint,
+    "path" text,
+    "url" text,
+    "storage_key" text,
+    "thumb_key" text,
+    "uploaded_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_complaint_attachments_complaint_id" ON "complaint_attachments" ("complaint_id");
+CREATE INDEX IF NOT EXISTS "idx_complaint_attachments_deleted_at" ON "complaint_attachments" ("deleted_at");
+
+CREATE TABLE "detergent_usage_histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "detergent_id" bigint,
+    "quantity_used" bigint,
+    "reason" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_detergent_usage_histories_deleted_at" ON "detergent_usage_histories" ("deleted_at");
+
+CREATE TABLE "receipts" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "document_no" varchar(32),
+    "year" bigint,
+    "seq_no" bigint,
+    "status" varchar(16) DEFAULT 'issued',
+    "cancel_reason" text,
+    "cancelled_at" timestamptz,
+    "subtotal" decimal,
+    "discount" decimal,
+    "vat_amount" decimal,
+    "total" decimal,
+    "payment_method" text,
+    "issued_at" timestamptz,
+    "issued_by" bigint,
+    "replaced_by_id" bigint,
+    "order_id" bigint,
+    "payment_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_receipts_payment_id" ON "receipts" ("payment_id");
+CREATE INDEX IF NOT EXISTS "idx_receipts_order_id" ON "receipts" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_receipts_year" ON "receipts" ("year");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_receipts_document_no" ON "receipts" ("document_no");
+CREATE INDEX IF NOT EXISTS "idx_receipts_deleted_at" ON "receipts" ("deleted_at");
+
+CREATE TABLE "document_sequences" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "prefix" varchar(8),
+    "year" bigint,
+    "last_no" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_doc_seq" ON "document_sequences" ("prefix","year");
+CREATE INDEX IF NOT EXISTS "idx_document_sequences_deleted_at" ON "document_sequences" ("deleted_at");
+
+CREATE TABLE "day_closings" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "business_date" varchar(10),
+    "closed_at" timestamptz,
+    "payment_count" bigint,
+    "total_sales" decimal,
+    "cash_sales" decimal,
+    "transfer_sales" decimal,
+    "cash_over_short" decimal,
+    "note" text,
+    "closed_by" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_day_closings_business_date" ON "day_closings" ("business_date");
+CREATE INDEX IF NOT EXISTS "idx_day_closings_deleted_at" ON "day_closings" ("deleted_at");
+
+CREATE TABLE "complaint_sla_policies" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "category" varchar(50),
+    "priority" varchar(20),
+    "first_response_minutes" bigint,
+    "resolution_minutes" bigint,
+    "is_active" boolean,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_complaint_sla_policies_priority" ON "complaint_sla_policies" ("priority");
+CREATE INDEX IF NOT EXISTS "idx_complaint_sla_policies_category" ON "complaint_sla_policies" ("category");
+CREATE INDEX IF NOT EXISTS "idx_complaint_sla_policies_deleted_at" ON "complaint_sla_policies" ("deleted_at");
+
+CREATE TABLE "complaint_categories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "code" varchar(50),
+    "name" text,
+    "default_priority" varchar(20),
+    "owner_position_id" bigint,
+    "last_assigned_employee_id" bigint,
+    "is_active" boolean,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_complaint_categories_code" ON "complaint_categories" ("code");
+CREATE INDEX IF NOT EXISTS "idx_complaint_categories_deleted_at" ON "complaint_categories" ("deleted_at");
+
+CREATE TABLE "shift_templates" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "start_time" varchar(5),
+    "end_time" varchar(5),
+    "break_minutes" bigint,
+    "is_active" boolean,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_shift_templates_deleted_at" ON "shift_templates" ("deleted_at");
+
+CREATE TABLE "employee_rosters" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "employee_id" bigint,
+    "weekday" bigint,
+    "shift_template_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roster_employee_weekday" ON "employee_rosters" ("employee_id","weekday");
+CREATE INDEX IF NOT EXISTS "idx_employee_rosters_deleted_at" ON "employee_rosters" ("deleted_at");
+
+CREATE TABLE "attendances" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "employee_id" bigint,
+    "work_date" varchar(10),
+    "shift_template_id" bigint,
+    "scheduled_start" timestamptz,
+    "scheduled_end" timestamptz,
+    "clock_in_at" timestamptz,
+    "clock_out_at" timestamptz,
+    "late_minutes" bigint,
+    "early_leave_minutes" bigint,
+    "worked_minutes" bigint,
+    "note" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_attendances_work_date" ON "attendances" ("work_date");
+CREATE INDEX IF NOT EXISTS "idx_attendances_employee_id" ON "attendances" ("employee_id");
+CREATE INDEX IF NOT EXISTS "idx_attendances_deleted_at" ON "attendances" ("deleted_at");
+
+CREATE TABLE "leave_requests" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "employee_id" bigint,
+    "leave_type" varchar(20),
+    "start_date" varchar(10),
+    "end_date" varchar(10),
+    "reason" text,
+    "status" varchar(20),
+    "decided_by_id" bigint,
+    "decided_at" timestamptz,
+    "decision_note" text,
+    "prev_status_id" bigint,
+    "applied_at" timestamptz,
+    "restored_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_status" ON "leave_requests" ("status");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_end_date" ON "leave_requests" ("end_date");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_start_date" ON "leave_requests" ("start_date");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_employee_id" ON "leave_requests" ("employee_id");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_deleted_at" ON "leave_requests" ("deleted_at");
+
+CREATE TABLE "password_reset_tokens" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "token_hash" varchar(64),
+    "expires_at" timestamptz,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_deleted_at" ON "password_reset_tokens" ("deleted_at");
+
+CREATE TABLE "auth_events" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "user_id" bigint,
+    "email" varchar(255),
+    "event_type" varchar(32),
+    "reason" varchar(64),
+    "ip" varchar(64),
+    "user_agent" varchar(255),
+    "actor_id" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_auth_events_event_type" ON "auth_events" ("event_type");
+CREATE INDEX IF NOT EXISTS "idx_auth_events_email" ON "auth_events" ("email");
+CREATE INDEX IF NOT EXISTS "idx_auth_events_user_id" ON "auth_events" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_auth_events_created_at" ON "auth_events" ("created_at");
+
+CREATE TABLE "audit_logs" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "actor_user_id" bigint,
+    "actor_ip" varchar(64),
+    "route" varchar(128),
+    "entity_type" varchar(64),
+    "entity_id" bigint,
+    "action" varchar(16),
+    "before_json" text,
+    "after_json" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_entity" ON "audit_logs" ("entity_type","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_user_id" ON "audit_logs" ("actor_user_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
@@ -0,0 +1,12 @@
+-- ย้อน 0002: ลบข้อมูลอ้างอิงตั้งต้น
+
+DELETE FROM "shift_templates" WHERE "name" IN ('กะเช้า', 'กะบ่าย');
+DELETE FROM "reply_templates" WHERE "title" IN ('รับเรื่องแล้ว', 'ชดเชยผ้าเสียหาย', 'ส่งผ้าล่าช้า');
+DELETE FROM "complaint_categories" WHERE "code" IN ('damaged_clothes', 'lost_item', 'late_delivery', 'payment_issue', 'staff_behaviour');
+DELETE FROM "complaint_sla_policies" WHERE "name" IN ('มาตรฐาน', 'ด่วน', 'ด่วนมาก');
+DELETE FROM "positions" WHERE "position_name" IN ('พนักงานซักผ้า', 'พนักงานขนส่งผ้า', 'หัวหน้างาน');
+DELETE FROM "discount_types" WHERE "type_name" IN ('เปอร์เซ็นต์', 'จำนวนเงิน');
+DELETE FROM "detergent_categories" WHERE "name" IN ('น้ำยาซัก', 'ปรับผ้านุ่ม');
+DELETE FROM "service_types" WHERE "type" IN ('ซัก 10kg', 'ซัก 14kg', 'ซัก 18kg', 'ซัก 28kg', 'อบ 14kg', 'อบ 25kg', 'ไม่อบ');
+DELETE FROM "genders" WHERE "name" IN ('ชาย', 'หญิง', 'อืนๆ');
+DELETE FROM "roles" WHERE "id" IN (1, 2, 3);
@@ -0,0 +1,3 @@
+-- ย้อน 0003: ลบ unique index
+
+DROP INDEX IF EXISTS "idx_receipts_issued_order";
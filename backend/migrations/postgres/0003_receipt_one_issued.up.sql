@@ -0,0 +1,7 @@
+-- 0003 ออเดอร์หนึ่งมีใบเสร็จสถานะ issued ได้ใบเดียว (postgres; ตรงกับ sqlite/0003_receipt_one_issued.up.sql)
+
+UPDATE "receipts" SET "status" = 'cancelled', "cancel_reason" = 'ออกซ้ำ', "cancelled_at" = CURRENT_TIMESTAMP
+WHERE "status" = 'issued' AND "deleted_at" IS NULL
+  AND "id" NOT IN (SELECT MAX("id") FROM "receipts" WHERE "status" = 'issued' AND "deleted_at" IS NULL GROUP BY "order_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_receipts_issued_order" ON "receipts" ("order_id") WHERE "status" = 'issued' AND "deleted_at" IS NULL;
@@ -0,0 +1,819 @@
+-- 0001 โครงสร้างตั้งต้น (ยกมาจาก schema ที่ AutoMigrate สร้างไว้เดิม)
+
+CREATE TABLE `genders` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text NOT NULL,
+    CONSTRAINT `uni_genders_name` UNIQUE (`name`)
+);
+CREATE INDEX `idx_genders_deleted_at` ON `genders`(`deleted_at`);
+
+CREATE TABLE `roles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text
+);
+CREATE INDEX `idx_roles_deleted_at` ON `roles`(`deleted_at`);
+
+CREATE TABLE `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `email` text,
+    `password` text,
+    `failed_login_count` integer,
+    `locked_until` datetime,
+    `role_id` integer,
+    `purchase_detergent` integer,
+    CONSTRAINT `fk_roles_users` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`)
+);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE INDEX `idx_users_deleted_at` ON `users`(`deleted_at`);
+
+CREATE TABLE `customers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `first_name` text,
+    `last_name` text,
+    `phone_number` text,
+    `gender_id` integer,
+    `user_id` integer,
+    CONSTRAINT `fk_genders_customers` FOREIGN KEY (`gender_id`) REFERENCES `genders`(`id`),
+    CONSTRAINT `fk_users_customers` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX `idx_customers_deleted_at` ON `customers`(`deleted_at`);
+
+CREATE TABLE `addresses` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `address_details` text,
+    `latitude` real,
+    `longitude` real,
+    `is_default` numeric,
+    `customer_id` integer,
+    CONSTRAINT `fk_customers_addresses` FOREIGN KEY (`customer_id`) REFERENCES `customers`(`id`)
+);
+CREATE INDEX `idx_addresses_deleted_at` ON `addresses`(`deleted_at`);
+
+CREATE TABLE `cloth_types` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `type_name` text NOT NULL
+);
+CREATE UNIQUE INDEX `idx_cloth_types_type_name` ON `cloth_types`(`type_name`);
+CREATE INDEX `idx_cloth_types_deleted_at` ON `cloth_types`(`deleted_at`);
+
+CREATE TABLE `employee_statuses` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `status_name` text,
+    `status_description` text
+);
+CREATE INDEX `idx_employee_statuses_deleted_at` ON `employee_statuses`(`deleted_at`);
+
+CREATE TABLE `positions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `position_name` text
+);
+CREATE INDEX `idx_positions_deleted_at` ON `positions`(`deleted_at`);
+
+CREATE TABLE `employees` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `code` text,
+    `first_name` text,
+    `last_name` text,
+    `phone` text,
+    `gender` text,
+    `start_date` datetime,
+    `user_id` integer,
+    `position_id` integer,
+    `employee_status_id` integer,
+    CONSTRAINT `fk_positions_employee` FOREIGN KEY (`position_id`) REFERENCES `positions`(`id`),
+    CONSTRAINT `fk_employee_statuses_employees` FOREIGN KEY (`employee_status_id`) REFERENCES `employee_statuses`(`id`),
+    CONSTRAINT `fk_users_employee` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE UNIQUE INDEX `idx_employees_code` ON `employees`(`code`);
+CREATE INDEX `idx_employees_deleted_at` ON `employees`(`deleted_at`);
+
+CREATE TABLE `orders` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `customer_id` integer,
+    `order_image` text,
+    `order_note` text,
+    `address_id` integer,
+    CONSTRAINT `fk_customers_orders` FOREIGN KEY (`customer_id`) REFERENCES `customers`(`id`),
+    CONSTRAINT `fk_addresses_orders` FOREIGN KEY (`address_id`) REFERENCES `addresses`(`id`)
+);
+CREATE INDEX `idx_orders_deleted_at` ON `orders`(`deleted_at`);
+
+CREATE TABLE `sorting_records` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `sorting_date` datetime,
+    `sorting_note` text,
+    `order_id` integer,
+    CONSTRAINT `fk_orders_sorting_record` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`)
+);
+CREATE INDEX `idx_sorting_records_deleted_at` ON `sorting_records`(`deleted_at`);
+
+CREATE TABLE `laundry_processes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `status` text,
+    `end_time` datetime,
+    `start_time` datetime,
+    `description` text,
+    `employee_id` integer,
+    `sorting_id` integer,
+    CONSTRAINT `fk_laundry_processes_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_laundry_processes_sorting_record` FOREIGN KEY (`sorting_id`) REFERENCES `sorting_records`(`id`)
+);
+CREATE INDEX `idx_laundry_processes_deleted_at` ON `laundry_processes`(`deleted_at`);
+
+CREATE TABLE `payments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `payment_type` text,
+    `check_payment_b64` text,
+    `total_amount` integer,
+    `payment_status` text,
+    `order_id` integer,
+    `trans_ref` text,
+    `verified_amount` integer,
+    `slip_date` datetime,
+    `slip_verified_at` datetime,
+    `cash_session_id` integer,
+    `received_by` integer,
+    CONSTRAINT `fk_orders_payment` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_cash_sessions_payments` FOREIGN KEY (`cash_session_id`) REFERENCES `cash_sessions`(`id`)
+);
+CREATE INDEX `idx_payments_cash_session_id` ON `payments`(`cash_session_id`);
+CREATE UNIQUE INDEX `idx_payments_trans_ref` ON `payments`(`trans_ref`);
+CREATE INDEX `idx_payments_deleted_at` ON `payments`(`deleted_at`);
+
+CREATE TABLE `complaints` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `status_complaint` text,
+    `title` text,
+    `description` text,
+    `createdate` datetime,
+    `public_id` text,
+    `email` text,
+    `order_id` integer,
+    `laundry_process_id` integer,
+    `payment_id` integer,
+    `category` text,
+    `priority` text DEFAULT "normal",
+    `sla_policy_id` integer,
+    `first_response_due_at` datetime,
+    `resolution_due_at` datetime,
+    `first_responded_at` datetime,
+    `resolved_at` datetime,
+    `first_response_breached_at` datetime,
+    `resolution_breached_at` datetime,
+    `escalated_at` datetime,
+    `escalated_to_id` integer,
+    `assigned_to_id` integer,
+    `assigned_at` datetime,
+    `satisfaction_rating` integer,
+    `satisfaction_comment` text,
+    `rated_at` datetime,
+    `customer_id` integer NOT NULL,
+    CONSTRAINT `fk_complaints_order` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_complaints_payment` FOREIGN KEY (`payment_id`) REFERENCES `payments`(`id`),
+    CONSTRAINT `fk_complaints_escalated_to` FOREIGN KEY (`escalated_to_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_complaints_assigned_to` FOREIGN KEY (`assigned_to_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_complaints_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT `fk_complaints_laundry_process` FOREIGN KEY (`laundry_process_id`) REFERENCES `laundry_processes`(`id`)
+);
+CREATE INDEX `idx_complaints_assigned_to_id` ON `complaints`(`assigned_to_id`);
+CREATE INDEX `idx_complaints_category` ON `complaints`(`category`);
+CREATE UNIQUE INDEX `idx_complaints_public_id` ON `complaints`(`public_id`);
+CREATE INDEX `idx_complaints_deleted_at` ON `complaints`(`deleted_at`);
+
+CREATE TABLE `service_types` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `type` text,
+    `price` real,
+    `capacity` integer
+);
+CREATE INDEX `idx_service_types_deleted_at` ON `service_types`(`deleted_at`);
+
+CREATE TABLE `sorted_clothes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `sorted_quantity` integer,
+    `cloth_type_id` integer,
+    `sorting_record_id` integer,
+    `service_type_id` integer,
+    CONSTRAINT `fk_service_types_sorted_clothes` FOREIGN KEY (`service_type_id`) REFERENCES `service_types`(`id`),
+    CONSTRAINT `fk_cloth_types_sorted_clothes` FOREIGN KEY (`cloth_type_id`) REFERENCES `cloth_types`(`id`),
+    CONSTRAINT `fk_sorting_records_sorted_clothes` FOREIGN KEY (`sorting_record_id`) REFERENCES `sorting_records`(`id`)
+);
+CREATE INDEX `idx_sorted_clothes_deleted_at` ON `sorted_clothes`(`deleted_at`);
+
+CREATE TABLE `complaint_sorted_clothes` (
+    `complaint_id` integer,
+    `sorted_clothes_id` integer,
+    PRIMARY KEY (`complaint_id`,`sorted_clothes_id`),
+    CONSTRAINT `fk_complaint_sorted_clothes_complaint` FOREIGN KEY (`complaint_id`) REFERENCES `complaints`(`id`),
+    CONSTRAINT `fk_complaint_sorted_clothes_sorted_clothes` FOREIGN KEY (`sorted_clothes_id`) REFERENCES `sorted_clothes`(`id`)
+);
+
+CREATE TABLE `detergent_categories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text,
+    `description` text
+);
+CREATE INDEX `idx_detergent_categories_deleted_at` ON `detergent_categories`(`deleted_at`);
+
+CREATE TABLE `detergents` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text,
+    `type` text,
+    `in_stock` integer,
+    `image` text,
+    `user_id` integer,
+    `category_id` integer,
+    CONSTRAINT `fk_detergents_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_detergent_categories_detergents` FOREIGN KEY (`category_id`) REFERENCES `detergent_categories`(`id`)
+);
+CREATE INDEX `idx_detergents_deleted_at` ON `detergents`(`deleted_at`);
+
+CREATE TABLE `order_detergents` (
+    `order_id` integer,
+    `detergent_id` integer,
+    PRIMARY KEY (`order_id`,`detergent_id`),
+    CONSTRAINT `fk_order_detergents_order` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_order_detergents_detergent` FOREIGN KEY (`detergent_id`) REFERENCES `detergents`(`id`)
+);
+
+CREATE TABLE `histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `payment_status` text,
+    `payment_id` integer,
+    CONSTRAINT `fk_payments_histories` FOREIGN KEY (`payment_id`) REFERENCES `payments`(`id`)
+);
+CREATE INDEX `idx_histories_deleted_at` ON `histories`(`deleted_at`);
+
+CREATE TABLE `history_complains` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `status_old` text,
+    `status_new` text,
+    `note` text,
+    `changed_date` datetime,
+    `changed_by` integer,
+    `complaint_id` integer,
+    CONSTRAINT `fk_history_complains_employee` FOREIGN KEY (`changed_by`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_complaints_histories` FOREIGN KEY (`complaint_id`) REFERENCES `complaints`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX `idx_history_complains_deleted_at` ON `history_complains`(`deleted_at`);
+
+CREATE TABLE `machines` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `machine_type` text,
+    `capacity_kg` integer,
+    `status` text,
+    `machine_number` integer
+);
+CREATE INDEX `idx_machines_deleted_at` ON `machines`(`deleted_at`);
+
+CREATE TABLE `machine_process` (
+    `machine_id` integer,
+    `laundry_process_id` integer,
+    PRIMARY KEY (`machine_id`,`laundry_process_id`),
+    CONSTRAINT `fk_machine_process_laundry_process` FOREIGN KEY (`laundry_process_id`) REFERENCES `laundry_processes`(`id`),
+    CONSTRAINT `fk_machine_process_machine` FOREIGN KEY (`machine_id`) REFERENCES `machines`(`id`)
+);
+
+CREATE TABLE `process_order` (
+    `order_id` integer,
+    `laundry_process_id` integer,
+    PRIMARY KEY (`order_id`,`laundry_process_id`),
+    CONSTRAINT `fk_process_order_order` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_process_order_laundry_process` FOREIGN KEY (`laundry_process_id`) REFERENCES `laundry_processes`(`id`)
+);
+
+CREATE TABLE `order_service_types` (
+    `service_type_id` integer,
+    `order_id` integer,
+    PRIMARY KEY (`service_type_id`,`order_id`),
+    CONSTRAINT `fk_order_service_types_order` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_order_service_types_service_type` FOREIGN KEY (`service_type_id`) REFERENCES `service_types`(`id`)
+);
+
+CREATE TABLE `order_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `order_id` integer,
+    CONSTRAINT `fk_orders_order_histories` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`)
+);
+CREATE INDEX `idx_order_histories_deleted_at` ON `order_histories`(`deleted_at`);
+
+CREATE TABLE `position_counts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `total_employee` integer,
+    `position_id` integer,
+    CONSTRAINT `fk_positions_position_count` FOREIGN KEY (`position_id`) REFERENCES `positions`(`id`)
+);
+CREATE INDEX `idx_position_counts_deleted_at` ON `position_counts`(`deleted_at`);
+
+CREATE TABLE `purchase_detergents` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `detergent_id` integer,
+    `quantity` integer,
+    `price` real,
+    `supplier` text,
+    `user_id` integer,
+    `image` text,
+    CONSTRAINT `fk_users_purchase_detergents` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_detergents_purchase_detergents` FOREIGN KEY (`detergent_id`) REFERENCES `detergents`(`id`)
+);
+CREATE INDEX `idx_purchase_detergents_deleted_at` ON `purchase_detergents`(`deleted_at`);
+
+CREATE TABLE `time_slots` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `start_time` datetime,
+    `end_time` datetime,
+    `slot_type` text NOT NULL,
+    `capacity` integer DEFAULT 5,
+    `status` text DEFAULT "available"
+);
+CREATE INDEX `idx_time_slots_deleted_at` ON `time_slots`(`deleted_at`);
+
+CREATE TABLE `queues` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `queue_type` text,
+    `status` text,
+    `time_slot_id` integer,
+    `order_id` integer,
+    CONSTRAINT `fk_orders_queues` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_time_slots_queue` FOREIGN KEY (`time_slot_id`) REFERENCES `time_slots`(`id`)
+);
+CREATE INDEX `idx_queues_deleted_at` ON `queues`(`deleted_at`);
+
+CREATE TABLE `queue_assignments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `assigned_time` datetime,
+    `queue_id` integer,
+    `employee_id` integer,
+    CONSTRAINT `fk_queue_assignments_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_queues_queueassignment` FOREIGN KEY (`queue_id`) REFERENCES `queues`(`id`)
+);
+CREATE INDEX `idx_queue_assignments_deleted_at` ON `queue_assignments`(`deleted_at`);
+
+CREATE TABLE `queue_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `queue_id` integer,
+    CONSTRAINT `fk_queues_queuehistory` FOREIGN KEY (`queue_id`) REFERENCES `queues`(`id`)
+);
+CREATE INDEX `idx_queue_histories_deleted_at` ON `queue_histories`(`deleted_at`);
+
+CREATE TABLE `reply_templates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `title` text,
+    `category` text,
+    `body` text,
+    `is_active` numeric
+);
+CREATE INDEX `idx_reply_templates_category` ON `reply_templates`(`category`);
+CREATE INDEX `idx_reply_templates_deleted_at` ON `reply_templates`(`deleted_at`);
+
+CREATE TABLE `reply_complaints` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `createdate_reply` datetime,
+    `reply` text,
+    `emp_id` integer,
+    `author_type` text DEFAULT "employee",
+    `customer_id` integer,
+    `is_internal` numeric,
+    `template_id` integer,
+    `complaint_id` integer,
+    CONSTRAINT `fk_complaints_replies` FOREIGN KEY (`complaint_id`) REFERENCES `complaints`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_reply_complaints_employee` FOREIGN KEY (`emp_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_reply_complaints_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers`(`id`),
+    CONSTRAINT `fk_reply_complaints_template` FOREIGN KEY (`template_id`) REFERENCES `reply_templates`(`id`)
+);
+CREATE INDEX `idx_reply_complaints_template_id` ON `reply_complaints`(`template_id`);
+CREATE INDEX `idx_reply_complaints_deleted_at` ON `reply_complaints`(`deleted_at`);
+
+CREATE TABLE `sorting_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `his_quantity` integer,
+    `recorded_at` datetime,
+    `action` text,
+    `sorted_clothes_id` integer,
+    `cloth_type_id` integer,
+    `service_type_id` integer,
+    CONSTRAINT `fk_sorted_clothes_sorting_histories` FOREIGN KEY (`sorted_clothes_id`) REFERENCES `sorted_clothes`(`id`)
+);
+CREATE INDEX `idx_sorting_histories_deleted_at` ON `sorting_histories`(`deleted_at`);
+
+CREATE TABLE `discount_types` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `type_name` text,
+    `description` text
+);
+CREATE INDEX `idx_discount_types_deleted_at` ON `discount_types`(`deleted_at`);
+
+CREATE TABLE `promotions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `promotion_name` text,
+    `description` text,
+    `discount_value` integer,
+    `start_date` datetime,
+    `end_date` datetime,
+    `status` text,
+    `promo_image` text,
+    `discount_type_id` integer,
+    CONSTRAINT `fk_discount_types_promotions` FOREIGN KEY (`discount_type_id`) REFERENCES `discount_types`(`id`)
+);
+CREATE INDEX `idx_promotions_deleted_at` ON `promotions`(`deleted_at`);
+
+CREATE TABLE `promotion_conditions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `condition_type` text,
+    `value` text,
+    `promotion_id` integer,
+    CONSTRAINT `fk_promotions_promotion_condition` FOREIGN KEY (`promotion_id`) REFERENCES `promotions`(`id`)
+);
+CREATE INDEX `idx_promotion_conditions_deleted_at` ON `promotion_conditions`(`deleted_at`);
+
+CREATE TABLE `promotion_usages` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `usage_date` datetime,
+    `status` text,
+    `promotion_id` integer,
+    `order_id` integer,
+    `customer_id` integer,
+    CONSTRAINT `fk_promotion_usages_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers`(`id`),
+    CONSTRAINT `fk_orders_promotion_usage` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_promotions_promotion_usage` FOREIGN KEY (`promotion_id`) REFERENCES `promotions`(`id`)
+);
+CREATE INDEX `idx_promotion_usages_deleted_at` ON `promotion_usages`(`deleted_at`);
+
+CREATE TABLE `complaint_attachments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `complaint_id` integer,
+    `original_name` text,
+    `file_name` text,
+    `mime_type` text,
+    `size_bytes` integer,
+    `path` text,
+    `url` text,
+    `storage_key` text,
+    `thumb_key` text,
+    `uploaded_at` datetime,
+    CONSTRAINT `fk_complaints_attachments` FOREIGN KEY (`complaint_id`) REFERENCES `complaints`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX `idx_complaint_attachments_complaint_id` ON `complaint_attachments`(`complaint_id`);
+CREATE INDEX `idx_complaint_attachments_deleted_at` ON `complaint_attachments`(`deleted_at`);
+
+CREATE TABLE `detergent_usage_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `user_id` integer,
+    `detergent_id` integer,
+    `quantity_used` integer,
+    `reason` text,
+    CONSTRAINT `fk_detergents_detergent_usage_histories` FOREIGN KEY (`detergent_id`) REFERENCES `detergents`(`id`),
+    CONSTRAINT `fk_users_detergent_usage_histories` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX `idx_detergent_usage_histories_deleted_at` ON `detergent_usage_histories`(`deleted_at`);
+
+CREATE TABLE `receipts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `document_no` text,
+    `year` integer,
+    `seq_no` integer,
+    `status` text DEFAULT "issued",
+    `cancel_reason` text,
+    `cancelled_at` datetime,
+    `subtotal` real,
+    `discount` real,
+    `vat_amount` real,
+    `total` real,
+    `payment_method` text,
+    `issued_at` datetime,
+    `issued_by` integer,
+    `replaced_by_id` integer,
+    `order_id` integer,
+    `payment_id` integer,
+    CONSTRAINT `fk_receipts_order` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`),
+    CONSTRAINT `fk_receipts_payment` FOREIGN KEY (`payment_id`) REFERENCES `payments`(`id`)
+);
+CREATE INDEX `idx_receipts_payment_id` ON `receipts`(`payment_id`);
+CREATE INDEX `idx_receipts_order_id` ON `receipts`(`order_id`);
+CREATE INDEX `idx_receipts_year` ON `receipts`(`year`);
+CREATE UNIQUE INDEX `idx_receipts_document_no` ON `receipts`(`document_no`);
+CREATE INDEX `idx_receipts_deleted_at` ON `receipts`(`deleted_at`);
+
+CREATE TABLE `document_sequences` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `prefix` text,
+    `year` integer,
+    `last_no` integer
+);
+CREATE UNIQUE INDEX `idx_doc_seq` ON `document_sequences`(`prefix`,`year`);
+CREATE INDEX `idx_document_sequences_deleted_at` ON `document_sequences`(`deleted_at`);
+
+CREATE TABLE `cash_sessions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `business_date` text,
+    `status` text,
+    `opened_at` datetime,
+    `opening_float` real,
+    `closed_at` datetime,
+    `counted_amount` real,
+    `expected_amount` real,
+    `difference` real,
+    `note` text,
+    `employee_id` integer,
+    CONSTRAINT `fk_cash_sessions_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`)
+);
+CREATE INDEX `idx_cash_sessions_employee_id` ON `cash_sessions`(`employee_id`);
+CREATE INDEX `idx_cash_sessions_status` ON `cash_sessions`(`status`);
+CREATE INDEX `idx_cash_sessions_business_date` ON `cash_sessions`(`business_date`);
+CREATE INDEX `idx_cash_sessions_deleted_at` ON `cash_sessions`(`deleted_at`);
+
+CREATE TABLE `day_closings` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `business_date` text,
+    `closed_at` datetime,
+    `payment_count` integer,
+    `total_sales` real,
+    `cash_sales` real,
+    `transfer_sales` real,
+    `cash_over_short` real,
+    `note` text,
+    `closed_by` integer,
+    CONSTRAINT `fk_day_closings_employee` FOREIGN KEY (`closed_by`) REFERENCES `employees`(`id`)
+);
+CREATE UNIQUE INDEX `idx_day_closings_business_date` ON `day_closings`(`business_date`);
+CREATE INDEX `idx_day_closings_deleted_at` ON `day_closings`(`deleted_at`);
+
+CREATE TABLE `complaint_sla_policies` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text,
+    `category` text,
+    `priority` text,
+    `first_response_minutes` integer,
+    `resolution_minutes` integer,
+    `is_active` numeric
+);
+CREATE INDEX `idx_complaint_sla_policies_priority` ON `complaint_sla_policies`(`priority`);
+CREATE INDEX `idx_complaint_sla_policies_category` ON `complaint_sla_policies`(`category`);
+CREATE INDEX `idx_complaint_sla_policies_deleted_at` ON `complaint_sla_policies`(`deleted_at`);
+
+CREATE TABLE `complaint_categories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `code` text,
+    `name` text,
+    `default_priority` text,
+    `owner_position_id` integer,
+    `last_assigned_employee_id` integer,
+    `is_active` numeric,
+    CONSTRAINT `fk_complaint_categories_owner_position` FOREIGN KEY (`owner_position_id`) REFERENCES `positions`(`id`)
+);
+CREATE UNIQUE INDEX `idx_complaint_categories_code` ON `complaint_categories`(`code`);
+CREATE INDEX `idx_complaint_categories_deleted_at` ON `complaint_categories`(`deleted_at`);
+
+CREATE TABLE `shift_templates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text,
+    `start_time` text,
+    `end_time` text,
+    `break_minutes` integer,
+    `is_active` numeric
+);
+CREATE INDEX `idx_shift_templates_deleted_at` ON `shift_templates`(`deleted_at`);
+
+CREATE TABLE `employee_rosters` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `employee_id` integer,
+    `weekday` integer,
+    `shift_template_id` integer,
+    CONSTRAINT `fk_employee_rosters_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_employee_rosters_shift_template` FOREIGN KEY (`shift_template_id`) REFERENCES `shift_templates`(`id`)
+);
+CREATE UNIQUE INDEX `idx_roster_employee_weekday` ON `employee_rosters`(`employee_id`,`weekday`);
+CREATE INDEX `idx_employee_rosters_deleted_at` ON `employee_rosters`(`deleted_at`);
+
+CREATE TABLE `attendances` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `employee_id` integer,
+    `work_date` text,
+    `shift_template_id` integer,
+    `scheduled_start` datetime,
+    `scheduled_end` datetime,
+    `clock_in_at` datetime,
+    `clock_out_at` datetime,
+    `late_minutes` integer,
+    `early_leave_minutes` integer,
+    `worked_minutes` integer,
+    `note` text,
+    CONSTRAINT `fk_attendances_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_attendances_shift_template` FOREIGN KEY (`shift_template_id`) REFERENCES `shift_templates`(`id`)
+);
+CREATE INDEX `idx_attendances_work_date` ON `attendances`(`work_date`);
+CREATE INDEX `idx_attendances_employee_id` ON `attendances`(`employee_id`);
+CREATE INDEX `idx_attendances_deleted_at` ON `attendances`(`deleted_at`);
+
+CREATE TABLE `leave_requests` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `employee_id` integer,
+    `leave_type` text,
+    `start_date` text,
+    `end_date` text,
+    `reason` text,
+    `status` text,
+    `decided_by_id` integer,
+    `decided_at` datetime,
+    `decision_note` text,
+    `prev_status_id` integer,
+    `applied_at` datetime,
+    `restored_at` datetime,
+    CONSTRAINT `fk_leave_requests_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`),
+    CONSTRAINT `fk_leave_requests_decided_by` FOREIGN KEY (`decided_by_id`) REFERENCES `employees`(`id`)
+);
+CREATE INDEX `idx_leave_requests_status` ON `leave_requests`(`status`);
+CREATE INDEX `idx_leave_requests_end_date` ON `leave_requests`(`end_date`);
+CREATE INDEX `idx_leave_requests_start_date` ON `leave_requests`(`start_date`);
+CREATE INDEX `idx_leave_requests_employee_id` ON `leave_requests`(`employee_id`);
+CREATE INDEX `idx_leave_requests_deleted_at` ON `leave_requests`(`deleted_at`);
+
+CREATE TABLE `password_reset_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `user_id` integer,
+    `token_hash` text,
+    `expires_at` datetime,
+    `used_at` datetime,
+    CONSTRAINT `fk_password_reset_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE UNIQUE INDEX `idx_password_reset_tokens_token_hash` ON `password_reset_tokens`(`token_hash`);
+CREATE INDEX `idx_password_reset_tokens_user_id` ON `password_reset_tokens`(`user_id`);
+CREATE INDEX `idx_password_reset_tokens_deleted_at` ON `password_reset_tokens`(`deleted_at`);
+
+CREATE TABLE `auth_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `user_id` integer,
+    `email` text,
+    `event_type` text,
+    `reason` text,
+    `ip` text,
+    `user_agent` text,
+    `actor_id` integer,
+    CONSTRAINT `fk_auth_events_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX `idx_auth_events_event_type` ON `auth_events`(`event_type`);
+CREATE INDEX `idx_auth_events_email` ON `auth_events`(`email`);
+CREATE INDEX `idx_auth_events_user_id` ON `auth_events`(`user_id`);
+CREATE INDEX `idx_auth_events_created_at` ON `auth_events`(`created_at`);
+
+CREATE TABLE `audit_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `actor_user_id` integer,
+    `actor_ip` text,
+    `route` text,
+    `entity_type` text,
+    `entity_id` integer,
+    `action` text,
+    `before_json` text,
+    `after_json` text,
+    CONSTRAINT `fk_audit_logs_actor_user` FOREIGN KEY (`actor_user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX `idx_audit_entity` ON `audit_logs`(`entity_type`,`entity_id`);
+CREATE INDEX `idx_audit_logs_actor_user_id` ON `audit_logs`(`actor_user_id`);
+CREATE INDEX `idx_audit_logs_created_at` ON `audit_logs`(`created_at`);
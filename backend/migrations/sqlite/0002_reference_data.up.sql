@@ -0,0 +1,59 @@
+-- 0002 ข้อมูลอ้างอิงที่ระบบต้องมี (ไม่ใช่ข้อมูลตัวอย่าง)
+-- role id ถูกอ้างในโค้ด: 1 = admin, 2 = customer, 3 = employee
+
+INSERT INTO `roles` (`id`, `created_at`, `updated_at`, `name`) VALUES
+    (1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'admin'),
+    (2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'customer'),
+    (3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'employee');
+
+INSERT INTO `genders` (`created_at`, `updated_at`, `name`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ชาย'),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'หญิง'),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'อืนๆ');
+
+INSERT INTO `service_types` (`created_at`, `updated_at`, `type`, `price`, `capacity`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ซัก 10kg', 50, 10),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ซัก 14kg', 70, 14),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ซัก 18kg', 90, 18),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ซัก 28kg', 120, 28),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'อบ 14kg', 50, 14),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'อบ 25kg', 70, 25),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ไม่อบ', 0, 0);
+
+INSERT INTO `detergent_categories` (`created_at`, `updated_at`, `name`, `description`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'น้ำยาซัก', 'สำหรับทำความสะอาดเสื้อผ้า'),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ปรับผ้านุ่ม', 'สำหรับทำให้ผ้านุ่มและมีกลิ่นหอม');
+
+INSERT INTO `discount_types` (`created_at`, `updated_at`, `type_name`, `description`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'เปอร์เซ็นต์', 'ลดเป็นเปอร์เซ็นต์'),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'จำนวนเงิน', 'ลดเป็นจำนวนเงิน');
+
+INSERT INTO `positions` (`created_at`, `updated_at`, `position_name`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'พนักงานซักผ้า'),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'พนักงานขนส่งผ้า'),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'หัวหน้างาน');
+
+-- SLA คำร้องเรียน (ค่าเริ่มต้น)
+INSERT INTO `complaint_sla_policies` (`created_at`, `updated_at`, `name`, `category`, `priority`, `first_response_minutes`, `resolution_minutes`, `is_active`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'มาตรฐาน', '', '', 240, 2880, 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ด่วน', '', 'high', 60, 1440, 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ด่วนมาก', '', 'urgent', 30, 480, 1);
+
+-- หมวดคำร้องเรียน -> ตำแหน่งที่รับผิดชอบ
+INSERT INTO `complaint_categories` (`created_at`, `updated_at`, `code`, `name`, `default_priority`, `owner_position_id`, `is_active`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'damaged_clothes', 'ผ้าเสียหาย', 'high', (SELECT `id` FROM `positions` WHERE `position_name` = 'พนักงานซักผ้า'), 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'lost_item', 'ของสูญหาย', 'high', (SELECT `id` FROM `positions` WHERE `position_name` = 'พนักงานซักผ้า'), 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'late_delivery', 'ส่งผ้าล่าช้า', 'normal', (SELECT `id` FROM `positions` WHERE `position_name` = 'พนักงานขนส่งผ้า'), 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'payment_issue', 'ปัญหาการชำระเงิน', 'normal', (SELECT `id` FROM `positions` WHERE `position_name` = 'หัวหน้างาน'), 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'staff_behaviour', 'พฤติกรรมพนักงาน', 'high', (SELECT `id` FROM `positions` WHERE `position_name` = 'หัวหน้างาน'), 1);
+
+-- เทมเพลตตอบกลับคำร้องเรียน (ค่าเริ่มต้น)
+INSERT INTO `reply_templates` (`created_at`, `updated_at`, `title`, `category`, `body`, `is_active`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'รับเรื่องแล้ว', '', 'เรียนคุณ {customerName} ทางร้านได้รับคำร้องเรียนเลขที่ {publicId} เรื่อง "{subject}" แล้ว และจะแจ้งความคืบหน้าโดยเร็วที่สุด ขออภัยในความไม่สะดวกค่ะ', 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ชดเชยผ้าเสียหาย', 'damaged_clothes', 'เรียนคุณ {customerName} ทางร้านขออภัยที่ผ้าในคำสั่งซื้อ {orderId} ได้รับความเสียหาย ทางร้านขอชดเชยเป็น {compensation} ค่ะ', 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'ส่งผ้าล่าช้า', 'late_delivery', 'เรียนคุณ {customerName} ขออภัยที่การจัดส่งคำสั่งซื้อ {orderId} ล่าช้า ขณะนี้ได้เร่งดำเนินการจัดส่งให้แล้วค่ะ', 1);
+
+-- กะการทำงาน (ค่าเริ่มต้น)
+INSERT INTO `shift_templates` (`created_at`, `updated_at`, `name`, `start_time`, `end_time`, `break_minutes`, `is_active`) VALUES
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'กะเช้า', '08:00', '17:00', 60, 1),
+    (CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'กะบ่าย', '13:00', '22:00', 60, 1);
@@ -0,0 +1,3 @@
+-- ย้อน 0003: ลบ unique index (ใบที่ถูกยกเลิกเพราะซ้ำไม่คืนสถานะ)
+
+DROP INDEX IF EXISTS `idx_receipts_issued_order`;
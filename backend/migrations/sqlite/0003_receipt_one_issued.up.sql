@@ -0,0 +1,8 @@
+-- 0003 ออเดอร์หนึ่งมีใบเสร็จสถานะ issued ได้ใบเดียว (กันออกซ้ำเมื่อมีคำขอพร้อมกัน)
+-- ใบที่ออกซ้ำไปแล้วเก็บใบล่าสุดไว้ ใบอื่นยกเลิก (คงเลขที่เอกสารไว้)
+
+UPDATE `receipts` SET `status` = 'cancelled', `cancel_reason` = 'ออกซ้ำ', `cancelled_at` = CURRENT_TIMESTAMP
+WHERE `status` = 'issued' AND `deleted_at` IS NULL
+  AND `id` NOT IN (SELECT MAX(`id`) FROM `receipts` WHERE `status` = 'issued' AND `deleted_at` IS NULL GROUP BY `order_id`);
+
+CREATE UNIQUE INDEX `idx_receipts_issued_order` ON `receipts`(`order_id`) WHERE `status` = 'issued' AND `deleted_at` IS NULL;
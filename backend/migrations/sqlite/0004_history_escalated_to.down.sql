@@ -0,0 +1,6 @@
+-- ย้อน 0004: คืนผู้รับเรื่องไปไว้ใน changed_by แล้วลบคอลัมน์
+
+UPDATE `history_complains` SET `changed_by` = `escalated_to_id`
+WHERE `changed_by` IS NULL AND `escalated_to_id` IS NOT NULL;
+
+ALTER TABLE `history_complains` DROP COLUMN `escalated_to_id`;
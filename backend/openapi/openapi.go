@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/OnpreeyaMi/project-sa/api"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// เอกสาร OpenAPI 3 ของ API ทั้งหมด (openapi.yaml ในโฟลเดอร์นี้ = ต้นฉบับที่แก้ด้วยมือ)
+// - GET /openapi.json เอกสารเดียวกันในรูป JSON (ให้ frontend ใช้ gen type/client)
+// - GET /docs         หน้าเอกสาร Swagger UI
+// - middlewares.ValidateRequest ตรวจคำขอกับเอกสารนี้ก่อนถึง handler
+// เพิ่ม/แก้ route ใน main.go ต้องแก้ openapi.yaml ด้วย (TestOpenAPICoversAllRoutes ตรวจให้)
+// ======================================================
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+var (
+	loadOnce sync.Once
+	doc      *openapi3.T
+	docJSON  []byte
+	loadErr  error
+)
+
+// Load อ่านและตรวจความถูกต้องของเอกสาร (ทำครั้งเดียว ใช้ผลเดิมตลอด)
+func Load() (*openapi3.T, error) {
+	loadOnce.Do(func() {
+		d, err := openapi3.NewLoader().LoadFromData(specYAML)
+		if err != nil {
+			loadErr = fmt.Errorf("openapi.yaml: %w", err)
+			return
+		}
+		if err := d.Validate(context.Background()); err != nil {
+			loadErr = fmt.Errorf("openapi.yaml ไม่ถูกต้อง: %w", err)
+			return
+		}
+		if docJSON, err = d.MarshalJSON(); err != nil {
+			loadErr = fmt.Errorf("openapi.yaml: %w", err)
+			return
+		}
+		doc = d
+	})
+	return doc, loadErr
+}
+
+// MustLoad เหมือน Load แต่ panic เมื่อเอกสารเสีย (เอกสารฝังมากับโปรแกรม ผิด = บั๊ก; main เรียก Load ตรวจก่อนแล้ว)
+func MustLoad() *openapi3.T {
+	d, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// PathFromGin แปลง route template ของ gin เป็น path ของ OpenAPI: /customers/:id -> /customers/{id}
+func PathFromGin(route string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Operation หา operation ของ method + route template ของ gin (nil = ไม่มีในเอกสาร)
+func Operation(d *openapi3.T, method, route string) *openapi3.Operation {
+	item := d.Paths.Find(PathFromGin(route))
+	if item == nil {
+		return nil
+	}
+	return item.GetOperation(method)
+}
+
+// Spec GET /openapi.json
+func Spec(c *gin.Context) {
+	if _, err := Load(); err != nil {
+		api.Fail(c, api.Internal(err))
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docJSON)
+}
+
+// Docs GET /docs (Swagger UI จาก CDN อ่าน /openapi.json)
+func Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsHTML))
+}
+
+const docsHTML = `<!doctype html>
+<html lang="th">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Project SA API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>
+`
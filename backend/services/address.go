@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ที่อยู่ของลูกค้า
+// - ลูกค้าหนึ่งคนมีที่อยู่หลัก (is_default) ได้ที่เดียว
+// - เปลี่ยนที่อยู่หลักในธุรกรรมเดียว (ไม่พบที่อยู่ = ไม่แตะที่อยู่หลักเดิม)
+// ======================================================
+
+var ErrAddressNotFound = errors.New("address_not_found")
+
+// NewAddress ที่อยู่ใหม่ของลูกค้า
+type NewAddress struct {
+	CustomerID     uint
+	AddressDetails string
+	Latitude       float64
+	Longitude      float64
+}
+
+// AddressService เพิ่มที่อยู่/ตั้งที่อยู่หลัก
+type AddressService struct {
+	db *gorm.DB
+}
+
+func NewAddressService(db *gorm.DB) *AddressService {
+	return &AddressService{db: db}
+}
+
+// Create เพิ่มที่อยู่ให้ลูกค้า
+func (s *AddressService) Create(in NewAddress) (*entity.Address, error) {
+	addr := entity.Address{
+		AddressDetails: in.AddressDetails,
+		Latitude:       in.Latitude,
+		Longitude:      in.Longitude,
+		CustomerID:     in.CustomerID,
+	}
+	if err := s.db.Create(&addr).Error; err != nil {
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// SetDefault ตั้งที่อยู่หลักของลูกค้า (ที่อยู่อื่นของลูกค้าไม่เป็นที่อยู่หลักอีก)
+func (s *AddressService) SetDefault(customerID, addressID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&entity.Address{}).Where("id = ? AND customer_id = ?", addressID, customerID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrAddressNotFound
+		}
+		if err := tx.Model(&entity.Address{}).
+			Where("customer_id = ?", customerID).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.Address{}).
+			Where("id = ?", addressID).
+			Update("is_default", true).Error
+	})
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func smallPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// ไฟล์ไม่กี่ร้อยไบต์แต่ IHDR ประกาศขนาด 10000x10000 (100 ล้านพิกเซล)
+func oversizedPNG(t *testing.T) []byte {
+	t.Helper()
+	data := smallPNG(t)
+	// signature 8 ไบต์ แล้วตามด้วย IHDR: length(4) type(4) width(4) height(4) ... crc(4)
+	ihdr := data[8+4 : 8+4+4+13]
+	binary.BigEndian.PutUint32(ihdr[4:], 10000)
+	binary.BigEndian.PutUint32(ihdr[8:], 10000)
+	binary.BigEndian.PutUint32(data[8+4+4+13:], crc32.ChecksumIEEE(ihdr))
+	return data
+}
+
+func TestImageProcessingRejectsOversizedImages(t *testing.T) {
+	big := oversizedPNG(t)
+	if cfg, err := png.DecodeConfig(bytes.NewReader(big)); err != nil || cfg.Width != 10000 {
+		t.Fatalf("crafted header not valid: %+v, %v", cfg, err)
+	}
+
+	if _, err := StripImageMetadata(big, "image/png"); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("StripImageMetadata err = %v, want ErrImageTooLarge", err)
+	}
+	if _, err := MakeThumbnail(big, "image/png", 320); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("MakeThumbnail err = %v, want ErrImageTooLarge", err)
+	}
+
+	small := smallPNG(t)
+	out, err := StripImageMetadata(small, "image/png")
+	if err != nil {
+		t.Fatalf("StripImageMetadata small image: %v", err)
+	}
+	if _, err := MakeThumbnail(out, "image/png", 320); err != nil {
+		t.Errorf("MakeThumbnail small image: %v", err)
+	}
+}
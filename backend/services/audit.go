@@ -0,0 +1,422 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ======================================================
+// Audit log ผ่าน GORM callbacks
+// - ลงทะเบียนตารางที่ต้องตรวจด้วย RegisterAuditCallbacks (เรียกครั้งเดียวตอนเริ่มโปรแกรม)
+// - ทุก create/update/delete ของตารางนั้นจะเก็บแถวก่อน/หลังแล้วบันทึกเฉพาะคอลัมน์ที่เปลี่ยน
+// - ผู้ทำรายการมาจาก context ของ statement (db.WithContext(WithAuditActor(...)))
+//   ไม่มี actor = ระบบ (เช่น งานเบื้องหลัง)
+// ======================================================
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+type AuditActor struct {
+	UserID *uint
+	IP     string
+	Route  string // เช่น "PUT /promotions/:id"
+}
+
+type auditActorKey struct{}
+
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func auditActorFrom(ctx context.Context) AuditActor {
+	if ctx != nil {
+		if a, ok := ctx.Value(auditActorKey{}).(AuditActor); ok {
+			return a
+		}
+	}
+	return AuditActor{}
+}
+
+// คอลัมน์ที่ไม่ถือว่าเป็นการเปลี่ยนข้อมูล / ต้องปิดค่า
+var (
+	auditIgnoredColumns = map[string]bool{"created_at": true, "updated_at": true}
+	auditMaskedColumns  = map[string]bool{"password": true}
+	auditMask           = "***"
+)
+
+var (
+	auditMu     sync.RWMutex
+	auditTables = map[string]string{} // ชื่อตาราง -> ชื่อ entity
+	// คอลัมน์ที่ไม่นับเพิ่มเติมรายตาราง (เช่น ตัวนับล็อกอินผิดของ users)
+	auditTableIgnored = map[string]map[string]bool{
+		"users": {"failed_login_count": true, "locked_until": true},
+	}
+)
+
+// AuditEntityName แปลงชื่อ entity/ตารางที่ผู้ใช้ส่งมา (ไม่สนตัวพิมพ์) เป็นชื่อ entity ที่บันทึก
+func AuditEntityName(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	auditMu.RLock()
+	defer auditMu.RUnlock()
+	for table, name := range auditTables {
+		if s == table || s == strings.ToLower(name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// AuditEntities รายชื่อ entity ที่ตรวจอยู่
+func AuditEntities() []string {
+	auditMu.RLock()
+	defer auditMu.RUnlock()
+	out := make([]string, 0, len(auditTables))
+	for _, name := range auditTables {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// RegisterAuditCallbacks เปิด audit ให้ models ที่ระบุ
+func RegisterAuditCallbacks(db *gorm.DB, models ...interface{}) error {
+	auditMu.Lock()
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			auditMu.Unlock()
+			return err
+		}
+		auditTables[stmt.Schema.Table] = stmt.Schema.Name
+	}
+	auditMu.Unlock()
+
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("audit:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("audit:before_update", auditBeforeChange); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("audit:after_update", auditAfterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("audit:before_delete", auditBeforeChange); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("audit:after_delete", auditAfterDelete)
+}
+
+func auditedEntity(db *gorm.DB) (string, bool) {
+	if db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return "", false
+	}
+	auditMu.RLock()
+	defer auditMu.RUnlock()
+	name, ok := auditTables[db.Statement.Table]
+	return name, ok
+}
+
+// session ใหม่บน connection/ธุรกรรมเดิม ไม่ผ่าน callback ของ audit ซ้ำ
+func auditSession(db *gorm.DB) *gorm.DB {
+	// ต้องใส่ Context ใน Session เดียวกัน (WithContext ต่อท้ายจะได้ statement เดิมกลับมา)
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: db.Statement.Context})
+}
+
+// query บนตารางเดียวกับ statement; ใช้ Model (ไม่ใช่ Table) เพื่อให้เงื่อนไขแบบ Delete(&T{}, id)
+// ที่อ้าง primary key แบบลอยๆ แปลงเป็นคอลัมน์ได้ และปิด soft delete อัตโนมัติ (กรองเองตามต้องการ)
+func auditQuery(db *gorm.DB) *gorm.DB {
+	return auditSession(db).Model(reflect.New(db.Statement.Schema.ModelType).Interface()).Unscoped()
+}
+
+func primaryKeyColumn(db *gorm.DB) string {
+	return db.Statement.Schema.PrioritizedPrimaryField.DBName
+}
+
+// ค่า primary key จาก model/ข้อมูลที่ส่งเข้า statement (struct หรือ slice)
+func statementPrimaryKeys(db *gorm.DB) []interface{} {
+	stmt := db.Statement
+	field := stmt.Schema.PrioritizedPrimaryField
+	var ids []interface{}
+	collect := func(rv reflect.Value) {
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+		if v, zero := field.ValueOf(stmt.Context, rv); !zero {
+			ids = append(ids, v)
+		}
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			collect(stmt.ReflectValue.Index(i))
+		}
+	default:
+		collect(stmt.ReflectValue)
+	}
+	return ids
+}
+
+// หาแถวที่ statement จะกระทบ: WHERE ที่ผู้เรียกใส่ + primary key ของ model
+func auditTargetRows(db *gorm.DB) ([]map[string]interface{}, error) {
+	stmt := db.Statement
+	q := auditQuery(db)
+	cond := false
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if w, ok := c.Expression.(clause.Where); ok && len(w.Exprs) > 0 {
+			q = q.Clauses(clause.Where{Exprs: w.Exprs})
+			cond = true
+		}
+	}
+	if ids := statementPrimaryKeys(db); len(ids) > 0 {
+		q = q.Where(clause.IN{Column: clause.Column{Name: primaryKeyColumn(db)}, Values: ids})
+		cond = true
+	}
+	if !cond {
+		return nil, nil // ไม่มีเงื่อนไข GORM จะปฏิเสธเองอยู่แล้ว
+	}
+	if !stmt.Unscoped && stmt.Schema.LookUpField("DeletedAt") != nil {
+		q = q.Where(clause.Expr{SQL: "deleted_at IS NULL"})
+	}
+	var rows []map[string]interface{}
+	err := q.Find(&rows).Error
+	return rows, err
+}
+
+func auditRowsByID(db *gorm.DB, ids []interface{}) (map[string]map[string]interface{}, error) {
+	out := map[string]map[string]interface{}{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	pk := primaryKeyColumn(db)
+	var rows []map[string]interface{}
+	if err := auditQuery(db).
+		Where(clause.IN{Column: clause.Column{Name: pk}, Values: ids}).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[fmt.Sprint(r[pk])] = r
+	}
+	return out, nil
+}
+
+func auditBeforeChange(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if _, ok := auditedEntity(db); !ok {
+		return
+	}
+	rows, err := auditTargetRows(db)
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "audit: load before rows", "error", err)
+		return
+	}
+	db.InstanceSet("audit:before", rows)
+}
+
+func auditBefore(db *gorm.DB) []map[string]interface{} {
+	v, ok := db.InstanceGet("audit:before")
+	if !ok {
+		return nil
+	}
+	rows, _ := v.([]map[string]interface{})
+	return rows
+}
+
+// ค่าที่เก็บลง JSON (ไบต์ -> ข้อความ, เวลา -> RFC3339)
+func auditValue(col string, v interface{}) interface{} {
+	if auditMaskedColumns[col] {
+		return auditMask
+	}
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case *time.Time:
+		if t == nil {
+			return nil
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func auditIgnored(table, col string) bool {
+	return auditIgnoredColumns[col] || auditTableIgnored[table][col]
+}
+
+// เฉพาะคอลัมน์ที่เปลี่ยน (ค่ารหัสผ่านเปลี่ยนแต่บันทึกเป็น ***)
+func auditDiff(table string, before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	b, a := map[string]interface{}{}, map[string]interface{}{}
+	for col, nv := range after {
+		if auditIgnored(table, col) {
+			continue
+		}
+		ov := before[col]
+		if fmt.Sprint(auditValue("", ov)) == fmt.Sprint(auditValue("", nv)) {
+			continue
+		}
+		b[col] = auditValue(col, ov)
+		a[col] = auditValue(col, nv)
+	}
+	return b, a
+}
+
+func auditSnapshot(table string, row map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for col, v := range row {
+		if !auditIgnored(table, col) {
+			out[col] = auditValue(col, v)
+		}
+	}
+	return out
+}
+
+func auditJSON(m map[string]interface{}) string {
+	if m == nil {
+		return ""
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func writeAuditLogs(db *gorm.DB, logs []entity.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	actor := auditActorFrom(db.Statement.Context)
+	for i := range logs {
+		logs[i].ActorUserID = actor.UserID
+		logs[i].ActorIP = actor.IP
+		logs[i].Route = actor.Route
+	}
+	if err := auditSession(db).Create(&logs).Error; err != nil {
+		slog.ErrorContext(db.Statement.Context, "audit: write", "error", err)
+	}
+}
+
+func auditRowID(db *gorm.DB, row map[string]interface{}) uint {
+	var id uint
+	fmt.Sscan(fmt.Sprint(row[primaryKeyColumn(db)]), &id)
+	return id
+}
+
+func auditAfterCreate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	name, ok := auditedEntity(db)
+	if !ok {
+		return
+	}
+	rows, err := auditRowsByID(db, statementPrimaryKeys(db))
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "audit: load created rows", "error", err)
+		return
+	}
+	logs := make([]entity.AuditLog, 0, len(rows))
+	for _, r := range rows {
+		logs = append(logs, entity.AuditLog{
+			EntityType: name,
+			EntityID:   auditRowID(db, r),
+			Action:     AuditCreate,
+			After:      auditJSON(auditSnapshot(db.Statement.Table, r)),
+		})
+	}
+	writeAuditLogs(db, logs)
+}
+
+func auditAfterUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	name, ok := auditedEntity(db)
+	if !ok {
+		return
+	}
+	before := auditBefore(db)
+	if len(before) == 0 {
+		return
+	}
+	pk := primaryKeyColumn(db)
+	ids := make([]interface{}, 0, len(before))
+	for _, r := range before {
+		ids = append(ids, r[pk])
+	}
+	after, err := auditRowsByID(db, ids)
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "audit: load updated rows", "error", err)
+		return
+	}
+	var logs []entity.AuditLog
+	for _, old := range before {
+		cur, ok := after[fmt.Sprint(old[pk])]
+		if !ok {
+			continue
+		}
+		b, a := auditDiff(db.Statement.Table, old, cur)
+		if len(a) == 0 {
+			continue
+		}
+		action := AuditUpdate
+		// soft delete ผ่าน Update("deleted_at") ถือเป็นการลบ
+		if _, ok := a["deleted_at"]; ok && cur["deleted_at"] != nil {
+			action = AuditDelete
+		}
+		logs = append(logs, entity.AuditLog{
+			EntityType: name,
+			EntityID:   auditRowID(db, old),
+			Action:     action,
+			Before:     auditJSON(b),
+			After:      auditJSON(a),
+		})
+	}
+	writeAuditLogs(db, logs)
+}
+
+func auditAfterDelete(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	name, ok := auditedEntity(db)
+	if !ok {
+		return
+	}
+	before := auditBefore(db)
+	logs := make([]entity.AuditLog, 0, len(before))
+	for _, r := range before {
+		logs = append(logs, entity.AuditLog{
+			EntityType: name,
+			EntityID:   auditRowID(db, r),
+			Action:     AuditDelete,
+			Before:     auditJSON(auditSnapshot(db.Statement.Table, r)),
+		})
+	}
+	writeAuditLogs(db, logs)
+}
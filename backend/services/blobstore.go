@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+)
+
+// BlobStore ที่เก็บไฟล์ (ดิสก์ในเครื่องตอนนี้, S3-compatible ภายหลัง)
+// key ใช้ "/" คั่นเสมอ เช่น complaints/CMP-.../photo.jpg
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	ErrBlobNotFound   = errors.New("blob not found")
+	ErrBlobInvalidKey = errors.New("invalid blob key")
+)
+
+// NewBlobStore เลือก store ตาม config.BlobSettings (ตอนนี้รองรับ local)
+func NewBlobStore(cfg config.BlobSettings) (BlobStore, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalBlobStore(cfg.LocalRoot), nil
+	default:
+		return nil, fmt.Errorf("unsupported BLOB_DRIVER %q", cfg.Driver)
+	}
+}
+
+// ---------- local disk ----------
+
+type LocalBlobStore struct {
+	Root string
+}
+
+func NewLocalBlobStore(root string) *LocalBlobStore {
+	return &LocalBlobStore{Root: root}
+}
+
+// กัน path traversal: key ต้องเป็น path สัมพัทธ์ที่ไม่ออกนอก Root
+func (s *LocalBlobStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || strings.Contains(key, "\\") || clean != "/"+key {
+		return "", ErrBlobInvalidKey
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(clean[1:])), nil
+}
+
+func (s *LocalBlobStore) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// เขียนไฟล์ชั่วคราวก่อนแล้วค่อย rename กันไฟล์ครึ่งๆ กลางๆ
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalBlobStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return f, err
+}
+
+func (s *LocalBlobStore) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// คำร้องเรียน: สร้าง (ผูกออเดอร์ + หมวด + SLA + มอบหมาย), ตอบกลับ, เปลี่ยนสถานะ, ข้อความ/คะแนนจากลูกค้า
+// การค้นหาคำร้อง (และตรวจเจ้าของ) อยู่ที่ controller; service รับคำร้องที่โหลดแล้ว
+// ======================================================
+
+// สถานะคำร้อง (ComplaintStatusClosed อยู่ใน complaint_sla.go)
+const (
+	ComplaintStatusNew        = "รอดำเนินการ"
+	ComplaintStatusInProgress = "กำลังดำเนินการ"
+)
+
+var (
+	ErrInvalidComplaintTransition = errors.New("invalid_transition")
+	ErrComplaintReasonRequired    = errors.New("reason_required")
+	ErrComplaintOrderNotFound     = errors.New("order_not_found")
+	ErrComplaintItemNotInOrder    = errors.New("item_not_in_order")
+	ErrComplaintClosed            = errors.New("complaint_closed")
+	ErrComplaintNotClosed         = errors.New("complaint_not_closed")
+	ErrComplaintAlreadyRated      = errors.New("complaint_already_rated")
+)
+
+// สถานะปลายทางที่อนุญาต (true = ต้องระบุเหตุผล เช่น เปิดงานใหม่/ย้อนสถานะ)
+var complaintTransitions = map[string]map[string]bool{
+	ComplaintStatusNew:        {ComplaintStatusInProgress: false, ComplaintStatusClosed: false},
+	ComplaintStatusInProgress: {ComplaintStatusClosed: false, ComplaintStatusNew: true},
+	ComplaintStatusClosed:     {ComplaintStatusInProgress: true, ComplaintStatusNew: true},
+}
+
+// ComplaintService ขั้นตอนของคำร้องเรียน
+type ComplaintService struct {
+	db *gorm.DB
+}
+
+func NewComplaintService(db *gorm.DB) *ComplaintService {
+	return &ComplaintService{db: db}
+}
+
+// NewComplaint คำร้องใหม่จากลูกค้า
+type NewComplaint struct {
+	CustomerID  uint
+	Title       string
+	Description string
+	Email       string
+	Category    string // รหัสหมวด (ว่าง = ไม่จัดหมวด)
+	Priority    string // ว่าง = ตามหมวด
+	OrderID     *uint  // ต้องเป็นออเดอร์ของลูกค้าคนนี้
+	ItemIDs     []uint // SortedClothes ในออเดอร์ที่มีปัญหา
+	PublicID    string
+}
+
+// Create บันทึกคำร้อง: ผูกออเดอร์ -> หมวด/ระดับความสำคัญ -> กำหนด SLA -> มอบหมายพนักงานตามหมวด
+func (s *ComplaintService) Create(in NewComplaint) (*entity.Complaint, error) {
+	comp := entity.Complaint{
+		StatusComplaint: ComplaintStatusNew,
+		Title:           in.Title,
+		Description:     in.Description,
+		CreateDate:      time.Now(),
+		Email:           in.Email,
+		PublicID:        in.PublicID,
+		Category:        in.Category,
+		Priority:        in.Priority,
+		CustomerID:      in.CustomerID,
+	}
+	if in.OrderID != nil {
+		if err := linkComplaintToOrder(s.db, &comp, *in.OrderID, in.ItemIDs); err != nil {
+			return nil, err
+		}
+	}
+	cat, err := ApplyComplaintCategory(s.db, &comp)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyComplaintSLA(s.db, &comp); err != nil {
+		return nil, err
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comp).Error; err != nil {
+			return err
+		}
+		return AssignComplaint(tx, &comp, cat, comp.CreateDate)
+	}); err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+// ตรวจว่าออเดอร์เป็นของลูกค้า แล้วผูก process ล่าสุด, payment และรายการผ้าที่ระบุ
+func linkComplaintToOrder(db *gorm.DB, comp *entity.Complaint, orderID uint, itemIDs []uint) error {
+	var order entity.Order
+	err := db.Preload("LaundryProcesses").
+		Preload("Payment", func(tx *gorm.DB) *gorm.DB { return tx.Omit("check_payment_b64") }).
+		Preload("SortingRecord.SortedClothes").
+		Where("id = ? AND customer_id = ?", orderID, comp.CustomerID).
+		First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrComplaintOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	comp.OrderID = &order.ID
+	var latest *entity.LaundryProcess
+	for _, p := range order.LaundryProcesses {
+		if p != nil && (latest == nil || p.ID > latest.ID) {
+			latest = p
+		}
+	}
+	if latest != nil {
+		comp.LaundryProcessID = &latest.ID
+	}
+	if order.Payment != nil {
+		comp.PaymentID = &order.Payment.ID
+	}
+
+	if len(itemIDs) > 0 {
+		owned := map[uint]*entity.SortedClothes{}
+		if order.SortingRecord != nil {
+			for _, sc := range order.SortingRecord.SortedClothes {
+				if sc != nil {
+					owned[sc.ID] = sc
+				}
+			}
+		}
+		comp.SortedClothes = make([]*entity.SortedClothes, 0, len(itemIDs))
+		for _, id := range itemIDs {
+			sc, ok := owned[id]
+			if !ok {
+				return ErrComplaintItemNotInOrder
+			}
+			comp.SortedClothes = append(comp.SortedClothes, sc)
+		}
+	}
+	return nil
+}
+
+// ComplaintReply คำตอบจากพนักงาน (Text = ข้อความสุดท้ายหลังเติมเทมเพลตแล้ว)
+type ComplaintReply struct {
+	EmployeeID uint
+	Text       string
+	TemplateID *uint
+	Internal   bool   // บันทึกภายใน ไม่แสดงให้ลูกค้าเห็น และไม่นับเป็นการตอบกลับตาม SLA
+	NewStatus  string // ว่าง = ไม่เปลี่ยนสถานะ
+	Note       string // เหตุผลการเปลี่ยนสถานะ (ว่าง = ใช้ข้อความตอบกลับ)
+}
+
+// Reply บันทึกคำตอบ หยุดนับ SLA ตอบกลับครั้งแรก และเปลี่ยนสถานะ (ถ้าระบุ) ในธุรกรรมเดียว
+func (s *ComplaintService) Reply(comp *entity.Complaint, in ComplaintReply) (*entity.ReplyComplaint, error) {
+	rep := entity.ReplyComplaint{
+		CreateReplyDate: time.Now(),
+		Reply:           in.Text,
+		EmpID:           in.EmployeeID,
+		ComplaintID:     comp.ID,
+		AuthorType:      "employee",
+		IsInternal:      in.Internal,
+		TemplateID:      in.TemplateID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rep).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if comp.FirstRespondedAt == nil && !in.Internal {
+			updates["first_responded_at"] = rep.CreateReplyDate
+			if comp.FirstResponseBreachedAt == nil && comp.FirstResponseDueAt != nil && rep.CreateReplyDate.After(*comp.FirstResponseDueAt) {
+				updates["first_response_breached_at"] = rep.CreateReplyDate
+			}
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(comp).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if in.NewStatus != "" {
+			note := strings.TrimSpace(in.Note)
+			if note == "" {
+				note = strings.TrimSpace(in.Text)
+			}
+			return changeComplaintStatus(tx, comp, in.NewStatus, in.EmployeeID, note, rep.CreateReplyDate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// SetStatus เปลี่ยนสถานะตาม complaintTransitions พร้อมประวัติ
+func (s *ComplaintService) SetStatus(comp *entity.Complaint, status string, employeeID uint, note string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return changeComplaintStatus(tx, comp, status, employeeID, strings.TrimSpace(note), time.Now())
+	})
+}
+
+// PostCustomerMessage ข้อความเพิ่มเติมจากลูกค้า (คำร้องที่ปิดงานแล้วส่งไม่ได้)
+func (s *ComplaintService) PostCustomerMessage(comp *entity.Complaint, text string) (*entity.ReplyComplaint, error) {
+	if comp.StatusComplaint == ComplaintStatusClosed {
+		return nil, ErrComplaintClosed
+	}
+	msg := entity.ReplyComplaint{
+		CreateReplyDate: time.Now(),
+		Reply:           text,
+		ComplaintID:     comp.ID,
+		AuthorType:      "customer",
+		CustomerID:      &comp.CustomerID,
+	}
+	if err := s.db.Create(&msg).Error; err != nil {
+		return nil, err
+	}
+	s.db.Preload("Customer").First(&msg, msg.ID)
+	return &msg, nil
+}
+
+// Rate คะแนนความพึงพอใจ (หลังปิดงาน ให้ได้ครั้งเดียว)
+func (s *ComplaintService) Rate(comp *entity.Complaint, score int, comment string) (time.Time, error) {
+	if comp.StatusComplaint != ComplaintStatusClosed {
+		return time.Time{}, ErrComplaintNotClosed
+	}
+	if comp.SatisfactionRating != nil {
+		return time.Time{}, ErrComplaintAlreadyRated
+	}
+	now := time.Now()
+	err := s.db.Model(comp).Updates(map[string]interface{}{
+		"satisfaction_rating":  score,
+		"satisfaction_comment": strings.TrimSpace(comment),
+		"rated_at":             now,
+	}).Error
+	return now, err
+}
+
+// เปลี่ยนสถานะและเขียนประวัติในธุรกรรมเดียวกัน (สถานะเดิม = สถานะใหม่ จะไม่ทำอะไร)
+func changeComplaintStatus(tx *gorm.DB, comp *entity.Complaint, newStatus string, empID uint, note string, now time.Time) error {
+	oldStatus := comp.StatusComplaint
+	if oldStatus == "" {
+		oldStatus = ComplaintStatusNew
+	}
+	if oldStatus == newStatus {
+		return nil
+	}
+	needReason, ok := complaintTransitions[oldStatus][newStatus]
+	if !ok {
+		return ErrInvalidComplaintTransition
+	}
+	if needReason && strings.TrimSpace(note) == "" {
+		return ErrComplaintReasonRequired
+	}
+
+	if err := tx.Model(comp).Updates(complaintStatusUpdates(comp, newStatus, now)).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(&entity.HistoryComplain{
+		StatusOld:   oldStatus,
+		StatusNew:   newStatus,
+		Note:        note,
+		ChangedDate: now,
+		ChangedBy:   &empID,
+		ComplaintID: comp.ID,
+	}).Error; err != nil {
+		return err
+	}
+	comp.StatusComplaint = newStatus
+	return nil
+}
+
+// ค่าที่ต้องอัปเดตเมื่อเปลี่ยนสถานะ (บันทึกเวลาปิดงาน/เปิดใหม่)
+func complaintStatusUpdates(comp *entity.Complaint, newStatus string, now time.Time) map[string]interface{} {
+	updates := map[string]interface{}{"status_complaint": newStatus}
+	if newStatus == ComplaintStatusClosed {
+		if comp.ResolvedAt == nil {
+			updates["resolved_at"] = now
+		}
+		if comp.ResolutionBreachedAt == nil && comp.ResolutionDueAt != nil && now.After(*comp.ResolutionDueAt) {
+			updates["resolution_breached_at"] = now
+		}
+	} else if comp.ResolvedAt != nil {
+		updates["resolved_at"] = nil
+	}
+	return updates
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// สถานะพนักงานที่รับงานได้ (EmployeeStatus.StatusName)
+const EmployeeStatusActive = "active"
+
+var ErrUnknownComplaintCategory = errors.New("unknown complaint category")
+
+// FindComplaintCategory หาหมวดจากรหัส (ต้องเปิดใช้งานอยู่)
+func FindComplaintCategory(db *gorm.DB, code string) (*entity.ComplaintCategory, error) {
+	var cat entity.ComplaintCategory
+	err := db.Where("code = ? AND is_active = ?", strings.TrimSpace(code), true).First(&cat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnknownComplaintCategory
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+// ApplyComplaintCategory ตรวจหมวดและใช้ระดับความสำคัญเริ่มต้นของหมวด (ถ้าไม่ได้ระบุมา)
+// หมวดว่าง = ไม่จัดหมวด คืน nil
+func ApplyComplaintCategory(db *gorm.DB, comp *entity.Complaint) (*entity.ComplaintCategory, error) {
+	comp.Category = strings.TrimSpace(comp.Category)
+	if comp.Category == "" {
+		return nil, nil
+	}
+	cat, err := FindComplaintCategory(db, comp.Category)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(comp.Priority) == "" && cat.DefaultPriority != "" {
+		comp.Priority = cat.DefaultPriority
+	}
+	return cat, nil
+}
+
+// พนักงาน active ในตำแหน่งที่กำหนด เรียงตาม id
+func activeEmployeeIDs(tx *gorm.DB, positionID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&entity.Employee{}).
+		Joins("JOIN employee_statuses ON employee_statuses.id = employees.employee_status_id").
+		Where("employees.position_id = ? AND LOWER(employee_statuses.status_name) = ?", positionID, EmployeeStatusActive).
+		Order("employees.id ASC").
+		Pluck("employees.id", &ids).Error
+	return ids, err
+}
+
+// AssignComplaint มอบหมายคำร้องให้พนักงานคนถัดไปของหมวด (round-robin ในคนที่อยู่ในกะ)
+// ต้องเรียกในธุรกรรมเดียวกับการบันทึกคำร้อง; ไม่มีพนักงานที่รับได้ = ปล่อยว่างไว้
+func AssignComplaint(tx *gorm.DB, comp *entity.Complaint, cat *entity.ComplaintCategory, now time.Time) error {
+	if cat == nil || cat.OwnerPositionID == nil {
+		return nil
+	}
+	// อ่านตัวชี้ล่าสุดใหม่ภายในธุรกรรม กันสองคำร้องได้คนเดียวกัน
+	var fresh entity.ComplaintCategory
+	if err := tx.Select("id", "last_assigned_employee_id").First(&fresh, cat.ID).Error; err != nil {
+		return err
+	}
+	ids, err := activeEmployeeIDs(tx, *cat.OwnerPositionID)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	// ให้คนที่อยู่ในกะก่อน; ไม่มีใครอยู่ในกะเลยค่อยวนทุกคนที่ active
+	onDuty, err := OnDutyEmployeeIDs(tx, now)
+	if err != nil {
+		return err
+	}
+	var duty []uint
+	for _, id := range ids {
+		if onDuty[id] {
+			duty = append(duty, id)
+		}
+	}
+	if len(duty) > 0 {
+		ids = duty
+	}
+
+	next := ids[0]
+	if fresh.LastAssignedEmployeeID != nil {
+		for _, id := range ids {
+			if id > *fresh.LastAssignedEmployeeID {
+				next = id
+				break
+			}
+		}
+	}
+
+	if err := tx.Model(&entity.ComplaintCategory{}).Where("id = ?", cat.ID).
+		Update("last_assigned_employee_id", next).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(comp).Updates(map[string]interface{}{
+		"assigned_to_id": next,
+		"assigned_at":    now,
+	}).Error; err != nil {
+		return err
+	}
+	comp.AssignedToID = &next
+	comp.AssignedAt = &now
+	cat.LastAssignedEmployeeID = &next
+	return nil
+}
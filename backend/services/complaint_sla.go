@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// สถานะคำร้องเรียนที่ถือว่าจบแล้ว (ไม่ต้องติดตาม SLA)
+const ComplaintStatusClosed = "ปิดงานแล้ว"
+
+// ระดับความสำคัญที่รองรับ
+var ComplaintPriorities = []string{"low", "normal", "high", "urgent"}
+
+// ค่าเริ่มต้นเมื่อยังไม่มีนโยบายในระบบ
+const (
+	defaultFirstResponseMinutes = 4 * 60
+	defaultResolutionMinutes    = 48 * 60
+)
+
+func NormalizePriority(p string) string {
+	p = strings.ToLower(strings.TrimSpace(p))
+	for _, v := range ComplaintPriorities {
+		if v == p {
+			return p
+		}
+	}
+	return "normal"
+}
+
+// หา policy ที่เจาะจงที่สุด: หมวด+ระดับ > หมวด > ระดับ > ค่ากลาง
+func MatchSLAPolicy(db *gorm.DB, category, priority string) (*entity.ComplaintSLAPolicy, error) {
+	var policies []entity.ComplaintSLAPolicy
+	if err := db.Where("is_active = ?", true).Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	var best *entity.ComplaintSLAPolicy
+	bestScore := -1
+	for i := range policies {
+		p := &policies[i]
+		if p.Category != "" && p.Category != category {
+			continue
+		}
+		if p.Priority != "" && p.Priority != priority {
+			continue
+		}
+		score := 0
+		if p.Category != "" {
+			score += 2
+		}
+		if p.Priority != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, nil
+}
+
+// ApplyComplaintSLA คำนวณกำหนดเวลาให้คำร้องก่อนบันทึก
+func ApplyComplaintSLA(db *gorm.DB, comp *entity.Complaint) error {
+	comp.Priority = NormalizePriority(comp.Priority)
+	policy, err := MatchSLAPolicy(db, comp.Category, comp.Priority)
+	if err != nil {
+		return err
+	}
+	first, resolve := defaultFirstResponseMinutes, defaultResolutionMinutes
+	if policy != nil {
+		comp.SLAPolicyID = &policy.ID
+		first, resolve = policy.FirstResponseMinutes, policy.ResolutionMinutes
+	}
+	base := comp.CreateDate
+	if base.IsZero() {
+		base = time.Now()
+	}
+	if first > 0 {
+		t := base.Add(time.Duration(first) * time.Minute)
+		comp.FirstResponseDueAt = &t
+	}
+	if resolve > 0 {
+		t := base.Add(time.Duration(resolve) * time.Minute)
+		comp.ResolutionDueAt = &t
+	}
+	return nil
+}
+
+// หัวหน้างานที่รับเรื่อง escalate: ตำแหน่งตาม SLA_SUPERVISOR_POSITION (ค่าเริ่มต้น "หัวหน้างาน")
+func findSupervisor(db *gorm.DB) (*entity.Employee, error) {
+	name := strings.TrimSpace(config.Current().Features.SLASupervisor)
+	var emp entity.Employee
+	err := db.Joins("JOIN positions ON positions.id = employees.position_id").
+		Where("positions.position_name = ?", name).
+		Order("employees.id ASC").
+		First(&emp).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+// CheckComplaintSLA ตรวจคำร้องที่ยังไม่ปิด ทำเครื่องหมายเกินกำหนดและส่งต่อหัวหน้างาน
+// คืนจำนวนคำร้องที่เพิ่งถูกพบว่าเกินกำหนดในรอบนี้
+func CheckComplaintSLA(db *gorm.DB, now time.Time) (int, error) {
+	var comps []entity.Complaint
+	// เทียบเวลาฝั่ง Go (SQLite เก็บเวลาเป็นข้อความ)
+	if err := db.Where("status_complaint <> ?", ComplaintStatusClosed).
+		Where("(first_response_breached_at IS NULL AND first_responded_at IS NULL AND first_response_due_at IS NOT NULL) OR " +
+			"(resolution_breached_at IS NULL AND resolution_due_at IS NOT NULL)").
+		Find(&comps).Error; err != nil {
+		return 0, err
+	}
+	if len(comps) == 0 {
+		return 0, nil
+	}
+
+	supervisor, err := findSupervisor(db)
+	if err != nil {
+		return 0, err
+	}
+
+	breached := 0
+	for _, comp := range comps {
+		updates := map[string]interface{}{}
+		var reasons []string
+		if comp.FirstResponseBreachedAt == nil && comp.FirstRespondedAt == nil &&
+			comp.FirstResponseDueAt != nil && now.After(*comp.FirstResponseDueAt) {
+			updates["first_response_breached_at"] = now
+			reasons = append(reasons, "เกินกำหนดตอบกลับครั้งแรก")
+		}
+		if comp.ResolutionBreachedAt == nil && comp.ResolutionDueAt != nil && now.After(*comp.ResolutionDueAt) {
+			updates["resolution_breached_at"] = now
+			reasons = append(reasons, "เกินกำหนดปิดงาน")
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if comp.EscalatedAt == nil && supervisor != nil {
+				updates["escalated_at"] = now
+				updates["escalated_to_id"] = supervisor.ID
+			}
+			if err := tx.Model(&entity.Complaint{}).Where("id = ?", comp.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+			if _, ok := updates["escalated_to_id"]; !ok {
+				return nil
+			}
+			return tx.Create(&entity.HistoryComplain{
+				StatusOld:     comp.StatusComplaint,
+				StatusNew:     comp.StatusComplaint,
+				Note:          fmt.Sprintf("SLA: %s ส่งต่อหัวหน้างาน", strings.Join(reasons, ", ")),
+				ChangedDate:   now,
+				EscalatedToID: &supervisor.ID, // ผู้ดำเนินการคือระบบ (ChangedBy = nil)
+				ComplaintID:   comp.ID,
+			}).Error
+		})
+		if err != nil {
+			return breached, err
+		}
+		breached++
+		slog.InfoContext(db.Statement.Context, "complaint SLA breached",
+			"complaint", comp.PublicID, "reasons", strings.Join(reasons, ", "))
+	}
+	return breached, nil
+}
+
+// StartComplaintSLAChecker รันตัวตรวจ SLA เป็นระยะเป็นงานเบื้องหลังของ jobs
+func StartComplaintSLAChecker(jobs *Jobs, db *gorm.DB, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	jobs.Every("complaint_sla", interval, func(ctx context.Context) error {
+		_, err := CheckComplaintSLA(db.WithContext(ctx), time.Now())
+		return err
+	})
+}
@@ -0,0 +1,914 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ขั้นตอนหลักของแต่ละ service บนฐานข้อมูลจริง (SQLite in-memory)
+// ======================================================
+
+func countRows(t *testing.T, q *gorm.DB) int64 {
+	t.Helper()
+	var n int64
+	if err := q.Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestOrderCreateStartsProcessQueueAndConsumesStock(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "order@example.com")
+	inStock := entity.Detergent{Name: "น้ำยาซัก", Type: "liquid", InStock: 2, CategoryID: 1}
+	empty := entity.Detergent{Name: "ปรับผ้านุ่ม", Type: "softener", InStock: 0, CategoryID: 2}
+	mustCreate(t, db, &inStock, &empty)
+
+	order, err := NewOrderService(db).Create(NewOrder{
+		CustomerID:     cus.ID,
+		AddressID:      addr.ID,
+		ServiceTypeIDs: []uint{1},
+		DetergentIDs:   []uint{inStock.ID, empty.ID},
+		OrderNote:      "แยกผ้าขาว",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(order.ServiceTypes) != 1 || len(order.Detergents) != 2 || order.Customer == nil || order.Address == nil {
+		t.Errorf("order not preloaded: %+v", order)
+	}
+
+	if p := latestProcess(t, db, order.ID); p.Status != ProcessPending {
+		t.Errorf("process status = %q, want %q", p.Status, ProcessPending)
+	}
+	if n := countRows(t, db.Model(&entity.Queue{}).Where("order_id = ? AND queue_type = ? AND status = ?", order.ID, QueuePickup, QueueWaiting)); n != 1 {
+		t.Errorf("pickup queues = %d, want 1", n)
+	}
+	if n := countRows(t, db.Model(&entity.OrderHistory{}).Where("order_id = ?", order.ID)); n != 1 {
+		t.Errorf("order histories = %d, want 1", n)
+	}
+
+	// น้ำยาที่มีของตัดไป 1 พร้อมประวัติ, ที่หมดสต็อกข้ามไป
+	for _, tc := range []struct {
+		d         entity.Detergent
+		wantStock int
+		wantUsage int64
+	}{
+		{inStock, 1, 1},
+		{empty, 0, 0},
+	} {
+		var got entity.Detergent
+		db.First(&got, tc.d.ID)
+		usage := countRows(t, db.Model(&entity.DetergentUsageHistory{}).Where("detergent_id = ? AND reason = ?", tc.d.ID, DetergentOrderUsageReason))
+		if got.InStock != tc.wantStock || usage != tc.wantUsage {
+			t.Errorf("%s: in_stock=%d usage=%d, want %d/%d", tc.d.Name, got.InStock, usage, tc.wantStock, tc.wantUsage)
+		}
+	}
+
+	// น้ำยาที่ไม่มีในระบบ = ทั้งออเดอร์ไม่ถูกบันทึก
+	before := countRows(t, db.Model(&entity.Order{}))
+	_, err = NewOrderService(db).Create(NewOrder{CustomerID: cus.ID, AddressID: addr.ID, DetergentIDs: []uint{999}})
+	if !errors.Is(err, ErrDetergentNotFound) {
+		t.Errorf("unknown detergent: err = %v, want %v", err, ErrDetergentNotFound)
+	}
+	if after := countRows(t, db.Model(&entity.Order{})); after != before {
+		t.Errorf("orders = %d after failed create, want %d", after, before)
+	}
+}
+
+func TestAddressSetDefault(t *testing.T) {
+	db := openTestDB(t)
+	svc := NewAddressService(db)
+	cus, home := seedCustomer(t, db, "address@example.com")
+	other, otherHome := seedCustomer(t, db, "address2@example.com")
+	work, err := svc.Create(NewAddress{CustomerID: cus.ID, AddressDetails: "ที่ทำงาน"})
+	if err != nil || work.IsDefault {
+		t.Fatalf("create: %+v, err = %v", work, err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		addressID   uint
+		wantErr     error
+		wantDefault uint
+	}{
+		{name: "switch to new address", addressID: work.ID, wantDefault: work.ID},
+		{name: "already default", addressID: work.ID, wantDefault: work.ID},
+		{name: "other customer's address", addressID: otherHome.ID, wantErr: ErrAddressNotFound, wantDefault: work.ID},
+		{name: "unknown address", addressID: 999, wantErr: ErrAddressNotFound, wantDefault: work.ID},
+		{name: "switch back", addressID: home.ID, wantDefault: home.ID},
+	} {
+		if err := svc.SetDefault(cus.ID, tc.addressID); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.wantErr)
+		}
+		var defaults []entity.Address
+		if err := db.Where("customer_id = ? AND is_default = ?", cus.ID, true).Find(&defaults).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(defaults) != 1 || defaults[0].ID != tc.wantDefault {
+			t.Errorf("%s: defaults = %+v, want only %d", tc.name, defaults, tc.wantDefault)
+		}
+	}
+	var otherAddr entity.Address
+	if err := db.First(&otherAddr, otherHome.ID).Error; err != nil || !otherAddr.IsDefault || otherAddr.CustomerID != other.ID {
+		t.Errorf("other customer's default changed: %+v, err = %v", otherAddr, err)
+	}
+}
+
+func TestProcessStatusFollowsRulesAndReleasesMachines(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "process@example.com")
+	order := seedOrder(t, db, cus, addr)
+	emp := seedEmployee(t, db, "EMP900")
+	washer := entity.Machine{Machine_type: MachineWashing, Status: MachineAvailable, Machine_number: 1}
+	dryer := entity.Machine{Machine_type: MachineDrying, Status: MachineAvailable, Machine_number: 2}
+	mustCreate(t, db, &washer, &dryer)
+
+	procs := NewProcessService(db)
+	queues := NewQueueService(db)
+	process := latestProcess(t, db, order.ID)
+
+	// ทำตามลำดับ: แต่ละขั้นใช้ผลของขั้นก่อนหน้า
+	steps := []struct {
+		name     string
+		before   func() error
+		status   string
+		wantErr  error
+		wantStat string
+	}{
+		{name: "wash before pickup", status: ProcessWashing, wantErr: ErrProcessNeedsPickup, wantStat: ProcessPending},
+		{name: "done before washing", status: ProcessDone, wantErr: ErrProcessNeedsWashOrDry, wantStat: ProcessPending},
+		{
+			name: "wash without washer",
+			before: func() error {
+				var q entity.Queue
+				if err := db.Where("order_id = ? AND queue_type = ?", order.ID, QueuePickup).First(&q).Error; err != nil {
+					return err
+				}
+				_, err := queues.ConfirmPickupDone(q.ID, emp.ID)
+				return err
+			},
+			status: ProcessWashing, wantErr: ErrProcessNeedsWasher, wantStat: ProcessPickedUp,
+		},
+		{
+			name: "wash",
+			before: func() error {
+				_, err := procs.AssignMachines(process.ID, []uint{washer.ID})
+				return err
+			},
+			status: ProcessWashing, wantStat: ProcessWashing,
+		},
+		{name: "dry without dryer", status: ProcessDrying, wantErr: ErrProcessNeedsDryer, wantStat: ProcessWashing},
+		{
+			name: "dry",
+			before: func() error {
+				_, err := procs.AssignMachines(process.ID, []uint{washer.ID, dryer.ID})
+				return err
+			},
+			status: ProcessDrying, wantStat: ProcessDrying,
+		},
+		{name: "done", status: ProcessDone, wantStat: ProcessDone},
+	}
+	for _, st := range steps {
+		if st.before != nil {
+			if err := st.before(); err != nil {
+				t.Fatalf("%s: setup: %v", st.name, err)
+			}
+		}
+		_, err := procs.UpdateStatus(process.ID, ProcessStatusUpdate{Status: st.status, EmployeeID: emp.ID})
+		if !errors.Is(err, st.wantErr) {
+			t.Errorf("%s: err = %v, want %v", st.name, err, st.wantErr)
+		}
+		if got := latestProcess(t, db, order.ID); got.Status != st.wantStat {
+			t.Errorf("%s: status = %q, want %q", st.name, got.Status, st.wantStat)
+		}
+	}
+
+	// เสร็จสิ้น: คืนเครื่องทุกเครื่อง + มีคิวส่งผ้า
+	for _, m := range []entity.Machine{washer, dryer} {
+		var got entity.Machine
+		db.First(&got, m.ID)
+		if got.Status != MachineAvailable {
+			t.Errorf("machine %d status = %q, want %q", m.ID, got.Status, MachineAvailable)
+		}
+	}
+	if n := countRows(t, db.Model(&entity.Queue{}).Where("order_id = ? AND queue_type = ?", order.ID, QueueDelivery)); n != 1 {
+		t.Errorf("delivery queues = %d, want 1", n)
+	}
+
+	if _, err := procs.RemoveMachine(process.ID, 999); !errors.Is(err, ErrMachineNotFound) {
+		t.Errorf("remove unknown machine: err = %v", err)
+	}
+	if _, err := procs.UpdateStatus(999, ProcessStatusUpdate{Status: ProcessDone}); !errors.Is(err, ErrProcessNotFound) {
+		t.Errorf("unknown process: err = %v", err)
+	}
+}
+
+func TestQueueTimeSlotCapacityAndAssignment(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "queue@example.com")
+	emp := seedEmployee(t, db, "EMP901")
+	slot := entity.TimeSlot{Start_time: time.Now(), End_time: time.Now().Add(time.Hour), SlotType: QueuePickup, Capacity: 2, Status: "available"}
+	mustCreate(t, db, &slot)
+
+	svc := NewQueueService(db)
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		order := seedOrder(t, db, cus, addr)
+		var q entity.Queue
+		if err := db.Where("order_id = ? AND queue_type = ?", order.ID, QueuePickup).First(&q).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, q.ID)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		queueID uint
+		slotID  uint
+		wantErr error
+	}{
+		{"first", ids[0], slot.ID, nil},
+		{"last seat", ids[1], slot.ID, nil},
+		{"full", ids[2], slot.ID, ErrTimeSlotFull},
+		{"unknown slot", ids[2], 999, ErrTimeSlotNotFound},
+		{"unknown queue", 999, slot.ID, ErrQueueNotFound},
+	} {
+		if _, err := svc.AssignTimeSlot(tc.queueID, tc.slotID); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+	var got entity.TimeSlot
+	db.First(&got, slot.ID)
+	if got.Status != "full" {
+		t.Errorf("slot status = %q, want full", got.Status)
+	}
+
+	q, err := svc.Accept(ids[0], emp.ID)
+	if err != nil || q.Status != QueuePickupInProgress {
+		t.Fatalf("accept: status=%v err=%v", q, err)
+	}
+	status := "cancelled"
+	if _, err := svc.Update(ids[0], QueueUpdate{Status: &status, EmployeeID: &emp.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, db.Model(&entity.QueueAssignment{}).Where("queue_id = ? AND employee_id = ?", ids[0], emp.ID)); n != 2 {
+		t.Errorf("assignments = %d, want 2", n)
+	}
+	if _, err := svc.Delete(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Delete(ids[0]); !errors.Is(err, ErrQueueNotFound) {
+		t.Errorf("delete twice: err = %v, want %v", err, ErrQueueNotFound)
+	}
+}
+
+func TestStockUseAndRestock(t *testing.T) {
+	db := openTestDB(t)
+	d := entity.Detergent{Name: "น้ำยาซัก", Type: "liquid", InStock: 5, CategoryID: 1}
+	mustCreate(t, db, &d)
+	svc := NewStockService(db)
+
+	for _, tc := range []struct {
+		name      string
+		id        uint
+		qty       int
+		wantErr   error
+		wantStock int
+	}{
+		{"unknown detergent", 999, 1, ErrDetergentNotFound, 5},
+		{"zero", d.ID, 0, ErrInvalidQuantity, 5},
+		{"negative", d.ID, -2, ErrInvalidQuantity, 5},
+		{"too many", d.ID, 6, ErrOutOfStock, 5},
+		{"use", d.ID, 3, nil, 2},
+		{"rest", d.ID, 2, nil, 0},
+	} {
+		_, err := svc.Use(DetergentUse{UserID: 1, DetergentID: tc.id, Quantity: tc.qty, Reason: "ทดสอบ"})
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.wantErr)
+		}
+		var got entity.Detergent
+		db.First(&got, d.ID)
+		if got.InStock != tc.wantStock {
+			t.Errorf("%s: in_stock = %d, want %d", tc.name, got.InStock, tc.wantStock)
+		}
+	}
+	if n := countRows(t, db.Model(&entity.DetergentUsageHistory{}).Where("detergent_id = ?", d.ID)); n != 2 {
+		t.Errorf("usage histories = %d, want 2", n)
+	}
+
+	got, purchase, err := svc.Restock(d.ID, DetergentRestock{Quantity: 10, Price: 250, Supplier: "ร้านส่ง", UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.InStock != 10 || purchase.ID == 0 || purchase.DetergentID != d.ID {
+		t.Errorf("restock: in_stock=%d purchase=%+v", got.InStock, purchase)
+	}
+	if _, _, err := svc.Restock(999, DetergentRestock{Quantity: 1}); !errors.Is(err, ErrDetergentNotFound) {
+		t.Errorf("restock unknown: err = %v", err)
+	}
+}
+
+func TestPayCash(t *testing.T) {
+	amount := 80
+	for _, tc := range []struct {
+		name       string
+		setup      func(t *testing.T, db *gorm.DB, emp *entity.Employee)
+		withEmp    bool
+		amount     *int
+		wantErr    error
+		wantStatus string
+		wantTotal  int
+	}{
+		{name: "no cashier = pending", wantStatus: PaymentPending, wantTotal: 100},
+		{name: "amount from checkout", amount: &amount, wantStatus: PaymentPending, wantTotal: 80},
+		{name: "cashier without open session", withEmp: true, wantErr: ErrNoOpenCashSession},
+		{
+			name: "cashier with open session",
+			setup: func(t *testing.T, db *gorm.DB, emp *entity.Employee) {
+				mustCreate(t, db, &entity.CashSession{EmployeeID: emp.ID, BusinessDate: BusinessDate(time.Now()), Status: "open", OpenedAt: time.Now()})
+			},
+			withEmp: true, wantStatus: PaymentPaid, wantTotal: 100,
+		},
+		{
+			name: "day closed",
+			setup: func(t *testing.T, db *gorm.DB, emp *entity.Employee) {
+				mustCreate(t, db, &entity.CashSession{EmployeeID: emp.ID, BusinessDate: BusinessDate(time.Now()), Status: "open", OpenedAt: time.Now()})
+				mustCreate(t, db, &entity.DayClosing{BusinessDate: BusinessDate(time.Now()), ClosedAt: time.Now()})
+			},
+			withEmp: true, wantErr: ErrDayClosed,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := openTestDB(t)
+			cus, addr := seedCustomer(t, db, "cash@example.com")
+			order := seedOrder(t, db, cus, addr)
+			emp := seedEmployee(t, db, "EMP902")
+			if tc.setup != nil {
+				tc.setup(t, db, emp)
+			}
+			in := CashPayment{OrderID: order.ID, Amount: tc.amount}
+			if tc.withEmp {
+				in.EmployeeID = &emp.ID
+			}
+
+			pay, rc, err := NewPaymentService(db).PayCash(in)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if pay.PaymentStatus != tc.wantStatus || pay.TotalAmount != tc.wantTotal || pay.PaymentType != "cash" {
+				t.Errorf("payment = %s/%d/%s, want %s/%d/cash", pay.PaymentStatus, pay.TotalAmount, pay.PaymentType, tc.wantStatus, tc.wantTotal)
+			}
+			if (pay.CashSessionID != nil) != (tc.wantStatus == PaymentPaid) {
+				t.Errorf("cash_session_id = %v for status %s", pay.CashSessionID, pay.PaymentStatus)
+			}
+			// ใบเสร็จออกพร้อมการรับเงิน เงินสดที่รอเก็บยังไม่มีใบ
+			if (rc != nil) != (tc.wantStatus == PaymentPaid) {
+				t.Errorf("receipt = %+v for status %s", rc, pay.PaymentStatus)
+			}
+		})
+	}
+}
+
+// ออเดอร์ที่ชำระแล้ว (สำหรับออกใบเสร็จ)
+func seedPaidOrder(t *testing.T, db *gorm.DB, cus *entity.Customer, addr *entity.Address) *entity.Order {
+	t.Helper()
+	order := seedOrder(t, db, cus, addr)
+	mustCreate(t, db, &entity.Payment{
+		OrderID: order.ID, PaymentType: "cash", PaymentStatus: PaymentPaid, TotalAmount: 90,
+		TransRef: fmt.Sprintf("TEST-%d", order.ID),
+	})
+	return order
+}
+
+func TestReceiptNumberingReissueAndCancel(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "receipt@example.com")
+	unpaid := seedOrder(t, db, cus, addr)
+	a := seedPaidOrder(t, db, cus, addr)
+	b := seedPaidOrder(t, db, cus, addr)
+	year := time.Now().In(ShopLocation()).Year()
+	docNo := func(n int) string { return fmt.Sprintf("RC%d-%06d", year, n) }
+
+	if _, err := EnsureReceipt(db, unpaid.ID); !errors.Is(err, ErrOrderNotPaid) {
+		t.Errorf("unpaid order: err = %v, want %v", err, ErrOrderNotPaid)
+	}
+	// ออเดอร์ที่ไม่ผ่านไม่กินเลขที่เอกสาร
+	rcA, err := EnsureReceipt(db, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rcB, err := EnsureReceipt(db, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rcA.DocumentNo != docNo(1) || rcB.DocumentNo != docNo(2) {
+		t.Fatalf("numbers = %s, %s; want %s, %s", rcA.DocumentNo, rcB.DocumentNo, docNo(1), docNo(2))
+	}
+	// ยอด: ราคาบริการ 100 จ่ายจริง 90 -> ส่วนลด 10, VAT ที่รวมอยู่ใน 90
+	if rcA.Subtotal != 100 || rcA.Discount != 10 || rcA.Total != 90 || rcA.VatAmount != 5.89 {
+		t.Errorf("amounts = %v/%v/%v/%v", rcA.Subtotal, rcA.Discount, rcA.Total, rcA.VatAmount)
+	}
+	// เรียกซ้ำได้ใบเดิม
+	if again, err := EnsureReceipt(db, a.ID); err != nil || again.ID != rcA.ID {
+		t.Errorf("ensure again = %v, %v; want receipt %d", again, err, rcA.ID)
+	}
+
+	emp := seedEmployee(t, db, "EMP950")
+	svc := NewReceiptService(db)
+	rcA2, err := svc.Reissue(a.ID, &emp.ID, "แก้ชื่อลูกค้า")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rcA2.DocumentNo != docNo(3) || rcA2.IssuedBy == nil || *rcA2.IssuedBy != emp.ID {
+		t.Errorf("reissued = %s by %v, want %s by %d", rcA2.DocumentNo, rcA2.IssuedBy, docNo(3), emp.ID)
+	}
+	var old entity.Receipt
+	if err := db.First(&old, rcA.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if old.Status != ReceiptCancelled || old.DocumentNo != docNo(1) || old.ReplacedByID == nil || *old.ReplacedByID != rcA2.ID {
+		t.Errorf("old receipt = %s/%s replacedBy %v", old.Status, old.DocumentNo, old.ReplacedByID)
+	}
+
+	if _, err := svc.Cancel(rcB.ID, "ลูกค้าขอคืนเงิน"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Cancel(rcB.ID, "ซ้ำ"); !errors.Is(err, ErrReceiptAlreadyCancelled) {
+		t.Errorf("cancel twice: err = %v, want %v", err, ErrReceiptAlreadyCancelled)
+	}
+	if n := countRows(t, db.Model(&entity.Receipt{}).Where("status = ?", ReceiptIssued)); n != 1 {
+		t.Errorf("issued receipts = %d, want 1", n)
+	}
+}
+
+func TestEnsureReceiptLosesRaceToOtherRequest(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "lost@example.com")
+	order := seedPaidOrder(t, db, cus, addr)
+
+	// จำลองคำขออื่นออกใบเสร็จให้ออเดอร์เดียวกัน หลังจากที่เราตรวจแล้วยังไม่พบใบ
+	var other *entity.Receipt
+	if err := db.Callback().Query().After("gorm:query").Register("test:race_receipt", func(tx *gorm.DB) {
+		if other != nil || tx.Statement.Table != "receipts" || tx.RowsAffected != 0 {
+			return
+		}
+		rc, err := IssueReceipt(db, order.ID, nil)
+		if err != nil {
+			t.Errorf("competing issue: %v", err)
+			return
+		}
+		other = rc
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := EnsureReceipt(db, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == nil || rc.ID != other.ID {
+		t.Fatalf("ensure = %+v, want the competing receipt %+v", rc, other)
+	}
+	if n := countRows(t, db.Model(&entity.Receipt{}).Where("order_id = ?", order.ID)); n != 1 {
+		t.Errorf("receipts = %d, want 1", n)
+	}
+	// เลขที่ของธุรกรรมที่แพ้ถูกคืน
+	var seq entity.DocumentSequence
+	if err := db.Where("prefix = ? AND year = ?", ReceiptPrefix, time.Now().In(ShopLocation()).Year()).First(&seq).Error; err != nil {
+		t.Fatal(err)
+	}
+	if seq.LastNo != 1 {
+		t.Errorf("last document no = %d, want 1", seq.LastNo)
+	}
+}
+
+func TestNextDocumentNoFirstOfYearRace(t *testing.T) {
+	db := openTestDB(t)
+
+	// จำลองอีกธุรกรรมสร้างแถวของปีไปก่อน หลังจากที่เราเพิ่มเลขแล้วยังไม่พบแถว
+	raced := false
+	if err := db.Callback().Create().Before("gorm:create").Register("test:race_sequence", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "document_sequences" {
+			return
+		}
+		raced = true
+		// ใช้ connection เดียวกับคำสั่งที่กำลังทำ (ฐานข้อมูลทดสอบมี connection เดียว)
+		if err := tx.Session(&gorm.Session{NewDB: true}).Create(&entity.DocumentSequence{Prefix: "TS", Year: 2031, LastNo: 1}).Error; err != nil {
+			t.Errorf("competing sequence: %v", err)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	docNo, n, err := NextDocumentNo(db, "TS", 2031)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !raced || docNo != "TS2031-000002" || n != 2 {
+		t.Errorf("document no = %s (%d), want TS2031-000002 after the competing first number", docNo, n)
+	}
+	if c := countRows(t, db.Model(&entity.DocumentSequence{}).Where("prefix = ?", "TS")); c != 1 {
+		t.Errorf("sequence rows = %d, want 1", c)
+	}
+}
+
+func TestEnsureReceiptConcurrently(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "race@example.com")
+	orders := []*entity.Order{seedPaidOrder(t, db, cus, addr), seedPaidOrder(t, db, cus, addr), seedPaidOrder(t, db, cus, addr)}
+
+	const perOrder = 8
+	type result struct {
+		orderID uint
+		rc      *entity.Receipt
+		err     error
+	}
+	results := make(chan result, len(orders)*perOrder)
+	var wg sync.WaitGroup
+	for _, o := range orders {
+		for i := 0; i < perOrder; i++ {
+			wg.Add(1)
+			go func(orderID uint) {
+				defer wg.Done()
+				rc, err := EnsureReceipt(db, orderID)
+				results <- result{orderID, rc, err}
+			}(o.ID)
+		}
+	}
+	wg.Wait()
+	close(results)
+
+	// ทุกคำขอของออเดอร์เดียวกันได้ใบเดียวกัน
+	byOrder := map[uint]string{}
+	for r := range results {
+		if r.err != nil {
+			t.Fatalf("order %d: %v", r.orderID, r.err)
+		}
+		if prev, ok := byOrder[r.orderID]; ok && prev != r.rc.DocumentNo {
+			t.Errorf("order %d got %s and %s", r.orderID, prev, r.rc.DocumentNo)
+		}
+		byOrder[r.orderID] = r.rc.DocumentNo
+	}
+	if n := countRows(t, db.Model(&entity.Receipt{})); n != int64(len(orders)) {
+		t.Errorf("receipts = %d, want %d", n, len(orders))
+	}
+	// เลขที่เอกสารต่อเนื่อง ไม่ขาดช่วงจากคำขอที่ชนกัน
+	var seq entity.DocumentSequence
+	if err := db.Where("prefix = ? AND year = ?", ReceiptPrefix, time.Now().Year()).First(&seq).Error; err != nil {
+		t.Fatal(err)
+	}
+	if seq.LastNo != len(orders) {
+		t.Errorf("last document no = %d, want %d", seq.LastNo, len(orders))
+	}
+}
+
+func TestCollectCashAndSlipPayments(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "collect@example.com")
+	emp := seedEmployee(t, db, "EMP903")
+	svc := NewPaymentService(db)
+
+	order := seedOrder(t, db, cus, addr)
+	if _, _, err := NewPaymentService(db).PayCash(CashPayment{OrderID: 999}); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("unknown order: err = %v", err)
+	}
+	pending, _, err := svc.PayCash(CashPayment{OrderID: order.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.CollectCash(pending.ID, emp.ID); !errors.Is(err, ErrNoOpenCashSession) {
+		t.Errorf("collect without session: err = %v", err)
+	}
+	mustCreate(t, db, &entity.CashSession{EmployeeID: emp.ID, BusinessDate: BusinessDate(time.Now()), Status: "open", OpenedAt: time.Now()})
+	paid, rc, err := svc.CollectCash(pending.ID, emp.ID)
+	if err != nil || paid.PaymentStatus != PaymentPaid || paid.ReceivedBy == nil || *paid.ReceivedBy != emp.ID {
+		t.Fatalf("collect: %+v, err = %v", paid, err)
+	}
+	if rc == nil || rc.OrderID != order.ID || rc.PaymentID != paid.ID {
+		t.Errorf("collect receipt = %+v", rc)
+	}
+	for _, tc := range []struct {
+		id      uint
+		wantErr error
+	}{
+		{pending.ID, ErrPaymentAlreadyPaid},
+		{999, ErrPaymentNotFound},
+	} {
+		if _, _, err := svc.CollectCash(tc.id, emp.ID); !errors.Is(err, tc.wantErr) {
+			t.Errorf("collect %d: err = %v, want %v", tc.id, err, tc.wantErr)
+		}
+	}
+	if _, _, err := svc.PayCash(CashPayment{OrderID: order.ID, EmployeeID: &emp.ID}); !errors.Is(err, ErrPaymentAlreadyPaid) {
+		t.Errorf("pay twice: err = %v", err)
+	}
+
+	// สลิปเดียวกันใช้กับออเดอร์ที่สองไม่ได้
+	first := seedOrder(t, db, cus, addr)
+	second := seedOrder(t, db, cus, addr)
+	slip := VerifiedSlip{OrderID: first.ID, Total: 100, Amount: 100, TransRef: "TX-0001"}
+	pay, rc, err := svc.RecordSlip(slip)
+	if err != nil || pay.PaymentStatus != PaymentPaid || pay.VerifiedAmount != 100 {
+		t.Fatalf("record slip: %+v, err = %v", pay, err)
+	}
+	if rc == nil || rc.OrderID != first.ID || rc.Total != 100 {
+		t.Errorf("slip receipt = %+v", rc)
+	}
+	slip.OrderID = second.ID
+	if _, _, err := svc.RecordSlip(slip); !errors.Is(err, ErrDuplicateSlip) {
+		t.Errorf("duplicate slip: err = %v, want %v", err, ErrDuplicateSlip)
+	}
+	if n := countRows(t, db.Model(&entity.Receipt{}).Where("order_id = ?", second.ID)); n != 0 {
+		t.Errorf("receipts for rejected slip = %d, want 0", n)
+	}
+}
+
+func TestPaymentDayLockUsesPostingDate(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "daylock@example.com")
+	emp := seedEmployee(t, db, "EMP905")
+	svc := NewPaymentService(db)
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	mustCreate(t, db, &entity.CashSession{EmployeeID: emp.ID, BusinessDate: BusinessDate(now), Status: "open", OpenedAt: now})
+
+	// สร้างรอเก็บเงินเมื่อวาน (ปิดยอดเมื่อวานแล้ว) รับเงินวันนี้ได้ ยอดลงวันนี้
+	order := seedOrder(t, db, cus, addr)
+	pending, _, err := svc.PayCash(CashPayment{OrderID: order.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(pending).UpdateColumn("created_at", yesterday).Error; err != nil {
+		t.Fatal(err)
+	}
+	mustCreate(t, db, &entity.DayClosing{BusinessDate: BusinessDate(yesterday), ClosedAt: now})
+	if _, _, err := svc.PayCash(CashPayment{OrderID: order.ID, EmployeeID: &emp.ID}); err != nil {
+		t.Errorf("pay pending payment from a closed day: err = %v", err)
+	}
+
+	// ชำระแล้ว ส่งสลิปซ้ำไม่ได้และข้อมูลเดิมไม่เปลี่ยน
+	slip := VerifiedSlip{OrderID: order.ID, Total: 100, Amount: 100, TransRef: "TX-LATE"}
+	if _, _, err := svc.RecordSlip(slip); !errors.Is(err, ErrPaymentAlreadyPaid) {
+		t.Errorf("slip for paid order: err = %v, want %v", err, ErrPaymentAlreadyPaid)
+	}
+	var paid entity.Payment
+	if err := db.First(&paid, pending.ID).Error; err != nil || paid.TransRef == "TX-LATE" || paid.PaymentType != "cash" {
+		t.Errorf("paid payment overwritten: %+v, err = %v", paid, err)
+	}
+
+	// สร้างวันนี้แต่วันนี้ปิดยอดแล้ว = ชำระไม่ได้
+	open := seedOrder(t, db, cus, addr)
+	if _, _, err := svc.PayCash(CashPayment{OrderID: open.ID}); err != nil {
+		t.Fatal(err)
+	}
+	mustCreate(t, db, &entity.DayClosing{BusinessDate: BusinessDate(now), ClosedAt: now})
+	slip = VerifiedSlip{OrderID: open.ID, Total: 100, Amount: 100, TransRef: "TX-CLOSED"}
+	if _, _, err := svc.RecordSlip(slip); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("slip on closed day: err = %v, want %v", err, ErrDayClosed)
+	}
+	if _, _, err := svc.PayCash(CashPayment{OrderID: open.ID, EmployeeID: &emp.ID}); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("cash on closed day: err = %v, want %v", err, ErrDayClosed)
+	}
+}
+
+func TestComplaintLifecycle(t *testing.T) {
+	db := openTestDB(t)
+	cus, addr := seedCustomer(t, db, "complaint@example.com")
+	other, otherAddr := seedCustomer(t, db, "other@example.com")
+	order := seedOrder(t, db, cus, addr)
+	otherOrder := seedOrder(t, db, other, otherAddr)
+	emp := seedEmployee(t, db, "EMP904")
+	svc := NewComplaintService(db)
+
+	newComplaint := func(t *testing.T, publicID string) *entity.Complaint {
+		t.Helper()
+		comp, err := svc.Create(NewComplaint{CustomerID: cus.ID, Title: "ผ้าหาย", Description: "เสื้อหาย 1 ตัว", OrderID: &order.ID, PublicID: publicID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return comp
+	}
+
+	comp := newComplaint(t, "CMP-1")
+	if comp.StatusComplaint != ComplaintStatusNew || comp.LaundryProcessID == nil || comp.FirstResponseDueAt == nil {
+		t.Errorf("created complaint = %+v", comp)
+	}
+	for _, tc := range []struct {
+		name    string
+		in      NewComplaint
+		wantErr error
+	}{
+		{"order of another customer", NewComplaint{CustomerID: cus.ID, OrderID: &otherOrder.ID, PublicID: "CMP-X1"}, ErrComplaintOrderNotFound},
+		{"item not in order", NewComplaint{CustomerID: cus.ID, OrderID: &order.ID, ItemIDs: []uint{999}, PublicID: "CMP-X2"}, ErrComplaintItemNotInOrder},
+		{"unknown category", NewComplaint{CustomerID: cus.ID, Category: "nope", PublicID: "CMP-X3"}, ErrUnknownComplaintCategory},
+	} {
+		if _, err := svc.Create(tc.in); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+
+	// บันทึกภายในไม่นับเป็นการตอบกลับ, ตอบลูกค้าครั้งแรกหยุดนับ SLA และเปลี่ยนสถานะพร้อมกันได้
+	if _, err := svc.Reply(comp, ComplaintReply{EmployeeID: emp.ID, Text: "ตรวจสอบภายใน", Internal: true}); err != nil {
+		t.Fatal(err)
+	}
+	db.First(comp, comp.ID)
+	if comp.FirstRespondedAt != nil {
+		t.Error("internal note stopped the first-response SLA")
+	}
+	if _, err := svc.Reply(comp, ComplaintReply{EmployeeID: emp.ID, Text: "รับเรื่องแล้ว", NewStatus: ComplaintStatusInProgress}); err != nil {
+		t.Fatal(err)
+	}
+	db.First(comp, comp.ID)
+	if comp.FirstRespondedAt == nil || comp.StatusComplaint != ComplaintStatusInProgress {
+		t.Errorf("after reply: responded=%v status=%q", comp.FirstRespondedAt, comp.StatusComplaint)
+	}
+
+	for _, tc := range []struct {
+		from, to string
+		note     string
+		wantErr  error
+	}{
+		{ComplaintStatusNew, ComplaintStatusInProgress, "", nil},
+		{ComplaintStatusNew, ComplaintStatusClosed, "", nil},
+		{ComplaintStatusInProgress, ComplaintStatusClosed, "", nil},
+		{ComplaintStatusInProgress, ComplaintStatusNew, "", ErrComplaintReasonRequired},
+		{ComplaintStatusInProgress, ComplaintStatusNew, "มอบหมายใหม่", nil},
+		{ComplaintStatusClosed, ComplaintStatusInProgress, "  ", ErrComplaintReasonRequired},
+		{ComplaintStatusClosed, ComplaintStatusInProgress, "ลูกค้าแจ้งเพิ่ม", nil},
+		{ComplaintStatusClosed, "ยกเลิก", "x", ErrInvalidComplaintTransition},
+		{ComplaintStatusClosed, ComplaintStatusClosed, "", nil},
+	} {
+		c := newComplaint(t, "CMP-"+tc.from+"-"+tc.to+tc.note)
+		if err := db.Model(c).Update("status_complaint", tc.from).Error; err != nil {
+			t.Fatal(err)
+		}
+		err := svc.SetStatus(c, tc.to, emp.ID, tc.note)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s -> %s: err = %v, want %v", tc.from, tc.to, err, tc.wantErr)
+			continue
+		}
+		want, wantHist := tc.to, int64(1)
+		if err != nil || tc.from == tc.to {
+			want, wantHist = tc.from, 0
+		}
+		var got entity.Complaint
+		db.First(&got, c.ID)
+		hist := countRows(t, db.Model(&entity.HistoryComplain{}).Where("complaint_id = ?", c.ID))
+		if got.StatusComplaint != want || hist != wantHist {
+			t.Errorf("%s -> %s: status=%q history=%d, want %q/%d", tc.from, tc.to, got.StatusComplaint, hist, want, wantHist)
+		}
+		if closed := tc.wantErr == nil && tc.to == ComplaintStatusClosed && tc.from != tc.to; (got.ResolvedAt != nil) != closed {
+			t.Errorf("%s -> %s: resolved_at = %v", tc.from, tc.to, got.ResolvedAt)
+		}
+	}
+
+	// ลูกค้าให้คะแนนได้หลังปิดงาน ครั้งเดียว; ปิดแล้วส่งข้อความไม่ได้
+	if _, err := svc.Rate(comp, 5, ""); !errors.Is(err, ErrComplaintNotClosed) {
+		t.Errorf("rate open complaint: err = %v", err)
+	}
+	if _, err := svc.PostCustomerMessage(comp, "ได้รับผ้าแล้วหรือยัง"); err != nil {
+		t.Errorf("customer message: %v", err)
+	}
+	if err := svc.SetStatus(comp, ComplaintStatusClosed, emp.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.PostCustomerMessage(comp, "ขอบคุณ"); !errors.Is(err, ErrComplaintClosed) {
+		t.Errorf("message on closed complaint: err = %v", err)
+	}
+	if _, err := svc.Rate(comp, 4, " ดี "); err != nil {
+		t.Fatal(err)
+	}
+	db.First(comp, comp.ID)
+	if comp.SatisfactionRating == nil || *comp.SatisfactionRating != 4 || comp.SatisfactionComment != "ดี" {
+		t.Errorf("rating = %v %q", comp.SatisfactionRating, comp.SatisfactionComment)
+	}
+	if _, err := svc.Rate(comp, 1, ""); !errors.Is(err, ErrComplaintAlreadyRated) {
+		t.Errorf("rate twice: err = %v", err)
+	}
+}
+
+func TestComplaintSLAEscalationIsRecordedAsSystem(t *testing.T) {
+	db := openTestDB(t)
+	cus, _ := seedCustomer(t, db, "sla@example.com")
+	var pos entity.Position
+	if err := db.Where("position_name = ?", "หัวหน้างาน").First(&pos).Error; err != nil {
+		t.Fatal(err)
+	}
+	supervisor := entity.Employee{Code: "EMP905", FirstName: "หัวหน้า", LastName: "งาน", PositionID: pos.ID}
+	mustCreate(t, db, &supervisor)
+
+	comp, err := NewComplaintService(db).Create(NewComplaint{CustomerID: cus.ID, Title: "รอนาน", Description: "ยังไม่มีคนตอบ", PublicID: "CMP-SLA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := CheckComplaintSLA(db, comp.FirstResponseDueAt.Add(time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("CheckComplaintSLA = %d, %v, want 1 breach", n, err)
+	}
+
+	var h entity.HistoryComplain
+	if err := db.Where("complaint_id = ?", comp.ID).First(&h).Error; err != nil {
+		t.Fatal(err)
+	}
+	if h.ChangedBy != nil {
+		t.Errorf("escalation recorded as changed by employee %d, want system (nil)", *h.ChangedBy)
+	}
+	if h.EscalatedToID == nil || *h.EscalatedToID != supervisor.ID {
+		t.Errorf("escalated to %v, want supervisor %d", h.EscalatedToID, supervisor.ID)
+	}
+	db.First(comp, comp.ID)
+	if comp.EscalatedToID == nil || *comp.EscalatedToID != supervisor.ID {
+		t.Errorf("complaint escalated to %v, want supervisor %d", comp.EscalatedToID, supervisor.ID)
+	}
+}
+
+func TestEmployeeCreateUpdateDelete(t *testing.T) {
+	db := openTestDB(t)
+	svc := NewEmployeeService(db)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	positionCount := func(id uint) int {
+		var pc entity.PositionCount
+		db.Where("position_id = ?", id).First(&pc)
+		return int(pc.TotalEmployee)
+	}
+
+	emp, err := svc.Create(EmployeeInput{Email: "staff@example.com", Password: "staff1234", FirstName: "สมชาย", PositionID: 1, Status: "active", StartDate: &start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emp.Code == "" || emp.User == nil || emp.User.RoleID != 3 || emp.EmployeeStatus == nil {
+		t.Errorf("created employee = %+v", emp)
+	}
+	if n := positionCount(1); n != 1 {
+		t.Errorf("position 1 count = %d, want 1", n)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		in      EmployeeInput
+		wantErr error
+	}{
+		{"no password", EmployeeInput{Email: "x@example.com"}, ErrCredentialsRequired},
+		{"email taken", EmployeeInput{Email: "staff@example.com", Password: "staff1234"}, ErrEmailTaken},
+		{"code taken", EmployeeInput{Email: "new@example.com", Password: "staff1234", Code: emp.Code}, ErrEmployeeCodeTaken},
+	} {
+		if _, err := svc.Create(tc.in); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+	if n := countRows(t, db.Model(&entity.User{}).Where("email = ?", "new@example.com")); n != 0 {
+		t.Errorf("user of failed create was kept")
+	}
+
+	// ย้ายตำแหน่งด้วยชื่อใหม่ -> สร้างตำแหน่ง และปรับจำนวนทั้งสองฝั่ง
+	updated, err := svc.Update(emp.ID, EmployeeInput{FirstName: "สมชาย", LastName: "ใจดี", Position: "พนักงานรีดผ้า"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Position == nil || updated.Position.PositionName != "พนักงานรีดผ้า" || updated.LastName != "ใจดี" {
+		t.Errorf("updated employee = %+v", updated)
+	}
+	if positionCount(1) != 0 || positionCount(updated.PositionID) != 1 {
+		t.Errorf("position counts = %d/%d, want 0/1", positionCount(1), positionCount(updated.PositionID))
+	}
+	other, err := svc.Create(EmployeeInput{Email: "other@example.com", Password: "staff1234", Code: "EMP777"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Update(other.ID, EmployeeInput{Email: "staff@example.com"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("update to taken email: err = %v", err)
+	}
+	if _, err := svc.Update(other.ID, EmployeeInput{Code: emp.Code}); !errors.Is(err, ErrEmployeeCodeTaken) {
+		t.Errorf("update to taken code: err = %v", err)
+	}
+	if _, err := svc.Update(999, EmployeeInput{}); !errors.Is(err, ErrEmployeeNotFound) {
+		t.Errorf("update unknown: err = %v", err)
+	}
+
+	if err := svc.Delete(emp.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(emp.ID); !errors.Is(err, ErrEmployeeNotFound) {
+		t.Errorf("get deleted: err = %v", err)
+	}
+	if n := countRows(t, db.Model(&entity.User{}).Where("email = ?", "staff@example.com")); n != 0 {
+		t.Errorf("user of deleted employee was kept")
+	}
+	if n := positionCount(updated.PositionID); n != 0 {
+		t.Errorf("position count after delete = %d, want 0", n)
+	}
+	if err := svc.Delete(emp.ID); err != nil {
+		t.Errorf("delete twice: %v", err)
+	}
+}
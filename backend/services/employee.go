@@ -0,0 +1,377 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ======================================================
+// ข้อมูลพนักงาน + บัญชีผู้ใช้ (role = employee)
+// - ตำแหน่ง/สถานะระบุเป็นชื่อได้ ไม่มีในระบบจะสร้างให้
+// - จำนวนพนักงานต่อตำแหน่ง (PositionCount) ปรับตามการสร้าง/ย้าย/ลบ
+// - ไม่ระบุรหัสพนักงาน = EMP<id>
+// ======================================================
+
+var (
+	ErrEmployeeNotFound    = errors.New("employee_not_found")
+	ErrEmailTaken          = errors.New("email_taken")
+	ErrEmployeeCodeTaken   = errors.New("employee_code_taken")
+	ErrCredentialsRequired = errors.New("credentials_required")
+	ErrPasswordRequired    = errors.New("password_required")
+)
+
+// EmployeeInput ข้อมูลพนักงานที่สร้าง/แก้ไข
+// แก้ไข: ช่องว่างของ Code/Position/Status/Email/Password และ StartDate nil = คงค่าเดิม
+type EmployeeInput struct {
+	Code              string
+	FirstName         string
+	LastName          string
+	Gender            string
+	Position          string // ชื่อตำแหน่ง (ใช้เมื่อ PositionID = 0)
+	PositionID        uint
+	Phone             string
+	Email             string
+	Password          string
+	StartDate         *time.Time
+	Status            string
+	StatusDescription string
+}
+
+// EmployeeService สร้าง/แก้ไข/ลบพนักงาน
+type EmployeeService struct {
+	db *gorm.DB
+}
+
+func NewEmployeeService(db *gorm.DB) *EmployeeService {
+	return &EmployeeService{db: db}
+}
+
+// Create สร้างบัญชีผู้ใช้ + พนักงาน (ต้องมีอีเมลและรหัสผ่าน)
+func (s *EmployeeService) Create(in EmployeeInput) (*entity.Employee, error) {
+	if strings.TrimSpace(in.Email) == "" || strings.TrimSpace(in.Password) == "" {
+		return nil, ErrCredentialsRequired
+	}
+	var emp entity.Employee
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		posID, err := resolvePosition(tx, in.PositionID, in.Position)
+		if err != nil {
+			return err
+		}
+		statusID, err := upsertEmployeeStatus(tx, in.Status, in.StatusDescription)
+		if err != nil {
+			return err
+		}
+		user, err := createEmployeeUser(tx, in.Email, in.Password)
+		if err != nil {
+			return err
+		}
+		code := strings.TrimSpace(in.Code)
+		if err := ensureEmployeeCodeFree(tx, code, 0); err != nil {
+			return err
+		}
+
+		emp = entity.Employee{
+			Code:             code,
+			FirstName:        in.FirstName,
+			LastName:         in.LastName,
+			Gender:           strings.ToLower(strings.TrimSpace(in.Gender)),
+			Phone:            in.Phone,
+			UserID:           user.ID,
+			PositionID:       posID,
+			EmployeeStatusID: statusID,
+		}
+		if in.StartDate != nil {
+			emp.StartDate = *in.StartDate
+		}
+		if err := tx.Create(&emp).Error; err != nil {
+			return err
+		}
+		if emp.Code == "" {
+			emp.Code = fmt.Sprintf("EMP%03d", emp.ID)
+			if err := tx.Model(&entity.Employee{}).Where("id = ?", emp.ID).Update("code", emp.Code).Error; err != nil {
+				return err
+			}
+		}
+		return modifyPositionCount(tx, posID, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(emp.ID)
+}
+
+// Get พนักงานพร้อม User/Position/EmployeeStatus
+func (s *EmployeeService) Get(id uint) (*entity.Employee, error) {
+	var emp entity.Employee
+	if err := s.db.Preload("User").Preload("Position").Preload("EmployeeStatus").First(&emp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+	return &emp, nil
+}
+
+// Update แก้ข้อมูลพนักงาน; ยังไม่มีบัญชีผู้ใช้และส่งอีเมลมา = สร้างบัญชีให้ (ต้องมีรหัสผ่าน)
+func (s *EmployeeService) Update(id uint, in EmployeeInput) (*entity.Employee, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var e entity.Employee
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&e, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrEmployeeNotFound
+			}
+			return err
+		}
+
+		if code := strings.TrimSpace(in.Code); code != "" && code != e.Code {
+			if err := ensureEmployeeCodeFree(tx, code, e.ID); err != nil {
+				return err
+			}
+			e.Code = code
+		}
+		newPosID, err := resolvePosition(tx, in.PositionID, in.Position)
+		if err != nil {
+			return err
+		}
+		oldPosID := e.PositionID
+		if in.StartDate != nil {
+			e.StartDate = *in.StartDate
+		}
+		if strings.TrimSpace(in.Status) != "" {
+			statusID, err := upsertEmployeeStatus(tx, in.Status, in.StatusDescription)
+			if err != nil {
+				return err
+			}
+			e.EmployeeStatusID = statusID
+		}
+		e.FirstName = in.FirstName
+		e.LastName = in.LastName
+		e.Gender = strings.ToLower(strings.TrimSpace(in.Gender))
+		e.Phone = in.Phone
+		e.PositionID = newPosID
+
+		if e.UserID != 0 {
+			if err := updateEmployeeUser(tx, e.UserID, in.Email, in.Password); err != nil {
+				return err
+			}
+		} else if strings.TrimSpace(in.Email) != "" {
+			if strings.TrimSpace(in.Password) == "" {
+				return ErrPasswordRequired
+			}
+			user, err := createEmployeeUser(tx, in.Email, in.Password)
+			if err != nil {
+				return err
+			}
+			e.UserID = user.ID
+		}
+
+		if err := tx.Omit(clause.Associations).Save(&e).Error; err != nil {
+			return err
+		}
+		if oldPosID != newPosID {
+			if err := modifyPositionCount(tx, oldPosID, -1); err != nil {
+				return err
+			}
+			return modifyPositionCount(tx, newPosID, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Delete ลบพนักงานพร้อมบัญชีผู้ใช้ที่ผูกไว้ (ไม่พบ = ถือว่าลบแล้ว)
+func (s *EmployeeService) Delete(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var emp entity.Employee
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&emp, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Delete(&entity.Employee{}, emp.ID).Error; err != nil {
+			return err
+		}
+		if emp.UserID != 0 {
+			if err := tx.Delete(&entity.User{}, emp.UserID).Error; err != nil {
+				return err
+			}
+		}
+		return modifyPositionCount(tx, emp.PositionID, -1)
+	})
+}
+
+// บัญชีผู้ใช้ใหม่ role = employee (อีเมลต้องไม่ซ้ำ)
+func createEmployeeUser(tx *gorm.DB, email, password string) (*entity.User, error) {
+	if err := ensureEmailFree(tx, email, 0); err != nil {
+		return nil, err
+	}
+	roleID, err := getOrCreateRoleID(tx, RoleEmployee)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := HashPassword(password, email)
+	if err != nil {
+		return nil, err
+	}
+	u := entity.User{Email: email, Password: hashed, RoleID: roleID}
+	if err := tx.Create(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// แก้อีเมล/รหัสผ่านของบัญชีเดิม (ข้อมูลเก่าที่ยังไม่มี role ตั้งเป็น employee)
+func updateEmployeeUser(tx *gorm.DB, userID uint, email, password string) error {
+	var user entity.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if strings.TrimSpace(email) != "" && email != user.Email {
+		if err := ensureEmailFree(tx, email, user.ID); err != nil {
+			return err
+		}
+		user.Email = email
+	}
+	if strings.TrimSpace(password) != "" {
+		hashed, err := HashPassword(password, user.Email)
+		if err != nil {
+			return err
+		}
+		user.Password = hashed
+	}
+	if user.RoleID == 0 {
+		roleID, err := getOrCreateRoleID(tx, RoleEmployee)
+		if err != nil {
+			return err
+		}
+		user.RoleID = roleID
+	}
+	return tx.Save(&user).Error
+}
+
+func ensureEmailFree(tx *gorm.DB, email string, selfID uint) error {
+	var u entity.User
+	err := tx.Select("id").Where("email = ?", email).First(&u).Error
+	switch {
+	case err == nil && u.ID != selfID:
+		return ErrEmailTaken
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	return nil
+}
+
+func ensureEmployeeCodeFree(tx *gorm.DB, code string, selfID uint) error {
+	if code == "" {
+		return nil
+	}
+	var e entity.Employee
+	err := tx.Select("id").Where("code = ?", code).First(&e).Error
+	switch {
+	case err == nil && e.ID != selfID:
+		return ErrEmployeeCodeTaken
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	return nil
+}
+
+// ตำแหน่งจาก id หรือชื่อ (ชื่อที่ยังไม่มีสร้างใหม่; ไม่ระบุทั้งคู่ = 0)
+func resolvePosition(tx *gorm.DB, id uint, name string) (uint, error) {
+	name = strings.TrimSpace(name)
+	if id != 0 || name == "" {
+		return id, nil
+	}
+	var pos entity.Position
+	err := tx.Where("position_name = ?", name).First(&pos).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pos = entity.Position{PositionName: name}
+		err = tx.Create(&pos).Error
+	}
+	return pos.ID, err
+}
+
+func modifyPositionCount(tx *gorm.DB, positionID uint, delta int) error {
+	if positionID == 0 {
+		return nil
+	}
+	var pc entity.PositionCount
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("position_id = ?", positionID).First(&pc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pc = entity.PositionCount{PositionID: positionID, TotalEmployee: 0}
+		err = tx.Create(&pc).Error
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Model(&entity.PositionCount{}).
+		Where("position_id = ?", positionID).
+		UpdateColumn("total_employee",
+			gorm.Expr("CASE WHEN total_employee + ? < 0 THEN 0 ELSE total_employee + ? END", delta, delta),
+		).Error
+}
+
+func defaultStatusDescription(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case EmployeeStatusActive:
+		return "กำลังปฏิบัติงาน"
+	case "inactive":
+		return "ยังไม่ปฏิบัติงาน"
+	case EmployeeStatusOnLeave:
+		return "ลาพัก"
+	default:
+		return ""
+	}
+}
+
+// หา/สร้าง EmployeeStatus ตามชื่อ และปรับคำอธิบายให้ตรง (คำอธิบายว่าง = ค่าตั้งต้นของชื่อนั้น)
+func upsertEmployeeStatus(tx *gorm.DB, name, desc string) (uint, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	desc = strings.TrimSpace(desc)
+	if name == "" {
+		return 0, nil
+	}
+	if desc == "" {
+		desc = defaultStatusDescription(name)
+	}
+	var st entity.EmployeeStatus
+	err := tx.Where("status_name = ?", name).First(&st).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		st = entity.EmployeeStatus{StatusName: name, StatusDescription: desc}
+		if err := tx.Create(&st).Error; err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, err
+	case st.StatusDescription != desc:
+		if err := tx.Model(&st).Update("StatusDescription", desc).Error; err != nil {
+			return 0, err
+		}
+	}
+	return st.ID, nil
+}
+
+func getOrCreateRoleID(tx *gorm.DB, name string) (uint, error) {
+	var r entity.Role
+	err := tx.Where("name = ?", name).First(&r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r = entity.Role{Name: name}
+		err = tx.Create(&r).Error
+	}
+	return r.ID, err
+}
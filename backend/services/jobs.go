@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ======================================================
+// งานเบื้องหลังแบบรันเป็นระยะ (ตรวจ SLA, sync สถานะลา ...)
+// ปิดโปรแกรม: Stop ยกเลิก context แล้วรอรอบที่กำลังรันให้จบ (ไม่เกิน deadline ของ ctx)
+// ======================================================
+
+type Jobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewJobs() *Jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Jobs{ctx: ctx, cancel: cancel}
+}
+
+// Every รัน fn ทันที 1 รอบ แล้วทุก interval จนกว่าจะ Stop
+// แต่ละรอบได้ context ที่มี request id ของรอบนั้น (ให้ log ตามรอยได้)
+func (j *Jobs) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			j.run(name, fn)
+			select {
+			case <-j.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *Jobs) run(name string, fn func(ctx context.Context) error) {
+	if j.ctx.Err() != nil {
+		return
+	}
+	ctx := WithRequestID(j.ctx, "job-"+name+"-"+NewRequestID()[:8])
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "job panic", "job", name, "panic", r)
+		}
+	}()
+	start := time.Now()
+	if err := fn(ctx); err != nil && j.ctx.Err() == nil {
+		slog.ErrorContext(ctx, "job failed", "job", name, "error", err)
+		return
+	}
+	slog.DebugContext(ctx, "job done", "job", name, "duration_ms", time.Since(start).Milliseconds())
+}
+
+// Stop หยุดรับรอบใหม่แล้วรอรอบที่ค้างอยู่ คืน ctx.Err() ถ้ารอไม่ทัน
+func (j *Jobs) Stop(ctx context.Context) error {
+	j.cancel()
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
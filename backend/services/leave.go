@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// สถานะคำขอลา
+const (
+	LeavePending   = "pending"
+	LeaveApproved  = "approved"
+	LeaveRejected  = "rejected"
+	LeaveCancelled = "cancelled"
+)
+
+// ประเภทการลาที่รองรับ
+var LeaveTypes = []string{"sick", "personal", "vacation"}
+
+// ชื่อ EmployeeStatus ระหว่างลา
+const EmployeeStatusOnLeave = "onleave"
+
+// IsSupervisor พนักงานอยู่ในตำแหน่งหัวหน้างาน (ตำแหน่งเดียวกับที่รับเรื่อง escalate: SLA_SUPERVISOR_POSITION)
+func IsSupervisor(db *gorm.DB, empID uint) (bool, error) {
+	name := strings.TrimSpace(config.Current().Features.SLASupervisor)
+	var n int64
+	err := db.Model(&entity.Employee{}).
+		Joins("JOIN positions ON positions.id = employees.position_id").
+		Where("employees.id = ? AND positions.position_name = ?", empID, name).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// หา/สร้าง EmployeeStatus ตามชื่อ
+func employeeStatusID(tx *gorm.DB, name, desc string) (uint, error) {
+	var st entity.EmployeeStatus
+	err := tx.Where("status_name = ?", name).First(&st).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		st = entity.EmployeeStatus{StatusName: name, StatusDescription: desc}
+		err = tx.Create(&st).Error
+	}
+	return st.ID, err
+}
+
+// SyncLeaveStatuses ตั้งสถานะ onleave ให้พนักงานที่อยู่ในช่วงลา และคืนสถานะเดิมเมื่อพ้นช่วงลา
+// (หรือเมื่อคำขอที่มีผลแล้วถูกยกเลิก) เรียกซ้ำได้ไม่มีผลข้างเคียง
+func SyncLeaveStatuses(db *gorm.DB, now time.Time) error {
+	today := now.In(ShopLocation()).Format("2006-01-02")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		onLeaveID, err := employeeStatusID(tx, EmployeeStatusOnLeave, "ลาพัก")
+		if err != nil {
+			return err
+		}
+
+		// 1) ถึงวันลาแล้ว -> onleave
+		var starting []entity.LeaveRequest
+		if err := tx.Where("status = ? AND applied_at IS NULL AND start_date <= ? AND end_date >= ?", LeaveApproved, today, today).
+			Find(&starting).Error; err != nil {
+			return err
+		}
+		for _, lr := range starting {
+			var emp entity.Employee
+			if err := tx.Select("id", "employee_status_id").First(&emp, lr.EmployeeID).Error; err != nil {
+				return err
+			}
+			updates := map[string]interface{}{"applied_at": now}
+			if emp.EmployeeStatusID != onLeaveID {
+				updates["prev_status_id"] = emp.EmployeeStatusID
+			}
+			if err := tx.Model(&entity.Employee{}).Where("id = ?", emp.ID).
+				Update("employee_status_id", onLeaveID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entity.LeaveRequest{}).Where("id = ?", lr.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		// 2) พ้นช่วงลา / ถูกยกเลิกหลังมีผล -> คืนสถานะเดิม
+		var ending []entity.LeaveRequest
+		if err := tx.Where("applied_at IS NOT NULL AND restored_at IS NULL AND (status <> ? OR end_date < ?)", LeaveApproved, today).
+			Find(&ending).Error; err != nil {
+			return err
+		}
+		for _, lr := range ending {
+			// ยังมีใบลาอื่นที่มีผลอยู่วันนี้ -> คงสถานะ onleave
+			var other int64
+			tx.Model(&entity.LeaveRequest{}).
+				Where("id <> ? AND employee_id = ? AND status = ? AND start_date <= ? AND end_date >= ?", lr.ID, lr.EmployeeID, LeaveApproved, today, today).
+				Count(&other)
+			if other == 0 {
+				restore := lr.PrevStatusID
+				if restore == nil {
+					activeID, err := employeeStatusID(tx, EmployeeStatusActive, "กำลังปฏิบัติงาน")
+					if err != nil {
+						return err
+					}
+					restore = &activeID
+				}
+				// คืนเฉพาะถ้ายัง onleave อยู่ (admin อาจเปลี่ยนสถานะเองระหว่างลา)
+				if err := tx.Model(&entity.Employee{}).
+					Where("id = ? AND employee_status_id = ?", lr.EmployeeID, onLeaveID).
+					Update("employee_status_id", *restore).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&entity.LeaveRequest{}).Where("id = ?", lr.ID).Update("restored_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// StartLeaveStatusSync ตรวจช่วงลาเป็นระยะเป็นงานเบื้องหลังของ jobs (รอบแรกรันทันที)
+func StartLeaveStatusSync(jobs *Jobs, db *gorm.DB, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	jobs.Every("leave_status_sync", interval, func(ctx context.Context) error {
+		return SyncLeaveStatuses(db.WithContext(ctx), time.Now())
+	})
+}
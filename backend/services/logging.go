@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// ======================================================
+// log แบบมีโครงสร้าง (JSON ผ่าน log/slog)
+// - request id ผูกกับ context: handler ใช้ slog.XxxContext(c.Request.Context(), ...)
+//   งานเบื้องหลังได้ id ของรอบที่รัน (เช่น job-complaint_sla-1a2b3c4d)
+// - log.Printf แบบเดิมก็ออกเป็น JSON ด้วย (ผ่าน slog.SetDefault)
+// ======================================================
+
+type requestIDKey struct{}
+
+// WithRequestID ผูก request id กับ context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom request id ใน context ("" ถ้าไม่มี)
+func RequestIDFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID สุ่ม id ขนาด 16 ตัวอักษร hex
+func NewRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ParseLogLevel debug|info|warn|error (ไม่รู้จัก = info)
+func ParseLogLevel(s string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// SetupLogging ตั้ง slog default เป็น JSON ลง w ทุก record มี request_id จาก context (ถ้ามี)
+func SetupLogging(w io.Writer, level slog.Level) *slog.Logger {
+	logger := slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+	slog.SetDefault(logger)
+	log.SetFlags(0) // เวลาอยู่ใน JSON แล้ว
+	return logger
+}
+
+type contextHandler struct{ slog.Handler }
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFrom(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
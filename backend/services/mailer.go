@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+)
+
+// Mailer ช่องทางส่งอีเมล (SMTP จริง หรือ local สำหรับทดสอบ/พัฒนา)
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string // text/plain
+}
+
+// NewMailer เลือกตัวส่งตาม config.MailSettings (ตรวจค่าแล้วตอนโหลด)
+// - local : เขียนไฟล์ .eml ลง LocalDir แทนการส่งจริง
+// - smtp  : ส่งผ่าน SMTPHost:SMTPPort
+func NewMailer(cfg config.MailSettings) (Mailer, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalMailer(cfg.LocalDir), nil
+	case "smtp":
+		return &SMTPMailer{
+			Host:     strings.TrimSpace(cfg.SMTPHost),
+			Port:     strconv.Itoa(cfg.SMTPPort),
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPass,
+			From:     strings.TrimSpace(cfg.From),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", cfg.Driver)
+	}
+}
+
+func buildMail(from string, msg MailMessage, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// กันการแทรก header ผ่านช่องผู้รับ/หัวเรื่อง
+func checkMailHeaders(msg MailMessage) error {
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+	return nil
+}
+
+// ---------- local (เขียนไฟล์แทนการส่ง) ----------
+
+type LocalMailer struct {
+	Dir string
+
+	mu   sync.Mutex
+	sent []MailMessage
+}
+
+func NewLocalMailer(dir string) *LocalMailer {
+	return &LocalMailer{Dir: dir}
+}
+
+func (m *LocalMailer) Send(ctx context.Context, msg MailMessage) error {
+	if err := checkMailHeaders(msg); err != nil {
+		return err
+	}
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	if m.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%03d.eml", now.Format("20060102-150405"), len(m.sent))
+	p := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(p, buildMail("no-reply@localhost", msg, now), 0o600); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "mail (local)", "to", msg.To, "subject", msg.Subject, "file", p)
+	return nil
+}
+
+// Sent อีเมลที่ส่งไปแล้ว (ใช้ตรวจในการทดสอบ)
+func (m *LocalMailer) Sent() []MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MailMessage(nil), m.sent...)
+}
+
+// ---------- SMTP ----------
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(_ context.Context, msg MailMessage) error {
+	if err := checkMailHeaders(msg); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{msg.To}, buildMail(m.From, msg, time.Now()))
+}
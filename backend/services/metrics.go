@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// Prometheus metrics (GET /metrics)
+// - HTTP: latency/สถานะต่อ route (label เป็น route template ไม่ใช่ path จริง กัน label บาน)
+// - ธุรกิจ: คำนวณจากฐานข้อมูลตอนถูก scrape (ไม่ต้องเก็บสถานะซ้ำในโปรแกรม)
+// - ผลตรวจสลิป: นับตอนเรียก VerifySlipBase64
+// ======================================================
+
+const metricsNamespace = "sa"
+
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"route", "method", "status"})
+
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	slipVerifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "payment_slip_verifications_total",
+		Help:      "Payment slip verifications by result (verified/failed) and failure reason.",
+	}, []string{"result", "reason"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration, httpRequestsInFlight, slipVerifications,
+	)
+}
+
+// MetricsHandler handler ของ /metrics
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// HTTPRequestStarted นับคำขอที่กำลังทำ คืนฟังก์ชันที่ต้องเรียกเมื่อจบพร้อม route/สถานะ
+func HTTPRequestStarted() func(route, method string, status int) {
+	start := time.Now()
+	httpRequestsInFlight.Inc()
+	return func(route, method string, status int) {
+		httpRequestsInFlight.Dec()
+		httpRequestDuration.WithLabelValues(route, method, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ผลตรวจสลิป
+const (
+	SlipVerified = "verified"
+	SlipFailed   = "failed"
+)
+
+// รหัสข้อผิดพลาดที่ EasySlip ตอบกลับใน message (นอกเหนือจากนี้นับเป็น easyslip_other)
+var easySlipErrorCodes = map[string]bool{
+	"invalid_payload": true, "invalid_image": true, "image_size_too_large": true,
+	"invalid_check_duplicate": true, "slip_not_found": true, "qrcode_not_found": true,
+	"duplicate_slip": true, "unauthorized": true, "access_denied": true,
+	"account_not_verified": true, "application_expired": true, "application_deactivated": true,
+	"quota_exceeded": true, "server_error": true, "api_server_error": true,
+}
+
+// EasySlipFailureReason แปลง message ของ EasySlip เป็น reason ของ metric (ค่าจำกัด)
+func EasySlipFailureReason(message string) string {
+	code := strings.ToLower(strings.TrimSpace(message))
+	if easySlipErrorCodes[code] {
+		return "easyslip_" + code
+	}
+	if strings.Contains(code, "duplicate") {
+		return "easyslip_duplicate_slip"
+	}
+	return "easyslip_other"
+}
+
+// RecordSlipVerification นับผลตรวจสลิป (reason ว่างเมื่อสำเร็จ)
+func RecordSlipVerification(result, reason string) {
+	slipVerifications.WithLabelValues(result, reason).Inc()
+}
+
+// ======================================================
+// ตัวเลขธุรกิจจากฐานข้อมูล
+// ======================================================
+
+var (
+	queuesDesc = prometheus.NewDesc(metricsNamespace+"_queues",
+		"Queues by type (pickup/delivery) and status.", []string{"type", "status"}, nil)
+	machinesDesc = prometheus.NewDesc(metricsNamespace+"_machines",
+		"Machines by type (washing/drying) and status (available/in_use).", []string{"type", "status"}, nil)
+	ordersDesc = prometheus.NewDesc(metricsNamespace+"_orders",
+		"Orders by lifecycle state (status of the latest laundry process).", []string{"state"}, nil)
+	paymentsDesc = prometheus.NewDesc(metricsNamespace+"_payments",
+		"Payments by payment status.", []string{"status"}, nil)
+	complaintsOpenDesc = prometheus.NewDesc(metricsNamespace+"_complaints_open",
+		"Complaints not yet closed, by status and priority.", []string{"status", "priority"}, nil)
+	complaintsBreachedDesc = prometheus.NewDesc(metricsNamespace+"_complaints_sla_breached_open",
+		"Open complaints that breached first-response or resolution SLA.", nil, nil)
+	detergentStockDesc = prometheus.NewDesc(metricsNamespace+"_detergent_stock",
+		"Detergent units in stock.", []string{"detergent", "type"}, nil)
+	scrapeErrorsDesc = prometheus.NewDesc(metricsNamespace+"_domain_metrics_scrape_errors",
+		"Domain metric queries that failed during this scrape.", nil, nil)
+)
+
+type domainCollector struct{ db func() *gorm.DB }
+
+// RegisterDomainMetrics ลงทะเบียนตัวเลขธุรกิจ (เรียกครั้งเดียว) db ถูกเรียกทุกครั้งที่ scrape
+func RegisterDomainMetrics(db func() *gorm.DB) error {
+	return metricsRegistry.Register(domainCollector{db})
+}
+
+func (domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{queuesDesc, machinesDesc, ordersDesc, paymentsDesc,
+		complaintsOpenDesc, complaintsBreachedDesc, detergentStockDesc, scrapeErrorsDesc} {
+		ch <- d
+	}
+}
+
+type labeledCount struct {
+	A, B  string
+	Count float64
+}
+
+func (dc domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := dc.db().WithContext(ctx)
+	failed := 0
+	// group ตามค่า label (alias) ให้ NULL กับ '' รวมเป็นชุดเดียว ไม่งั้น label ซ้ำ
+	gauge := func(desc *prometheus.Desc, query *gorm.DB, labels int) {
+		var rows []labeledCount
+		if err := query.Scan(&rows).Error; err != nil {
+			failed++
+			slog.Warn("metrics: query failed", "metric", desc.String(), "error", err)
+			return
+		}
+		for _, r := range rows {
+			values := []string{r.A, r.B}[:labels]
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, r.Count, values...)
+		}
+	}
+
+	gauge(queuesDesc, db.Table("queues").Where("deleted_at IS NULL").
+		Select("COALESCE(queue_type, '') AS a, COALESCE(status, '') AS b, COUNT(*) AS count").Group("a, b"), 2)
+	gauge(machinesDesc, db.Table("machines").Where("deleted_at IS NULL").
+		Select("COALESCE(machine_type, '') AS a, COALESCE(status, '') AS b, COUNT(*) AS count").Group("a, b"), 2)
+	// สถานะของออเดอร์ = สถานะของ laundry process ล่าสุดที่ผูกกับออเดอร์
+	gauge(ordersDesc, db.Table("process_order AS po").
+		Joins("JOIN laundry_processes lp ON lp.id = po.laundry_process_id").
+		Joins("JOIN orders o ON o.id = po.order_id AND o.deleted_at IS NULL").
+		Where("lp.id = (SELECT MAX(p2.laundry_process_id) FROM process_order p2 WHERE p2.order_id = po.order_id)").
+		Select("COALESCE(lp.status, '') AS a, COUNT(*) AS count").Group("a"), 1)
+	gauge(paymentsDesc, db.Table("payments").Where("deleted_at IS NULL").
+		Select("COALESCE(payment_status, '') AS a, COUNT(*) AS count").Group("a"), 1)
+	gauge(complaintsOpenDesc, db.Table("complaints").
+		Where("deleted_at IS NULL AND status_complaint <> ?", ComplaintStatusClosed).
+		Select("COALESCE(status_complaint, '') AS a, COALESCE(priority, '') AS b, COUNT(*) AS count").Group("a, b"), 2)
+
+	var breached int64
+	if err := db.Table("complaints").
+		Where("deleted_at IS NULL AND status_complaint <> ?", ComplaintStatusClosed).
+		Where("first_response_breached_at IS NOT NULL OR resolution_breached_at IS NOT NULL").
+		Count(&breached).Error; err != nil {
+		failed++
+		slog.Warn("metrics: query failed", "metric", "complaints_sla_breached_open", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(complaintsBreachedDesc, prometheus.GaugeValue, float64(breached))
+	}
+
+	gauge(detergentStockDesc, db.Table("detergents").Where("deleted_at IS NULL").
+		Select("COALESCE(name, '') AS a, COALESCE(type, '') AS b, SUM(in_stock) AS count").Group("a, b"), 2)
+
+	ch <- prometheus.MustNewConstMetric(scrapeErrorsDesc, prometheus.GaugeValue, float64(failed))
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ออเดอร์ใหม่
+// สร้างออเดอร์ = ผูกบริการ/น้ำยา + ประวัติ + ตัดสต็อกน้ำยาทางร้าน + กระบวนการซัก (รอดำเนินการ) + คิวรับผ้า
+// ทั้งหมดในธุรกรรมเดียว
+// ======================================================
+
+var ErrOrderNotFound = errors.New("order_not_found")
+
+// NewOrder ข้อมูลออเดอร์จากลูกค้า
+type NewOrder struct {
+	CustomerID     uint
+	AddressID      uint
+	ServiceTypeIDs []uint
+	DetergentIDs   []uint // น้ำยาทางร้าน (ตัดสต็อกชิ้นละ 1)
+	OrderImage     string
+	OrderNote      string
+}
+
+// OrderService สร้างออเดอร์
+type OrderService struct {
+	db *gorm.DB
+}
+
+func NewOrderService(db *gorm.DB) *OrderService {
+	return &OrderService{db: db}
+}
+
+// Create สร้างออเดอร์ แล้วคืนออเดอร์พร้อม Customer/ServiceTypes/Detergents/Address
+func (s *OrderService) Create(in NewOrder) (*entity.Order, error) {
+	order := entity.Order{
+		CustomerID: in.CustomerID,
+		AddressID:  in.AddressID,
+		OrderImage: in.OrderImage,
+		OrderNote:  in.OrderNote,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		if len(in.ServiceTypeIDs) > 0 {
+			var types []entity.ServiceType
+			if err := tx.Find(&types, in.ServiceTypeIDs).Error; err != nil {
+				return err
+			}
+			if len(types) > 0 {
+				if err := tx.Model(&order).Association("ServiceTypes").Append(types); err != nil {
+					return err
+				}
+			}
+		}
+		if len(in.DetergentIDs) > 0 {
+			var detergents []entity.Detergent
+			if err := tx.Find(&detergents, in.DetergentIDs).Error; err != nil {
+				return err
+			}
+			if len(detergents) > 0 {
+				if err := tx.Model(&order).Association("Detergents").Append(detergents); err != nil {
+					return err
+				}
+			}
+			if err := consumeDetergents(tx, in.DetergentIDs, in.CustomerID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&entity.OrderHistory{OrderID: order.ID}).Error; err != nil {
+			return err
+		}
+		process := entity.LaundryProcess{Status: ProcessPending, Start_time: time.Now()}
+		if err := tx.Create(&process).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&process).Association("Order").Append(&order); err != nil {
+			return err
+		}
+		return tx.Create(&entity.Queue{Queue_type: QueuePickup, Status: QueueWaiting, OrderID: order.ID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Customer").Preload("ServiceTypes").Preload("Detergents").Preload("Address").
+		First(&order, order.ID).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
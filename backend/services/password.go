@@ -0,0 +1,173 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// รหัสผ่าน: กติกาความปลอดภัย + โทเค็นรีเซ็ตรหัสผ่าน (ใช้กับ User ทุก role)
+// ======================================================
+
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 72 // bcrypt ใช้ได้แค่ 72 ไบต์แรก
+)
+
+var (
+	ErrPasswordTooShort  = errors.New("รหัสผ่านต้องมีอย่างน้อย 8 ตัวอักษร")
+	ErrPasswordTooLong   = errors.New("รหัสผ่านต้องยาวไม่เกิน 72 ไบต์")
+	ErrPasswordTooSimple = errors.New("รหัสผ่านต้องมีทั้งตัวอักษรและตัวเลข")
+	ErrPasswordHasSpace  = errors.New("รหัสผ่านต้องไม่มีช่องว่าง")
+	ErrPasswordLikeEmail = errors.New("รหัสผ่านต้องไม่ซ้ำกับอีเมล")
+	ErrResetTokenInvalid = errors.New("ลิงก์รีเซ็ตรหัสผ่านไม่ถูกต้องหรือหมดอายุแล้ว")
+	ErrPasswordUnchanged = errors.New("รหัสผ่านใหม่ต้องไม่ซ้ำกับรหัสผ่านเดิม")
+)
+
+// ValidatePassword ตรวจกติการหัสผ่านใหม่ (email ใช้กันตั้งรหัสเป็นชื่ออีเมล)
+func ValidatePassword(pw, email string) error {
+	if len([]rune(pw)) < PasswordMinLength {
+		return ErrPasswordTooShort
+	}
+	if len(pw) > PasswordMaxLength {
+		return ErrPasswordTooLong
+	}
+	var letter, digit bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsSpace(r):
+			return ErrPasswordHasSpace
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsLetter(r):
+			letter = true
+		}
+	}
+	if !letter || !digit {
+		return ErrPasswordTooSimple
+	}
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		local := email
+		if i := strings.Index(email, "@"); i > 0 {
+			local = email[:i]
+		}
+		if p := strings.ToLower(pw); p == email || p == local {
+			return ErrPasswordLikeEmail
+		}
+	}
+	return nil
+}
+
+// HashPassword ตรวจกติกาแล้วแฮชรหัสผ่าน
+func HashPassword(pw, email string) (string, error) {
+	if err := ValidatePassword(pw, email); err != nil {
+		return "", err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// IsPasswordPolicyError ใช้แยก 400 (ผู้ใช้ตั้งรหัสไม่ผ่านกติกา) ออกจาก 500
+func IsPasswordPolicyError(err error) bool {
+	for _, e := range []error{ErrPasswordTooShort, ErrPasswordTooLong, ErrPasswordTooSimple, ErrPasswordHasSpace, ErrPasswordLikeEmail} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- โทเค็นรีเซ็ตรหัสผ่าน ----------
+
+// PasswordResetTTL อายุโทเค็นรีเซ็ตรหัสผ่าน (PASSWORD_RESET_TTL)
+func PasswordResetTTL() time.Duration { return config.Current().Auth.PasswordResetTTL }
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssuePasswordReset สร้างโทเค็นใหม่ให้ user (โทเค็นเก่าที่ยังไม่ใช้ถูกยกเลิก) คืนค่าโทเค็นดิบสำหรับส่งอีเมล
+func IssuePasswordReset(db *gorm.DB, userID uint, now time.Time) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(raw)
+	exp := now.Add(PasswordResetTTL())
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entity.PasswordResetToken{
+			UserID:    userID,
+			TokenHash: hashResetToken(token),
+			ExpiresAt: exp,
+		}).Error
+	})
+	return token, exp, err
+}
+
+// ResetPassword ใช้โทเค็น (ครั้งเดียว) ตั้งรหัสผ่านใหม่
+func ResetPassword(db *gorm.DB, token, newPassword string, now time.Time) (*entity.User, error) {
+	var user entity.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rt entity.PasswordResetToken
+		if err := tx.Where("token_hash = ?", hashResetToken(strings.TrimSpace(token))).First(&rt).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResetTokenInvalid
+			}
+			return err
+		}
+		if rt.UsedAt != nil || !now.Before(rt.ExpiresAt) {
+			return ErrResetTokenInvalid
+		}
+		if err := tx.First(&user, rt.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResetTokenInvalid
+			}
+			return err
+		}
+		hashed, err := HashPassword(newPassword, user.Email)
+		if err != nil {
+			return err
+		}
+		// ตั้ง used_at แบบมีเงื่อนไข กันสองคำขอใช้โทเค็นเดียวกันพร้อมกัน
+		res := tx.Model(&entity.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", rt.ID).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrResetTokenInvalid
+		}
+		user.Password = hashed
+		// ยืนยันตัวตนผ่านอีเมลแล้ว ปลดล็อกบัญชีที่ถูกล็อกจากการล็อกอินผิดด้วย
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"password":           hashed,
+			"failed_login_count": 0,
+			"locked_until":       nil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// การชำระเงินของออเดอร์ (หนึ่งออเดอร์ = หนึ่ง Payment)
+// - สลิปโอนเงิน: บันทึกหลังตรวจกับ EasySlip แล้ว (trans_ref ซ้ำ = สลิปซ้ำ)
+// - เงินสด: มีพนักงานรับเงิน = เข้ารอบลิ้นชักของพนักงานทันที, ไม่มี = รอเก็บเงิน (pending)
+// - วันที่ปิดยอดแล้วห้ามเพิ่ม/แก้การชำระเงิน (ดูจากวันที่ลงยอด = วันที่ชำระ ตรงกับ Z-report ไม่ใช่วันที่สร้างรายการ)
+// - ชำระแล้วห้ามชำระซ้ำ (ไม่ว่าด้วยเงินสดหรือสลิป)
+// - ชำระครบ = ออกใบเสร็จในธุรกรรมเดียวกัน (ออกไม่ได้ = ไม่บันทึกการชำระเงิน)
+// ======================================================
+
+var (
+	ErrDayClosed          = errors.New("day_closed")
+	ErrNoOpenCashSession  = errors.New("no_open_cash_session")
+	ErrPaymentAlreadyPaid = errors.New("payment_already_paid")
+	ErrPaymentNotFound    = errors.New("payment_not_found")
+	ErrDuplicateSlip      = errors.New("duplicate_slip")
+)
+
+// สถานะการชำระเงิน
+const (
+	PaymentPending = "pending"
+	PaymentPaid    = "paid"
+)
+
+// BusinessDate วันทำการ (YYYY-MM-DD ตามเวลาร้าน)
+func BusinessDate(t time.Time) string {
+	return t.In(ShopLocation()).Format("2006-01-02")
+}
+
+// EnsureDayOpen ห้ามแก้ไข/เพิ่มการชำระเงินในวันที่ปิดยอดแล้ว
+func EnsureDayOpen(tx *gorm.DB, t time.Time) error {
+	var n int64
+	if err := tx.Model(&entity.DayClosing{}).Where("business_date = ?", BusinessDate(t)).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrDayClosed
+	}
+	return nil
+}
+
+// FindOpenCashSession รอบลิ้นชักที่พนักงานเปิดอยู่ (ไม่มี = ErrNoOpenCashSession)
+func FindOpenCashSession(tx *gorm.DB, employeeID uint) (*entity.CashSession, error) {
+	var s entity.CashSession
+	if err := tx.Where("employee_id = ? AND status = ?", employeeID, "open").First(&s).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoOpenCashSession
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// RecordCashPayment บันทึกรับเงินสดเข้ารอบลิ้นชักของพนักงาน
+func RecordCashPayment(tx *gorm.DB, pay *entity.Payment, employeeID uint) error {
+	now := time.Now()
+	if err := EnsureDayOpen(tx, now); err != nil {
+		return err
+	}
+	sess, err := FindOpenCashSession(tx, employeeID)
+	if err != nil {
+		return err
+	}
+	pay.PaymentType = "cash"
+	pay.PaymentStatus = PaymentPaid
+	pay.CashSessionID = &sess.ID
+	pay.ReceivedBy = &employeeID
+	pay.SlipVerifiedAt = &now
+	if pay.VerifiedAmount == 0 {
+		pay.VerifiedAmount = pay.TotalAmount
+	}
+	return tx.Save(pay).Error
+}
+
+// PaymentService บันทึกการชำระเงิน
+type PaymentService struct {
+	db *gorm.DB
+}
+
+func NewPaymentService(db *gorm.DB) *PaymentService {
+	return &PaymentService{db: db}
+}
+
+// CashPayment ชำระเงินสด
+type CashPayment struct {
+	OrderID    uint
+	Amount     *int  // ยอดสุทธิหลังหักโปร (nil/0 = รวมราคาบริการของออเดอร์)
+	EmployeeID *uint // พนักงานผู้รับเงิน (nil = รอเก็บเงิน)
+}
+
+// PayCash บันทึกการชำระเงินสดของออเดอร์ (ใบเสร็จเป็น nil เมื่อยังรอเก็บเงิน)
+func (s *PaymentService) PayCash(in CashPayment) (*entity.Payment, *entity.Receipt, error) {
+	total := 0
+	if in.Amount != nil && *in.Amount > 0 {
+		total = *in.Amount
+	} else {
+		var order entity.Order
+		if err := s.db.Preload("ServiceTypes").First(&order, in.OrderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, ErrOrderNotFound
+			}
+			return nil, nil, err
+		}
+		sum := 0.0
+		for _, st := range order.ServiceTypes {
+			sum += st.Price
+		}
+		total = int(math.Round(sum))
+	}
+
+	var pay entity.Payment
+	var rc *entity.Receipt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := EnsureDayOpen(tx, time.Now()); err != nil {
+			return err
+		}
+		err := tx.Where("order_id = ?", in.OrderID).First(&pay).Error
+		switch {
+		case err == nil:
+			if pay.PaymentStatus == PaymentPaid {
+				return ErrPaymentAlreadyPaid
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// trans_ref เป็น UNIQUE: เงินสดไม่มีเลขอ้างอิงจากธนาคารจึงสร้างเอง
+			pay = entity.Payment{OrderID: in.OrderID, TransRef: fmt.Sprintf("CASH-%d-%d", in.OrderID, time.Now().UnixNano())}
+		default:
+			return err
+		}
+		pay.PaymentType = "cash"
+		pay.TotalAmount = total
+		pay.VerifiedAmount = total
+
+		if in.EmployeeID == nil {
+			pay.PaymentStatus = PaymentPending
+			return tx.Save(&pay).Error
+		}
+		if err := RecordCashPayment(tx, &pay, *in.EmployeeID); err != nil {
+			return err
+		}
+		rc, err = EnsureReceipt(tx, pay.OrderID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &pay, rc, nil
+}
+
+// CollectCash พนักงานรับเงินสดของการชำระเงินที่รอเก็บ แล้วออกใบเสร็จ
+func (s *PaymentService) CollectCash(paymentID, employeeID uint) (*entity.Payment, *entity.Receipt, error) {
+	var pay entity.Payment
+	var rc *entity.Receipt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&pay, paymentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return err
+		}
+		if pay.PaymentStatus == PaymentPaid {
+			return ErrPaymentAlreadyPaid
+		}
+		if err := RecordCashPayment(tx, &pay, employeeID); err != nil {
+			return err
+		}
+		var err error
+		rc, err = EnsureReceipt(tx, pay.OrderID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &pay, rc, nil
+}
+
+// VerifiedSlip สลิปโอนเงินที่ตรวจกับผู้ให้บริการแล้ว
+type VerifiedSlip struct {
+	OrderID  uint
+	Total    int // ยอดที่ต้องชำระ
+	Amount   int // ยอดที่อ่านได้จากสลิป
+	TransRef string
+	SlipDate *time.Time
+	Image    string // base64 ของรูปสลิป
+}
+
+// RecordSlip บันทึกการชำระด้วยสลิป (สร้าง Payment ให้ออเดอร์ถ้ายังไม่มี) แล้วออกใบเสร็จ
+func (s *PaymentService) RecordSlip(in VerifiedSlip) (*entity.Payment, *entity.Receipt, error) {
+	var pay entity.Payment
+	var rc *entity.Receipt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("order_id = ?", in.OrderID).First(&pay).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			pay = entity.Payment{OrderID: in.OrderID, PaymentType: "PromptPay", TotalAmount: in.Total, PaymentStatus: PaymentPending}
+			err = tx.Create(&pay).Error
+		}
+		if err != nil {
+			return err
+		}
+		if pay.PaymentStatus == PaymentPaid {
+			return ErrPaymentAlreadyPaid
+		}
+		now := time.Now()
+		if err := EnsureDayOpen(tx, now); err != nil {
+			return err
+		}
+
+		pay.TransRef = in.TransRef
+		pay.VerifiedAmount = in.Amount
+		pay.SlipDate = in.SlipDate
+		pay.SlipVerifiedAt = &now
+		pay.PaymentStatus = PaymentPaid
+		pay.CheckPaymentB64 = in.Image
+		if err := tx.Save(&pay).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrDuplicateSlip
+			}
+			return err
+		}
+		rc, err = EnsureReceipt(tx, pay.OrderID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &pay, rc, nil
+}
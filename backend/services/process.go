@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ======================================================
+// กระบวนการซัก (LaundryProcess) + เครื่องซัก/อบที่ผูกกับกระบวนการ
+// ลำดับสถานะ: รอดำเนินการ -> รับผ้าเรียบร้อย (รับผ้าเสร็จ) -> กำลังซัก -> กำลังอบ -> เสร็จสิ้น
+// - เสร็จสิ้นจาก กำลังซัก ได้เลย (ไม่อบ)
+// - เสร็จสิ้น = คืนเครื่องทั้งหมดเป็น available + สร้างคิวส่งผ้า
+// ======================================================
+
+// สถานะกระบวนการซัก
+const (
+	ProcessPending  = "รอดำเนินการ"
+	ProcessPickedUp = "รับผ้าเรียบร้อย"
+	ProcessWashing  = "กำลังซัก"
+	ProcessDrying   = "กำลังอบ"
+	ProcessDone     = "เสร็จสิ้น"
+)
+
+// ประเภท/สถานะเครื่อง
+const (
+	MachineWashing   = "washing"
+	MachineDrying    = "drying"
+	MachineAvailable = "available"
+	MachineInUse     = "in_use"
+)
+
+var (
+	ErrProcessNotFound       = errors.New("process_not_found")
+	ErrMachineNotFound       = errors.New("machine_not_found")
+	ErrMachineNotInProcess   = errors.New("machine_not_in_process")
+	ErrProcessNeedsPickup    = errors.New("process_needs_pickup")
+	ErrProcessNeedsWashing   = errors.New("process_needs_washing")
+	ErrProcessNeedsWashOrDry = errors.New("process_needs_wash_or_dry")
+	ErrProcessNeedsWasher    = errors.New("process_needs_washer")
+	ErrProcessNeedsDryer     = errors.New("process_needs_dryer")
+)
+
+// เงื่อนไขก่อนเปลี่ยนเป็นสถานะหนึ่ง (สถานะที่ไม่มีในตาราง เปลี่ยนได้เสมอ)
+type processRule struct {
+	from       []string // สถานะก่อนหน้าที่อนุญาต
+	machine    string   // ประเภทเครื่องที่ต้องผูกไว้แล้ว ("" = ไม่ต้องมี)
+	errFrom    error
+	errMachine error
+}
+
+var processRules = map[string]processRule{
+	ProcessWashing: {from: []string{ProcessPickedUp}, machine: MachineWashing, errFrom: ErrProcessNeedsPickup, errMachine: ErrProcessNeedsWasher},
+	ProcessDrying:  {from: []string{ProcessWashing}, machine: MachineDrying, errFrom: ErrProcessNeedsWashing, errMachine: ErrProcessNeedsDryer},
+	ProcessDone:    {from: []string{ProcessDrying, ProcessWashing}, errFrom: ErrProcessNeedsWashOrDry},
+}
+
+// CheckProcessTransition ตรวจว่ากระบวนการ (พร้อม Machines) เปลี่ยนเป็นสถานะ to ได้หรือไม่
+func CheckProcessTransition(p *entity.LaundryProcess, to string) error {
+	rule, ok := processRules[to]
+	if !ok {
+		return nil
+	}
+	allowed := false
+	for _, s := range rule.from {
+		if p.Status == s {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return rule.errFrom
+	}
+	if rule.machine != "" {
+		for _, m := range p.Machines {
+			if m.Machine_type == rule.machine {
+				return nil
+			}
+		}
+		return rule.errMachine
+	}
+	return nil
+}
+
+// ProcessService สร้าง/เปลี่ยนสถานะกระบวนการซัก และผูก/คืนเครื่อง
+type ProcessService struct {
+	db *gorm.DB
+}
+
+func NewProcessService(db *gorm.DB) *ProcessService {
+	return &ProcessService{db: db}
+}
+
+// Create สร้างกระบวนการ (รอดำเนินการ) ให้ออเดอร์ พร้อมคิวรับผ้า
+func (s *ProcessService) Create(orderID, employeeID uint, description string) (*entity.LaundryProcess, error) {
+	process := entity.LaundryProcess{
+		Status:      ProcessPending,
+		Start_time:  time.Now(),
+		Description: description,
+		EmployeeID:  employeeID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var order entity.Order
+		if err := tx.First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		if err := tx.Create(&process).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&process).Association("Order").Append(&order); err != nil {
+			return err
+		}
+		return tx.Create(&entity.Queue{Queue_type: QueuePickup, Status: QueueWaiting, OrderID: order.ID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &process, nil
+}
+
+// ProcessStatusUpdate ค่าที่ใช้เปลี่ยนสถานะ (EmployeeID 0 = คงผู้รับผิดชอบเดิม)
+type ProcessStatusUpdate struct {
+	Status     string
+	Note       string
+	EmployeeID uint
+}
+
+// UpdateStatus เปลี่ยนสถานะตาม processRules
+// เสร็จสิ้น: บันทึกเวลาจบ คืนเครื่องทั้งหมด และสร้างคิวส่งผ้าให้ทุกออเดอร์ที่ยังไม่มี
+func (s *ProcessService) UpdateStatus(id uint, in ProcessStatusUpdate) (*entity.LaundryProcess, error) {
+	var process entity.LaundryProcess
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Machines").First(&process, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProcessNotFound
+			}
+			return err
+		}
+		if err := CheckProcessTransition(&process, in.Status); err != nil {
+			return err
+		}
+
+		process.Status = in.Status
+		process.Description = in.Note
+		if in.EmployeeID != 0 {
+			process.EmployeeID = in.EmployeeID
+		}
+		if in.Status == ProcessDone {
+			process.End_time = time.Now()
+			ids := make([]uint, len(process.Machines))
+			for i, m := range process.Machines {
+				ids[i] = m.ID
+				m.Status = MachineAvailable
+			}
+			if err := setMachineStatus(tx, ids, MachineAvailable); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit(clause.Associations).Save(&process).Error; err != nil {
+			return err
+		}
+
+		if in.Status == ProcessDone {
+			var orders []entity.Order
+			if err := tx.Model(&process).Association("Order").Find(&orders); err != nil {
+				return err
+			}
+			for _, o := range orders {
+				if err := ensureDeliveryQueue(tx, o.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &process, nil
+}
+
+// AssignMachines ตั้งชุดเครื่องของกระบวนการใหม่ทั้งชุด
+// เครื่องที่หลุดจากชุด -> available, เครื่องในชุดใหม่ -> in_use
+func (s *ProcessService) AssignMachines(id uint, machineIDs []uint) (*entity.LaundryProcess, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var process entity.LaundryProcess
+		if err := tx.Preload("Machines").First(&process, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProcessNotFound
+			}
+			return err
+		}
+		var machines []entity.Machine
+		if len(machineIDs) > 0 {
+			if err := tx.Where("id IN ?", machineIDs).Find(&machines).Error; err != nil {
+				return err
+			}
+		}
+
+		keep := make(map[uint]bool, len(machineIDs))
+		for _, mid := range machineIDs {
+			keep[mid] = true
+		}
+		var released []uint
+		for _, m := range process.Machines {
+			if !keep[m.ID] {
+				released = append(released, m.ID)
+			}
+		}
+		if err := setMachineStatus(tx, released, MachineAvailable); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&process).Association("Machines").Clear(); err != nil {
+			return err
+		}
+		if len(machines) == 0 {
+			return nil
+		}
+		if err := tx.Model(&process).Association("Machines").Append(machines); err != nil {
+			return err
+		}
+		return setMachineStatus(tx, machineIDs, MachineInUse)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withMachines(id)
+}
+
+// RemoveMachine นำเครื่องออกจากกระบวนการ แล้วคืนเครื่องเป็น available
+func (s *ProcessService) RemoveMachine(id, machineID uint) (*entity.LaundryProcess, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var process entity.LaundryProcess
+		if err := tx.Preload("Machines").First(&process, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProcessNotFound
+			}
+			return err
+		}
+		var machine entity.Machine
+		if err := tx.First(&machine, machineID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMachineNotFound
+			}
+			return err
+		}
+		found := false
+		for _, m := range process.Machines {
+			if m.ID == machine.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrMachineNotInProcess
+		}
+		if err := tx.Model(&process).Association("Machines").Delete(&machine); err != nil {
+			return err
+		}
+		return setMachineStatus(tx, []uint{machine.ID}, MachineAvailable)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withMachines(id)
+}
+
+func (s *ProcessService) withMachines(id uint) (*entity.LaundryProcess, error) {
+	var p entity.LaundryProcess
+	if err := s.db.Preload("Machines").First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func setMachineStatus(tx *gorm.DB, ids []uint, status string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(&entity.Machine{}).Where("id IN ?", ids).Update("status", status).Error
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// คิวรับ-ส่งผ้า
+// pickup:   waiting -> pickup_in_progress -> done (กระบวนการซักล่าสุด -> รับผ้าเรียบร้อย)
+// delivery: waiting -> delivery_in_progress -> delivered
+// ทุกครั้งที่พนักงานรับ/ปิดคิว บันทึก QueueAssignment, ปิดคิวบันทึก QueueHistory
+// ======================================================
+
+// ประเภท/สถานะคิว
+const (
+	QueuePickup             = "pickup"
+	QueueDelivery           = "delivery"
+	QueueWaiting            = "waiting"
+	QueuePickupInProgress   = "pickup_in_progress"
+	QueueDeliveryInProgress = "delivery_in_progress"
+	QueueDone               = "done"
+	QueueDelivered          = "delivered"
+)
+
+var (
+	ErrQueueNotFound    = errors.New("queue_not_found")
+	ErrTimeSlotNotFound = errors.New("time_slot_not_found")
+	ErrTimeSlotFull     = errors.New("time_slot_full")
+)
+
+// QueueService ขั้นตอนของคิวรับ-ส่งผ้า
+type QueueService struct {
+	db *gorm.DB
+}
+
+func NewQueueService(db *gorm.DB) *QueueService {
+	return &QueueService{db: db}
+}
+
+// CreatePickup สร้างคิวรับผ้า (ยังไม่เลือกช่วงเวลา)
+func (s *QueueService) CreatePickup(orderID uint) (*entity.Queue, error) {
+	q := entity.Queue{Queue_type: QueuePickup, Status: QueueWaiting, OrderID: orderID}
+	if err := s.db.Create(&q).Error; err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// AssignTimeSlot ผูกคิวกับช่วงเวลา (ช่วงเวลาที่คิวครบ capacity แล้วรับไม่ได้; รับคิวสุดท้ายแล้วตั้งเป็น full)
+func (s *QueueService) AssignTimeSlot(id, timeSlotID uint) (*entity.Queue, error) {
+	var q entity.Queue
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findQueue(tx, id, &q); err != nil {
+			return err
+		}
+		var slot entity.TimeSlot
+		if err := tx.First(&slot, timeSlotID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTimeSlotNotFound
+			}
+			return err
+		}
+		var count int64
+		if err := tx.Model(&entity.Queue{}).Where("time_slot_id = ?", timeSlotID).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) >= slot.Capacity {
+			return ErrTimeSlotFull
+		}
+		q.TimeSlotID = &timeSlotID
+		if err := tx.Save(&q).Error; err != nil {
+			return err
+		}
+		if int(count+1) >= slot.Capacity {
+			return tx.Model(&slot).Update("status", "full").Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Accept พนักงานรับคิว (pickup/delivery -> *_in_progress)
+func (s *QueueService) Accept(id, employeeID uint) (*entity.Queue, error) {
+	return s.advance(id, employeeID, func(tx *gorm.DB, q *entity.Queue) error {
+		switch strings.ToLower(strings.TrimSpace(q.Queue_type)) {
+		case QueuePickup:
+			q.Status = QueuePickupInProgress
+		case QueueDelivery:
+			q.Status = QueueDeliveryInProgress
+		}
+		return nil
+	})
+}
+
+// ConfirmPickupDone รับผ้าเสร็จ: ปิดคิว + เลื่อนกระบวนการซักล่าสุดของออเดอร์เป็น รับผ้าเรียบร้อย
+func (s *QueueService) ConfirmPickupDone(id, employeeID uint) (*entity.Queue, error) {
+	return s.advance(id, employeeID, func(tx *gorm.DB, q *entity.Queue) error {
+		q.Status = QueueDone
+		if err := tx.Create(&entity.QueueHistory{QueueID: q.ID}).Error; err != nil {
+			return err
+		}
+		var process entity.LaundryProcess
+		err := tx.Joins("JOIN process_order ON process_order.laundry_process_id = laundry_processes.id").
+			Where("process_order.order_id = ?", q.OrderID).
+			Order("laundry_processes.created_at desc").
+			First(&process).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&process).Update("status", ProcessPickedUp).Error
+	})
+}
+
+// ConfirmDeliveryDone ส่งผ้าเสร็จ: ปิดคิว
+func (s *QueueService) ConfirmDeliveryDone(id, employeeID uint) (*entity.Queue, error) {
+	return s.advance(id, employeeID, func(tx *gorm.DB, q *entity.Queue) error {
+		q.Status = QueueDelivered
+		return tx.Create(&entity.QueueHistory{QueueID: q.ID}).Error
+	})
+}
+
+// QueueUpdate ค่าที่แก้ได้ (nil = คงเดิม)
+type QueueUpdate struct {
+	Status     *string
+	EmployeeID *uint
+}
+
+// Update แก้สถานะ / มอบหมายพนักงานใหม่
+func (s *QueueService) Update(id uint, in QueueUpdate) (*entity.Queue, error) {
+	var q entity.Queue
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findQueue(tx, id, &q); err != nil {
+			return err
+		}
+		if in.Status != nil {
+			q.Status = *in.Status
+		}
+		if err := tx.Save(&q).Error; err != nil {
+			return err
+		}
+		if in.EmployeeID != nil {
+			return assignQueue(tx, q.ID, *in.EmployeeID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Delete ลบคิว (คืนคิวที่ลบเพื่อแสดงผล)
+func (s *QueueService) Delete(id uint) (*entity.Queue, error) {
+	var q entity.Queue
+	if err := findQueue(s.db, id, &q); err != nil {
+		return nil, err
+	}
+	if err := s.db.Delete(&q).Error; err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// เปลี่ยนสถานะคิวด้วย step แล้วบันทึกผู้รับผิดชอบ ในธุรกรรมเดียว
+func (s *QueueService) advance(id, employeeID uint, step func(tx *gorm.DB, q *entity.Queue) error) (*entity.Queue, error) {
+	var q entity.Queue
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findQueue(tx, id, &q); err != nil {
+			return err
+		}
+		if err := step(tx, &q); err != nil {
+			return err
+		}
+		if err := tx.Save(&q).Error; err != nil {
+			return err
+		}
+		return assignQueue(tx, q.ID, employeeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func findQueue(db *gorm.DB, id uint, q *entity.Queue) error {
+	if err := db.First(q, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQueueNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func assignQueue(tx *gorm.DB, queueID, employeeID uint) error {
+	return tx.Create(&entity.QueueAssignment{QueueID: queueID, EmployeeID: employeeID, Assigned_time: time.Now()}).Error
+}
+
+// สร้างคิวส่งผ้าให้ออเดอร์ (มีอยู่แล้วไม่สร้างซ้ำ)
+func ensureDeliveryQueue(tx *gorm.DB, orderID uint) error {
+	var n int64
+	if err := tx.Model(&entity.Queue{}).Where("order_id = ? AND queue_type = ?", orderID, QueueDelivery).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	return tx.Create(&entity.Queue{Queue_type: QueueDelivery, Status: QueueWaiting, OrderID: orderID}).Error
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter นับจำนวนครั้งต่อ key ในหน้าต่างเวลาคงที่ (เก็บในหน่วยความจำ ต่อ 1 process)
+type RateLimiter struct {
+	Limit  int
+	Window time.Duration
+
+	mu        sync.Mutex
+	hits      map[string]*rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{Limit: limit, Window: window, hits: map[string]*rateWindow{}}
+}
+
+// Allow นับ 1 ครั้งให้ key; เกินโควตาคืน false พร้อมเวลาที่ต้องรอ
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.window(key, now)
+	if w.count >= l.Limit {
+		return false, w.start.Add(l.Window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// Check ตรวจว่า key ยังไม่เกินโควตาโดยไม่นับเพิ่ม (ใช้คู่กับ Allow เมื่อจะนับเฉพาะครั้งที่ล้มเหลว)
+func (l *RateLimiter) Check(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.window(key, now)
+	if w.count >= l.Limit {
+		return false, w.start.Add(l.Window).Sub(now)
+	}
+	return true, 0
+}
+
+// หน้าต่างปัจจุบันของ key (ต้องถือ l.mu อยู่แล้ว)
+func (l *RateLimiter) window(key string, now time.Time) *rateWindow {
+	// ล้าง key ที่หมดหน้าต่างแล้วเป็นระยะ กัน map โตไม่หยุด
+	if now.Sub(l.lastSweep) >= l.Window {
+		for k, w := range l.hits {
+			if now.Sub(w.start) >= l.Window {
+				delete(l.hits, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.hits[key]
+	if !ok || now.Sub(w.start) >= l.Window {
+		w = &rateWindow{start: now}
+		l.hits[key] = w
+	}
+	return w
+}
+
+// Reset ล้างตัวนับของ key (เช่น หลัง admin ปลดล็อกบัญชี)
+func (l *RateLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.hits, key)
+}
+
+// ResetAll ล้างตัวนับทุก key
+func (l *RateLimiter) ResetAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hits = map[string]*rateWindow{}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ======================================================
+// ใบเสร็จรับเงิน/ใบกำกับภาษี
+// - เลขที่เอกสาร RC<ปี>-<ลำดับ 6 หลัก> ต่อเนื่องรายปี (ขอเลขในธุรกรรมเดียวกับการสร้างใบ
+//   ถ้า rollback เลขจะถูกคืนด้วย จึงไม่มีเลขขาดช่วง)
+// - ออเดอร์หนึ่งมีใบ issued ได้ใบเดียว (unique index จาก migration 0003)
+// - ยกเลิกแล้วยังคงเลขที่เดิมไว้
+// ======================================================
+
+const (
+	ReceiptPrefix = "RC"
+	VatRatePct    = 7.0 // ราคาค่าบริการรวม VAT แล้ว
+
+	ReceiptIssued    = "issued"
+	ReceiptCancelled = "cancelled"
+)
+
+var (
+	ErrOrderNotPaid            = errors.New("order_not_paid")
+	ErrReceiptNotFound         = errors.New("receipt_not_found")
+	ErrReceiptAlreadyCancelled = errors.New("receipt_already_cancelled")
+	ErrReceiptConflict         = errors.New("receipt_conflict")
+)
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// NextDocumentNo ขอเลขที่เอกสารถัดไปของปี (ต้องเรียกภายใน transaction เดียวกับการสร้างเอกสาร)
+func NextDocumentNo(tx *gorm.DB, prefix string, year int) (string, int, error) {
+	inc := func() (int64, error) {
+		res := tx.Model(&entity.DocumentSequence{}).
+			Where("prefix = ? AND year = ?", prefix, year).
+			UpdateColumn("last_no", gorm.Expr("last_no + 1"))
+		return res.RowsAffected, res.Error
+	}
+	n, err := inc()
+	if err != nil {
+		return "", 0, err
+	}
+	if n == 0 {
+		// เอกสารแรกของปี: ธุรกรรมที่ออกพร้อมกันอาจสร้างแถวไปก่อน -> ไม่สร้างซ้ำ แล้วเพิ่มเลขต่อจากแถวนั้น
+		seq := entity.DocumentSequence{Prefix: prefix, Year: year}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "prefix"}, {Name: "year"}},
+			DoNothing: true,
+		}).Create(&seq).Error; err != nil {
+			return "", 0, err
+		}
+		if _, err := inc(); err != nil {
+			return "", 0, err
+		}
+	}
+	var seq entity.DocumentSequence
+	if err := tx.Where("prefix = ? AND year = ?", prefix, year).First(&seq).Error; err != nil {
+		return "", 0, err
+	}
+	return fmt.Sprintf("%s%d-%06d", prefix, year, seq.LastNo), seq.LastNo, nil
+}
+
+// ReceiptAmounts คำนวณยอดจากออเดอร์ + การชำระเงิน (ยอดก่อนลด, ส่วนลด, VAT ที่รวมอยู่, ยอดสุทธิ)
+func ReceiptAmounts(order *entity.Order, pay *entity.Payment) (subtotal, discount, vat, total float64) {
+	for _, st := range order.ServiceTypes {
+		if st != nil {
+			subtotal += st.Price
+		}
+	}
+	total = float64(pay.TotalAmount)
+	if total <= 0 {
+		total = float64(pay.VerifiedAmount)
+	}
+	if subtotal < total {
+		subtotal = total
+	}
+	discount = subtotal - total
+	vat = round2(total * VatRatePct / (100 + VatRatePct))
+	return round2(subtotal), round2(discount), vat, round2(total)
+}
+
+// IssueReceipt ออกใบเสร็จใหม่ให้ออเดอร์ที่ชำระแล้ว (ใบ issued เดิมต้องถูกยกเลิกก่อน)
+func IssueReceipt(tx *gorm.DB, orderID uint, issuedBy *uint) (*entity.Receipt, error) {
+	var order entity.Order
+	if err := tx.Preload("ServiceTypes").Preload("Payment").First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if order.Payment == nil || !strings.EqualFold(order.Payment.PaymentStatus, PaymentPaid) {
+		return nil, ErrOrderNotPaid
+	}
+
+	// ปีของเลขที่เอกสารตามเวลาร้าน (ช่วงข้ามปีต้องไม่ใช้ปีของเวลาเซิร์ฟเวอร์)
+	now := time.Now().In(ShopLocation())
+	docNo, seq, err := NextDocumentNo(tx, ReceiptPrefix, now.Year())
+	if err != nil {
+		return nil, err
+	}
+	subtotal, discount, vat, total := ReceiptAmounts(&order, order.Payment)
+	rc := entity.Receipt{
+		DocumentNo:    docNo,
+		Year:          now.Year(),
+		SeqNo:         seq,
+		Status:        ReceiptIssued,
+		Subtotal:      subtotal,
+		Discount:      discount,
+		VatAmount:     vat,
+		Total:         total,
+		PaymentMethod: order.Payment.PaymentType,
+		IssuedAt:      now,
+		IssuedBy:      issuedBy,
+		OrderID:       order.ID,
+		PaymentID:     order.Payment.ID,
+	}
+	if err := tx.Create(&rc).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrReceiptConflict
+		}
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// IssuedReceipt ใบเสร็จที่ใช้งานอยู่ของออเดอร์ (gorm.ErrRecordNotFound = ยังไม่มี)
+func IssuedReceipt(db *gorm.DB, orderID uint) (*entity.Receipt, error) {
+	var rc entity.Receipt
+	if err := db.Where("order_id = ? AND status = ?", orderID, ReceiptIssued).First(&rc).Error; err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// EnsureReceipt คืนใบเสร็จที่ใช้งานอยู่ของออเดอร์ ถ้ายังไม่มีจะออกให้ใหม่
+// ถ้าคำขออื่นออกให้ก่อนระหว่างนี้ การสร้างจะชน unique index และ rollback (คืนเลขที่เอกสาร) แล้วใช้ใบของคำขอนั้น
+// PaymentService เรียกภายในธุรกรรมของการชำระเงิน (ใช้ savepoint) ใบเสร็จจึงออกพร้อมกับการชำระเงินเสมอ
+func EnsureReceipt(db *gorm.DB, orderID uint) (*entity.Receipt, error) {
+	rc, err := IssuedReceipt(db, orderID)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return rc, err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		r, err := IssueReceipt(tx, orderID, nil)
+		rc = r
+		return err
+	})
+	if errors.Is(err, ErrReceiptConflict) {
+		return IssuedReceipt(db, orderID)
+	}
+	return rc, err
+}
+
+// ReceiptService ออกใหม่/ยกเลิกใบเสร็จ (พนักงาน)
+type ReceiptService struct {
+	db *gorm.DB
+}
+
+func NewReceiptService(db *gorm.DB) *ReceiptService {
+	return &ReceiptService{db: db}
+}
+
+// Reissue ยกเลิกใบที่ใช้งานอยู่ (ถ้ามี) แล้วออกใบใหม่ด้วยเลขถัดไป
+// ยกเลิกก่อนออกใบใหม่ เพราะออเดอร์มีใบ issued ได้ใบเดียว
+func (s *ReceiptService) Reissue(orderID uint, employeeID *uint, reason string) (*entity.Receipt, error) {
+	var out *entity.Receipt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		old, err := IssuedReceipt(tx, orderID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if old != nil {
+			now := time.Now()
+			if err := tx.Model(old).Updates(map[string]interface{}{
+				"status":        ReceiptCancelled,
+				"cancel_reason": reason,
+				"cancelled_at":  &now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		rc, err := IssueReceipt(tx, orderID, employeeID)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			if err := tx.Model(old).Update("replaced_by_id", rc.ID).Error; err != nil {
+				return err
+			}
+		}
+		out = rc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Cancel ยกเลิกใบเสร็จ (คงเลขที่ไว้)
+func (s *ReceiptService) Cancel(receiptID uint, reason string) (*entity.Receipt, error) {
+	var rc entity.Receipt
+	if err := s.db.First(&rc, receiptID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReceiptNotFound
+		}
+		return nil, err
+	}
+	if rc.Status == ReceiptCancelled {
+		return nil, ErrReceiptAlreadyCancelled
+	}
+	now := time.Now()
+	rc.Status = ReceiptCancelled
+	rc.CancelReason = reason
+	rc.CancelledAt = &now
+	if err := s.db.Save(&rc).Error; err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/go-pdf/fpdf"
+)
+
+// ข้อมูลที่ใช้พิมพ์ใบเสร็จ (ไม่ผูกกับ gorm เพื่อให้เรียกใช้/ทดสอบแยกได้)
+type ReceiptDocument struct {
+	DocumentNo    string
+	IssuedAt      time.Time
+	Cancelled     bool
+	CancelReason  string
+	ShopName      string
+	ShopTaxID     string
+	ShopAddress   string
+	CustomerName  string
+	CustomerPhone string
+	CustomerAddr  string
+	OrderID       uint
+	PaymentMethod string
+	TransRef      string
+	Lines         []ReceiptLine
+	Subtotal      float64
+	Discount      float64
+	DiscountLabel string
+	VatRate       float64 // เช่น 7 (ราคารวม VAT แล้ว)
+	VatAmount     float64
+	Total         float64
+}
+
+type ReceiptLine struct {
+	Description string
+	Quantity    int
+	UnitPrice   float64
+	Amount      float64
+	InfoOnly    bool // แถวแสดงรายการผ้าที่คัดแยก ไม่มีราคา
+}
+
+// ป้ายกำกับ: ใช้ภาษาไทยได้เมื่อมีฟอนต์ UTF-8 (RECEIPT_FONT_PATH) เท่านั้น
+var receiptLabels = map[string][2]string{
+	"title":     {"ใบเสร็จรับเงิน / ใบกำกับภาษีอย่างย่อ", "RECEIPT / ABBREVIATED TAX INVOICE"},
+	"docNo":     {"เลขที่", "No."},
+	"date":      {"วันที่", "Date"},
+	"customer":  {"ลูกค้า", "Customer"},
+	"phone":     {"โทร", "Phone"},
+	"address":   {"ที่อยู่", "Address"},
+	"order":     {"ออเดอร์", "Order"},
+	"desc":      {"รายการ", "Description"},
+	"qty":       {"จำนวน", "Qty"},
+	"unit":      {"ราคา/หน่วย", "Unit price"},
+	"amount":    {"จำนวนเงิน", "Amount"},
+	"subtotal":  {"รวมเป็นเงิน", "Subtotal"},
+	"discount":  {"ส่วนลด", "Discount"},
+	"vat":       {"ภาษีมูลค่าเพิ่ม (รวมในราคา)", "VAT (included)"},
+	"total":     {"ยอดชำระสุทธิ", "Total paid"},
+	"method":    {"ชำระโดย", "Paid by"},
+	"ref":       {"อ้างอิง", "Reference"},
+	"taxID":     {"เลขประจำตัวผู้เสียภาษี", "Tax ID"},
+	"cancelled": {"ยกเลิกแล้ว", "CANCELLED"},
+	"clothes":   {"รายการผ้าที่คัดแยก", "Sorted clothes"},
+}
+
+// RenderReceiptPDF สร้างไฟล์ PDF ของใบเสร็จ
+func RenderReceiptPDF(doc ReceiptDocument) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A5", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 12)
+
+	family, thai := "Helvetica", false
+	if path := strings.TrimSpace(config.Current().Shop.ReceiptFontPath); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			pdf.AddUTF8Font("receipt", "", path)
+			pdf.AddUTF8Font("receipt", "B", path)
+			family, thai = "receipt", true
+		}
+	}
+	label := func(k string) string {
+		l := receiptLabels[k]
+		if thai {
+			return l[0]
+		}
+		return l[1]
+	}
+	// ฟอนต์มาตรฐานพิมพ์ได้เฉพาะ latin-1 ตัดอักขระอื่นออกเพื่อไม่ให้ PDF เสีย
+	text := func(s string) string {
+		if thai {
+			return s
+		}
+		var b strings.Builder
+		for _, r := range s {
+			if r < 0x80 {
+				b.WriteRune(r)
+			} else {
+				b.WriteRune('?')
+			}
+		}
+		return b.String()
+	}
+	money := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+
+	pdf.AddPage()
+	pageW, _ := pdf.GetPageSize()
+	contentW := pageW - 20
+
+	pdf.SetFont(family, "B", 13)
+	if doc.ShopName != "" {
+		pdf.CellFormat(contentW, 7, text(doc.ShopName), "", 1, "C", false, 0, "")
+	}
+	pdf.SetFont(family, "", 9)
+	if doc.ShopAddress != "" {
+		pdf.MultiCell(contentW, 4.5, text(doc.ShopAddress), "", "C", false)
+	}
+	if doc.ShopTaxID != "" {
+		pdf.CellFormat(contentW, 4.5, text(label("taxID")+" "+doc.ShopTaxID), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(2)
+	pdf.SetFont(family, "B", 11)
+	pdf.CellFormat(contentW, 6, text(label("title")), "", 1, "C", false, 0, "")
+
+	pdf.SetFont(family, "", 9)
+	half := contentW / 2
+	pdf.CellFormat(half, 5, text(label("docNo")+": "+doc.DocumentNo), "", 0, "L", false, 0, "")
+	pdf.CellFormat(half, 5, text(label("date")+": "+doc.IssuedAt.Format("02/01/2006 15:04")), "", 1, "R", false, 0, "")
+	pdf.CellFormat(contentW, 5, text(fmt.Sprintf("%s: #%d", label("order"), doc.OrderID)), "", 1, "L", false, 0, "")
+	if doc.CustomerName != "" {
+		pdf.CellFormat(contentW, 5, text(label("customer")+": "+doc.CustomerName), "", 1, "L", false, 0, "")
+	}
+	if doc.CustomerPhone != "" {
+		pdf.CellFormat(contentW, 5, text(label("phone")+": "+doc.CustomerPhone), "", 1, "L", false, 0, "")
+	}
+	if doc.CustomerAddr != "" {
+		pdf.MultiCell(contentW, 5, text(label("address")+": "+doc.CustomerAddr), "", "L", false)
+	}
+	pdf.Ln(2)
+
+	// ตารางรายการ
+	wDesc, wQty, wUnit := contentW*0.52, contentW*0.12, contentW*0.18
+	wAmt := contentW - wDesc - wQty - wUnit
+	pdf.SetFont(family, "B", 9)
+	pdf.CellFormat(wDesc, 6, text(label("desc")), "TB", 0, "L", false, 0, "")
+	pdf.CellFormat(wQty, 6, text(label("qty")), "TB", 0, "R", false, 0, "")
+	pdf.CellFormat(wUnit, 6, text(label("unit")), "TB", 0, "R", false, 0, "")
+	pdf.CellFormat(wAmt, 6, text(label("amount")), "TB", 1, "R", false, 0, "")
+	pdf.SetFont(family, "", 9)
+
+	infoHeader := false
+	for _, ln := range doc.Lines {
+		if ln.InfoOnly {
+			if !infoHeader {
+				pdf.SetFont(family, "B", 8)
+				pdf.CellFormat(contentW, 5, text(label("clothes")), "", 1, "L", false, 0, "")
+				pdf.SetFont(family, "", 8)
+				infoHeader = true
+			}
+			pdf.CellFormat(wDesc, 4.5, text("  "+ln.Description), "", 0, "L", false, 0, "")
+			pdf.CellFormat(wQty, 4.5, fmt.Sprint(ln.Quantity), "", 1, "R", false, 0, "")
+			continue
+		}
+		pdf.CellFormat(wDesc, 5, text(ln.Description), "", 0, "L", false, 0, "")
+		pdf.CellFormat(wQty, 5, fmt.Sprint(ln.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(wUnit, 5, money(ln.UnitPrice), "", 0, "R", false, 0, "")
+		pdf.CellFormat(wAmt, 5, money(ln.Amount), "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont(family, "", 9)
+	pdf.CellFormat(contentW, 1, "", "B", 1, "", false, 0, "")
+	pdf.Ln(1)
+
+	sumRow := func(k, v string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont(family, style, 9)
+		pdf.CellFormat(contentW-wAmt, 5, text(k), "", 0, "R", false, 0, "")
+		pdf.CellFormat(wAmt, 5, v, "", 1, "R", false, 0, "")
+	}
+	sumRow(label("subtotal"), money(doc.Subtotal), false)
+	if doc.Discount > 0 {
+		k := label("discount")
+		if doc.DiscountLabel != "" {
+			k += " (" + doc.DiscountLabel + ")"
+		}
+		sumRow(k, "-"+money(doc.Discount), false)
+	}
+	sumRow(fmt.Sprintf("%s %.0f%%", label("vat"), doc.VatRate), money(doc.VatAmount), false)
+	sumRow(label("total"), money(doc.Total), true)
+	pdf.Ln(2)
+
+	pdf.SetFont(family, "", 9)
+	pdf.CellFormat(contentW, 5, text(label("method")+": "+doc.PaymentMethod), "", 1, "L", false, 0, "")
+	if doc.TransRef != "" {
+		pdf.CellFormat(contentW, 5, text(label("ref")+": "+doc.TransRef), "", 1, "L", false, 0, "")
+	}
+
+	if doc.Cancelled {
+		pdf.Ln(3)
+		pdf.SetTextColor(200, 0, 0)
+		pdf.SetFont(family, "B", 14)
+		pdf.CellFormat(contentW, 8, text(label("cancelled")), "1", 1, "C", false, 0, "")
+		if doc.CancelReason != "" {
+			pdf.SetFont(family, "", 9)
+			pdf.MultiCell(contentW, 5, text(doc.CancelReason), "", "C", false)
+		}
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ตัวแปรในเทมเพลต เช่น {customerName}
+var replyPlaceholderRe = regexp.MustCompile(`\{([A-Za-z][A-Za-z0-9_]*)\}`)
+
+// ReplyTemplatePlaceholders ตัวแปรที่ระบบเติมให้จากคำร้อง/ลูกค้า
+var ReplyTemplatePlaceholders = []string{"customerName", "orderId", "publicId", "subject", "category", "employeeName"}
+
+// RenderReplyTemplate แทนค่าตัวแปรใน body; ตัวแปรที่ไม่มีค่า (หรือค่าว่าง) คงไว้ตามเดิมและคืนชื่อใน missing
+func RenderReplyTemplate(body string, vars map[string]string) (string, []string) {
+	seen := map[string]bool{}
+	var missing []string
+	out := replyPlaceholderRe.ReplaceAllStringFunc(body, func(m string) string {
+		name := m[1 : len(m)-1]
+		if v := strings.TrimSpace(vars[name]); v != "" {
+			return v
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return m
+	})
+	sort.Strings(missing)
+	return out, missing
+}
@@ -0,0 +1,24 @@
+package services
+
+import "gorm.io/gorm"
+
+// ชื่อ role ตามที่ seed ไว้ในตาราง roles (migration 0002)
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+	RoleEmployee = "employee"
+)
+
+// UserRole ชื่อ role ของผู้ใช้ ("" = ไม่พบผู้ใช้หรือผู้ใช้ถูกลบแล้ว)
+func UserRole(db *gorm.DB, userID uint) (string, error) {
+	var name string
+	err := db.Table("users").
+		Select("roles.name").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("users.id = ? AND users.deleted_at IS NULL", userID).
+		Scan(&name).Error
+	return name, err
+}
+
+// IsStaffRole พนักงานหรือผู้ดูแลระบบ
+func IsStaffRole(role string) bool { return role == RoleAdmin || role == RoleEmployee }
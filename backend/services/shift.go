@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+var shopLocation *time.Location
+
+// SetShopLocation ตั้งเขตเวลาของร้าน (main ตั้งจาก SHOP_TIMEZONE)
+func SetShopLocation(loc *time.Location) { shopLocation = loc }
+
+// เขตเวลาของร้าน (วันทำการ/วันทำงานตามกะ) ยังไม่ตั้ง = Asia/Bangkok
+func ShopLocation() *time.Location {
+	if shopLocation != nil {
+		return shopLocation
+	}
+	if loc, err := time.LoadLocation("Asia/Bangkok"); err == nil {
+		return loc
+	}
+	return time.Local
+}
+
+// ลงเวลาเข้างานก่อนเริ่มกะได้ไม่เกินเท่านี้ (นับเป็นกะนั้น)
+const ClockInEarlyWindow = 2 * time.Hour
+
+var ErrInvalidClock = errors.New("invalid clock time")
+
+// ParseClock แปลง "HH:MM" เป็นจำนวนนาทีนับจากเที่ยงคืน
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidClock
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ShiftWindow ช่วงเวลาจริงของกะในวันที่ date (YYYY-MM-DD, เขตเวลาร้าน)
+func ShiftWindow(tpl *entity.ShiftTemplate, date string) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, ShopLocation())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startMin, err := ParseClock(tpl.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endMin, err := ParseClock(tpl.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start := day.Add(time.Duration(startMin) * time.Minute)
+	end := day.Add(time.Duration(endMin) * time.Minute)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1) // ข้ามเที่ยงคืน
+	}
+	return start, end, nil
+}
+
+// ScheduledShift กะที่ตรงกับเวลาหนึ่ง (Template เป็น nil = ไม่มีกะในตาราง)
+type ScheduledShift struct {
+	WorkDate string
+	Template *entity.ShiftTemplate
+	Start    time.Time
+	End      time.Time
+}
+
+// FindScheduledShift หากะของพนักงานที่ครอบคลุมเวลา at (รวมกะข้ามคืนของเมื่อวาน)
+// ไม่มีกะ = คืน WorkDate เป็นวันนี้
+func FindScheduledShift(db *gorm.DB, employeeID uint, at time.Time) (*ScheduledShift, error) {
+	at = at.In(ShopLocation())
+	today := at.Format("2006-01-02")
+
+	var rosters []entity.EmployeeRoster
+	if err := db.Preload("ShiftTemplate").
+		Where("employee_id = ? AND weekday IN ?", employeeID, []int{int(at.Weekday()), int(at.AddDate(0, 0, -1).Weekday())}).
+		Find(&rosters).Error; err != nil {
+		return nil, err
+	}
+
+	// เมื่อวาน (กะข้ามคืน) ก่อน แล้ววันนี้
+	for _, date := range []string{at.AddDate(0, 0, -1).Format("2006-01-02"), today} {
+		d, _ := time.ParseInLocation("2006-01-02", date, ShopLocation())
+		for i := range rosters {
+			r := &rosters[i]
+			if r.Weekday != int(d.Weekday()) || r.ShiftTemplate == nil {
+				continue
+			}
+			start, end, err := ShiftWindow(r.ShiftTemplate, date)
+			if err != nil {
+				return nil, fmt.Errorf("shift %d: %w", r.ShiftTemplateID, err)
+			}
+			if !at.Before(start.Add(-ClockInEarlyWindow)) && at.Before(end) {
+				return &ScheduledShift{WorkDate: date, Template: r.ShiftTemplate, Start: start, End: end}, nil
+			}
+		}
+	}
+	return &ScheduledShift{WorkDate: today}, nil
+}
+
+// OnDutyEmployeeIDs พนักงานที่อยู่ในเวลางานตอน at:
+// ตามตารางกะ หรือ ลงเวลาเข้างานแล้วยังไม่ออก; ไม่รวมคนที่ลา (อนุมัติแล้ว) ในวันนั้น
+func OnDutyEmployeeIDs(db *gorm.DB, at time.Time) (map[uint]bool, error) {
+	at = at.In(ShopLocation())
+	out := map[uint]bool{}
+
+	var rosters []entity.EmployeeRoster
+	if err := db.Preload("ShiftTemplate").
+		Where("weekday IN ?", []int{int(at.Weekday()), int(at.AddDate(0, 0, -1).Weekday())}).
+		Find(&rosters).Error; err != nil {
+		return nil, err
+	}
+	for _, date := range []string{at.AddDate(0, 0, -1).Format("2006-01-02"), at.Format("2006-01-02")} {
+		d, _ := time.ParseInLocation("2006-01-02", date, ShopLocation())
+		for i := range rosters {
+			r := &rosters[i]
+			if r.Weekday != int(d.Weekday()) || r.ShiftTemplate == nil {
+				continue
+			}
+			start, end, err := ShiftWindow(r.ShiftTemplate, date)
+			if err == nil && !at.Before(start) && at.Before(end) {
+				out[r.EmployeeID] = true
+			}
+		}
+	}
+
+	var open []uint
+	if err := db.Model(&entity.Attendance{}).Where("clock_out_at IS NULL").
+		Distinct().Pluck("employee_id", &open).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range open {
+		out[id] = true
+	}
+
+	var onLeave []uint
+	today := at.Format("2006-01-02")
+	if err := db.Model(&entity.LeaveRequest{}).
+		Where("status = ? AND start_date <= ? AND end_date >= ?", LeaveApproved, today, today).
+		Pluck("employee_id", &onLeave).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range onLeave {
+		delete(out, id)
+	}
+	return out, nil
+}
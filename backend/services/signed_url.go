@@ -0,0 +1,47 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+)
+
+// ลิงก์ดาวน์โหลดไฟล์แบบมีวันหมดอายุ: /attachments/:id?variant=&exp=<unix>&sig=<hmac>
+// secret และอายุลิงก์มาจาก config (auth.fileURLSecret ว่าง = ใช้ JWT secret, auth.fileURLTTL)
+
+func fileURLSecret() []byte { return []byte(config.Current().Auth.FileURLSecret) }
+
+// FileURLTTL อายุลิงก์ดาวน์โหลด (FILE_URL_TTL)
+func FileURLTTL() time.Duration { return config.Current().Auth.FileURLTTL }
+
+func fileSignature(id uint, variant string, exp int64) string {
+	mac := hmac.New(sha256.New, fileURLSecret())
+	fmt.Fprintf(mac, "%d|%s|%d", id, variant, exp)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignFileQuery คืน query string ที่ลงลายเซ็นแล้ว
+func SignFileQuery(id uint, variant string, exp time.Time) string {
+	q := url.Values{}
+	if variant != "" {
+		q.Set("variant", variant)
+	}
+	q.Set("exp", strconv.FormatInt(exp.Unix(), 10))
+	q.Set("sig", fileSignature(id, variant, exp.Unix()))
+	return q.Encode()
+}
+
+// VerifyFileSignature ตรวจลายเซ็นและวันหมดอายุ
+func VerifyFileSignature(id uint, variant, expStr, sig string, now time.Time) bool {
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(fileSignature(id, variant, exp)), []byte(sig))
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// สต็อกน้ำยา: รับเข้า (PurchaseDetergent) / เบิกใช้ (DetergentUsageHistory)
+// ทุกการเปลี่ยน InStock มีประวัติคู่กันในธุรกรรมเดียว
+// ======================================================
+
+var (
+	ErrDetergentNotFound = errors.New("detergent_not_found")
+	ErrInvalidQuantity   = errors.New("invalid_quantity")
+	ErrOutOfStock        = errors.New("out_of_stock")
+)
+
+// เหตุผลการใช้น้ำยาที่ลูกค้าเลือกตอนสั่งออเดอร์
+const DetergentOrderUsageReason = "ใช้จากออเดอร์ลูกค้า"
+
+// StockService จัดการสต็อกน้ำยา
+type StockService struct {
+	db *gorm.DB
+}
+
+func NewStockService(db *gorm.DB) *StockService {
+	return &StockService{db: db}
+}
+
+// CreateDetergent เพิ่มน้ำยาใหม่
+func (s *StockService) CreateDetergent(d *entity.Detergent) error {
+	return s.db.Create(d).Error
+}
+
+// CreateWithPurchase เพิ่มน้ำยาใหม่พร้อมประวัติการซื้อครั้งแรก
+func (s *StockService) CreateWithPurchase(d *entity.Detergent, p *entity.PurchaseDetergent) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(d).Error; err != nil {
+			return err
+		}
+		p.DetergentID = d.ID
+		return tx.Create(p).Error
+	})
+}
+
+// DeleteDetergent ลบน้ำยา (soft delete ดูย้อนหลังได้ที่ /detergents/deleted)
+func (s *StockService) DeleteDetergent(id uint) error {
+	return s.db.Delete(&entity.Detergent{}, id).Error
+}
+
+// DetergentUse การเบิกน้ำยาไปใช้
+type DetergentUse struct {
+	UserID      uint
+	DetergentID uint
+	Quantity    int
+	Reason      string
+}
+
+// Use เบิกน้ำยา (จำนวนต้องมากกว่า 0 และไม่เกินที่มีในสต็อก)
+func (s *StockService) Use(in DetergentUse) (*entity.DetergentUsageHistory, error) {
+	var h entity.DetergentUsageHistory
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var d entity.Detergent
+		if err := findDetergent(tx, in.DetergentID, &d); err != nil {
+			return err
+		}
+		if in.Quantity <= 0 {
+			return ErrInvalidQuantity
+		}
+		if d.InStock < in.Quantity {
+			return ErrOutOfStock
+		}
+		d.InStock -= in.Quantity
+		if err := tx.Save(&d).Error; err != nil {
+			return err
+		}
+		h = entity.DetergentUsageHistory{UserID: in.UserID, DetergentID: d.ID, QuantityUsed: in.Quantity, Reason: in.Reason}
+		return tx.Create(&h).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// DetergentRestock การรับน้ำยาเข้าสต็อก
+type DetergentRestock struct {
+	Quantity int
+	Price    float64
+	Supplier string
+	UserID   uint
+	Image    string
+}
+
+// Restock เพิ่มสต็อกและบันทึกประวัติการซื้อ
+func (s *StockService) Restock(id uint, in DetergentRestock) (*entity.Detergent, *entity.PurchaseDetergent, error) {
+	var d entity.Detergent
+	var p entity.PurchaseDetergent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findDetergent(tx, id, &d); err != nil {
+			return err
+		}
+		d.InStock += in.Quantity
+		if err := tx.Save(&d).Error; err != nil {
+			return err
+		}
+		p = entity.PurchaseDetergent{
+			DetergentID: d.ID,
+			Quantity:    in.Quantity,
+			Price:       in.Price,
+			Supplier:    in.Supplier,
+			UserID:      in.UserID,
+			Image:       in.Image,
+		}
+		return tx.Create(&p).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &d, &p, nil
+}
+
+// ตัดสต็อกน้ำยาทางร้านที่ลูกค้าเลือก ชิ้นละ 1 (หมดสต็อกแล้วข้าม ไม่ทำให้ออเดอร์ล้ม)
+func consumeDetergents(tx *gorm.DB, ids []uint, userID uint) error {
+	for _, id := range ids {
+		var d entity.Detergent
+		if err := findDetergent(tx, id, &d); err != nil {
+			return err
+		}
+		if d.InStock <= 0 {
+			continue
+		}
+		d.InStock--
+		if err := tx.Save(&d).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&entity.DetergentUsageHistory{
+			UserID:       userID,
+			DetergentID:  d.ID,
+			QuantityUsed: 1,
+			Reason:       DetergentOrderUsageReason,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findDetergent(tx *gorm.DB, id uint, d *entity.Detergent) error {
+	if err := tx.First(d, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDetergentNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/entity"
+	"github.com/OnpreeyaMi/project-sa/migrations"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ฐานข้อมูลสำหรับ test ของ service: SQLite in-memory แยกฐานต่อ test (รัน migration ครบแล้ว)
+// service รับ *gorm.DB ตรง ๆ จึงไม่ต้องแตะ config.DB
+// ======================================================
+
+var testDBSeq atomic.Int64
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.OpenDatabase(config.DatabaseConfig{
+		Driver:       config.DriverSQLite,
+		DSN:          fmt.Sprintf("file:svctest%d?mode=memory&cache=shared", testDBSeq.Add(1)),
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func mustCreate(t *testing.T, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, v := range values {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("create %T: %v", v, err)
+		}
+	}
+}
+
+// ลูกค้า + ที่อยู่ สำหรับสร้างออเดอร์
+func seedCustomer(t *testing.T, db *gorm.DB, email string) (*entity.Customer, *entity.Address) {
+	t.Helper()
+	user := entity.User{Email: email, RoleID: 2}
+	mustCreate(t, db, &user)
+	cus := entity.Customer{FirstName: "ลูกค้า", LastName: email, UserID: user.ID}
+	mustCreate(t, db, &cus)
+	addr := entity.Address{AddressDetails: "บ้านเลขที่ 1", CustomerID: cus.ID, IsDefault: true}
+	mustCreate(t, db, &addr)
+	return &cus, &addr
+}
+
+// ออเดอร์ใหม่ผ่าน OrderService (มี process รอดำเนินการ + คิวรับผ้าแล้ว)
+func seedOrder(t *testing.T, db *gorm.DB, cus *entity.Customer, addr *entity.Address) *entity.Order {
+	t.Helper()
+	order, err := NewOrderService(db).Create(NewOrder{
+		CustomerID:     cus.ID,
+		AddressID:      addr.ID,
+		ServiceTypeIDs: []uint{1, 5}, // ซัก 10kg (50) + อบ 14kg (50)
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	return order
+}
+
+func seedEmployee(t *testing.T, db *gorm.DB, code string) *entity.Employee {
+	t.Helper()
+	emp := entity.Employee{Code: code, FirstName: "พนักงาน", LastName: code}
+	mustCreate(t, db, &emp)
+	return &emp
+}
+
+func latestProcess(t *testing.T, db *gorm.DB, orderID uint) *entity.LaundryProcess {
+	t.Helper()
+	var p entity.LaundryProcess
+	if err := db.Joins("JOIN process_order ON process_order.laundry_process_id = laundry_processes.id").
+		Where("process_order.order_id = ?", orderID).
+		Order("laundry_processes.id desc").
+		First(&p).Error; err != nil {
+		t.Fatalf("latest process of order %d: %v", orderID, err)
+	}
+	return &p
+}
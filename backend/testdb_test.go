@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OnpreeyaMi/project-sa/config"
+	"github.com/OnpreeyaMi/project-sa/controller"
+	"github.com/OnpreeyaMi/project-sa/migrations"
+	"github.com/OnpreeyaMi/project-sa/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ======================================================
+// ฐานข้อมูลสำหรับ integration test
+// - ค่าเริ่มต้น: SQLite in-memory แยกฐานต่อ test
+// - ฐานข้อมูลจริง (ดู docker-compose.test.yml):
+//   TEST_DB_DRIVER=postgres TEST_DB_DSN="host=localhost port=55432 user=sa password=sa dbname=sa_test sslmode=disable" go test ./...
+//   TEST_DB_DRIVER=mysql    TEST_DB_DSN="sa:sa@tcp(localhost:53306)/sa_test" go test ./...
+//   ทุก test ล้าง schema (migrate down จนหมด) แล้ว migrate up ใหม่ จึงห้ามชี้ไปที่ฐานข้อมูลที่ใช้งานจริง
+// ======================================================
+
+var testDBSeq atomic.Int64
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	dir, err := os.MkdirTemp("", "sa-test-")
+	if err != nil {
+		panic(err)
+	}
+	controller.SetMailer(services.NewLocalMailer(dir))
+	controller.SetAttachmentStore(services.NewLocalBlobStore(dir))
+	if err := services.RegisterDomainMetrics(func() *gorm.DB { return config.DB }); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func testDatabaseConfig() config.DatabaseConfig {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("TEST_DB_DRIVER")))
+	if driver == "" || driver == config.DriverSQLite {
+		// ชื่อไม่ซ้ำต่อ test; ฐาน in-memory อยู่เท่าที่ connection ยังเปิด จึงใช้ connection เดียวตลอด
+		return config.DatabaseConfig{
+			Driver:       config.DriverSQLite,
+			DSN:          fmt.Sprintf("file:satest%d?mode=memory&cache=shared", testDBSeq.Add(1)),
+			MaxOpenConns: 1,
+			MaxIdleConns: 1,
+		}
+	}
+	return config.DatabaseConfig{Driver: driver, DSN: os.Getenv("TEST_DB_DSN"), MaxOpenConns: 4, MaxIdleConns: 2}
+}
+
+// openTestDB เปิดฐานข้อมูลว่างที่รัน migration ครบแล้ว และตั้งเป็น config.DB ระหว่าง test
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.OpenDatabase(testDatabaseConfig())
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if db.Dialector.Name() != config.DriverSQLite {
+		for {
+			m, err := migrations.Down(db)
+			if err != nil {
+				t.Fatalf("reset test database: %v", err)
+			}
+			if m == nil {
+				break
+			}
+		}
+	}
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	if err := services.RegisterAuditCallbacks(db, auditedModels...); err != nil {
+		t.Fatalf("audit callbacks: %v", err)
+	}
+
+	// ฐานใหม่ = ผู้ใช้ชุดใหม่ ตัวนับการเข้าสู่ระบบต่อบัญชีจึงเริ่มใหม่ด้วย
+	controller.ResetLoginLimits()
+
+	prev := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = prev
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -1,9 +1,20 @@
 import React from "react";
 import ReactDOM from "react-dom/client";
 import { BrowserRouter } from "react-router-dom";
+import axios from "axios";
 import App from "./App";
 import "./index.css";
 
+// แนบ token ของผู้ที่ล็อกอินให้ทุกคำขอผ่าน axios (endpoint ที่แก้ข้อมูลต้องมี token)
+axios.interceptors.request.use((config) => {
+  const t = localStorage.getItem("token");
+  if (t && !config.headers?.Authorization) {
+    config.headers = config.headers || {};
+    (config.headers as any).Authorization = `Bearer ${t}`;
+  }
+  return config;
+});
+
 ReactDOM.createRoot(document.getElementById("root")!).render(
   <React.StrictMode>
     <BrowserRouter>
@@ -5,6 +5,7 @@ import { Link } from "react-router-dom";
 import { SearchOutlined, FilterOutlined, ReloadOutlined } from "@ant-design/icons";
 import "./StatusUpdate.css";
 import { useEffect } from "react";
+import { fetchAllPages } from "../../services/apiEnvelope";
 
 const StatusUpdate: React.FC = () => {
   const [orders, setOrders] = useState<any[]>([]);
@@ -13,8 +14,7 @@ const StatusUpdate: React.FC = () => {
   const [statusFilter, setStatusFilter] = useState<string | undefined>(undefined);
 
   useEffect(() => {
-    fetch("http://localhost:8000/ordersdetails")
-      .then(res => res.json())
+    fetchAllPages<any>("http://localhost:8000/ordersdetails", (url) => fetch(url).then(res => res.json()))
       .then(data => {
         setOrders(data);
         setLoading(false);
@@ -45,8 +45,7 @@ const StatusUpdate: React.FC = () => {
   }, [orders, searchText, statusFilter]);
 
 useEffect(() => {
-  fetch("http://localhost:8000/ordersdetails")
-    .then(res => res.json())
+  fetchAllPages<any>("http://localhost:8000/ordersdetails", (url) => fetch(url).then(res => res.json()))
     .then(data => {
       setOrders(data);  // จะได้ array ของ order + status
       setLoading(false);
@@ -2,6 +2,7 @@ import React, { useEffect, useMemo, useRef, useState } from "react";
 import { Send, Upload, X, CheckCircle2, AlertCircle } from "lucide-react";
 import CustomerSidebar from "../../component/layout/customer/CusSidebar";
 import { useUser } from "../../hooks/UserContext";
+import { fetchAllPages } from "../../services/apiEnvelope";
 
 type OrderOption = {
   id: number;
@@ -11,10 +12,16 @@ type OrderOption = {
   createdAt?: string;
 };
 
+type CategoryOption = {
+  code: string;
+  name: string;
+};
+
 export type NewComplaintPayload = {
   customerName: string; // แสดงเฉย ๆ
   email?: string;
   orderId?: string; // -> string เพื่อส่ง FormData ง่าย
+  category?: string; // รหัสหมวด -> ใช้กำหนดความเร่งด่วน/ผู้รับผิดชอบ
   subject: string; // -> title
   message: string; // -> description
   attachments?: File[];
@@ -29,11 +36,13 @@ export default function CustomerComplaintPage() {
     customerName: "",
     email: "",
     orderId: "",
+    category: "",
     subject: "",
     message: "",
     attachments: [],
   });
   const [orders, setOrders] = useState<OrderOption[]>([]);
+  const [categories, setCategories] = useState<CategoryOption[]>([]);
   const [submitting, setSubmitting] = useState(false);
   const [successId, setSuccessId] = useState<string | null>(null);
   const [errorMsg, setErrorMsg] = useState<string | null>(null);
@@ -124,6 +133,15 @@ export default function CustomerComplaintPage() {
     // eslint-disable-next-line react-hooks/exhaustive-deps
   }, [customerId, user?.token]);
 
+  // ---- โหลดหมวดคำร้องเรียน ----
+  useEffect(() => {
+    fetchAllPages<any>(`${API_BASE}/complaint-categories`, (url) => fetch(url).then((r) => (r.ok ? r.json() : null)))
+      .then((items) =>
+        setCategories(items.map((c: any) => ({ code: c.Code ?? c.code, name: c.Name ?? c.name })))
+      )
+      .catch((err) => console.error("fetch complaint categories failed", err));
+  }, []);
+
   // ---- validation ----
   const isValid = useMemo(
     () => form.subject.trim().length > 3 && form.message.trim().length > 5,
@@ -154,6 +172,7 @@ export default function CustomerComplaintPage() {
     const res = await fetch(`${apiBase}/complaints/${encodeURIComponent(publicId)}/attachments`, {
       method: "POST",
       body: fd,
+      headers: { Authorization: user?.token ? `Bearer ${user.token}` : "" },
       // อย่าตั้ง Content-Type เอง ให้ browser ใส่ boundary ให้อัตโนมัติ
     });
 
@@ -185,6 +204,7 @@ export default function CustomerComplaintPage() {
       fd1.append("customerId", String(customerId));
       if (form.email) fd1.append("email", form.email);
       if (form.orderId) fd1.append("orderId", form.orderId);
+      if (form.category) fd1.append("category", form.category);
 
       const res1 = await fetch(`${API_BASE}/complaints`, {
         method: "POST",
@@ -210,6 +230,7 @@ export default function CustomerComplaintPage() {
         customerName: "",
         email: "",
         orderId: "",
+        category: "",
         subject: "",
         message: "",
         attachments: [],
@@ -324,6 +345,24 @@ export default function CustomerComplaintPage() {
               </div>
             </div>
 
+            <div className="grid grid-cols-1 md:grid-cols-3 gap-4">
+              <div>
+                <label className="block text-xl font-medium text-gray-700">ประเภทปัญหา</label>
+                <select
+                  className="mt-1 w-full rounded-xl border px-3 py-2 focus:ring-2 focus:ring-blue-600 focus:outline-none"
+                  value={form.category}
+                  onChange={(e) => setForm({ ...form, category: e.target.value })}
+                >
+                  <option value="">— ไม่ระบุ —</option>
+                  {categories.map((c) => (
+                    <option key={c.code} value={c.code}>
+                      {c.name}
+                    </option>
+                  ))}
+                </select>
+              </div>
+            </div>
+
             <div className="grid grid-cols-1 md:grid-cols-3 gap-4">
               <div className="md:col-span-3">
                 <label className="block text-xl font-medium text-gray-700">รายละเอียด *</label>
@@ -2,7 +2,7 @@
 import React, { useEffect, useMemo, useState } from "react";
 import { Search, Eye, Send, Loader2, X, Maximize2 } from "lucide-react";
 import EmployeeSidebar from "../../component/layout/employee/empSidebar";
-import { useUser } from "../../hooks/UserContext";
+import type { ListResponse } from "../../services/apiEnvelope";
 
 const API_BASE = import.meta.env.VITE_API_BASE_URL || "http://localhost:8000";
 
@@ -48,40 +48,36 @@ function timeAgo(iso: string) {
 }
 
 // ---------- API ----------
+// /employee/* ต้องใช้ token พนักงาน (ผู้ตอบ/ผู้เปลี่ยนสถานะมาจาก token)
+function authHeaders(): Record<string, string> {
+  const token = localStorage.getItem("token");
+  return token ? { Authorization: `Bearer ${token}` } : {};
+}
+
 async function apiListComplaints(params: {
   q?: string; status?: "all" | Status; page?: number; pageSize?: number;
-}): Promise<{ items: ComplaintRow[]; total: number; page: number; pageSize: number }> {
+}): Promise<ListResponse<ComplaintRow>> {
   const url = new URL("/employee/complaints", API_BASE);
   if (params.q) url.searchParams.set("q", params.q);
   url.searchParams.set("status", params.status ?? "all");
   url.searchParams.set("page", String(params.page ?? 1));
   url.searchParams.set("pageSize", String(params.pageSize ?? 8));
-  const res = await fetch(url.toString());
+  const res = await fetch(url.toString(), { headers: authHeaders() });
   if (!res.ok) throw new Error("โหลดรายการคำร้องเรียนไม่สำเร็จ");
   return res.json();
 }
 
 async function apiGetComplaintDetail(publicId: string): Promise<ComplaintDetail> {
-  const res = await fetch(`${API_BASE}/employee/complaints/${publicId}`);
+  const res = await fetch(`${API_BASE}/employee/complaints/${publicId}`, { headers: authHeaders() });
   if (!res.ok) throw new Error("โหลดรายละเอียดคำร้องเรียนไม่สำเร็จ");
   return res.json();
 }
 
-function getEmpIdFromAnywhere(user: ReturnType<typeof useUser>["user"]) {
-  const fromCtx = user?.employeeId ? Number(user.employeeId) : 0;
-  const fromLS = Number(localStorage.getItem("employeeId") || 0);
-  return fromCtx || fromLS || 0;
-}
-
-async function apiAddReply(publicId: string, text: string, newStatus: Status | undefined, userToken?: string, empId?: number) {
-  if (!empId) throw new Error("ไม่พบรหัสพนักงาน (empId)");
+async function apiAddReply(publicId: string, text: string, newStatus: Status | undefined) {
   const res = await fetch(`${API_BASE}/employee/complaints/${publicId}/replies`, {
     method: "POST",
-    headers: {
-      "Content-Type": "application/json",
-      ...(userToken ? { Authorization: `Bearer ${userToken}` } : {}),
-    },
-    body: JSON.stringify({ empId, text, newStatus: newStatus ?? null }),
+    headers: { "Content-Type": "application/json", ...authHeaders() },
+    body: JSON.stringify({ text, newStatus: newStatus ?? null }),
   });
   if (!res.ok) throw new Error("บันทึกการตอบกลับไม่สำเร็จ");
   return res.json() as Promise<{ ok: true; reply: ReplyItem }>;
@@ -90,7 +86,7 @@ async function apiAddReply(publicId: string, text: string, newStatus: Status | u
 async function apiSetStatus(publicId: string, status: Status) {
   const res = await fetch(`${API_BASE}/employee/complaints/${publicId}/status`, {
     method: "PATCH",
-    headers: { "Content-Type": "application/json" },
+    headers: { "Content-Type": "application/json", ...authHeaders() },
     body: JSON.stringify({ status }),
   });
   if (!res.ok) throw new Error("อัปเดตสถานะไม่สำเร็จ");
@@ -238,19 +234,11 @@ function ReplyDrawer({
   open: boolean; onClose: () => void; item: ComplaintRow | null;
   onStatusChanged?: (s: Status) => void;
 }) {
-  const { user, refreshEmployee } = useUser();
   const [loading, setLoading] = useState(false);
   const [detail, setDetail] = useState<ComplaintDetail | null>(null);
   const [text, setText] = useState("");
   const [chooseStatus, setChooseStatus] = useState<Status | "">("");
 
-  useEffect(() => {
-    if (user?.role === "employee") {
-      const empId = getEmpIdFromAnywhere(user);
-      if (!empId) { void refreshEmployee(); }
-    }
-  }, [user, refreshEmployee]);
-
   useEffect(() => {
     let on = true;
     if (open && item) {
@@ -270,17 +258,7 @@ function ReplyDrawer({
     if (!text.trim()) return;
     try {
       setLoading(true);
-      const empId = getEmpIdFromAnywhere(user);
-      if (!empId) {
-        await refreshEmployee();
-      }
-      const finalEmpId = getEmpIdFromAnywhere(user);
-      if (!finalEmpId) {
-        alert("ไม่พบรหัสพนักงานในระบบ กรุณาลองใหม่หรือลงชื่อเข้าใช้ใหม่");
-        return;
-      }
-
-      await apiAddReply(item.id, text.trim(), chooseStatus || undefined, user?.token, finalEmpId);
+      await apiAddReply(item.id, text.trim(), chooseStatus || undefined);
       setText("");
       if (chooseStatus) onStatusChanged?.(chooseStatus);
       const d = await apiGetComplaintDetail(item.id);
@@ -399,7 +377,7 @@ export default function ComplaintReplyPage() {
     let on = true;
     setLoading(true);
     apiListComplaints({ q: query, status, page, pageSize })
-      .then((d) => { if (on) { setItems(d.items); setTotal(d.total); } })
+      .then((d) => { if (on) { setItems(d.data); setTotal(d.meta.total); } })
       .catch(() => {})
       .finally(() => setLoading(false));
     return () => { on = false; };
@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { Form, Input, Select, Button, Modal, Table as AntTable, Space, Popconfirm, message } from 'antd';
 import AdminSidebar from '../../component/layout/admin/AdminSidebar';
 import axios from 'axios';
+import { fetchAllPages } from "../../services/apiEnvelope";
 
 interface Customer {
     ID: number;
@@ -42,8 +43,7 @@ const CustomerManagement: React.FC = () => {
     // ----------------- FETCH -----------------
     const fetchCustomers = async () => {
         try {
-            const response = await axios.get(API_URL);
-            setCustomers(response.data); // backend return array directly
+            setCustomers(await fetchAllPages<Customer>(API_URL, async (url) => (await axios.get(url)).data));
         } catch (err) {
             console.error(err);
             message.error('ไม่สามารถดึงข้อมูลลูกค้าได้');
@@ -131,17 +131,15 @@ const CustomerManagement: React.FC = () => {
         setOrderHistoryLoading(true);
         setOrderHistoryModalVisible(true);
         try {
-            const res = await axios.get(`http://localhost:8000/order-histories?customerId=${customer.ID}`);
+            const histories = await fetchAllPages<any>(`http://localhost:8000/order-histories?customerId=${customer.ID}`, async (url) => (await axios.get(url)).data);
             // กรองเฉพาะออเดอร์ที่ customerId ตรงกับที่เลือก
-            const filteredOrders = Array.isArray(res.data)
-                ? res.data.filter((item: any) => {
+            const filteredOrders = histories.filter((item: any) => {
                     // กรณี Order อยู่ใน item หรือ item เองมี CustomerID
                     if (item.Order && item.Order.CustomerID) {
                         return item.Order.CustomerID === customer.ID;
                     }
                     return item.CustomerID === customer.ID;
-                })
-                : [];
+                });
             setOrderHistory(filteredOrders);
         } catch (err) {
             message.error("ไม่สามารถดึงประวัติออเดอร์ได้");
@@ -156,11 +154,9 @@ const CustomerManagement: React.FC = () => {
         setAddressLoading(true);
         setAddressModalVisible(true);
         try {
-            const res = await axios.get(`http://localhost:8000/addresses?customerId=${customer.ID}`);
+            const addresses = await fetchAllPages<any>(`http://localhost:8000/addresses?customerId=${customer.ID}`, async (url) => (await axios.get(url)).data);
             // กรองเฉพาะ address ที่ CustomerID ตรงกับลูกค้าที่เลือก
-            const filteredAddresses = Array.isArray(res.data)
-                ? res.data.filter((addr: any) => addr.CustomerID === customer.ID)
-                : [];
+            const filteredAddresses = addresses.filter((addr: any) => addr.CustomerID === customer.ID);
             setAddressList(filteredAddresses);
         } catch (err) {
             message.error("ไม่สามารถดึงข้อมูลที่อยู่ได้");
@@ -15,6 +15,7 @@ import PromotionSelector, { type Promo as PromoSelectorType } from "./PromotionD
 import PaymentModal from "./PaymentModal";
 import PaymentSuccessModal from "./slipDemo";
 import CustomerSidebar from "../../../component/layout/customer/CusSidebar";
+import { fetchAllPages } from "../../../services/apiEnvelope";
 
 // ---------- Utilities ----------
 const toBaht = (n: number) =>
@@ -97,7 +98,6 @@ export default function Payment() {
   const [openQR, setOpenQR] = useState(false);
   const promptPayTarget = "0645067561"; // your shop PromptPay target
 
-  const [savingCash, setSavingCash] = useState(false);
 
   // Fetch checkout + promotions
   useEffect(() => {
@@ -133,9 +133,11 @@ export default function Payment() {
         setTotalAmount(apiSubtotal || sumFromItems);
 
         // 2) Promotions
-        const promoRes = await fetch(`${BASE}/promotions`);
-        if (!promoRes.ok) throw new Error(`Promotions HTTP ${promoRes.status}`);
-        const rawPromos: any[] = await promoRes.json();
+        const rawPromos = await fetchAllPages<any>(`${BASE}/promotions`, async (url) => {
+          const promoRes = await fetch(url);
+          if (!promoRes.ok) throw new Error(`Promotions HTTP ${promoRes.status}`);
+          return promoRes.json();
+        });
         if (!mounted) return;
 
         // Map raw to Promo shape used by UI
@@ -220,43 +222,17 @@ export default function Payment() {
     return Math.min(cut, totalAmount);
   }, [totalAmount, selectedPromo]);
 
-  async function handlePayCash() {
+  // เงินสด: ลูกค้าชำระกับพนักงานตอนรับ-ส่งผ้า พนักงานเป็นผู้บันทึกการรับเงิน (POST /payments/cash ใช้ token พนักงาน)
+  function handlePayCash() {
     if (!orderId) {
       alert("ไม่พบหมายเลขคำสั่งซื้อ");
       return;
     }
-    try {
-      setSavingCash(true);
-      const res = await fetch(`${BASE}/payments/cash`, {
-        method: "POST",
-        headers: { "Content-Type": "application/json" },
-        body: JSON.stringify({ 
-          order_id: orderId ,
-          amount: finalTotal,}), // ส่งแค่ order_id ตามที่ออกแบบไว้
-      });
-
-      const data = await res.json().catch(() => ({}));
-      if (!res.ok) {
-        alert("ชำระเงินสดล้มเหลว: " + (data?.error ?? res.status));
-        return;
-      }
-
-      // บันทึกสำเร็จ → เปิด Success Modal เดิมที่คุณใช้กับ PromptPay
-      setPaid(true);
-      setPaidAt(new Date());
-
-      // ถ้าต้องรีโหลด checkout เพื่อให้สถานะหน้าอัปเดตจาก DB จริง ๆ
-      // คุณอาจเรียก fetch อีกครั้ง หรือ setPaid/setPaymentId/state อื่น ๆ ที่จำเป็น
-      // (ข้ามได้ถ้ายังไม่ต้องการ)
-    } catch (err) {
-      console.error(err);
-      alert("เชื่อมต่อไม่สำเร็จ");
-    } finally {
-      setSavingCash(false);
-    }
+    alert(`กรุณาชำระเงินสด ${finalTotal} บาท กับพนักงานเมื่อรับ-ส่งผ้า พนักงานจะบันทึกการรับเงินให้`);
   }
 
 
+
   const finalTotal = useMemo(() => Math.max(0, Math.round((totalAmount - discount) * 100) / 100), [totalAmount, discount]);
 
   if (loading) return <div className="p-6">กำลังโหลด...</div>;
@@ -342,11 +318,11 @@ export default function Payment() {
 
             <button className="w-[320px] bg-gray-100 text-gray-700 py-2 rounded-xl border hover:bg-gray-200"
               onClick={handlePayCash}              
-              disabled={savingCash || !orderId}
+              disabled={!orderId}
             >
               <div className="flex items-center justify-center gap-2">
                 <BsCashCoin size={24} />
-                <span>{savingCash ? "กำลังบันทึก..." : "ชำระเงินสด"}</span>
+                <span>ชำระเงินสด</span>
               </div>
             </button>
           </div>
@@ -19,6 +19,7 @@ import dayjs from "dayjs";
 import axios from "axios";
 import AdminSidebar from "../../component/layout/admin/AdminSidebar";
 import { useNavigate } from "react-router-dom";
+import { fetchAllPages } from "../../services/apiEnvelope";
 
 interface PromotionCondition {
   id?: number;
@@ -53,8 +54,7 @@ const PromotionManagement: React.FC = () => {
   // ดึงข้อมูลโปรโมชั่น
   const fetchPromotions = async () => {
     try {
-      const res = await axios.get("http://localhost:8000/promotions");
-      setPromotions(res.data);
+      setPromotions(await fetchAllPages<Promotion>("http://localhost:8000/promotions", async (url) => (await axios.get(url)).data));
     } catch (err) {
       message.error("โหลดข้อมูลโปรโมชั่นล้มเหลว");
     }
@@ -2,6 +2,7 @@ import React, { useEffect, useState } from "react";
 import { Table, Input, DatePicker, Select, Tag, message } from "antd";
 import axios from "axios";
 import AdminSidebar from "../../component/layout/admin/AdminSidebar";
+import { fetchAllPages } from "../../services/apiEnvelope";
 
 interface PromotionUsage {
   ID: number;
@@ -28,8 +29,7 @@ const PromotionUsageHistory: React.FC = () => {
 
   const fetchUsages = async () => {
     try {
-      const res = await axios.get("http://localhost:8000/promotion-usages");
-      setUsages(res.data);
+      setUsages(await fetchAllPages<PromotionUsage>("http://localhost:8000/promotion-usages", async (url) => (await axios.get(url)).data));
     } catch (err) {
       message.error("โหลดข้อมูลประวัติการใช้โปรโมชั่นล้มเหลว");
     }
@@ -37,8 +37,7 @@ const PromotionUsageHistory: React.FC = () => {
 
   const fetchPromotions = async () => {
     try {
-      const res = await axios.get("http://localhost:8000/promotions");
-      setPromotions(res.data);
+      setPromotions(await fetchAllPages<{ PromotionName: string }>("http://localhost:8000/promotions", async (url) => (await axios.get(url)).data));
     } catch (err) {
       // ไม่ต้องแจ้ง error
     }
@@ -139,7 +139,6 @@ const StockAdminPage: React.FC = () => {
         softener: 2,
       };
       const categoryId = detergentTypeMap[values.type] || 1;
-      const userId = 1; // สมมุติ admin id
       const image = uploadedImage || null;
       if (exist) {
         await updateDetergentStock(exist.key, values.quantity);
@@ -150,7 +149,6 @@ const StockAdminPage: React.FC = () => {
             Name: values.name,
             Type: values.type,
             InStock: values.quantity,
-            UserID: userId,
             CategoryID: categoryId,
             Image: image || "",
           },
@@ -158,7 +156,6 @@ const StockAdminPage: React.FC = () => {
             Quantity: values.quantity,
             Price: values.price,
             Supplier: values.supplier || "",
-            UserID: userId,
             Image: image || "",
           },
         });
@@ -190,9 +187,7 @@ const StockAdminPage: React.FC = () => {
       return;
     }
     try {
-      // สมมุติ user_id = 1 (ควรดึงจาก auth จริง)
       const payload: any = {
-        user_id: 1,
         detergent_id: item.key,
         quantity_used: values.quantity,
         reason: values.reason,
@@ -18,7 +18,6 @@ import {
 import { useNavigate } from "react-router-dom";
 import EmployeeSidebar from "../../../component/layout/employee/empSidebar";
 import { TbBackground } from "react-icons/tb";
-import { useUser } from "../../../hooks/UserContext";
 
 const { Title, Text } = Typography;
 const { Option } = Select;
@@ -41,7 +40,6 @@ const StockEmployeePage: React.FC = () => {
   const [form] = Form.useForm();
   const [useItemForm] = Form.useForm();
   const navigate = useNavigate();
-  const { user } = useUser();
 
   useEffect(() => {
     fetchStockData();
@@ -97,7 +95,6 @@ const StockEmployeePage: React.FC = () => {
     }
     try {
       const payload: any = {
-        user_id: user?.id, // ส่ง user_id ของคนที่ login
         detergent_id: item.key,
         quantity_used: values.quantity,
         reason: values.reason
@@ -1,5 +1,6 @@
 // src/services/Employee.ts
 import axios from "axios";
+import { fetchAllPages } from "./apiEnvelope";
 
 export type EmpStatus = "active" | "inactive" | "onleave";
 export type EmpGender = "male" | "female" | "other";
@@ -71,8 +72,7 @@ const toApiPayload = (p: EmployeeUpsert) => {
 
 export const EmployeeService = {
   async list(): Promise<Employee[]> {
-    const res = await api.get("/employees");
-    return Array.isArray(res.data) ? res.data : [];
+    return fetchAllPages<Employee>("/employees", async (url) => (await api.get(url)).data);
   },
 
   async get(id: number): Promise<Employee> {
@@ -6,26 +6,33 @@ import type {
   HistoryEntry,
   OrderSummary,
 } from "../interfaces/LaundryCheck/types";
+import { fetchAllPages } from "./apiEnvelope";
 
 const API_BASE = import.meta?.env?.VITE_API_BASE || "http://localhost:8000";
 
 // ===== Lookups =====
 export async function FetchClothTypes(): Promise<ClothType[]> {
-  const res = await fetch(`${API_BASE}/clothtypes`, { cache: "no-store" });
-  if (!res.ok) throw new Error("โหลดประเภทผ้าไม่สำเร็จ");
-  return res.json();
+  return fetchAllPages<ClothType>(`${API_BASE}/clothtypes`, async (url) => {
+    const res = await fetch(url, { cache: "no-store" });
+    if (!res.ok) throw new Error("โหลดประเภทผ้าไม่สำเร็จ");
+    return res.json();
+  });
 }
 export async function FetchServiceTypes(): Promise<ServiceType[]> {
-  const res = await fetch(`${API_BASE}/servicetypes`, { cache: "no-store" });
-  if (!res.ok) throw new Error("โหลดบริการไม่สำเร็จ");
-  return res.json();
+  return fetchAllPages<ServiceType>(`${API_BASE}/servicetypes`, async (url) => {
+    const res = await fetch(url, { cache: "no-store" });
+    if (!res.ok) throw new Error("โหลดบริการไม่สำเร็จ");
+    return res.json();
+  });
 }
 export async function FetchCustomers(): Promise<
   { ID: number; Name: string; Phone: string; AddressID?: number; Address?: string; Note?: string }[]
 > {
-  const res = await fetch(`${API_BASE}/laundry-check/customers`, { cache: "no-store" });
-  if (!res.ok) throw new Error("โหลดลูกค้าไม่สำเร็จ");
-  return res.json();
+  return fetchAllPages(`${API_BASE}/laundry-check/customers`, async (url) => {
+    const res = await fetch(url, { cache: "no-store" });
+    if (!res.ok) throw new Error("โหลดลูกค้าไม่สำเร็จ");
+    return res.json();
+  });
 }
 
 // ===== พนักงาน =====
@@ -82,18 +89,22 @@ export async function FetchOrderDetail(orderId: number): Promise<OrderDetail> {
   return res.json();
 }
 export async function FetchOrderHistory(orderId: number): Promise<HistoryEntry[]> {
-  const res = await fetch(`${API_BASE}/laundry-check/orders/${orderId}/history?t=${Date.now()}`, {
-    cache: "no-store",
-    headers: { "Cache-Control": "no-store, max-age=0, must-revalidate" },
+  return fetchAllPages<HistoryEntry>(`${API_BASE}/laundry-check/orders/${orderId}/history?t=${Date.now()}`, async (url) => {
+    const res = await fetch(url, {
+      cache: "no-store",
+      headers: { "Cache-Control": "no-store, max-age=0, must-revalidate" },
+    });
+    if (!res.ok) throw new Error("โหลดประวัติไม่สำเร็จ");
+    return res.json();
   });
-  if (!res.ok) throw new Error("โหลดประวัติไม่สำเร็จ");
-  return res.json();
 }
 export async function FetchOrders(): Promise<OrderSummary[]> {
-  const res = await fetch(`${API_BASE}/laundry-check/orders?t=${Date.now()}`, {
-    cache: "no-store",
-    headers: { "Cache-Control": "no-store, max-age=0, must-revalidate" },
+  return fetchAllPages<OrderSummary>(`${API_BASE}/laundry-check/orders?t=${Date.now()}`, async (url) => {
+    const res = await fetch(url, {
+      cache: "no-store",
+      headers: { "Cache-Control": "no-store, max-age=0, must-revalidate" },
+    });
+    if (!res.ok) throw new Error("โหลดรายการออเดอร์ไม่สำเร็จ");
+    return res.json();
   });
-  if (!res.ok) throw new Error("โหลดรายการออเดอร์ไม่สำเร็จ");
-  return res.json();
 }
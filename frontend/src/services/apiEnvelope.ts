@@ -0,0 +1,50 @@
+// รูปแบบรายการจาก backend: { data: [...], meta: { page, pageSize, total, totalPages } }
+// รายการยาวถูกแบ่งหน้าเสมอ (ดู fetchAllPages)
+// รูปแบบ error: { error: "ข้อความ", code: "order_not_found", details?: [{ field, code, message }] }
+
+export type PageMeta = {
+  page: number;
+  pageSize: number;
+  total: number;
+  totalPages: number;
+};
+
+export type ListResponse<T> = {
+  data: T[];
+  meta: PageMeta;
+};
+
+export type FieldError = { field: string; code: string; message: string };
+
+export type ApiError = {
+  error: string;
+  code: string;
+  details?: FieldError[];
+  requestId?: string;
+};
+
+// ดึง array ออกจาก envelope ของรายการ (ไม่มีข้อมูล = [])
+export function listData<T>(body: unknown): T[] {
+  const data = (body as { data?: unknown } | null)?.data;
+  return Array.isArray(data) ? (data as T[]) : [];
+}
+
+// backend ส่งรายการได้ไม่เกินครั้งละ MAX_PAGE_SIZE (api.MaxPageSize) ไม่ส่ง pageSize ก็ได้ไม่เกินนี้
+export const MAX_PAGE_SIZE = 200;
+
+// เติม ?page=&pageSize= ต่อท้าย url
+export function pageURL(url: string, page: number, pageSize = MAX_PAGE_SIZE): string {
+  return `${url}${url.includes("?") ? "&" : "?"}page=${page}&pageSize=${pageSize}`;
+}
+
+// ดึงรายการทุกหน้า: ขอทีละ MAX_PAGE_SIZE จนครบ meta.totalPages
+// load รับ url ของแต่ละหน้าแล้วคืน body (JSON) ของคำตอบ
+export async function fetchAllPages<T>(url: string, load: (pageUrl: string) => Promise<unknown>): Promise<T[]> {
+  const items: T[] = [];
+  for (let page = 1; ; page++) {
+    const body = await load(pageURL(url, page));
+    items.push(...listData<T>(body));
+    const totalPages = (body as { meta?: PageMeta } | null)?.meta?.totalPages ?? 1;
+    if (page >= totalPages) return items;
+  }
+}
@@ -1,4 +1,5 @@
 // src/api/complaints.ts
+import type { ListResponse } from "./apiEnvelope";
 const API_BASE = import.meta.env.VITE_API_BASE_URL || "http://localhost:8000";
 
 export type ComplaintItem = {
@@ -15,6 +16,12 @@ export type ComplaintItem = {
 
 export type ReplyItem = { at: string; by: string; text: string; };
 
+// /employee/* ต้องใช้ token พนักงาน (ผู้ตอบ/ผู้เปลี่ยนสถานะมาจาก token)
+function authHeaders(): Record<string, string> {
+  const token = localStorage.getItem("token");
+  return token ? { Authorization: `Bearer ${token}` } : {};
+}
+
 export async function listComplaints(params: {
   q?: string; status?: "all" | "new" | "in_progress" | "resolved";
   page?: number; pageSize?: number;
@@ -24,13 +31,13 @@ export async function listComplaints(params: {
   url.searchParams.set("status", params.status ?? "all");
   url.searchParams.set("page", String(params.page ?? 1));
   url.searchParams.set("pageSize", String(params.pageSize ?? 8));
-  const res = await fetch(url);
+  const res = await fetch(url, { headers: authHeaders() });
   if (!res.ok) throw new Error("โหลดรายการคำร้องเรียนไม่สำเร็จ");
-  return res.json() as Promise<{ items: ComplaintItem[]; total: number; page: number; pageSize: number }>;
+  return res.json() as Promise<ListResponse<ComplaintItem>>;
 }
 
 export async function getComplaintDetail(publicId: string) {
-  const res = await fetch(`${API_BASE}/employee/complaints/${publicId}`);
+  const res = await fetch(`${API_BASE}/employee/complaints/${publicId}`, { headers: authHeaders() });
   if (!res.ok) throw new Error("โหลดรายละเอียดคำร้องเรียนไม่สำเร็จ");
   return res.json() as Promise<{
     id: string; orderId?: string; customerName: string; email?: string;
@@ -41,13 +48,12 @@ export async function getComplaintDetail(publicId: string) {
 }
 
 export async function addReply(publicId: string, payload: {
-  empId: number; text: string; newStatus?: "new"|"in_progress"|"resolved";
+  text: string; newStatus?: "new"|"in_progress"|"resolved";
 }) {
   const res = await fetch(`${API_BASE}/employee/complaints/${publicId}/replies`, {
     method: "POST",
-    headers: { "Content-Type": "application/json" },
+    headers: { "Content-Type": "application/json", ...authHeaders() },
     body: JSON.stringify({
-      empId: payload.empId,
       text: payload.text,
       newStatus: payload.newStatus ?? null
     }),
@@ -59,7 +65,7 @@ export async function addReply(publicId: string, payload: {
 export async function setStatus(publicId: string, status: "new"|"in_progress"|"resolved") {
   const res = await fetch(`${API_BASE}/employee/complaints/${publicId}/status`, {
     method: "PATCH",
-    headers: { "Content-Type": "application/json" },
+    headers: { "Content-Type": "application/json", ...authHeaders() },
     body: JSON.stringify({ status }),
   });
   if (!res.ok) throw new Error("อัปเดตสถานะไม่สำเร็จ");
@@ -1,27 +1,33 @@
 import type{ OrderService ,ServiceType, Detergent, OrderHistory} from "../interfaces/types";
 import axios from "axios";
+import { fetchAllPages } from "./apiEnvelope";
 const API_BASE = "http://localhost:8000"; // ปรับตาม backend ของคุณ
 
 // ดึงรายการ ServiceType จาก backend
 export const fetchServiceTypes = async (): Promise<ServiceType[]> => {
-  const res = await fetch(`${API_BASE}/servicetypes`);
-  if (!res.ok) throw new Error("ไม่สามารถดึง ServiceType ได้");
-  return res.json();
+  return fetchAllPages<ServiceType>(`${API_BASE}/servicetypes`, async (url) => {
+    const res = await fetch(url);
+    if (!res.ok) throw new Error("ไม่สามารถดึง ServiceType ได้");
+    return res.json();
+  });
 };
 
 // ดึงรายการ Detergent จาก backend
 export const fetchDetergents = async (): Promise<Detergent[]> => {
-  const res = await fetch(`${API_BASE}/detergents`);
-  if (!res.ok) throw new Error("ไม่สามารถดึง Detergents ได้");
-  return res.json();
+  return fetchAllPages<Detergent>(`${API_BASE}/detergents`, async (url) => {
+    const res = await fetch(url);
+    if (!res.ok) throw new Error("ไม่สามารถดึง Detergents ได้");
+    return res.json();
+  });
 };
 
 // ดึงรายการ Detergent ตามประเภทจาก backend
 export const fetchDetergentsByType = async (Type: string): Promise<Detergent[]> => {
-  const res = await fetch(`http://localhost:8000/detergents/type/${Type}`);
-  if (!res.ok) throw new Error("ไม่สามารถดึง Detergents ตามประเภทได้");
-  const result = await res.json();
-  return result.data || [];
+  return fetchAllPages<Detergent>(`http://localhost:8000/detergents/type/${Type}`, async (url) => {
+    const res = await fetch(url);
+    if (!res.ok) throw new Error("ไม่สามารถดึง Detergents ตามประเภทได้");
+    return res.json();
+  });
 };
 
 // สร้างออเดอร์ใหม่
@@ -43,8 +49,7 @@ export const createOrder = async (orderData: OrderService) => {
 // ดึงประวัติการสั่งซื้อทั้งหมด
 export const fetchOrderHistories = async (): Promise<OrderHistory[]> => {
   try {
-    const response = await axios.get(`${API_BASE}/order-histories`);
-    return response.data;
+    return await fetchAllPages<OrderHistory>(`${API_BASE}/order-histories`, async (url) => (await axios.get(url)).data);
   } catch (error) {
     console.error("Error fetching order histories:", error);
     throw error;
@@ -65,8 +70,7 @@ export const fetchCustomerNameById = async (customerId: number) => {
 // ดึงที่อยู่ของลูกค้าตาม ID
 export const fetchAddresses = async (customerId: number) => {
   try {
-    const response = await axios.get(`${API_BASE}/addresses?customer_id=${customerId}`);
-    return response.data;
+    return await fetchAllPages<any>(`${API_BASE}/addresses?customer_id=${customerId}`, async (url) => (await axios.get(url)).data);
   } catch (error) {
     console.error("Error fetching addresses:", error);
     throw error;
@@ -1,4 +1,5 @@
 // src/services/orderdetailService.ts
+import { fetchAllPages } from "./apiEnvelope";
 export interface Machine {
   ID: number;
   Machine_type: string;
@@ -46,16 +47,20 @@ export const orderdeailService = {
     return res.json();
   },
   getProcessesByOrder: async (orderId: string) => {
-    const res = await fetch(`${API_BASE}/process/${orderId}/order`);
-    if (!res.ok) throw new Error("Failed to fetch processes by order");
-    return res.json();
+    return fetchAllPages<any>(`${API_BASE}/process/${orderId}/order`, async (url) => {
+      const res = await fetch(url);
+      if (!res.ok) throw new Error("Failed to fetch processes by order");
+      return res.json();
+    });
 
   },
   // ดึงเครื่องซัก/อบทั้งหมด
   getMachines: async (): Promise<Machine[]> => {
-    const res = await fetch(`${API_BASE}/machines`);
-    if (!res.ok) throw new Error("Failed to fetch machines");
-    return res.json();
+    return fetchAllPages<Machine>(`${API_BASE}/machines`, async (url) => {
+      const res = await fetch(url);
+      if (!res.ok) throw new Error("Failed to fetch machines");
+      return res.json();
+    });
   },
 
   // บันทึกเครื่องซัก/อบสำหรับ LaundryProcess
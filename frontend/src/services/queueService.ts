@@ -1,3 +1,4 @@
+import { fetchAllPages } from "./apiEnvelope";
 const API_BASE = "http://localhost:8000"; // หรือเปลี่ยนเป็น BASE URL ของ backend
 export interface Queue {
   ID: number;
@@ -39,9 +40,11 @@ export interface QueueHistory {
 export const queueService = {
   // ดึงคิว pickup/delivery
   getQueues: async (type: "pickup" | "delivery"): Promise<Queue[]> => {
-    const res = await fetch(`${API_BASE}/queues?type=${type}`);
-    if (!res.ok) throw new Error("Failed to fetch queues");
-    return res.json();
+    return fetchAllPages<Queue>(`${API_BASE}/queues?type=${type}`, async (url) => {
+      const res = await fetch(url);
+      if (!res.ok) throw new Error("Failed to fetch queues");
+      return res.json();
+    });
   },
 
   // พนักงานกดรับคิว
@@ -110,16 +113,20 @@ export const queueService = {
 
   // ดึง TimeSlot ทั้งหมด (optionally filter by type)
   getTimeSlots: async (type?: "pickup" | "delivery"): Promise<TimeSlot[]> => {
-    let url = `${API_BASE}/timeslots`;
-    if (type) url += `?type=${type}`;
-    const res = await fetch(url);
-    if (!res.ok) throw new Error("Failed to fetch timeslots");
-    return res.json();
+    let base = `${API_BASE}/timeslots`;
+    if (type) base += `?type=${type}`;
+    return fetchAllPages<TimeSlot>(base, async (url) => {
+      const res = await fetch(url);
+      if (!res.ok) throw new Error("Failed to fetch timeslots");
+      return res.json();
+    });
   },
   // ดึงประวัติคิวที่เสร็จแล้ว
   getQueueHistories: async (): Promise<QueueHistory[]> => {
-    const res = await fetch(`${API_BASE}/queue_histories`);
-    if (!res.ok) throw new Error("Failed to fetch queue histories");
-    return res.json();
+    return fetchAllPages<QueueHistory>(`${API_BASE}/queue_histories`, async (url) => {
+      const res = await fetch(url);
+      if (!res.ok) throw new Error("Failed to fetch queue histories");
+      return res.json();
+    });
   },
 };
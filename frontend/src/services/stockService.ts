@@ -5,7 +5,6 @@ export interface Detergent {
   Name: string;
   Type: string;
   InStock: number;
-  UserID: number;
   CategoryID: number;
   Image: string;
 }
@@ -14,7 +13,6 @@ export interface PurchaseDetergent {
   Quantity: number;
   Price: number;
   Supplier: string;
-  UserID: number;
   Image: string;
 }
 
@@ -47,14 +45,13 @@ export async function getDetergentUsageHistory() {
   return response.data;
 }
 
-export async function useDetergent({ user_id, detergent_id, quantity_used, reason }: {
-  user_id: number;
+// ผู้ใช้ที่บันทึกคือเจ้าของ token (backend อ่านจาก token)
+export async function useDetergent({ detergent_id, quantity_used, reason }: {
   detergent_id: number;
   quantity_used: number;
   reason: string;
 }) {
   const response = await axios.post(`${API_BASE}/detergents/use`, {
-    user_id,
     detergent_id,
     quantity_used,
     reason